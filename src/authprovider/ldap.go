@@ -0,0 +1,169 @@
+package authprovider
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	"gopkg.in/ldap.v2"
+)
+
+// LDAPAuthProvider binds as the user on the LDAP server and provisions the user in the users collection
+type LDAPAuthProvider struct {
+	config.LDAPConfig
+}
+
+// Name returns the name of the provider
+func (l LDAPAuthProvider) Name() string {
+	return LDAPProviderName
+}
+
+// Authenticate binds with the credential and creates or updates the vortex user on success
+func (l LDAPAuthProvider) Authenticate(session *mongo.Session, credential entity.LoginCredential) (entity.User, bool, error) {
+	// an empty password is an unauthenticated bind which always succeeds
+	if credential.Password == "" {
+		return entity.User{}, false, nil
+	}
+
+	conn, err := l.dial()
+	if err != nil {
+		return entity.User{}, false, err
+	}
+	defer conn.Close()
+
+	if err := conn.Bind(l.BindDN, l.BindPassword); err != nil {
+		return entity.User{}, false, fmt.Errorf("Failed to bind the service account: %v", err)
+	}
+
+	entry, err := l.findUser(conn, credential.Username)
+	if err != nil || entry == nil {
+		return entity.User{}, false, err
+	}
+
+	if err := conn.Bind(entry.DN, credential.Password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return entity.User{}, false, nil
+		}
+		return entity.User{}, false, err
+	}
+
+	// search the groups with the service account again
+	if err := conn.Bind(l.BindDN, l.BindPassword); err != nil {
+		return entity.User{}, false, fmt.Errorf("Failed to bind the service account: %v", err)
+	}
+	role, err := l.findRole(conn, entry.DN)
+	if err != nil {
+		return entity.User{}, false, err
+	}
+	if role == "" {
+		return entity.User{}, false, nil
+	}
+
+	user, err := l.provision(session, entry, role)
+	if err != nil {
+		return entity.User{}, false, err
+	}
+	return user, true, nil
+}
+
+func (l LDAPAuthProvider) dial() (*ldap.Conn, error) {
+	host, _, err := net.SplitHostPort(l.Addr)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{ServerName: host, InsecureSkipVerify: l.InsecureSkipVerify}
+
+	if l.UseTLS {
+		return ldap.DialTLS("tcp", l.Addr, tlsConfig)
+	}
+	conn, err := ldap.Dial("tcp", l.Addr)
+	if err != nil {
+		return nil, err
+	}
+	if l.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// findUser returns the entry of the username, or nil if the directory doesn't have exactly one
+func (l LDAPAuthProvider) findUser(conn *ldap.Conn, username string) (*ldap.Entry, error) {
+	result, err := conn.Search(ldap.NewSearchRequest(
+		l.UserBaseDN,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, 0, false,
+		strings.Replace(l.UserFilter, "%s", ldap.EscapeFilter(username), -1),
+		l.attributes(),
+		nil,
+	))
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(result.Entries) != 1 {
+		return nil, nil
+	}
+	return result.Entries[0], nil
+}
+
+// attributes returns the configured attributes of the user entry
+func (l LDAPAuthProvider) attributes() []string {
+	attributes := []string{}
+	for _, attribute := range []string{l.EmailAttribute, l.DisplayNameAttribute, l.FirstNameAttribute, l.LastNameAttribute, l.PhoneNumberAttribute} {
+		if attribute != "" {
+			attributes = append(attributes, attribute)
+		}
+	}
+	return attributes
+}
+
+// findRole returns the highest role mapped from the groups of the user
+func (l LDAPAuthProvider) findRole(conn *ldap.Conn, userDN string) (string, error) {
+	if l.GroupBaseDN == "" {
+		return l.DefaultRole, nil
+	}
+
+	result, err := conn.Search(ldap.NewSearchRequest(
+		l.GroupBaseDN,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		strings.Replace(l.GroupFilter, "%s", ldap.EscapeFilter(userDN), -1),
+		[]string{"dn"},
+		nil,
+	))
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return l.DefaultRole, nil
+		}
+		return "", err
+	}
+
+	groups := []string{}
+	for _, group := range result.Entries {
+		groups = append(groups, group.DN)
+	}
+	return mapRole(groups, l.GroupRoles, l.DefaultRole), nil
+}
+
+// provision creates or updates the vortex user of the LDAP entry
+func (l LDAPAuthProvider) provision(session *mongo.Session, entry *ldap.Entry, role string) (entity.User, error) {
+	email := entry.GetAttributeValue(l.EmailAttribute)
+	if email == "" {
+		return entity.User{}, fmt.Errorf("The LDAP user %s has no %s attribute", entry.DN, l.EmailAttribute)
+	}
+	return provision(session, LDAPProviderName, entity.User{
+		LoginCredential: entity.LoginCredential{Username: email},
+		Role:            role,
+		DisplayName:     entry.GetAttributeValue(l.DisplayNameAttribute),
+		FirstName:       entry.GetAttributeValue(l.FirstNameAttribute),
+		LastName:        entry.GetAttributeValue(l.LastNameAttribute),
+		PhoneNumber:     entry.GetAttributeValue(l.PhoneNumberAttribute),
+	})
+}
@@ -0,0 +1,165 @@
+package authprovider
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"gopkg.in/asn1-ber.v1"
+	"gopkg.in/ldap.v2"
+)
+
+// testLDAPEntry is an entry of the in-process LDAP server
+type testLDAPEntry struct {
+	dn         string
+	password   string
+	attributes map[string][]string
+}
+
+// testLDAPServer is a minimal in-process LDAP server which supports simple bind and search
+type testLDAPServer struct {
+	listener net.Listener
+	entries  []testLDAPEntry
+	wg       sync.WaitGroup
+}
+
+func newTestLDAPServer(t *testing.T, entries []testLDAPEntry) *testLDAPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &testLDAPServer{listener: listener, entries: entries}
+	server.wg.Add(1)
+	go server.serve()
+	return server
+}
+
+func (s *testLDAPServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *testLDAPServer) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+func (s *testLDAPServer) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *testLDAPServer) handle(conn net.Conn) {
+	defer conn.Close()
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		messageID := packet.Children[0].Value.(int64)
+		request := packet.Children[1]
+
+		switch request.Tag {
+		case ldap.ApplicationBindRequest:
+			dn := ber.DecodeString(request.Children[1].Data.Bytes())
+			password := ber.DecodeString(request.Children[2].Data.Bytes())
+			resultCode := ldap.LDAPResultInvalidCredentials
+			if entry := s.find(dn); entry != nil && entry.password == password {
+				resultCode = ldap.LDAPResultSuccess
+			}
+			conn.Write(ldapResult(messageID, ldap.ApplicationBindResponse, resultCode).Bytes())
+		case ldap.ApplicationSearchRequest:
+			baseDN := strings.ToLower(ber.DecodeString(request.Children[0].Data.Bytes()))
+			filter := request.Children[6]
+			for _, entry := range s.entries {
+				if strings.HasSuffix(strings.ToLower(entry.dn), baseDN) && matchFilter(entry, filter) {
+					conn.Write(ldapSearchEntry(messageID, entry).Bytes())
+				}
+			}
+			conn.Write(ldapResult(messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess).Bytes())
+		case ldap.ApplicationUnbindRequest:
+			return
+		}
+	}
+}
+
+func (s *testLDAPServer) find(dn string) *testLDAPEntry {
+	for i, entry := range s.entries {
+		if strings.EqualFold(entry.dn, dn) {
+			return &s.entries[i]
+		}
+	}
+	return nil
+}
+
+// matchFilter supports the and, or, equality match and present filters
+func matchFilter(entry testLDAPEntry, filter *ber.Packet) bool {
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, child := range filter.Children {
+			if !matchFilter(entry, child) {
+				return false
+			}
+		}
+		return true
+	case ldap.FilterOr:
+		for _, child := range filter.Children {
+			if matchFilter(entry, child) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterEqualityMatch:
+		attribute := ber.DecodeString(filter.Children[0].Data.Bytes())
+		value := ber.DecodeString(filter.Children[1].Data.Bytes())
+		for _, v := range entry.attributes[attribute] {
+			if strings.EqualFold(v, value) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterPresent:
+		_, ok := entry.attributes[ber.DecodeString(filter.Data.Bytes())]
+		return ok
+	}
+	return false
+}
+
+func ldapEnvelope(messageID int64, operation *ber.Packet) *ber.Packet {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "Message ID"))
+	packet.AppendChild(operation)
+	return packet
+}
+
+func ldapResult(messageID int64, tag ber.Tag, resultCode int) *ber.Packet {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	result.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, uint64(resultCode), "Result Code"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+	return ldapEnvelope(messageID, result)
+}
+
+func ldapSearchEntry(messageID int64, entry testLDAPEntry) *ber.Packet {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.dn, "Object Name"))
+	attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	for name, values := range entry.attributes {
+		attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		attribute.AppendChild(set)
+		attributes.AppendChild(attribute)
+	}
+	result.AppendChild(attributes)
+	return ldapEnvelope(messageID, result)
+}
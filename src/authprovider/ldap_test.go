@@ -0,0 +1,203 @@
+package authprovider
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func init() {
+	rand.Seed(time.Now().UnixNano())
+}
+
+type LDAPAuthProviderTestSuite struct {
+	suite.Suite
+	sp       *serviceprovider.Container
+	session  *mongo.Session
+	server   *testLDAPServer
+	provider LDAPAuthProvider
+	email    string
+}
+
+func (suite *LDAPAuthProviderTestSuite) SetupSuite() {
+	cf := config.MustRead("../../config/testing.json")
+	suite.sp = serviceprovider.NewForTesting(cf)
+	suite.session = suite.sp.Mongo.NewSession()
+
+	suite.email = namesgenerator.GetRandomName(0) + "@linkernetworks.com"
+	suite.server = newTestLDAPServer(suite.T(), []testLDAPEntry{
+		{
+			dn:       "cn=admin,dc=linkernetworks,dc=com",
+			password: "admin",
+		},
+		{
+			dn:       "uid=john,ou=people,dc=linkernetworks,dc=com",
+			password: "p@ssw0rd",
+			attributes: map[string][]string{
+				"uid":             {"john"},
+				"mail":            {suite.email},
+				"cn":              {"John Doe"},
+				"givenName":       {"John"},
+				"sn":              {"Doe"},
+				"telephoneNumber": {"0900000000"},
+			},
+		},
+		{
+			dn:       "uid=jane,ou=people,dc=linkernetworks,dc=com",
+			password: "p@ssw0rd",
+			attributes: map[string][]string{
+				"uid":  {"jane"},
+				"mail": {"jane-" + suite.email},
+			},
+		},
+		{
+			dn: "cn=developers,ou=groups,dc=linkernetworks,dc=com",
+			attributes: map[string][]string{
+				"member": {"uid=john,ou=people,dc=linkernetworks,dc=com"},
+			},
+		},
+		{
+			dn: "cn=admins,ou=groups,dc=linkernetworks,dc=com",
+			attributes: map[string][]string{
+				"member": {"uid=john,ou=people,dc=linkernetworks,dc=com"},
+			},
+		},
+	})
+
+	suite.provider = LDAPAuthProvider{config.LDAPConfig{
+		Addr:                 suite.server.Addr(),
+		BindDN:               "cn=admin,dc=linkernetworks,dc=com",
+		BindPassword:         "admin",
+		UserBaseDN:           "ou=people,dc=linkernetworks,dc=com",
+		UserFilter:           "(mail=%s)",
+		EmailAttribute:       "mail",
+		DisplayNameAttribute: "cn",
+		FirstNameAttribute:   "givenName",
+		LastNameAttribute:    "sn",
+		PhoneNumberAttribute: "telephoneNumber",
+		GroupBaseDN:          "ou=groups,dc=linkernetworks,dc=com",
+		GroupFilter:          "(member=%s)",
+		GroupRoles: map[string]string{
+			"cn=developers,ou=groups,dc=linkernetworks,dc=com": entity.UserRole,
+			"cn=admins,ou=groups,dc=linkernetworks,dc=com":     entity.RootRole,
+		},
+	}}
+}
+
+func (suite *LDAPAuthProviderTestSuite) TearDownSuite() {
+	suite.server.Close()
+	suite.session.Remove(entity.UserCollectionName, "loginCredential.username", suite.email)
+}
+
+func TestLDAPAuthProviderSuite(t *testing.T) {
+	suite.Run(t, new(LDAPAuthProviderTestSuite))
+}
+
+func (suite *LDAPAuthProviderTestSuite) TestAuthenticate() {
+	user, passed, err := suite.provider.Authenticate(suite.session, entity.LoginCredential{
+		Username: suite.email,
+		Password: "p@ssw0rd",
+	})
+	suite.NoError(err)
+	suite.True(passed)
+	suite.Equal(suite.email, user.LoginCredential.Username)
+	suite.Equal(LDAPProviderName, user.AuthProvider)
+	suite.Equal("John Doe", user.DisplayName)
+	// the highest role of the groups
+	suite.Equal(entity.RootRole, user.Role)
+
+	// the user is provisioned
+	retUser := entity.User{}
+	err = suite.session.FindOne(entity.UserCollectionName, bson.M{"loginCredential.username": suite.email}, &retUser)
+	suite.NoError(err)
+	suite.Equal(user.ID, retUser.ID)
+	suite.Empty(retUser.LoginCredential.Password)
+
+	// and updated on the next login
+	again, passed, err := suite.provider.Authenticate(suite.session, entity.LoginCredential{
+		Username: suite.email,
+		Password: "p@ssw0rd",
+	})
+	suite.NoError(err)
+	suite.True(passed)
+	suite.Equal(user.ID, again.ID)
+}
+
+func (suite *LDAPAuthProviderTestSuite) TestAuthenticateFail() {
+	testCases := []struct {
+		caseName   string
+		credential entity.LoginCredential
+	}{
+		{"wrongPassword", entity.LoginCredential{Username: suite.email, Password: "wrong"}},
+		{"emptyPassword", entity.LoginCredential{Username: suite.email, Password: ""}},
+		{"unknownUser", entity.LoginCredential{Username: "nobody@linkernetworks.com", Password: "p@ssw0rd"}},
+		// jane is not in any mapped group and there is no default role
+		{"withoutRole", entity.LoginCredential{Username: "jane-" + suite.email, Password: "p@ssw0rd"}},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.caseName, func(t *testing.T) {
+			_, passed, err := suite.provider.Authenticate(suite.session, tc.credential)
+			suite.NoError(err)
+			suite.False(passed)
+		})
+	}
+}
+
+func (suite *LDAPAuthProviderTestSuite) TestDefaultRole() {
+	provider := suite.provider
+	provider.DefaultRole = entity.GuestRole
+	email := "jane-" + suite.email
+	defer suite.session.Remove(entity.UserCollectionName, "loginCredential.username", email)
+
+	user, passed, err := provider.Authenticate(suite.session, entity.LoginCredential{
+		Username: email,
+		Password: "p@ssw0rd",
+	})
+	suite.NoError(err)
+	suite.True(passed)
+	suite.Equal(entity.GuestRole, user.Role)
+	// the display name falls back to the email
+	suite.Equal(email, user.DisplayName)
+}
+
+func (suite *LDAPAuthProviderTestSuite) TestNotTakeOverLocalUser() {
+	email := "jane-" + suite.email
+	user := entity.User{
+		ID: bson.NewObjectId(),
+		LoginCredential: entity.LoginCredential{
+			Username: email,
+			Password: "local",
+		},
+		Role: entity.RootRole,
+	}
+	suite.session.Insert(entity.UserCollectionName, &user)
+	defer suite.session.Remove(entity.UserCollectionName, "_id", user.ID)
+
+	provider := suite.provider
+	provider.DefaultRole = entity.GuestRole
+	_, passed, err := provider.Authenticate(suite.session, entity.LoginCredential{
+		Username: email,
+		Password: "p@ssw0rd",
+	})
+	suite.Error(err)
+	suite.False(passed)
+}
+
+func (suite *LDAPAuthProviderTestSuite) TestServiceAccountFail() {
+	provider := suite.provider
+	provider.BindPassword = "wrong"
+	_, passed, err := provider.Authenticate(suite.session, entity.LoginCredential{
+		Username: suite.email,
+		Password: "p@ssw0rd",
+	})
+	suite.Error(err)
+	suite.False(passed)
+}
@@ -0,0 +1,28 @@
+package authprovider
+
+import (
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/server/backend"
+)
+
+// LocalAuthProvider checks the password hash of the users collection
+type LocalAuthProvider struct{}
+
+// Name returns the name of the provider
+func (local LocalAuthProvider) Name() string {
+	return LocalProviderName
+}
+
+// Authenticate checks the credential against the local users
+func (local LocalAuthProvider) Authenticate(session *mongo.Session, credential entity.LoginCredential) (entity.User, bool, error) {
+	user, passed, err := backend.Authenticate(session, credential)
+	if err != nil || !passed {
+		return entity.User{}, false, err
+	}
+	// the users of the external providers can't sign in with a local password
+	if user.AuthProvider != "" {
+		return entity.User{}, false, nil
+	}
+	return user, true, nil
+}
@@ -0,0 +1,357 @@
+package authprovider
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/cache"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+)
+
+// oidcLeeway is the allowed clock skew between vortex and the provider
+const oidcLeeway = time.Minute
+
+// oidcCacheTTL is how long the discovery document and the JWKS of a provider are reused
+const oidcCacheTTL = time.Hour
+
+var oidcClient = &http.Client{Timeout: 10 * time.Second}
+
+var (
+	// discoveryCache maps the issuer to its discovery document
+	discoveryCache = cache.New(oidcCacheTTL)
+	// jwksCache maps the JWKS URI to its keys
+	jwksCache = cache.New(oidcCacheTTL)
+)
+
+// oidcDiscovery is the part of the provider's discovery document used by vortex
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCProvider is an OpenID Connect relying party of the provider discovered from the issuer
+type OIDCProvider struct {
+	config.OIDCConfig
+	AuthorizationEndpoint string
+	TokenEndpoint         string
+	JWKSURI               string
+}
+
+// NewOIDCProvider discovers the endpoints of the provider from the issuer,
+// the discovery document is cached for oidcCacheTTL
+func NewOIDCProvider(cf config.OIDCConfig) (*OIDCProvider, error) {
+	issuer := strings.TrimSuffix(cf.Issuer, "/")
+	discovery := oidcDiscovery{}
+	if cached, ok := discoveryCache.Get(issuer); ok {
+		discovery = cached.(oidcDiscovery)
+	} else {
+		if err := getJSON(issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+			return nil, fmt.Errorf("Failed to discover the OIDC provider %s: %v", cf.Issuer, err)
+		}
+		if strings.TrimSuffix(discovery.Issuer, "/") != issuer {
+			return nil, fmt.Errorf("The OIDC provider issuer %s doesn't match %s", discovery.Issuer, cf.Issuer)
+		}
+		discoveryCache.Set(issuer, discovery)
+	}
+
+	provider := OIDCProvider{
+		OIDCConfig:            cf,
+		AuthorizationEndpoint: discovery.AuthorizationEndpoint,
+		TokenEndpoint:         discovery.TokenEndpoint,
+		JWKSURI:               discovery.JWKSURI,
+	}
+	// the ID tokens carry the exact issuer of the discovery
+	provider.Issuer = discovery.Issuer
+	if provider.EmailClaim == "" {
+		provider.EmailClaim = "email"
+	}
+	if provider.NameClaim == "" {
+		provider.NameClaim = "name"
+	}
+	if provider.GroupsClaim == "" {
+		provider.GroupsClaim = "groups"
+	}
+	return &provider, nil
+}
+
+// AuthCodeURL returns the URL of the provider login page for the authorization code flow
+func (p *OIDCProvider) AuthCodeURL(state, nonce string) string {
+	scopes := p.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"email", "profile"}
+	}
+	query := url.Values{
+		"response_type": {"code"},
+		"client_id":     {p.ClientID},
+		"redirect_uri":  {p.RedirectURL},
+		"scope":         {strings.Join(append([]string{"openid"}, scopes...), " ")},
+		"state":         {state},
+		"nonce":         {nonce},
+	}
+	separator := "?"
+	if strings.Contains(p.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return p.AuthorizationEndpoint + separator + query.Encode()
+}
+
+// Exchange exchanges the authorization code for the ID token
+func (p *OIDCProvider) Exchange(code string) (string, error) {
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {p.RedirectURL},
+	}
+	request, err := http.NewRequest("POST", p.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	request.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+
+	resp, err := oidcClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	token := struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("Failed to decode the token response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || token.Error != "" {
+		return "", fmt.Errorf("Failed to exchange the authorization code: %s %s", token.Error, token.ErrorDescription)
+	}
+	if token.IDToken == "" {
+		return "", fmt.Errorf("The token response has no id_token")
+	}
+	return token.IDToken, nil
+}
+
+// Verify verifies the signature of the ID token with the provider's JWKS and checks its claims
+func (p *OIDCProvider) Verify(rawIDToken string, nonce string) (jwt.MapClaims, error) {
+	// the time claims are checked below with leeway
+	parser := jwt.Parser{SkipClaimsValidation: true}
+	token, err := parser.Parse(rawIDToken, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		default:
+			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		return p.publicKey(kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("Invalid ID token claims")
+	}
+	if iss, _ := claims["iss"].(string); iss != p.Issuer {
+		return nil, fmt.Errorf("Unexpected ID token issuer %s", iss)
+	}
+	if !hasAudience(claims["aud"], p.ClientID) {
+		return nil, fmt.Errorf("The ID token isn't issued to %s", p.ClientID)
+	}
+	now := time.Now()
+	exp, ok := claims["exp"].(float64)
+	if !ok || now.After(time.Unix(int64(exp), 0).Add(oidcLeeway)) {
+		return nil, fmt.Errorf("The ID token is expired")
+	}
+	if iat, ok := claims["iat"].(float64); ok && now.Add(oidcLeeway).Before(time.Unix(int64(iat), 0)) {
+		return nil, fmt.Errorf("The ID token is issued in the future")
+	}
+	if claimNonce, _ := claims["nonce"].(string); claimNonce != nonce {
+		return nil, fmt.Errorf("Unexpected ID token nonce")
+	}
+	return claims, nil
+}
+
+// Provision creates or updates the vortex user of the ID token claims.
+// It returns false if the user is not given any role.
+func (p *OIDCProvider) Provision(session *mongo.Session, claims jwt.MapClaims) (entity.User, bool, error) {
+	profile, err := p.profile(claims)
+	if err != nil {
+		return entity.User{}, false, err
+	}
+	if profile.Role == "" {
+		return entity.User{}, false, nil
+	}
+	user, err := provision(session, OIDCProviderName, profile)
+	if err != nil {
+		return entity.User{}, false, err
+	}
+	return user, true, nil
+}
+
+// profile maps the claims onto the vortex user
+func (p *OIDCProvider) profile(claims jwt.MapClaims) (entity.User, error) {
+	email, _ := claims[p.EmailClaim].(string)
+	if email == "" {
+		return entity.User{}, fmt.Errorf("The ID token has no %s claim", p.EmailClaim)
+	}
+	if verified, ok := claims["email_verified"].(bool); ok && !verified {
+		return entity.User{}, fmt.Errorf("The email %s is not verified", email)
+	}
+
+	groups := []string{}
+	switch value := claims[p.GroupsClaim].(type) {
+	case string:
+		groups = append(groups, value)
+	case []interface{}:
+		for _, group := range value {
+			if name, ok := group.(string); ok {
+				groups = append(groups, name)
+			}
+		}
+	}
+
+	user := entity.User{
+		LoginCredential: entity.LoginCredential{Username: email},
+		Role:            mapRole(groups, p.GroupRoles, p.DefaultRole),
+	}
+	user.DisplayName, _ = claims[p.NameClaim].(string)
+	user.FirstName, _ = claims["given_name"].(string)
+	user.LastName, _ = claims["family_name"].(string)
+	user.PhoneNumber, _ = claims["phone_number"].(string)
+	return user, nil
+}
+
+// jsonWebKey is a public key of the provider's JWKS
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// publicKey returns the signing key of the kid from the cached JWKS.
+// The JWKS is fetched again for an unknown kid so the rotated keys are picked up.
+func (p *OIDCProvider) publicKey(kid string) (interface{}, error) {
+	cached, ok := jwksCache.Get(p.JWKSURI)
+	if ok {
+		if key, found := findSigningKey(cached.([]jsonWebKey), kid); found {
+			return key.publicKey()
+		}
+	}
+
+	jwks := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+	if err := getJSON(p.JWKSURI, &jwks); err != nil {
+		return nil, fmt.Errorf("Failed to fetch the JWKS: %v", err)
+	}
+	jwksCache.Set(p.JWKSURI, jwks.Keys)
+	if key, found := findSigningKey(jwks.Keys, kid); found {
+		return key.publicKey()
+	}
+	return nil, fmt.Errorf("Unknown signing key %s", kid)
+}
+
+// findSigningKey returns the signing key of the kid in the keys
+func findSigningKey(keys []jsonWebKey, kid string) (jsonWebKey, bool) {
+	for _, key := range keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		// a token without kid can only be verified by the only key
+		if key.Kid == kid || (kid == "" && len(keys) == 1) {
+			return key, true
+		}
+	}
+	return jsonWebKey{}, false
+}
+
+func (key jsonWebKey) publicKey() (interface{}, error) {
+	switch key.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		curves := map[string]elliptic.Curve{
+			"P-256": elliptic.P256(),
+			"P-384": elliptic.P384(),
+			"P-521": elliptic.P521(),
+		}
+		curve, ok := curves[key.Crv]
+		if !ok {
+			return nil, fmt.Errorf("Unsupported curve %s", key.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(key.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(key.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	}
+	return nil, fmt.Errorf("Unsupported key type %s", key.Kty)
+}
+
+// hasAudience checks the aud claim, which is either a string or an array
+func hasAudience(aud interface{}, clientID string) bool {
+	switch value := aud.(type) {
+	case string:
+		return value == clientID
+	case []interface{}:
+		for _, audience := range value {
+			if audience == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func getJSON(url string, v interface{}) error {
+	resp, err := oidcClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responds %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,175 @@
+package authprovider
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/linkernetworks/vortex/src/authprovider/oidctest"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestOIDCProvider(t *testing.T, server *oidctest.Server) *OIDCProvider {
+	provider, err := NewOIDCProvider(config.OIDCConfig{
+		Issuer:       server.URL,
+		ClientID:     server.ClientID,
+		ClientSecret: server.ClientSecret,
+		RedirectURL:  "http://localhost:7890/v1/users/oidc/callback",
+		GroupRoles: map[string]string{
+			"developers": entity.UserRole,
+			"admins":     entity.RootRole,
+		},
+	})
+	assert.NoError(t, err)
+	return provider
+}
+
+func TestNewOIDCProvider(t *testing.T) {
+	server := oidctest.NewServer("vortex", "secret")
+	defer server.Close()
+
+	provider := newTestOIDCProvider(t, server)
+	assert.Equal(t, server.URL+"/token", provider.TokenEndpoint)
+	assert.Equal(t, "email", provider.EmailClaim)
+
+	_, err := NewOIDCProvider(config.OIDCConfig{Issuer: server.URL + "/other"})
+	assert.Error(t, err)
+}
+
+func TestOIDCProviderCache(t *testing.T) {
+	server := oidctest.NewServer("vortex", "secret")
+	defer server.Close()
+
+	newTestOIDCProvider(t, server)
+	provider := newTestOIDCProvider(t, server)
+	assert.Equal(t, 1, server.Requests("/.well-known/openid-configuration"))
+
+	for i := 0; i < 2; i++ {
+		_, err := provider.Verify(server.SignIDToken(jwt.MapClaims{"nonce": "my-nonce"}), "my-nonce")
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 1, server.Requests("/jwks"))
+
+	// the JWKS is fetched again for an unknown kid
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"nonce": "my-nonce"})
+	token.Header["kid"] = "rotated"
+	unknown, err := token.SignedString(server.Key)
+	assert.NoError(t, err)
+	_, err = provider.Verify(unknown, "my-nonce")
+	assert.Error(t, err)
+	assert.Equal(t, 2, server.Requests("/jwks"))
+}
+
+func TestOIDCAuthorizationCodeFlow(t *testing.T) {
+	server := oidctest.NewServer("vortex", "secret")
+	defer server.Close()
+	server.SetUser(jwt.MapClaims{
+		"sub":    "john",
+		"email":  "john@linkernetworks.com",
+		"name":   "John Doe",
+		"groups": []string{"developers", "admins"},
+	})
+	provider := newTestOIDCProvider(t, server)
+
+	loginURL, err := url.Parse(provider.AuthCodeURL("my-state", "my-nonce"))
+	assert.NoError(t, err)
+	assert.Equal(t, "openid email profile", loginURL.Query().Get("scope"))
+
+	// the provider signs in the user and redirects back with the code
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(loginURL.String())
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+	callbackURL, err := resp.Location()
+	assert.NoError(t, err)
+	assert.Equal(t, "my-state", callbackURL.Query().Get("state"))
+
+	rawIDToken, err := provider.Exchange(callbackURL.Query().Get("code"))
+	assert.NoError(t, err)
+	claims, err := provider.Verify(rawIDToken, "my-nonce")
+	assert.NoError(t, err)
+
+	user, err := provider.profile(claims)
+	assert.NoError(t, err)
+	assert.Equal(t, "john@linkernetworks.com", user.LoginCredential.Username)
+	assert.Equal(t, "John Doe", user.DisplayName)
+	// the highest role of the groups
+	assert.Equal(t, entity.RootRole, user.Role)
+
+	// a code can only be exchanged once
+	_, err = provider.Exchange(callbackURL.Query().Get("code"))
+	assert.Error(t, err)
+}
+
+func TestOIDCVerifyFail(t *testing.T) {
+	server := oidctest.NewServer("vortex", "secret")
+	defer server.Close()
+	provider := newTestOIDCProvider(t, server)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	forged := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   server.URL,
+		"aud":   "vortex",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"nonce": "my-nonce",
+	})
+	forged.Header["kid"] = oidctest.KeyID
+	forgedToken, err := forged.SignedString(otherKey)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		caseName string
+		idToken  string
+	}{
+		{"wrongNonce", server.SignIDToken(jwt.MapClaims{"nonce": "other"})},
+		{"wrongAudience", server.SignIDToken(jwt.MapClaims{"nonce": "my-nonce", "aud": "other"})},
+		{"wrongIssuer", server.SignIDToken(jwt.MapClaims{"nonce": "my-nonce", "iss": "https://other"})},
+		{"expired", server.SignIDToken(jwt.MapClaims{"nonce": "my-nonce", "exp": time.Now().Add(-time.Hour).Unix()})},
+		{"forged", forgedToken},
+		{"malformed", "not-a-jwt"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			_, err := provider.Verify(tc.idToken, "my-nonce")
+			assert.Error(t, err)
+		})
+	}
+
+	// the audience can be an array
+	_, err = provider.Verify(server.SignIDToken(jwt.MapClaims{"nonce": "my-nonce", "aud": []string{"other", "vortex"}}), "my-nonce")
+	assert.NoError(t, err)
+}
+
+func TestOIDCProfile(t *testing.T) {
+	provider := &OIDCProvider{OIDCConfig: config.OIDCConfig{
+		EmailClaim:  "email",
+		NameClaim:   "name",
+		GroupsClaim: "roles",
+		GroupRoles:  map[string]string{"developers": entity.UserRole},
+	}}
+
+	// the groups claim can be a string
+	user, err := provider.profile(jwt.MapClaims{"email": "jane@linkernetworks.com", "roles": "developers"})
+	assert.NoError(t, err)
+	assert.Equal(t, entity.UserRole, user.Role)
+
+	// no role without a mapped group or a default role
+	user, err = provider.profile(jwt.MapClaims{"email": "jane@linkernetworks.com"})
+	assert.NoError(t, err)
+	assert.Empty(t, user.Role)
+
+	_, err = provider.profile(jwt.MapClaims{"email": "jane@linkernetworks.com", "email_verified": false})
+	assert.Error(t, err)
+
+	_, err = provider.profile(jwt.MapClaims{"name": "Jane"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,143 @@
+package authprovider
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/linkernetworks/logger"
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/utils/timeutils"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/server/backend"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// The names of the authentication providers
+const (
+	LocalProviderName string = "local"
+	LDAPProviderName  string = "ldap"
+	OIDCProviderName  string = "oidc"
+)
+
+// ErrUserNotManaged is returned when the user exists but is managed by another provider
+var ErrUserNotManaged = errors.New("The user is managed by another authentication provider")
+
+// roleRanks is used to pick the highest role of the user's groups
+var roleRanks = map[string]int{
+	entity.GuestRole: 1,
+	entity.UserRole:  2,
+	entity.RootRole:  3,
+}
+
+// AuthProvider is authentication provider interface
+type AuthProvider interface {
+	// Name returns the name of the provider
+	Name() string
+	// Authenticate returns the vortex user of the credential and whether the credential is accepted.
+	// A provider returns false without an error when it doesn't know the user.
+	Authenticate(session *mongo.Session, credential entity.LoginCredential) (entity.User, bool, error)
+}
+
+// GetAuthProviders will get the chain of authentication providers of the config
+func GetAuthProviders(cf *config.AuthConfig) ([]AuthProvider, error) {
+	if cf == nil || len(cf.Providers) == 0 {
+		return []AuthProvider{LocalAuthProvider{}}, nil
+	}
+
+	providers := []AuthProvider{}
+	for _, name := range cf.Providers {
+		switch name {
+		case LocalProviderName:
+			providers = append(providers, LocalAuthProvider{})
+		case LDAPProviderName:
+			if cf.LDAP == nil {
+				return nil, fmt.Errorf("The ldap provider is enabled without ldap config")
+			}
+			providers = append(providers, LDAPAuthProvider{*cf.LDAP})
+		default:
+			return nil, fmt.Errorf("Unsupported Auth Provider %s", name)
+		}
+	}
+	return providers, nil
+}
+
+// Authenticate tries the providers in order and returns the user of the first provider accepting the credential.
+// The error of a failed provider is only returned when no provider accepts the credential,
+// except backend.ErrUserDisabled which stops the chain.
+func Authenticate(session *mongo.Session, providers []AuthProvider, credential entity.LoginCredential) (entity.User, bool, error) {
+	var lastErr error
+	for _, provider := range providers {
+		user, passed, err := provider.Authenticate(session, credential)
+		if err == backend.ErrUserDisabled {
+			// the other providers must not sign the disabled user in
+			return entity.User{}, false, err
+		}
+		if err != nil {
+			if err != mgo.ErrNotFound {
+				logger.Warnf("Auth provider %s failed to authenticate %s: %v", provider.Name(), credential.Username, err)
+				lastErr = err
+			}
+			continue
+		}
+		if passed {
+			return user, true, nil
+		}
+	}
+	return entity.User{}, false, lastErr
+}
+
+// mapRole returns the highest role mapped from the groups, or the default role if no group is mapped
+func mapRole(groups []string, groupRoles map[string]string, defaultRole string) string {
+	role := defaultRole
+	for _, group := range groups {
+		for name, groupRole := range groupRoles {
+			if strings.EqualFold(group, name) && roleRanks[groupRole] > roleRanks[role] {
+				role = groupRole
+			}
+		}
+	}
+	return role
+}
+
+// provision creates or updates the vortex user managed by the external provider.
+// The profile carries the username, role and names given by the provider.
+func provision(session *mongo.Session, providerName string, profile entity.User) (entity.User, error) {
+	email := strings.ToLower(profile.LoginCredential.Username)
+
+	user := entity.User{}
+	err := session.FindOne(entity.UserCollectionName, bson.M{"loginCredential.username": email}, &user)
+	switch {
+	case err == mgo.ErrNotFound:
+		user = entity.User{
+			ID:           bson.NewObjectId(),
+			AuthProvider: providerName,
+			CreatedAt:    timeutils.Now(),
+		}
+	case err != nil:
+		return entity.User{}, err
+	case user.AuthProvider != providerName:
+		// never take over the users of another provider
+		return entity.User{}, ErrUserNotManaged
+	}
+	if user.Disabled {
+		return entity.User{}, backend.ErrUserDisabled
+	}
+
+	user.LoginCredential = entity.LoginCredential{Username: email}
+	user.Role = profile.Role
+	user.DisplayName = profile.DisplayName
+	user.FirstName = profile.FirstName
+	user.LastName = profile.LastName
+	user.PhoneNumber = profile.PhoneNumber
+	if user.DisplayName == "" {
+		user.DisplayName = email
+	}
+
+	if _, err := session.C(entity.UserCollectionName).UpsertId(user.ID, &user); err != nil {
+		return entity.User{}, err
+	}
+	return user, nil
+}
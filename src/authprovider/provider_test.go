@@ -0,0 +1,78 @@
+package authprovider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeAuthProvider struct {
+	name   string
+	user   entity.User
+	passed bool
+	err    error
+}
+
+func (f fakeAuthProvider) Name() string {
+	return f.name
+}
+
+func (f fakeAuthProvider) Authenticate(session *mongo.Session, credential entity.LoginCredential) (entity.User, bool, error) {
+	return f.user, f.passed, f.err
+}
+
+func TestGetAuthProviders(t *testing.T) {
+	providers, err := GetAuthProviders(nil)
+	assert.NoError(t, err)
+	assert.Len(t, providers, 1)
+	assert.Equal(t, LocalProviderName, providers[0].Name())
+
+	providers, err = GetAuthProviders(&config.AuthConfig{
+		Providers: []string{"local", "ldap"},
+		LDAP:      &config.LDAPConfig{Addr: "localhost:389"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, providers, 2)
+	assert.Equal(t, LDAPProviderName, providers[1].Name())
+
+	_, err = GetAuthProviders(&config.AuthConfig{Providers: []string{"ldap"}})
+	assert.Error(t, err)
+
+	_, err = GetAuthProviders(&config.AuthConfig{Providers: []string{"kerberos"}})
+	assert.Error(t, err)
+}
+
+func TestAuthenticateChain(t *testing.T) {
+	credential := entity.LoginCredential{Username: "john@linkernetworks.com", Password: "p@ssw0rd"}
+	john := entity.User{DisplayName: "John"}
+
+	// the first provider accepting the credential wins
+	user, passed, err := Authenticate(nil, []AuthProvider{
+		fakeAuthProvider{name: "first"},
+		fakeAuthProvider{name: "second", user: john, passed: true},
+		fakeAuthProvider{name: "third", err: fmt.Errorf("unreachable")},
+	}, credential)
+	assert.NoError(t, err)
+	assert.True(t, passed)
+	assert.Equal(t, "John", user.DisplayName)
+
+	// a failed provider doesn't stop the chain
+	_, passed, err = Authenticate(nil, []AuthProvider{
+		fakeAuthProvider{name: "first", err: fmt.Errorf("unreachable")},
+		fakeAuthProvider{name: "second", user: john, passed: true},
+	}, credential)
+	assert.NoError(t, err)
+	assert.True(t, passed)
+
+	// but is reported when nobody accepts the credential
+	_, passed, err = Authenticate(nil, []AuthProvider{
+		fakeAuthProvider{name: "first", err: fmt.Errorf("unreachable")},
+		fakeAuthProvider{name: "second"},
+	}, credential)
+	assert.Error(t, err)
+	assert.False(t, passed)
+}
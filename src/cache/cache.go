@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+type entry struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+// TTLCache is a concurrency safe cache whose entries expire after a fixed duration
+type TTLCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]entry
+	// nextSweep is when the expired entries, which are never read again, are dropped next
+	nextSweep time.Time
+}
+
+// New will return a new TTLCache
+func New(ttl time.Duration) *TTLCache {
+	return &TTLCache{
+		ttl:     ttl,
+		entries: map[string]entry{},
+	}
+}
+
+// Get returns the value of the key if it exists and is not expired
+func (c *TTLCache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(e.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return e.value, true
+}
+
+// Set stores the value of the key for the ttl of the cache
+func (c *TTLCache) Set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	// drop the expired entries once per ttl so the cache doesn't grow forever,
+	// the other expired entries are dropped when they are read
+	if now.After(c.nextSweep) {
+		for k, e := range c.entries {
+			if now.After(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		c.nextSweep = now.Add(c.ttl)
+	}
+	c.entries[key] = entry{value: value, expiresAt: now.Add(c.ttl)}
+}
+
+// Increment counts an event of the key and returns the count of the events since the first one,
+// the count restarts when the ttl of the first event has passed
+func (c *TTLCache) Increment(key string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	e, ok := c.entries[key]
+	count, _ := e.value.(int)
+	if !ok || now.After(e.expiresAt) {
+		c.entries[key] = entry{value: 1, expiresAt: now.Add(c.ttl)}
+		return 1
+	}
+	e.value = count + 1
+	c.entries[key] = e
+	return count + 1
+}
+
+// Delete removes the key from the cache
+func (c *TTLCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+}
@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTTLCache(t *testing.T) {
+	c := New(time.Minute)
+
+	_, ok := c.Get("session")
+	assert.False(t, ok)
+
+	c.Set("session", true)
+	value, ok := c.Get("session")
+	assert.True(t, ok)
+	assert.Equal(t, true, value)
+
+	c.Delete("session")
+	_, ok = c.Get("session")
+	assert.False(t, ok)
+}
+
+func TestTTLCacheExpired(t *testing.T) {
+	c := New(10 * time.Millisecond)
+
+	c.Set("session", true)
+	time.Sleep(20 * time.Millisecond)
+	_, ok := c.Get("session")
+	assert.False(t, ok)
+}
+
+func TestTTLCacheSweep(t *testing.T) {
+	c := New(10 * time.Millisecond)
+
+	c.Set("expired", true)
+	time.Sleep(20 * time.Millisecond)
+	// the first set after the ttl drops the expired entries
+	c.Set("session", true)
+	assert.Len(t, c.entries, 1)
+
+	// the sweep runs at most once per ttl
+	c.entries["expired"] = entry{value: true, expiresAt: time.Now().Add(-time.Second)}
+	c.Set("other", true)
+	assert.Len(t, c.entries, 3)
+}
+
+func TestTTLCacheIncrement(t *testing.T) {
+	c := New(10 * time.Millisecond)
+
+	assert.Equal(t, 1, c.Increment("mail"))
+	assert.Equal(t, 2, c.Increment("mail"))
+	assert.Equal(t, 1, c.Increment("other"))
+
+	// the count restarts after the ttl
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, 1, c.Increment("mail"))
+}
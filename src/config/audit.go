@@ -0,0 +1,7 @@
+package config
+
+// AuditConfig is the structure for the audit log of the API calls
+type AuditConfig struct {
+	// Retention is how long the audit records are kept, e.g. "2160h". The records are kept forever if it's empty
+	Retention string `json:"retention"`
+}
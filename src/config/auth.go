@@ -0,0 +1,105 @@
+package config
+
+// AuthConfig is the structure for the authentication config
+type AuthConfig struct {
+	// Providers is the chain of authentication providers tried in order on sign in, "local" if empty
+	Providers []string    `json:"providers"`
+	LDAP      *LDAPConfig `json:"ldap"`
+	// OIDC enables the single sign-on with an OpenID Connect provider
+	OIDC *OIDCConfig `json:"oidc"`
+
+	PasswordPolicy *PasswordPolicyConfig `json:"passwordPolicy"`
+	Lockout        *LockoutConfig        `json:"lockout"`
+	// PasswordResetExpiry is how long a password reset token is valid, e.g. "24h"
+	PasswordResetExpiry string `json:"passwordResetExpiry"`
+	// PasswordResetURL is the page of the web UI setting the new password,
+	// the reset token is added as the token query parameter of the mailed link
+	PasswordResetURL string `json:"passwordResetURL"`
+	// Admin is the bootstrap admin created on the first start, the VORTEX_ADMIN_USERNAME
+	// and VORTEX_ADMIN_PASSWORD environment variables take precedence
+	Admin *AdminConfig `json:"admin"`
+}
+
+// PasswordPolicyConfig is the structure for the rules of the local passwords
+type PasswordPolicyConfig struct {
+	MinLength     int  `json:"minLength"`
+	RequireUpper  bool `json:"requireUpper"`
+	RequireLower  bool `json:"requireLower"`
+	RequireDigit  bool `json:"requireDigit"`
+	RequireSymbol bool `json:"requireSymbol"`
+	// HistorySize is the number of the previous passwords which can't be reused
+	HistorySize int `json:"historySize"`
+}
+
+// LockoutConfig is the structure for locking the users out after failed sign in
+type LockoutConfig struct {
+	// MaxAttempts is the number of the failed sign in within the window to lock the user, 0 disables the lockout
+	MaxAttempts int `json:"maxAttempts"`
+	// Window is the period counting the failed sign in, e.g. "15m"
+	Window string `json:"window"`
+	// Duration is how long the user is locked, e.g. "30m". The user is locked until unlocked by root if it's empty
+	Duration string `json:"duration"`
+}
+
+// AdminConfig is the structure for the bootstrap admin credentials
+type AdminConfig struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// LDAPConfig is the structure for the LDAP authentication provider
+type LDAPConfig struct {
+	// Addr is the host:port of the LDAP server
+	Addr string `json:"addr"`
+	// UseTLS dials the server with TLS (ldaps), StartTLS upgrades a plain connection
+	UseTLS             bool `json:"useTLS"`
+	StartTLS           bool `json:"startTLS"`
+	InsecureSkipVerify bool `json:"insecureSkipVerify"`
+
+	// BindDN and BindPassword is the service account used to search users and groups
+	BindDN       string `json:"bindDN"`
+	BindPassword string `json:"bindPassword"`
+
+	// UserBaseDN and UserFilter find the user entry, %s in the filter is replaced by the username
+	UserBaseDN string `json:"userBaseDN"`
+	UserFilter string `json:"userFilter"`
+
+	// The attributes of the user entry mapped to the vortex user
+	EmailAttribute       string `json:"emailAttribute"`
+	DisplayNameAttribute string `json:"displayNameAttribute"`
+	FirstNameAttribute   string `json:"firstNameAttribute"`
+	LastNameAttribute    string `json:"lastNameAttribute"`
+	PhoneNumberAttribute string `json:"phoneNumberAttribute"`
+
+	// GroupBaseDN and GroupFilter find the groups of the user, %s in the filter is replaced by the user DN
+	GroupBaseDN string `json:"groupBaseDN"`
+	GroupFilter string `json:"groupFilter"`
+	// GroupRoles maps the group DN to the vortex role, the highest role of the user's groups wins
+	GroupRoles map[string]string `json:"groupRoles"`
+	// DefaultRole is given to the users not in any mapped group, they are rejected if it's empty
+	DefaultRole string `json:"defaultRole"`
+}
+
+// OIDCConfig is the structure for the OpenID Connect single sign-on
+type OIDCConfig struct {
+	// Issuer is the issuer URL used to discover the provider
+	Issuer       string `json:"issuer"`
+	ClientID     string `json:"clientID"`
+	ClientSecret string `json:"clientSecret"`
+	// RedirectURL is the callback URL registered on the provider, which ends with /v1/users/oidc/callback
+	RedirectURL string `json:"redirectURL"`
+	// PortalURL is the page of the web UI completing the login, the one-time code
+	// is added as the code query parameter and exchanged at /v1/users/oidc/token
+	PortalURL string `json:"portalURL"`
+	// Scopes are requested besides openid, e.g. email, profile and groups
+	Scopes []string `json:"scopes"`
+
+	// The claims of the ID token mapped to the vortex user, "email", "name" and "groups" if empty
+	EmailClaim  string `json:"emailClaim"`
+	NameClaim   string `json:"nameClaim"`
+	GroupsClaim string `json:"groupsClaim"`
+	// GroupRoles maps the group to the vortex role, the highest role of the user's groups wins
+	GroupRoles map[string]string `json:"groupRoles"`
+	// DefaultRole is given to the users not in any mapped group, they are rejected if it's empty
+	DefaultRole string `json:"defaultRole"`
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/linkernetworks/logger"
 	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/jwtprovider"
+	"github.com/linkernetworks/vortex/src/mailprovider"
 	"github.com/linkernetworks/vortex/src/prometheusprovider"
 )
 
@@ -16,6 +18,11 @@ type Config struct {
 	Prometheus *prometheusprovider.PrometheusConfig `json:"prometheus"`
 	Kubernetes *kubernetesConfig                    `json:"kubernetes"`
 	Registry   *registryConfig                      `json:"registry"`
+	JWT        *jwtprovider.JWTConfig               `json:"jwt"`
+	Auth       *AuthConfig                          `json:"auth"`
+	SMTP       *mailprovider.SMTPConfig             `json:"smtp"`
+	Audit      *AuditConfig                         `json:"audit"`
+	IPAM       *IPAMConfig                          `json:"ipam"`
 	Logger     logger.LoggerConfig                  `json:"logger"`
 
 	// the version settings of the current application
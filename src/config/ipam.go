@@ -0,0 +1,10 @@
+package config
+
+// IPAMConfig is the structure for the IP address management of the custom networks
+type IPAMConfig struct {
+	// ServerURL is the URL of the vortex server reached by the init containers of the pods,
+	// "http://vortex-server.vortex:7890" if it's empty
+	ServerURL string `json:"serverURL"`
+	// ReleaseInterval is how often the addresses of the deleted pods are released, e.g. "1m". It's one minute if it's empty
+	ReleaseInterval string `json:"releaseInterval"`
+}
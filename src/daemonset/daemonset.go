@@ -0,0 +1,113 @@
+package daemonset
+
+import (
+	"fmt"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/deployment"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/ipam"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/linkernetworks/vortex/src/utils"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CheckDaemonSetParameter will check the parameters of the DaemonSet
+func CheckDaemonSetParameter(sp *serviceprovider.Container, daemonSet *entity.DaemonSet) error {
+	template := daemonSet.PodTemplate()
+	// the pods on the nodes can't share the static addresses
+	if deployment.HasStaticAddress(&template) {
+		return fmt.Errorf("the DaemonSet can't have the static addresses, they are allocated from the subnets of the networks")
+	}
+	if err := deployment.CheckPodParameter(sp, &template); err != nil {
+		return err
+	}
+
+	if daemonSet.NetworkType != entity.DeploymentCustomNetwork || len(daemonSet.Networks) == 0 {
+		return nil
+	}
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	nodes, err := deployment.NetworkNodes(session, daemonSet.Networks)
+	if err != nil {
+		return fmt.Errorf("check the network nodes error:%v", err)
+	}
+	if len(nodes) == 0 {
+		return fmt.Errorf("no node has all the networks of the DaemonSet")
+	}
+	if len(daemonSet.NodeAffinity) != 0 && len(utils.Intersection(daemonSet.NodeAffinity, nodes)) == 0 {
+		return fmt.Errorf("no node of the node affinity has all the networks of the DaemonSet")
+	}
+	return nil
+}
+
+// generateDaemonSet generates the kubernetes DaemonSet of the DaemonSet
+func generateDaemonSet(session *mongo.Session, daemonSet *entity.DaemonSet, ipamURL string) (*appsv1.DaemonSet, error) {
+	podTemplate := daemonSet.PodTemplate()
+	template, err := deployment.GeneratePodTemplate(session, &podTemplate, ipamURL, false)
+	if err != nil {
+		return nil, err
+	}
+
+	p := appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   daemonSet.Name,
+			Labels: daemonSet.Labels,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					// vortex default label
+					deployment.DefaultLabel: daemonSet.Name,
+				},
+			},
+			Template: template,
+			UpdateStrategy: appsv1.DaemonSetUpdateStrategy{
+				Type: appsv1.RollingUpdateDaemonSetStrategyType,
+			},
+		},
+	}
+	return &p, nil
+}
+
+// CreateDaemonSet will create the DaemonSet
+func CreateDaemonSet(sp *serviceprovider.Container, daemonSet *entity.DaemonSet) error {
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	p, err := generateDaemonSet(session, daemonSet, ipam.ServerURL(sp.Config.IPAM))
+	if err != nil {
+		return err
+	}
+	_, err = sp.KubeCtl.CreateDaemonSet(p, daemonSet.Namespace)
+	return err
+}
+
+// UpdateDaemonSet will update the pod template of the running DaemonSet, the pods are replaced node by node
+func UpdateDaemonSet(sp *serviceprovider.Container, daemonSet *entity.DaemonSet) error {
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	p, err := generateDaemonSet(session, daemonSet, ipam.ServerURL(sp.Config.IPAM))
+	if err != nil {
+		return err
+	}
+	current, err := sp.KubeCtl.GetDaemonSet(daemonSet.Name, daemonSet.Namespace)
+	if err != nil {
+		return err
+	}
+
+	// the selector is immutable
+	current.Labels = p.Labels
+	current.Spec.Template = p.Spec.Template
+	_, err = sp.KubeCtl.UpdateDaemonSet(current, daemonSet.Namespace)
+	return err
+}
+
+// DeleteDaemonSet will delete the DaemonSet
+func DeleteDaemonSet(sp *serviceprovider.Container, daemonSet *entity.DaemonSet) error {
+	return sp.KubeCtl.DeleteDaemonSet(daemonSet.Name, daemonSet.Namespace)
+}
@@ -0,0 +1,158 @@
+package daemonset
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/deployment"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/ipam"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/suite"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func init() {
+	rand.Seed(time.Now().UnixNano())
+}
+
+type DaemonSetTestSuite struct {
+	suite.Suite
+	sp      *serviceprovider.Container
+	network entity.Network
+}
+
+func (suite *DaemonSetTestSuite) SetupSuite() {
+	cf := config.MustRead("../../config/testing.json")
+	suite.sp = serviceprovider.NewForTesting(cf)
+
+	suite.network = entity.Network{
+		ID:         bson.NewObjectId(),
+		Name:       namesgenerator.GetRandomName(0),
+		BridgeName: namesgenerator.GetRandomName(0),
+		Nodes: []entity.Node{
+			{Name: "node1"},
+			{Name: "node2"},
+		},
+		Subnets: []entity.Subnet{{CIDR: "10.1.0.0/24"}},
+	}
+	session := suite.sp.Mongo.NewSession()
+	defer session.Close()
+	session.Insert(entity.NetworkCollectionName, suite.network)
+}
+
+func (suite *DaemonSetTestSuite) TearDownSuite() {
+	session := suite.sp.Mongo.NewSession()
+	defer session.Close()
+	session.Remove(entity.NetworkCollectionName, "_id", suite.network.ID)
+}
+
+func TestDaemonSetSuite(t *testing.T) {
+	suite.Run(t, new(DaemonSetTestSuite))
+}
+
+func (suite *DaemonSetTestSuite) newDaemonSet() *entity.DaemonSet {
+	return &entity.DaemonSet{
+		ID:        bson.NewObjectId(),
+		Name:      namesgenerator.GetRandomName(0),
+		Namespace: "default",
+		Containers: []entity.Container{
+			{
+				Name:    namesgenerator.GetRandomName(0),
+				Image:   "busybox",
+				Command: []string{"sleep", "3600"},
+			},
+		},
+		NetworkType: entity.DeploymentCustomNetwork,
+		Networks: []entity.DeploymentNetwork{
+			{
+				Name:   suite.network.Name,
+				IfName: "eth1",
+			},
+		},
+	}
+}
+
+func (suite *DaemonSetTestSuite) TestCheckDaemonSetParameter() {
+	daemonSet := suite.newDaemonSet()
+	suite.NoError(CheckDaemonSetParameter(suite.sp, daemonSet))
+
+	daemonSet.NodeAffinity = []string{"node2", "node3"}
+	suite.NoError(CheckDaemonSetParameter(suite.sp, daemonSet))
+
+	daemonSet.NodeAffinity = []string{"node3"}
+	suite.Error(CheckDaemonSetParameter(suite.sp, daemonSet))
+
+	daemonSet = suite.newDaemonSet()
+	daemonSet.Networks[0].IPAddress = "10.1.0.10"
+	daemonSet.Networks[0].Netmask = "255.255.255.0"
+	suite.Error(CheckDaemonSetParameter(suite.sp, daemonSet))
+
+	daemonSet = suite.newDaemonSet()
+	daemonSet.Networks[0].Name = namesgenerator.GetRandomName(0)
+	suite.Error(CheckDaemonSetParameter(suite.sp, daemonSet))
+}
+
+func (suite *DaemonSetTestSuite) TestGenerateDaemonSet() {
+	session := suite.sp.Mongo.NewSession()
+	defer session.Close()
+
+	testCases := []struct {
+		caseName      string
+		networkType   string
+		nodeAffinity  []string
+		rHostNetwork  bool
+		rNodeAffinity []string
+	}{
+		{"hostNetwork", entity.DeploymentHostNetwork, []string{"node3"}, true, []string{"node3"}},
+		{"clusterNetwork", entity.DeploymentClusterNetwork, []string{}, false, nil},
+		{"customNetwork", entity.DeploymentCustomNetwork, []string{}, false, []string{"node1", "node2"}},
+		{"customNetworkWithNodeAffinity", entity.DeploymentCustomNetwork, []string{"node2", "node3"}, false, []string{"node2"}},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.caseName, func(t *testing.T) {
+			daemonSet := suite.newDaemonSet()
+			daemonSet.NetworkType = tc.networkType
+			daemonSet.NodeAffinity = tc.nodeAffinity
+
+			p, err := generateDaemonSet(session, daemonSet, ipam.DefaultServerURL)
+			suite.NoError(err)
+			suite.Equal(daemonSet.Name, p.Spec.Selector.MatchLabels[deployment.DefaultLabel])
+			suite.Equal(tc.rHostNetwork, p.Spec.Template.Spec.HostNetwork)
+			if tc.rNodeAffinity == nil {
+				suite.Nil(p.Spec.Template.Spec.Affinity.NodeAffinity)
+				return
+			}
+			suite.Equal(tc.rNodeAffinity, p.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions[0].Values)
+		})
+	}
+}
+
+func (suite *DaemonSetTestSuite) TestCreateDaemonSet() {
+	daemonSet := suite.newDaemonSet()
+	err := CreateDaemonSet(suite.sp, daemonSet)
+	suite.NoError(err)
+
+	daemonSet.Containers[0].Image = "busybox:1.29"
+	err = UpdateDaemonSet(suite.sp, daemonSet)
+	suite.NoError(err)
+	current, err := suite.sp.KubeCtl.GetDaemonSet(daemonSet.Name, daemonSet.Namespace)
+	suite.NoError(err)
+	suite.Equal("busybox:1.29", current.Spec.Template.Spec.Containers[0].Image)
+
+	err = DeleteDaemonSet(suite.sp, daemonSet)
+	suite.NoError(err)
+	_, err = suite.sp.KubeCtl.GetDaemonSet(daemonSet.Name, daemonSet.Namespace)
+	suite.Error(err)
+}
+
+func (suite *DaemonSetTestSuite) TestUpdateDaemonSetFail() {
+	daemonSet := suite.newDaemonSet()
+	err := UpdateDaemonSet(suite.sp, daemonSet)
+	suite.Error(err)
+}
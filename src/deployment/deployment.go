@@ -2,11 +2,14 @@ package deployment
 
 import (
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
 
 	"github.com/linkernetworks/mongo"
 	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/ipam"
+	"github.com/linkernetworks/vortex/src/kubeutils"
 	"github.com/linkernetworks/vortex/src/serviceprovider"
 	"github.com/linkernetworks/vortex/src/utils"
 
@@ -15,7 +18,9 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
+	mgo "gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 )
 
@@ -30,6 +35,9 @@ const ConfigMapNamePrefix = "configmap"
 // DefaultLabel is the label we used for our deploying application/deployment/pods
 const DefaultLabel = "vortex"
 
+// RevisionAnnotation is the annotation of the replica set revision Kubernetes assigns to the deployment
+const RevisionAnnotation = "deployment.kubernetes.io/revision"
+
 // NotificationEmailAccount is the label we do notify to user email account
 const NotificationEmailAccount = "email_account"
 
@@ -38,6 +46,31 @@ const NotificationEmailDomain = "email_domain"
 
 // CheckDeploymentParameter will Check Deployment's Parameter
 func CheckDeploymentParameter(sp *serviceprovider.Container, deploy *entity.Deployment) error {
+	//Check the strategy
+	if _, err := generateStrategy(deploy.Strategy); err != nil {
+		return err
+	}
+
+	return CheckPodParameter(sp, deploy)
+}
+
+// CheckStaticAddressReplicas rejects the replicas sharing the static addresses, the stored deployment is nil on create
+// and the deployments stored before the check keep their replicas until their networks are changed
+func CheckStaticAddressReplicas(stored *entity.Deployment, deploy *entity.Deployment) error {
+	if stored != nil && stored.NetworkType == deploy.NetworkType && equalField(reflect.ValueOf(stored.Networks), reflect.ValueOf(deploy.Networks)) {
+		return nil
+	}
+
+	// every replica gets the same static address
+	if HasStaticAddress(deploy) && deploy.Replicas > 1 {
+		return fmt.Errorf("the deployment with the static addresses can't have more than one replica")
+	}
+	return nil
+}
+
+// CheckPodParameter checks the volumes, the networks and the secrets used by the pods of the deployment,
+// the other workloads reuse it for their pods
+func CheckPodParameter(sp *serviceprovider.Container, deploy *entity.Deployment) error {
 	session := sp.Mongo.NewSession()
 	defer session.Close()
 
@@ -53,15 +86,19 @@ func CheckDeploymentParameter(sp *serviceprovider.Container, deploy *entity.Depl
 
 	//Check the network
 	for _, v := range deploy.Networks {
-		count, err := session.Count(entity.NetworkCollectionName, bson.M{"name": v.Name})
-		if err != nil {
-			return fmt.Errorf("check the network name error:%v", err)
-		} else if count == 0 {
+		network := entity.Network{}
+		if err := session.FindOne(entity.NetworkCollectionName, bson.M{"name": v.Name}, &network); err == mgo.ErrNotFound {
 			return fmt.Errorf("the network named %s doesn't exist", v.Name)
+		} else if err != nil {
+			return fmt.Errorf("check the network name error:%v", err)
+		}
+		if v.IPAddress == "" && len(network.Subnets) == 0 {
+			return fmt.Errorf("the network named %s has no subnet to allocate the address of %s", v.Name, v.IfName)
 		}
 	}
 
-	return nil
+	//Check the secret
+	return kubeutils.CheckSecrets(session, deploy.Namespace, deploy.Secrets, deploy.Containers)
 }
 
 func generateVolume(session *mongo.Session, deploy *entity.Deployment) ([]corev1.Volume, []corev1.VolumeMount, error) {
@@ -139,14 +176,16 @@ func generateNodeLabels(networks []entity.Network) []string {
 	return utils.Intersections(totalNames)
 }
 
+// generateClientCommand generates the arguments of the network client, the allocated address is passed by
+// kubeutils.GenerateIPAMClientCommand if the network has no static address
 func generateClientCommand(network entity.DeploymentNetwork) (command []string) {
-	ip := utils.IPToCIDR(network.IPAddress, network.Netmask)
-
 	command = []string{
 		"--server=unix:///tmp/vortex.sock",
 		"--bridge=" + network.BridgeName,
 		"--nic=" + network.IfName,
-		"--ip=" + ip,
+	}
+	if network.IPAddress != "" {
+		command = append(command, "--ip="+utils.IPToCIDR(network.IPAddress, network.Netmask))
 	}
 
 	if network.VlanTag != nil {
@@ -165,16 +204,60 @@ func generateClientCommand(network entity.DeploymentNetwork) (command []string)
 	return
 }
 
-func generateInitContainer(networks []entity.DeploymentNetwork) ([]corev1.Container, error) {
+// usesIPAM reports whether any network of the deployment gets its address from the IPAM
+func usesIPAM(networks []entity.DeploymentNetwork) bool {
+	for _, v := range networks {
+		if v.IPAddress == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// HasStaticAddress reports whether any network of the deployment has a static address, which can't be shared by the replicas
+func HasStaticAddress(deploy *entity.Deployment) bool {
+	if deploy.NetworkType != entity.DeploymentCustomNetwork {
+		return false
+	}
+	for _, v := range deploy.Networks {
+		if v.IPAddress != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func generateInitContainer(networks []entity.DeploymentNetwork, ipamURL string, ordinalAddress bool) ([]corev1.Container, error) {
 	containers := []corev1.Container{}
 
 	ethtools := []string{}
 	for i, v := range networks {
+		command := []string{"/go/bin/client"}
+		volumeMounts := []corev1.VolumeMount{
+			{
+				Name:      "grpc-sock",
+				MountPath: "/tmp/",
+			},
+		}
+		args := generateClientCommand(v)
+		// the address is requested by the IPAM container before the client sets up the interface
+		if v.IPAddress == "" {
+			containers = append(containers, kubeutils.GenerateIPAMContainer(i, ipamURL, v.Name, v.IfName))
+			command = kubeutils.GenerateIPAMClientCommand(command[0], v.IfName)
+			_, ipamMount := kubeutils.GenerateIPAMVolume()
+			volumeMounts = append(volumeMounts, ipamMount)
+		} else if ordinalAddress {
+			// the address is derived from the ordinal of the pod by the wrapped command
+			command = kubeutils.GenerateOrdinalClientCommand(command[0], v.IPAddress, v.Netmask)
+			base := v
+			base.IPAddress = ""
+			args = generateClientCommand(base)
+		}
 		containers = append(containers, corev1.Container{
 			Name:    fmt.Sprintf("init-network-client-%d", i),
-			Image:   "sdnvortex/network-controller:v0.4.9",
-			Command: []string{"/go/bin/client"},
-			Args:    generateClientCommand(v),
+			Image:   kubeutils.NetworkClientImage,
+			Command: command,
+			Args:    args,
 			Env: []corev1.EnvVar{
 				{
 					Name: "POD_NAME",
@@ -201,12 +284,7 @@ func generateInitContainer(networks []entity.DeploymentNetwork) ([]corev1.Contai
 					},
 				},
 			},
-			VolumeMounts: []corev1.VolumeMount{
-				{
-					Name:      "grpc-sock",
-					MountPath: "/tmp/",
-				},
-			},
+			VolumeMounts: volumeMounts,
 		})
 
 		if strings.HasPrefix(v.BridgeName, "netdev") {
@@ -230,10 +308,35 @@ func generateInitContainer(networks []entity.DeploymentNetwork) ([]corev1.Contai
 	return containers, nil
 }
 
+// NetworkNodes returns the nodes having all the custom networks, the pods using them can only run on these nodes
+func NetworkNodes(session *mongo.Session, networks []entity.DeploymentNetwork) ([]string, error) {
+	ret := []entity.Network{}
+	for _, v := range networks {
+		network := entity.Network{}
+		if err := session.FindOne(entity.NetworkCollectionName, bson.M{"name": v.Name}, &network); err != nil {
+			return nil, err
+		}
+		ret = append(ret, network)
+	}
+	return generateNodeLabels(ret), nil
+}
+
+// selectNodes limits the node affinity to the nodes of the networks, the pods can run on any node of the networks
+// if the node affinity is empty
+func selectNodes(nodeAffinity []string, networkNodes []string) []string {
+	if len(networkNodes) == 0 {
+		return nodeAffinity
+	}
+	if len(nodeAffinity) == 0 {
+		return networkNodes
+	}
+	return utils.Intersection(nodeAffinity, networkNodes)
+}
+
 //For the network, we will generate two things
 //[]string => a list of nodes and it will apply on nodeaffinity
 //[]corev1.Container => a list of init container we will apply on deploy
-func generateNetwork(session *mongo.Session, deploy *entity.Deployment) ([]string, []corev1.Container, error) {
+func generateNetwork(session *mongo.Session, deploy *entity.Deployment, ipamURL string, ordinalAddress bool) ([]string, []corev1.Container, error) {
 	networks := []entity.Network{}
 	for i, v := range deploy.Networks {
 		network := entity.Network{}
@@ -245,7 +348,7 @@ func generateNetwork(session *mongo.Session, deploy *entity.Deployment) ([]strin
 	}
 
 	nodes := generateNodeLabels(networks)
-	containers, err := generateInitContainer(deploy.Networks)
+	containers, err := generateInitContainer(deploy.Networks, ipamURL, ordinalAddress)
 	return nodes, containers, err
 }
 
@@ -286,31 +389,53 @@ func generateAffinity(nodeNames []string) *corev1.Affinity {
 	}
 }
 
-func generateEnvVars(deploy *entity.Deployment) []corev1.EnvVar {
-	envVars := []corev1.EnvVar{}
-
-	for k, v := range deploy.EnvVars {
-		envVars = append(envVars, corev1.EnvVar{
-			Name:  k,
-			Value: v,
-		})
+// generateStrategy converts the strategy of the deployment, Recreate if the type is empty
+func generateStrategy(strategy entity.DeploymentStrategy) (appsv1.DeploymentStrategy, error) {
+	switch strategy.Type {
+	case "", entity.DeploymentRecreateStrategy:
+		if strategy.MaxSurge != "" || strategy.MaxUnavailable != "" {
+			return appsv1.DeploymentStrategy{}, fmt.Errorf("The maxSurge and maxUnavailable are only for the %s strategy", entity.DeploymentRollingUpdateStrategy)
+		}
+		return appsv1.DeploymentStrategy{
+			Type: appsv1.RecreateDeploymentStrategyType,
+		}, nil
+	case entity.DeploymentRollingUpdateStrategy:
+		rollingUpdate := &appsv1.RollingUpdateDeployment{}
+		if strategy.MaxSurge != "" {
+			maxSurge := intstr.Parse(strategy.MaxSurge)
+			if _, err := intstr.GetValueFromIntOrPercent(&maxSurge, 100, true); err != nil {
+				return appsv1.DeploymentStrategy{}, fmt.Errorf("Invalid maxSurge %s: %v", strategy.MaxSurge, err)
+			}
+			rollingUpdate.MaxSurge = &maxSurge
+		}
+		if strategy.MaxUnavailable != "" {
+			maxUnavailable := intstr.Parse(strategy.MaxUnavailable)
+			if _, err := intstr.GetValueFromIntOrPercent(&maxUnavailable, 100, false); err != nil {
+				return appsv1.DeploymentStrategy{}, fmt.Errorf("Invalid maxUnavailable %s: %v", strategy.MaxUnavailable, err)
+			}
+			rollingUpdate.MaxUnavailable = &maxUnavailable
+		}
+		return appsv1.DeploymentStrategy{
+			Type:          appsv1.RollingUpdateDeploymentStrategyType,
+			RollingUpdate: rollingUpdate,
+		}, nil
+	default:
+		return appsv1.DeploymentStrategy{}, fmt.Errorf("Unsupported Deployment Strategy %s", strategy.Type)
 	}
-	return envVars
 }
 
-// CreateDeployment will Create Deployment
-func CreateDeployment(sp *serviceprovider.Container, deploy *entity.Deployment) error {
-	session := sp.Mongo.NewSession()
-	defer session.Close()
-
+// GeneratePodTemplate generates the pod template of the deployment, the other workloads reuse it for their pods.
+// If ordinalAddress is true, the static address of each custom network belongs to the pod of ordinal 0
+// and the pod of ordinal n gets the address plus n, e.g. the replicas of the StatefulSet
+func GeneratePodTemplate(session *mongo.Session, deploy *entity.Deployment, ipamURL string, ordinalAddress bool) (corev1.PodTemplateSpec, error) {
 	volumes, volumeMounts, err := generateVolume(session, deploy)
 	if err != nil {
-		return err
+		return corev1.PodTemplateSpec{}, err
 	}
 
 	configMaps, configMapMounts, err := generateConfigMap(deploy)
 	if err != nil {
-		return err
+		return corev1.PodTemplateSpec{}, err
 	}
 
 	nodeAffinity := deploy.NodeAffinity
@@ -321,10 +446,12 @@ func CreateDeployment(sp *serviceprovider.Container, deploy *entity.Deployment)
 		hostNetwork = true
 	case entity.DeploymentCustomNetwork:
 		var tmp []string
-		tmp, initContainers, err = generateNetwork(session, deploy)
-		if len(tmp) != 0 {
-			nodeAffinity = utils.Intersection(nodeAffinity, tmp)
+		tmp, initContainers, err = generateNetwork(session, deploy, ipamURL, ordinalAddress)
+		if usesIPAM(deploy.Networks) {
+			ipamVolume, _ := kubeutils.GenerateIPAMVolume()
+			volumes = append(volumes, ipamVolume)
 		}
+		nodeAffinity = selectNodes(nodeAffinity, tmp)
 	case entity.DeploymentClusterNetwork:
 		// For cluster network, we won't set the nodeAffinity and any network options.
 	default:
@@ -332,7 +459,7 @@ func CreateDeployment(sp *serviceprovider.Container, deploy *entity.Deployment)
 	}
 
 	if err != nil {
-		return err
+		return corev1.PodTemplateSpec{}, err
 	}
 
 	volumes = append(volumes, corev1.Volume{
@@ -346,43 +473,56 @@ func CreateDeployment(sp *serviceprovider.Container, deploy *entity.Deployment)
 
 	volumes = append(volumes, configMaps...)
 	volumeMounts = append(volumeMounts, configMapMounts...)
+	secrets, secretMounts := kubeutils.GenerateSecretVolumes(deploy.Secrets)
+	volumes = append(volumes, secrets...)
+	volumeMounts = append(volumeMounts, secretMounts...)
 
 	var containers []corev1.Container
-	var c corev1.Container
-
 	securityContext := generateContainerSecurity(deploy)
-	envVars := generateEnvVars(deploy)
-
 	for _, deployContainer := range deploy.Containers {
-		c = corev1.Container{
-			Name:            deployContainer.Name,
-			Image:           deployContainer.Image,
-			Command:         deployContainer.Command,
-			VolumeMounts:    volumeMounts,
-			SecurityContext: securityContext,
-			Env:             envVars,
-		}
-		if deployContainer.ResourceRequestCPU != 0 && deployContainer.ResourceRequestMemory != 0 {
-			c.Resources = corev1.ResourceRequirements{
-				Requests: corev1.ResourceList{
-					"cpu":    resource.MustParse(strconv.Itoa(deployContainer.ResourceRequestCPU) + "m"),
-					"memory": resource.MustParse(strconv.Itoa(deployContainer.ResourceRequestMemory) + "Mi"),
-				},
-			}
-		} else if deployContainer.ResourceRequestMemory != 0 {
-			c.Resources = corev1.ResourceRequirements{
-				Requests: corev1.ResourceList{
-					"memory": resource.MustParse(strconv.Itoa(deployContainer.ResourceRequestMemory) + "Mi"),
-				},
-			}
-		} else if deployContainer.ResourceRequestCPU != 0 {
-			c.Resources = corev1.ResourceRequirements{
-				Requests: corev1.ResourceList{
-					"cpu": resource.MustParse(strconv.Itoa(deployContainer.ResourceRequestCPU) + "m"),
-				},
-			}
-		}
-		containers = append(containers, c)
+		containers = append(containers, kubeutils.GenerateContainer(deployContainer, deploy.EnvVars, volumeMounts, securityContext))
+	}
+
+	imagePullSecrets, err := kubeutils.GenerateImagePullSecrets(session, deploy.Namespace, deploy.Containers)
+	if err != nil {
+		return corev1.PodTemplateSpec{}, err
+	}
+
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				// vortex default label
+				DefaultLabel: deploy.Name,
+			},
+		},
+		Spec: corev1.PodSpec{
+			InitContainers:   initContainers,
+			Containers:       containers,
+			Volumes:          volumes,
+			Affinity:         generateAffinity(nodeAffinity),
+			RestartPolicy:    corev1.RestartPolicyAlways,
+			HostNetwork:      hostNetwork,
+			ImagePullSecrets: imagePullSecrets,
+		},
+	}
+
+	// pass the same labels to pod
+	for k, v := range deploy.Labels {
+		template.ObjectMeta.Labels[k] = v
+	}
+	return template, nil
+}
+
+// generateDeployment generates the kubernetes deployment of the deployment
+func generateDeployment(session *mongo.Session, deploy *entity.Deployment, ipamURL string) (*appsv1.Deployment, error) {
+	strategy, err := generateStrategy(deploy.Strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := GeneratePodTemplate(session, deploy, ipamURL, false)
+	if err != nil {
+		return nil, err
 	}
 
 	p := appsv1.Deployment{
@@ -398,47 +538,180 @@ func CreateDeployment(sp *serviceprovider.Container, deploy *entity.Deployment)
 				},
 			},
 			Replicas: &deploy.Replicas,
-			Strategy: appsv1.DeploymentStrategy{
-				Type: appsv1.RecreateDeploymentStrategyType,
-			},
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{
-						// vortex default label
-						DefaultLabel: deploy.Name,
-					},
-				},
-				Spec: corev1.PodSpec{
-					InitContainers: initContainers,
-					Containers:     containers,
-					Volumes:        volumes,
-					Affinity:       generateAffinity(nodeAffinity),
-					RestartPolicy:  corev1.RestartPolicyAlways,
-					HostNetwork:    hostNetwork,
-					ImagePullSecrets: []corev1.LocalObjectReference{
-						{Name: "dockerhub-token"},
-					},
-				},
-			},
+			Strategy: strategy,
+			Template: template,
 		},
 	}
+	return &p, nil
+}
 
-	// pass the same labels to pod
-	for k, v := range deploy.Labels {
-		p.Spec.Template.ObjectMeta.Labels[k] = v
+// CreateDeployment will Create Deployment
+func CreateDeployment(sp *serviceprovider.Container, deploy *entity.Deployment) error {
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	p, err := generateDeployment(session, deploy, ipam.ServerURL(sp.Config.IPAM))
+	if err != nil {
+		return err
 	}
+	_, err = sp.KubeCtl.CreateDeployment(p, deploy.Namespace)
+	return err
+}
 
-	_, err = sp.KubeCtl.CreateDeployment(&p, deploy.Namespace)
+// UpdateDeployment will update the running deployment to the spec of the deployment in place,
+// the pods are replaced by the strategy of the deployment
+func UpdateDeployment(sp *serviceprovider.Container, deploy *entity.Deployment) error {
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	p, err := generateDeployment(session, deploy, ipam.ServerURL(sp.Config.IPAM))
+	if err != nil {
+		return err
+	}
+	current, err := sp.KubeCtl.GetDeployment(deploy.Name, deploy.Namespace)
+	if err != nil {
+		return err
+	}
+
+	// the selector is immutable
+	current.Labels = p.Labels
+	// the autoscaler owns the replicas of an autoscaled deployment
+	if !deploy.IsEnableAutoscale {
+		current.Spec.Replicas = p.Spec.Replicas
+	}
+	current.Spec.Strategy = p.Spec.Strategy
+	current.Spec.Template = p.Spec.Template
+	_, err = sp.KubeCtl.UpdateDeployment(current, deploy.Namespace)
 	return err
 }
 
+// ReplicaSetRevision returns the revision of the current replica set of the running deployment,
+// it's empty until Kubernetes handles the latest spec
+func ReplicaSetRevision(sp *serviceprovider.Container, deploy *entity.Deployment) (string, error) {
+	current, err := sp.KubeCtl.GetDeployment(deploy.Name, deploy.Namespace)
+	if err != nil {
+		return "", err
+	}
+	if current.Status.ObservedGeneration < current.Generation {
+		return "", nil
+	}
+	return current.Annotations[RevisionAnnotation], nil
+}
+
+// ChangedFields returns the fields of the deployment spec changed by the update,
+// the identity, the owner and the autoscaler are not compared
+func ChangedFields(stored *entity.Deployment, update *entity.Deployment) []string {
+	ignored := map[string]bool{
+		"ID":                true,
+		"OwnerID":           true,
+		"CreatedBy":         true,
+		"CreatedAt":         true,
+		"IsEnableAutoscale": true,
+		"AutoscalerInfo":    true,
+	}
+
+	changed := []string{}
+	storedValue, updateValue := reflect.ValueOf(*stored), reflect.ValueOf(*update)
+	for i := 0; i < storedValue.NumField(); i++ {
+		field := storedValue.Type().Field(i)
+		if ignored[field.Name] || equalField(storedValue.Field(i), updateValue.Field(i)) {
+			continue
+		}
+		changed = append(changed, strings.Split(field.Tag.Get("json"), ",")[0])
+	}
+	return changed
+}
+
+// equalField compares the field values, the nil and the empty maps or slices are equal
+func equalField(a reflect.Value, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Map, reflect.Slice:
+		if a.Len() == 0 && b.Len() == 0 {
+			return true
+		}
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
 // DeleteDeployment will delete a deployment
 func DeleteDeployment(sp *serviceprovider.Container, deploy *entity.Deployment) error {
 	return sp.KubeCtl.DeleteDeployment(deploy.Name, deploy.Namespace)
 }
 
-// CreateAutoscaler will create a autoscaler
-func CreateAutoscaler(sp *serviceprovider.Container, autoscalerInfo entity.AutoscalerInfo) error {
+// ErrBehaviorUnsupported is returned when the autoscaler has the behavior, the autoscalers are created by
+// the autoscaling/v2beta1 API which has no behavior
+var ErrBehaviorUnsupported = fmt.Errorf("The scaling behavior isn't supported by the autoscaling/v2beta1 API of the autoscalers")
+
+// generateMetrics generates the metrics of the autoscaler, the resource metric first
+func generateMetrics(autoscalerInfo entity.AutoscalerInfo) ([]v2beta1.MetricSpec, error) {
+	metrics := []v2beta1.MetricSpec{}
+	if autoscalerInfo.ResourceName != "" {
+		utilization := autoscalerInfo.TargetAverageUtilization
+		metrics = append(metrics, v2beta1.MetricSpec{
+			Type: v2beta1.ResourceMetricSourceType,
+			Resource: &v2beta1.ResourceMetricSource{
+				Name:                     autoscalerInfo.ResourceName,
+				TargetAverageUtilization: &utilization,
+			},
+		})
+	}
+
+	for _, m := range autoscalerInfo.Metrics {
+		switch m.Type {
+		case entity.AutoscalerPodsMetric:
+			target, err := resource.ParseQuantity(m.TargetAverageValue)
+			if err != nil {
+				return nil, fmt.Errorf("invalid target of the metric %s: %v", m.MetricName, err)
+			}
+			metrics = append(metrics, v2beta1.MetricSpec{
+				Type: v2beta1.PodsMetricSourceType,
+				Pods: &v2beta1.PodsMetricSource{
+					MetricName:         m.MetricName,
+					TargetAverageValue: target,
+				},
+			})
+		case entity.AutoscalerObjectMetric:
+			if m.Target == nil {
+				return nil, fmt.Errorf("the object of the metric %s is required", m.MetricName)
+			}
+			target, err := resource.ParseQuantity(m.TargetValue)
+			if err != nil {
+				return nil, fmt.Errorf("invalid target of the metric %s: %v", m.MetricName, err)
+			}
+			metrics = append(metrics, v2beta1.MetricSpec{
+				Type: v2beta1.ObjectMetricSourceType,
+				Object: &v2beta1.ObjectMetricSource{
+					Target: v2beta1.CrossVersionObjectReference{
+						Kind:       m.Target.Kind,
+						Name:       m.Target.Name,
+						APIVersion: m.Target.APIVersion,
+					},
+					MetricName:  m.MetricName,
+					TargetValue: target,
+				},
+			})
+		default:
+			return nil, fmt.Errorf("unsupported metric type %s", m.Type)
+		}
+	}
+
+	if len(metrics) == 0 {
+		return nil, fmt.Errorf("the autoscaler needs at least one metric")
+	}
+	return metrics, nil
+}
+
+// generateAutoscaler generates the HorizontalPodAutoscaler of the deployment
+func generateAutoscaler(autoscalerInfo entity.AutoscalerInfo) (*v2beta1.HorizontalPodAutoscaler, error) {
+	if autoscalerInfo.Behavior != nil {
+		return nil, ErrBehaviorUnsupported
+	}
+	metrics, err := generateMetrics(autoscalerInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	minReplicas := autoscalerInfo.MinReplicas
 	autoscaler := v2beta1.HorizontalPodAutoscaler{
 		ObjectMeta: metav1.ObjectMeta{
 			// use deployment name to name autoscaler's name
@@ -451,20 +724,21 @@ func CreateAutoscaler(sp *serviceprovider.Container, autoscalerInfo entity.Autos
 				Kind:       "Deployment",
 				Name:       autoscalerInfo.ScaleTargetRefName,
 			},
-			MinReplicas: &autoscalerInfo.MinReplicas,
+			MinReplicas: &minReplicas,
 			MaxReplicas: autoscalerInfo.MaxReplicas,
-			Metrics: []v2beta1.MetricSpec{
-				{
-					Type: v2beta1.ResourceMetricSourceType,
-					Resource: &v2beta1.ResourceMetricSource{
-						Name:                     autoscalerInfo.ResourceName,
-						TargetAverageUtilization: &autoscalerInfo.TargetAverageUtilization,
-					},
-				},
-			},
+			Metrics:     metrics,
 		},
 	}
-	_, err := sp.KubeCtl.CreateAutoscaler(&autoscaler, autoscalerInfo.Namespace)
+	return &autoscaler, nil
+}
+
+// CreateAutoscaler will create a autoscaler
+func CreateAutoscaler(sp *serviceprovider.Container, autoscalerInfo entity.AutoscalerInfo) error {
+	autoscaler, err := generateAutoscaler(autoscalerInfo)
+	if err != nil {
+		return err
+	}
+	_, err = sp.KubeCtl.CreateAutoscaler(autoscaler, autoscalerInfo.Namespace)
 	return err
 }
 
@@ -2,16 +2,21 @@ package deployment
 
 import (
 	"math/rand"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/linkernetworks/vortex/src/config"
 	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/ipam"
 	"github.com/linkernetworks/vortex/src/serviceprovider"
 	"github.com/moby/moby/pkg/namesgenerator"
 	"github.com/stretchr/testify/suite"
 
+	appsv1 "k8s.io/api/apps/v1"
+	v2beta1 "k8s.io/api/autoscaling/v2beta1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"gopkg.in/mgo.v2/bson"
 )
@@ -69,6 +74,74 @@ func (suite *DeploymentTestSuite) TestCheckDeploymentParameter() {
 	suite.NoError(err)
 }
 
+func (suite *DeploymentTestSuite) TestCheckDeploymentParameterNetworks() {
+	session := suite.sp.Mongo.NewSession()
+	defer session.Close()
+
+	network := entity.Network{
+		ID:   bson.NewObjectId(),
+		Name: namesgenerator.GetRandomName(0),
+	}
+	session.Insert(entity.NetworkCollectionName, network)
+	defer session.Remove(entity.NetworkCollectionName, "name", network.Name)
+	pool := entity.Network{
+		ID:      bson.NewObjectId(),
+		Name:    namesgenerator.GetRandomName(1),
+		Subnets: []entity.Subnet{{CIDR: "10.1.0.0/24"}},
+	}
+	session.Insert(entity.NetworkCollectionName, pool)
+	defer session.Remove(entity.NetworkCollectionName, "name", pool.Name)
+
+	deploy := &entity.Deployment{
+		ID:          bson.NewObjectId(),
+		Name:        namesgenerator.GetRandomName(0),
+		NetworkType: entity.DeploymentCustomNetwork,
+		Replicas:    3,
+		Networks: []entity.DeploymentNetwork{
+			{Name: pool.Name, IfName: "eth1"},
+		},
+	}
+	suite.NoError(CheckDeploymentParameter(suite.sp, deploy))
+
+	// the network has no subnet to allocate from
+	deploy.Networks[0].Name = network.Name
+	suite.Error(CheckDeploymentParameter(suite.sp, deploy))
+
+	// the static address is checked with the replicas by CheckStaticAddressReplicas
+	deploy.Networks[0].IPAddress = "10.1.0.2"
+	deploy.Networks[0].Netmask = "255.255.255.0"
+	suite.NoError(CheckDeploymentParameter(suite.sp, deploy))
+}
+
+func (suite *DeploymentTestSuite) TestCheckStaticAddressReplicas() {
+	deploy := &entity.Deployment{
+		Name:        namesgenerator.GetRandomName(0),
+		NetworkType: entity.DeploymentCustomNetwork,
+		Replicas:    3,
+		Networks: []entity.DeploymentNetwork{
+			{Name: "pool", IfName: "eth1"},
+		},
+	}
+	suite.NoError(CheckStaticAddressReplicas(nil, deploy))
+
+	// the replicas can't share the static address
+	deploy.Networks[0].IPAddress = "10.1.0.2"
+	deploy.Networks[0].Netmask = "255.255.255.0"
+	suite.Error(CheckStaticAddressReplicas(nil, deploy))
+
+	// the deployment stored before the check can be updated without changing its networks
+	stored := *deploy
+	stored.Networks = []entity.DeploymentNetwork{deploy.Networks[0]}
+	deploy.Replicas = 4
+	suite.NoError(CheckStaticAddressReplicas(&stored, deploy))
+
+	// but the changed networks are checked
+	deploy.Networks[0].IPAddress = "10.1.0.3"
+	suite.Error(CheckStaticAddressReplicas(&stored, deploy))
+	deploy.Replicas = 1
+	suite.NoError(CheckStaticAddressReplicas(&stored, deploy))
+}
+
 func (suite *DeploymentTestSuite) TestCheckDeploymentParameterFail() {
 	testCases := []struct {
 		caseName string
@@ -280,6 +353,12 @@ func (suite *DeploymentTestSuite) TestGenerateNodeLabels() {
 	suite.Equal([]string{"node4", "node5"}, names)
 }
 
+func (suite *DeploymentTestSuite) TestSelectNodes() {
+	suite.Equal([]string{"node1"}, selectNodes([]string{"node1"}, nil))
+	suite.Equal([]string{"node1", "node2"}, selectNodes([]string{}, []string{"node1", "node2"}))
+	suite.Equal([]string{"node2"}, selectNodes([]string{"node2", "node3"}, []string{"node1", "node2"}))
+}
+
 func (suite *DeploymentTestSuite) TestGenerateClientCommand() {
 	bName := namesgenerator.GetRandomName(0)
 	ifName := namesgenerator.GetRandomName(0)
@@ -311,6 +390,17 @@ func (suite *DeploymentTestSuite) TestGenerateClientCommand() {
 	}
 	suite.Equal(ans, command)
 
+	// the allocated address is passed by the IPAM client command
+	deployNetwork.IPAddress = ""
+	deployNetwork.Netmask = ""
+	command = generateClientCommand(deployNetwork)
+	ans = []string{
+		"--server=unix:///tmp/vortex.sock",
+		"--bridge=" + bName,
+		"--nic=" + ifName,
+		"--vlan=123",
+	}
+	suite.Equal(ans, command)
 }
 
 func (suite *DeploymentTestSuite) TestGenerateNetwork() {
@@ -342,12 +432,72 @@ func (suite *DeploymentTestSuite) TestGenerateNetwork() {
 		},
 	}
 
-	nodes, containers, err := generateNetwork(session, deploy)
+	nodes, containers, err := generateNetwork(session, deploy, ipam.DefaultServerURL, false)
 	suite.NoError(err)
 	suite.Equal(1, len(containers))
 	suite.Equal(0, len(nodes))
 }
 
+func (suite *DeploymentTestSuite) TestGenerateNetworkWithIPAM() {
+	network := entity.Network{
+		ID:         bson.NewObjectId(),
+		Name:       namesgenerator.GetRandomName(0),
+		BridgeName: namesgenerator.GetRandomName(0),
+		Subnets:    []entity.Subnet{{CIDR: "10.1.0.0/24"}},
+	}
+	session := suite.sp.Mongo.NewSession()
+	defer session.Close()
+
+	session.Insert(entity.NetworkCollectionName, network)
+	defer session.Remove(entity.NetworkCollectionName, "name", network.Name)
+
+	deploy := &entity.Deployment{
+		ID:   bson.NewObjectId(),
+		Name: namesgenerator.GetRandomName(0),
+		Networks: []entity.DeploymentNetwork{
+			{
+				Name:   network.Name,
+				IfName: "eth1",
+			},
+		},
+	}
+
+	_, containers, err := generateNetwork(session, deploy, "http://vortex:7890", false)
+	suite.NoError(err)
+	suite.Equal(2, len(containers))
+	suite.Equal("init-ipam-0", containers[0].Name)
+	suite.Contains(containers[0].Env, corev1.EnvVar{Name: "IPAM_URL", Value: "http://vortex:7890"})
+	suite.Equal("init-network-client-0", containers[1].Name)
+	suite.Equal("/bin/sh", containers[1].Command[0])
+	suite.Len(containers[1].VolumeMounts, 2)
+}
+
+func (suite *DeploymentTestSuite) TestGenerateInitContainerWithOrdinalAddress() {
+	networks := []entity.DeploymentNetwork{
+		{
+			Name:       namesgenerator.GetRandomName(0),
+			IfName:     "eth1",
+			IPAddress:  "10.1.0.10",
+			Netmask:    "255.255.255.0",
+			BridgeName: "br0",
+		},
+	}
+
+	containers, err := generateInitContainer(networks, ipam.DefaultServerURL, true)
+	suite.NoError(err)
+	suite.Equal(1, len(containers))
+	suite.Equal("/bin/sh", containers[0].Command[0])
+	suite.Contains(containers[0].Command[2], "167837706")
+	for _, arg := range containers[0].Args {
+		suite.False(strings.HasPrefix(arg, "--ip="))
+	}
+
+	containers, err = generateInitContainer(networks, ipam.DefaultServerURL, false)
+	suite.NoError(err)
+	suite.Equal([]string{"/go/bin/client"}, containers[0].Command)
+	suite.Contains(containers[0].Args, "--ip=10.1.0.10/24")
+}
+
 func (suite *DeploymentTestSuite) TestGenerateNetworkFail() {
 	networkName := namesgenerator.GetRandomName(0)
 	deployName := namesgenerator.GetRandomName(0)
@@ -368,7 +518,7 @@ func (suite *DeploymentTestSuite) TestGenerateNetworkFail() {
 	session := suite.sp.Mongo.NewSession()
 	defer session.Close()
 
-	nodes, containers, err := generateNetwork(session, deploy)
+	nodes, containers, err := generateNetwork(session, deploy, ipam.DefaultServerURL, false)
 	suite.Error(err)
 	suite.Nil(nodes)
 	suite.Nil(containers)
@@ -464,6 +614,23 @@ func (suite *DeploymentTestSuite) TestCreateDeploymentWithNetworkTypes() {
 			false,
 			[]string{"node1"},
 		},
+		{
+			"customNetworkWithoutNodeAffinity",
+			&entity.Deployment{
+				ID:          bson.NewObjectId(),
+				Name:        namesgenerator.GetRandomName(0),
+				Containers:  []entity.Container{},
+				NetworkType: entity.DeploymentCustomNetwork,
+				Networks: []entity.DeploymentNetwork{
+					{
+						Name: networkName,
+					},
+				},
+				NodeAffinity: []string{},
+			},
+			false,
+			[]string{"node1", "node2", "node3"},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -527,6 +694,240 @@ func (suite *DeploymentTestSuite) TestCreateDeploymentWithAutoscaler() {
 	err = DeleteAutoscaler(suite.sp, autoscaler)
 	suite.NoError(err)
 
+	// the autoscaling/v2beta1 API has no behavior
+	window := int32(300)
+	autoscaler.Behavior = &entity.AutoscalerBehavior{
+		ScaleDown: &entity.AutoscalerScalingRules{StabilizationWindowSeconds: &window},
+	}
+	err = CreateAutoscaler(suite.sp, autoscaler)
+	suite.Equal(ErrBehaviorUnsupported, err)
+
 	err = DeleteDeployment(suite.sp, deploy)
 	suite.NoError(err)
 }
+
+func (suite *DeploymentTestSuite) TestGenerateAutoscaler() {
+	autoscalerInfo := entity.AutoscalerInfo{
+		Namespace:                "default",
+		ScaleTargetRefName:       "packet-processor",
+		ResourceName:             corev1.ResourceCPU,
+		MinReplicas:              1,
+		MaxReplicas:              10,
+		TargetAverageUtilization: 80,
+		Metrics: []entity.AutoscalerMetric{
+			{
+				Type:               entity.AutoscalerPodsMetric,
+				MetricName:         "container_network_receive_packets",
+				TargetAverageValue: "10k",
+			},
+			{
+				Type:        entity.AutoscalerObjectMetric,
+				MetricName:  "requests",
+				Target:      &entity.AutoscalerObjectReference{Kind: "Service", Name: "packet-processor", APIVersion: "v1"},
+				TargetValue: "500",
+			},
+		},
+	}
+
+	autoscaler, err := generateAutoscaler(autoscalerInfo)
+	suite.NoError(err)
+	suite.Equal("packet-processor", autoscaler.Name)
+	suite.Equal(int32(1), *autoscaler.Spec.MinReplicas)
+	suite.Len(autoscaler.Spec.Metrics, 3)
+	suite.Equal(v2beta1.ResourceMetricSourceType, autoscaler.Spec.Metrics[0].Type)
+	suite.Equal(int32(80), *autoscaler.Spec.Metrics[0].Resource.TargetAverageUtilization)
+	suite.Equal("container_network_receive_packets", autoscaler.Spec.Metrics[1].Pods.MetricName)
+	suite.Equal(int64(10000), autoscaler.Spec.Metrics[1].Pods.TargetAverageValue.Value())
+	suite.Equal("Service", autoscaler.Spec.Metrics[2].Object.Target.Kind)
+	suite.Equal(int64(500), autoscaler.Spec.Metrics[2].Object.TargetValue.Value())
+
+	// the custom metrics only
+	autoscalerInfo.ResourceName = ""
+	autoscaler, err = generateAutoscaler(autoscalerInfo)
+	suite.NoError(err)
+	suite.Len(autoscaler.Spec.Metrics, 2)
+	suite.Equal(v2beta1.PodsMetricSourceType, autoscaler.Spec.Metrics[0].Type)
+
+	// the autoscaling/v2beta1 API has no behavior
+	autoscalerInfo.Behavior = &entity.AutoscalerBehavior{
+		ScaleDown: &entity.AutoscalerScalingRules{
+			Policies: []entity.AutoscalerScalingPolicy{
+				{Type: "Percent", Value: 10, PeriodSeconds: 60},
+			},
+		},
+	}
+	_, err = generateAutoscaler(autoscalerInfo)
+	suite.Equal(ErrBehaviorUnsupported, err)
+}
+
+func (suite *DeploymentTestSuite) TestGenerateAutoscalerFail() {
+	autoscalerInfo := entity.AutoscalerInfo{
+		Namespace:          "default",
+		ScaleTargetRefName: "packet-processor",
+		MinReplicas:        1,
+		MaxReplicas:        10,
+	}
+	_, err := generateAutoscaler(autoscalerInfo)
+	suite.Error(err)
+
+	autoscalerInfo.Metrics = []entity.AutoscalerMetric{
+		{Type: entity.AutoscalerPodsMetric, MetricName: "container_network_receive_packets", TargetAverageValue: "many"},
+	}
+	_, err = generateAutoscaler(autoscalerInfo)
+	suite.Error(err)
+
+	autoscalerInfo.Metrics = []entity.AutoscalerMetric{
+		{Type: entity.AutoscalerObjectMetric, MetricName: "requests", TargetValue: "500"},
+	}
+	_, err = generateAutoscaler(autoscalerInfo)
+	suite.Error(err)
+}
+
+func (suite *DeploymentTestSuite) TestGenerateStrategy() {
+	strategy, err := generateStrategy(entity.DeploymentStrategy{})
+	suite.NoError(err)
+	suite.Equal(appsv1.RecreateDeploymentStrategyType, strategy.Type)
+
+	strategy, err = generateStrategy(entity.DeploymentStrategy{
+		Type:           entity.DeploymentRollingUpdateStrategy,
+		MaxSurge:       "2",
+		MaxUnavailable: "25%",
+	})
+	suite.NoError(err)
+	suite.Equal(appsv1.RollingUpdateDeploymentStrategyType, strategy.Type)
+	suite.Equal(2, strategy.RollingUpdate.MaxSurge.IntValue())
+	suite.Equal("25%", strategy.RollingUpdate.MaxUnavailable.String())
+
+	testCases := []struct {
+		caseName string
+		strategy entity.DeploymentStrategy
+	}{
+		{"UnknownType", entity.DeploymentStrategy{Type: "BlueGreen"}},
+		{"RecreateWithMaxSurge", entity.DeploymentStrategy{Type: entity.DeploymentRecreateStrategy, MaxSurge: "1"}},
+		{"InvalidMaxSurge", entity.DeploymentStrategy{Type: entity.DeploymentRollingUpdateStrategy, MaxSurge: "many"}},
+		{"InvalidMaxUnavailable", entity.DeploymentStrategy{Type: entity.DeploymentRollingUpdateStrategy, MaxUnavailable: "10 %"}},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.caseName, func(t *testing.T) {
+			_, err := generateStrategy(tc.strategy)
+			suite.Error(err)
+		})
+	}
+}
+
+func (suite *DeploymentTestSuite) TestUpdateDeployment() {
+	containers := []entity.Container{
+		{
+			Name:    namesgenerator.GetRandomName(0),
+			Image:   "busybox:1.28",
+			Command: []string{"sleep", "3600"},
+		},
+	}
+
+	deploy := &entity.Deployment{
+		ID:          bson.NewObjectId(),
+		Name:        namesgenerator.GetRandomName(0),
+		Containers:  containers,
+		NetworkType: entity.DeploymentClusterNetwork,
+		Replicas:    1,
+	}
+	err := CreateDeployment(suite.sp, deploy)
+	suite.NoError(err)
+	defer DeleteDeployment(suite.sp, deploy)
+
+	deploy.Containers[0].Image = "busybox:1.29"
+	deploy.Replicas = 3
+	deploy.Strategy = entity.DeploymentStrategy{
+		Type:     entity.DeploymentRollingUpdateStrategy,
+		MaxSurge: "1",
+	}
+	err = UpdateDeployment(suite.sp, deploy)
+	suite.NoError(err)
+
+	result, err := suite.sp.KubeCtl.GetDeployment(deploy.Name, deploy.Namespace)
+	suite.NoError(err)
+	suite.Equal(int32(3), *result.Spec.Replicas)
+	suite.Equal("busybox:1.29", result.Spec.Template.Spec.Containers[0].Image)
+	suite.Equal(appsv1.RollingUpdateDeploymentStrategyType, result.Spec.Strategy.Type)
+	suite.Equal(deploy.Name, result.Spec.Selector.MatchLabels[DefaultLabel])
+
+	// the autoscaler keeps the replicas
+	deploy.IsEnableAutoscale = true
+	deploy.Replicas = 1
+	err = UpdateDeployment(suite.sp, deploy)
+	suite.NoError(err)
+	result, err = suite.sp.KubeCtl.GetDeployment(deploy.Name, deploy.Namespace)
+	suite.NoError(err)
+	suite.Equal(int32(3), *result.Spec.Replicas)
+}
+
+func (suite *DeploymentTestSuite) TestUpdateDeploymentFail() {
+	deploy := &entity.Deployment{
+		ID:          bson.NewObjectId(),
+		Name:        namesgenerator.GetRandomName(0),
+		NetworkType: entity.DeploymentClusterNetwork,
+	}
+	err := UpdateDeployment(suite.sp, deploy)
+	suite.Error(err)
+}
+
+func (suite *DeploymentTestSuite) TestChangedFields() {
+	stored := &entity.Deployment{
+		ID:        bson.NewObjectId(),
+		Name:      "awesome",
+		Namespace: "default",
+		Labels:    map[string]string{"app": "awesome"},
+		Replicas:  1,
+	}
+	update := &entity.Deployment{
+		Name:       "awesome",
+		Namespace:  "default",
+		Labels:     map[string]string{"app": "awesome"},
+		EnvVars:    map[string]string{},
+		Volumes:    []entity.DeploymentVolume{},
+		Replicas:   1,
+		Capability: false,
+	}
+	suite.Empty(ChangedFields(stored, update))
+
+	update.Replicas = 2
+	update.EnvVars["DEBUG"] = "true"
+	update.IsEnableAutoscale = true
+	suite.Equal([]string{"envVars", "replicas"}, ChangedFields(stored, update))
+}
+
+func (suite *DeploymentTestSuite) TestReplicaSetRevision() {
+	deploy := &entity.Deployment{
+		Name:      namesgenerator.GetRandomName(0),
+		Namespace: "default",
+	}
+	_, err := ReplicaSetRevision(suite.sp, deploy)
+	suite.Error(err)
+
+	current := appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        deploy.Name,
+			Generation:  2,
+			Annotations: map[string]string{RevisionAnnotation: "1"},
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 1,
+		},
+	}
+	_, err = suite.sp.KubeCtl.CreateDeployment(&current, deploy.Namespace)
+	suite.NoError(err)
+	defer DeleteDeployment(suite.sp, deploy)
+
+	// the latest spec is not handled yet
+	revision, err := ReplicaSetRevision(suite.sp, deploy)
+	suite.NoError(err)
+	suite.Equal("", revision)
+
+	current.Annotations[RevisionAnnotation] = "2"
+	current.Status.ObservedGeneration = 2
+	_, err = suite.sp.KubeCtl.UpdateDeployment(&current, deploy.Namespace)
+	suite.NoError(err)
+	revision, err = ReplicaSetRevision(suite.sp, deploy)
+	suite.NoError(err)
+	suite.Equal("2", revision)
+}
@@ -0,0 +1,146 @@
+package deployment
+
+import (
+	"sort"
+
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/kubeutils"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MaxStatusEvents is the number of the latest events returned with the deployment status
+const MaxStatusEvents = 50
+
+// GetDeploymentStatus aggregates the live status of the deployment, the pods of its replica sets and their recent events
+func GetDeploymentStatus(sp *serviceprovider.Container, deploy *entity.Deployment) (*entity.DeploymentStatus, error) {
+	current, err := sp.KubeCtl.GetDeployment(deploy.Name, deploy.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &entity.DeploymentStatus{
+		Name:                current.Name,
+		Namespace:           current.Namespace,
+		Replicas:            current.Status.Replicas,
+		UpdatedReplicas:     current.Status.UpdatedReplicas,
+		ReadyReplicas:       current.Status.ReadyReplicas,
+		AvailableReplicas:   current.Status.AvailableReplicas,
+		UnavailableReplicas: current.Status.UnavailableReplicas,
+		ObservedGeneration:  current.Status.ObservedGeneration,
+		Generation:          current.Generation,
+		Conditions:          generateConditions(current.Status.Conditions),
+		Pods:                []entity.DeploymentPodStatus{},
+		Events:              []entity.Event{},
+	}
+
+	// the replica sets and the pods of the deployment carry the labels of its selector
+	selector, err := metav1.LabelSelectorAsSelector(current.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+	replicaSets, err := sp.KubeCtl.GetReplicaSets(deploy.Namespace, selector.String())
+	if err != nil {
+		return nil, err
+	}
+	// involved objects of the events, by kind and name
+	involved := map[string]map[string]bool{
+		"Deployment": {current.Name: true},
+		"ReplicaSet": {},
+		"Pod":        {},
+	}
+	owners := map[string]*appsv1.ReplicaSet{}
+	for _, rs := range replicaSets {
+		if metav1.IsControlledBy(rs, current) {
+			owners[string(rs.UID)] = rs
+			involved["ReplicaSet"][rs.Name] = true
+		}
+	}
+
+	pods, err := sp.KubeCtl.GetPodsBySelector(deploy.Namespace, selector.String())
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods {
+		owner := metav1.GetControllerOf(pod)
+		if owner == nil {
+			continue
+		}
+		rs, ok := owners[string(owner.UID)]
+		if !ok {
+			continue
+		}
+		involved["Pod"][pod.Name] = true
+		status.Pods = append(status.Pods, generatePodStatus(pod, rs.Name))
+	}
+	sort.Slice(status.Pods, func(i, j int) bool {
+		return status.Pods[i].Name < status.Pods[j].Name
+	})
+
+	for kind, names := range involved {
+		for name := range names {
+			events, err := sp.KubeCtl.GetEvents(deploy.Namespace, kind, name)
+			if err != nil {
+				return nil, err
+			}
+			for _, event := range events {
+				// not every clientset honors the field selector
+				if event.InvolvedObject.Kind == kind && event.InvolvedObject.Name == name {
+					status.Events = append(status.Events, generateEvent(event))
+				}
+			}
+		}
+	}
+	sort.SliceStable(status.Events, func(i, j int) bool {
+		return status.Events[i].LastTimestamp.After(status.Events[j].LastTimestamp)
+	})
+	if len(status.Events) > MaxStatusEvents {
+		status.Events = status.Events[:MaxStatusEvents]
+	}
+	return status, nil
+}
+
+func generateConditions(conditions []appsv1.DeploymentCondition) []entity.DeploymentCondition {
+	ret := []entity.DeploymentCondition{}
+	for _, c := range conditions {
+		ret = append(ret, entity.DeploymentCondition{
+			Type:               string(c.Type),
+			Status:             string(c.Status),
+			Reason:             c.Reason,
+			Message:            c.Message,
+			LastUpdateTime:     c.LastUpdateTime.Time,
+			LastTransitionTime: c.LastTransitionTime.Time,
+		})
+	}
+	return ret
+}
+
+func generatePodStatus(pod *corev1.Pod, replicaSet string) entity.DeploymentPodStatus {
+	return entity.DeploymentPodStatus{
+		Name:           pod.Name,
+		ReplicaSet:     replicaSet,
+		Node:           pod.Spec.NodeName,
+		IP:             pod.Status.PodIP,
+		Phase:          string(pod.Status.Phase),
+		Reason:         pod.Status.Reason,
+		Message:        pod.Status.Message,
+		InitContainers: kubeutils.GenerateContainerStatuses(pod.Status.InitContainerStatuses),
+		Containers:     kubeutils.GenerateContainerStatuses(pod.Status.ContainerStatuses),
+	}
+}
+
+func generateEvent(event *corev1.Event) entity.Event {
+	return entity.Event{
+		Kind:           event.InvolvedObject.Kind,
+		Name:           event.InvolvedObject.Name,
+		Type:           event.Type,
+		Reason:         event.Reason,
+		Message:        event.Message,
+		Count:          event.Count,
+		FirstTimestamp: event.FirstTimestamp.Time,
+		LastTimestamp:  event.LastTimestamp.Time,
+	}
+}
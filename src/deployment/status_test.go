@@ -0,0 +1,168 @@
+package deployment
+
+import (
+	"time"
+
+	"github.com/linkernetworks/vortex/src/entity"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func (suite *DeploymentTestSuite) TestGetDeploymentStatus() {
+	namespace := "status"
+	deploy := &entity.Deployment{
+		Name:      "status-deployment",
+		Namespace: namespace,
+	}
+	_, err := GetDeploymentStatus(suite.sp, deploy)
+	suite.Error(err)
+
+	controller := true
+	current := appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       deploy.Name,
+			UID:        types.UID("deployment-uid"),
+			Generation: 1,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": deploy.Name}},
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration:  1,
+			Replicas:            1,
+			UnavailableReplicas: 1,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue, Reason: "ReplicaSetUpdated"},
+			},
+		},
+	}
+	_, err = suite.sp.KubeCtl.CreateDeployment(&current, namespace)
+	suite.NoError(err)
+	defer DeleteDeployment(suite.sp, deploy)
+
+	replicaSets := []appsv1.ReplicaSet{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "status-deployment-1",
+				UID:    types.UID("replicaset-uid"),
+				Labels: map[string]string{"app": deploy.Name},
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "Deployment", Name: deploy.Name, UID: current.UID, Controller: &controller},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "other-1",
+				UID:    types.UID("other-uid"),
+				Labels: map[string]string{"app": "other"},
+			},
+		},
+	}
+	for i := range replicaSets {
+		_, err = suite.sp.KubeCtl.Clientset.AppsV1().ReplicaSets(namespace).Create(&replicaSets[i])
+		suite.NoError(err)
+	}
+
+	pods := []corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "status-deployment-1-abcde",
+				Labels: map[string]string{"app": deploy.Name},
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "ReplicaSet", Name: "status-deployment-1", UID: "replicaset-uid", Controller: &controller},
+				},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodPending,
+				InitContainerStatuses: []corev1.ContainerStatus{
+					{
+						Name:         "init-network-client-0",
+						RestartCount: 3,
+						State: corev1.ContainerState{
+							Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+						},
+						LastTerminationState: corev1.ContainerState{
+							Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Message: "bridge not found"},
+						},
+					},
+				},
+				ContainerStatuses: []corev1.ContainerStatus{
+					{
+						Name: "busybox",
+						State: corev1.ContainerState{
+							Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"},
+						},
+					},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "other-1-abcde",
+				Labels: map[string]string{"app": "other"},
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "ReplicaSet", Name: "other-1", UID: "other-uid", Controller: &controller},
+				},
+			},
+		},
+	}
+	for i := range pods {
+		_, err = suite.sp.KubeCtl.CreatePod(&pods[i], namespace)
+		suite.NoError(err)
+	}
+
+	now := time.Now()
+	events := []corev1.Event{
+		{
+			ObjectMeta:     metav1.ObjectMeta{Name: "event-1"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Deployment", Name: deploy.Name},
+			Reason:         "ScalingReplicaSet",
+			LastTimestamp:  metav1.NewTime(now.Add(-time.Minute)),
+		},
+		{
+			ObjectMeta:     metav1.ObjectMeta{Name: "event-2"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "status-deployment-1-abcde"},
+			Reason:         "BackOff",
+			LastTimestamp:  metav1.NewTime(now),
+		},
+		{
+			ObjectMeta:     metav1.ObjectMeta{Name: "event-3"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "other-1-abcde"},
+			Reason:         "Pulled",
+			LastTimestamp:  metav1.NewTime(now),
+		},
+	}
+	for i := range events {
+		_, err = suite.sp.KubeCtl.Clientset.CoreV1().Events(namespace).Create(&events[i])
+		suite.NoError(err)
+	}
+
+	status, err := GetDeploymentStatus(suite.sp, deploy)
+	suite.NoError(err)
+	suite.Equal(int32(1), status.UnavailableReplicas)
+	suite.Len(status.Conditions, 1)
+	suite.Equal("Progressing", status.Conditions[0].Type)
+
+	suite.Len(status.Pods, 1)
+	pod := status.Pods[0]
+	suite.Equal("status-deployment-1-abcde", pod.Name)
+	suite.Equal("status-deployment-1", pod.ReplicaSet)
+	suite.Equal("Pending", pod.Phase)
+	suite.Len(pod.InitContainers, 1)
+	suite.Equal("waiting", pod.InitContainers[0].State)
+	suite.Equal("CrashLoopBackOff", pod.InitContainers[0].Reason)
+	suite.Equal("bridge not found", pod.InitContainers[0].Message)
+	suite.Equal(int32(1), pod.InitContainers[0].ExitCode)
+	suite.Equal(int32(3), pod.InitContainers[0].RestartCount)
+	suite.Len(pod.Containers, 1)
+	suite.Equal("PodInitializing", pod.Containers[0].Reason)
+
+	// the latest event comes first
+	suite.Len(status.Events, 2)
+	suite.Equal("BackOff", status.Events[0].Reason)
+	suite.Equal("ScalingReplicaSet", status.Events[1].Reason)
+}
@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// The const for personal API tokens
+const (
+	APITokenCollectionName string = "api_tokens"
+	// APITokenPrefix tells API tokens apart from session JWTs
+	APITokenPrefix string = "vtx_"
+
+	APITokenReadOnly  string = "read-only"
+	APITokenReadWrite string = "read-write"
+)
+
+// APIToken is the structure for a named personal API token. Only the SHA256 of the token is stored.
+type APIToken struct {
+	ID      bson.ObjectId `bson:"_id,omitempty" json:"id" validate:"-"`
+	OwnerID bson.ObjectId `bson:"ownerID,omitempty" json:"ownerID" validate:"-"`
+	Name    string        `bson:"name" json:"name" validate:"required"`
+	// Token is only returned when the token is created
+	Token     string `bson:"-" json:"token,omitempty" validate:"-"`
+	TokenHash string `bson:"tokenHash" json:"-" validate:"-"`
+	Scope     string `bson:"scope" json:"scope" validate:"required,eq=read-only|eq=read-write"`
+	// Namespaces restricts the token to these namespaces, an empty list means no restriction
+	Namespaces []string   `bson:"namespaces" json:"namespaces" validate:"dive,k8sname"`
+	ExpiresAt  *time.Time `bson:"expiresAt,omitempty" json:"expiresAt,omitempty" validate:"-"`
+	CreatedAt  *time.Time `bson:"createdAt,omitempty" json:"createdAt,omitempty" validate:"-"`
+}
+
+// GetCollection - get model mongo collection name.
+func (t APIToken) GetCollection() string {
+	return APITokenCollectionName
+}
@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// AuditCollectionName's const
+const (
+	AuditCollectionName string = "audit"
+)
+
+// The outcome of the audited request
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+// AuditRecord is the structure for an audited API call
+type AuditRecord struct {
+	ID         bson.ObjectId     `bson:"_id,omitempty" json:"id"`
+	UserID     string            `bson:"userID,omitempty" json:"userID,omitempty"`
+	Role       string            `bson:"role,omitempty" json:"role,omitempty"`
+	APITokenID string            `bson:"apiTokenID,omitempty" json:"apiTokenID,omitempty"`
+	Method     string            `bson:"method" json:"method"`
+	Route      string            `bson:"route" json:"route"`
+	Path       string            `bson:"path" json:"path"`
+	Resource   string            `bson:"resource" json:"resource"`
+	Parameters map[string]string `bson:"parameters,omitempty" json:"parameters,omitempty"`
+	// Body is the JSON request body with the secrets redacted
+	Body       string `bson:"body,omitempty" json:"body,omitempty"`
+	RemoteAddr string `bson:"remoteAddr" json:"remoteAddr"`
+	Status     int    `bson:"status" json:"status"`
+	Outcome    string `bson:"outcome" json:"outcome"`
+	// Latency is the handling time in milliseconds
+	Latency int64 `bson:"latency" json:"latency"`
+	// TerminalSessionID is the session of the started terminal
+	TerminalSessionID string     `bson:"terminalSessionID,omitempty" json:"terminalSessionID,omitempty"`
+	ExpiresAt         *time.Time `bson:"expiresAt,omitempty" json:"expiresAt,omitempty"`
+	CreatedAt         time.Time  `bson:"createdAt" json:"createdAt"`
+}
+
+// GetCollection - get model mongo collection name.
+func (r AuditRecord) GetCollection() string {
+	return AuditCollectionName
+}
+
+// AuditQuery is the structure for filtering the audit records
+type AuditQuery struct {
+	UserID   string
+	Resource string
+	Outcome  string
+	Since    time.Time
+	Until    time.Time
+}
@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	// DaemonSetCollectionName is a const string
+	DaemonSetCollectionName string = "daemonsets"
+)
+
+// DaemonSet is the structure for the DaemonSet info, the pods are described like the Deployment.
+// One pod runs on every node selected by the node affinity and the nodes of the custom networks,
+// the addresses of the custom networks are allocated from their subnets
+type DaemonSet struct {
+	ID           bson.ObjectId       `bson:"_id,omitempty" json:"id" validate:"-"`
+	OwnerID      bson.ObjectId       `bson:"ownerID,omitempty" json:"ownerID" validate:"-"`
+	Name         string              `bson:"name" json:"name" validate:"required,k8sname"`
+	Namespace    string              `bson:"namespace" json:"namespace" validate:"required"`
+	Labels       map[string]string   `bson:"labels,omitempty" json:"labels" validate:"required,dive,keys,printascii,endkeys,required,printascii"`
+	EnvVars      map[string]string   `bson:"envVars,omitempty" json:"envVars" validate:"required,dive,keys,printascii,endkeys,required,printascii"`
+	Containers   []Container         `bson:"containers" json:"containers" validate:"required,dive,required"`
+	Volumes      []DeploymentVolume  `bson:"volumes,omitempty" json:"volumes" validate:"required,dive,required"`
+	ConfigMaps   []DeploymentConfig  `bson:"configMaps,omitempty" json:"configMaps" validate:"required,dive,required"`
+	Secrets      []SecretVolume      `bson:"secrets,omitempty" json:"secrets,omitempty" validate:"omitempty,dive,required"`
+	Networks     []DeploymentNetwork `bson:"networks,omitempty" json:"networks" validate:"required,dive,required"`
+	Capability   bool                `bson:"capability" json:"capability" validate:"-"`
+	NetworkType  string              `bson:"networkType" json:"networkType" validate:"required,eq=host|eq=cluster|eq=custom"`
+	NodeAffinity []string            `bson:"nodeAffinity" json:"nodeAffinity" validate:"required"`
+	CreatedBy    User                `json:"createdBy" validate:"-"`
+	CreatedAt    *time.Time          `bson:"createdAt,omitempty" json:"createdAt,omitempty" validate:"-"`
+}
+
+// GetCollection - get model mongo collection name.
+func (m DaemonSet) GetCollection() string {
+	return DaemonSetCollectionName
+}
+
+// PodTemplate returns the deployment describing the pods of the DaemonSet
+func (m DaemonSet) PodTemplate() Deployment {
+	return Deployment{
+		ID:           m.ID,
+		OwnerID:      m.OwnerID,
+		Name:         m.Name,
+		Namespace:    m.Namespace,
+		Labels:       m.Labels,
+		EnvVars:      m.EnvVars,
+		Containers:   m.Containers,
+		Volumes:      m.Volumes,
+		ConfigMaps:   m.ConfigMaps,
+		Secrets:      m.Secrets,
+		Networks:     m.Networks,
+		Capability:   m.Capability,
+		NetworkType:  m.NetworkType,
+		NodeAffinity: m.NodeAffinity,
+	}
+}
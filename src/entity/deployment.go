@@ -17,6 +17,10 @@ const (
 	DeploymentClusterNetwork = "cluster"
 	// DeploymentCustomNetwork is custom which means the custom netwokr we created before, it support the OVS and DPDK network for additional network interface card
 	DeploymentCustomNetwork = "custom"
+	// DeploymentRecreateStrategy kills all the existing pods before creating the new ones
+	DeploymentRecreateStrategy = "Recreate"
+	// DeploymentRollingUpdateStrategy replaces the pods gradually
+	DeploymentRollingUpdateStrategy = "RollingUpdate"
 )
 
 // DeploymentRouteGw is the structure for add IP routing table
@@ -30,14 +34,15 @@ type DeploymentRouteIntf struct {
 	DstCIDR string `bson:"dstCIDR" json:"dstCIDR" validate:"required,cidrv4"`
 }
 
-// DeploymentNetwork is the structure for deployment network info
+// DeploymentNetwork is the structure for deployment network info,
+// the address is allocated from the subnets of the network when the pod starts if IPAddress is empty
 type DeploymentNetwork struct {
 	Name   string `bson:"name" json:"name" validate:"required"`
 	IfName string `bson:"ifName" json:"ifName" validate:"required"`
 	// can not validate nil
 	VlanTag    *int32                `bson:"vlanTag" json:"vlanTag" validate:"-"`
-	IPAddress  string                `bson:"ipAddress" json:"ipAddress" validate:"required,ipv4"`
-	Netmask    string                `bson:"netmask" json:"netmask" validate:"required,ipv4"`
+	IPAddress  string                `bson:"ipAddress" json:"ipAddress" validate:"omitempty,ipv4"`
+	Netmask    string                `bson:"netmask" json:"netmask" validate:"omitempty,ipv4"`
 	RoutesGw   []DeploymentRouteGw   `bson:"routesGw,omitempty" json:"routesGw" validate:"required,dive,required"`
 	RoutesIntf []DeploymentRouteIntf `bson:"routesIntf,omitempty" json:"routesIntf" validate:"required,dive,required"`
 
@@ -57,6 +62,15 @@ type DeploymentConfig struct {
 	MountPath string `bson:"mountPath" json:"mountPath" validate:"required"`
 }
 
+// DeploymentStrategy is the structure for replacing the pods of the deployment
+type DeploymentStrategy struct {
+	// Type is Recreate or RollingUpdate, Recreate if it's empty
+	Type string `bson:"type,omitempty" json:"type,omitempty" validate:"omitempty,eq=Recreate|eq=RollingUpdate"`
+	// MaxSurge and MaxUnavailable of the RollingUpdate are the number or the percentage of the pods, e.g. "25%"
+	MaxSurge       string `bson:"maxSurge,omitempty" json:"maxSurge,omitempty" validate:"-"`
+	MaxUnavailable string `bson:"maxUnavailable,omitempty" json:"maxUnavailable,omitempty" validate:"-"`
+}
+
 // Deployment is the structure for deployment info
 type Deployment struct {
 	ID                bson.ObjectId       `bson:"_id,omitempty" json:"id" validate:"-"`
@@ -68,6 +82,7 @@ type Deployment struct {
 	Containers        []Container         `bson:"containers" json:"containers" validate:"required,dive,required"`
 	Volumes           []DeploymentVolume  `bson:"volumes,omitempty" json:"volumes" validate:"required,dive,required"`
 	ConfigMaps        []DeploymentConfig  `bson:"configMaps,omitempty" json:"configMaps" validate:"required,dive,required"`
+	Secrets           []SecretVolume      `bson:"secrets,omitempty" json:"secrets,omitempty" validate:"omitempty,dive,required"`
 	Networks          []DeploymentNetwork `bson:"networks,omitempty" json:"networks" validate:"required,dive,required"`
 	Capability        bool                `bson:"capability" json:"capability" validate:"-"`
 	NetworkType       string              `bson:"networkType" json:"networkType" validate:"required,eq=host|eq=cluster|eq=custom"`
@@ -77,7 +92,8 @@ type Deployment struct {
 	CreatedBy         User                `json:"createdBy" validate:"-"`
 	CreatedAt         *time.Time          `bson:"createdAt,omitempty" json:"createdAt,omitempty" validate:"-"`
 
-	Replicas int32 `bson:"replicas" json:"replicas" validate:"required"`
+	Replicas int32              `bson:"replicas" json:"replicas" validate:"required"`
+	Strategy DeploymentStrategy `bson:"strategy" json:"strategy"`
 }
 
 // AutoscalerInfo is the structure for deploying a autoscaler with a deployment
@@ -85,12 +101,65 @@ type AutoscalerInfo struct {
 	Name      string `bson:"name" json:"name" validate:"required,k8sname"`
 	Namespace string `bson:"namespace" json:"namespace" validate:"required"`
 	// ScaleTargetRef is deployment name
-	ScaleTargetRefName          string              `bson:"scaleTargetRefName" json:"scaleTargetRefName" validate:"required,k8sname"`
-	IsCapableAutoscaleResources [2]string           `bson:"isCapableAutoscaleResources" json:"isCapableAutoscaleResources" validate:"-"`
-	ResourceName                corev1.ResourceName `bson:"resourceName" json:"resourceName" validate:"required,eq=cpu|eq=memory"`
-	MinReplicas                 int32               `bson:"minReplicas" json:"minReplicas" validate:"required,numeric"`
-	MaxReplicas                 int32               `bson:"maxReplicas" json:"maxReplicas" validate:"required,numeric"`
-	TargetAverageUtilization    int32               `bson:"targetAverageUtilization" json:"targetAverageUtilization" validate:"required,numeric"`
+	ScaleTargetRefName          string    `bson:"scaleTargetRefName" json:"scaleTargetRefName" validate:"required,k8sname"`
+	IsCapableAutoscaleResources [2]string `bson:"isCapableAutoscaleResources" json:"isCapableAutoscaleResources" validate:"-"`
+	// ResourceName and TargetAverageUtilization are the resource metric, it's optional if the other metrics are set
+	ResourceName             corev1.ResourceName `bson:"resourceName" json:"resourceName" validate:"omitempty,eq=cpu|eq=memory"`
+	MinReplicas              int32               `bson:"minReplicas" json:"minReplicas" validate:"required,numeric"`
+	MaxReplicas              int32               `bson:"maxReplicas" json:"maxReplicas" validate:"required,numeric,gtefield=MinReplicas"`
+	TargetAverageUtilization int32               `bson:"targetAverageUtilization" json:"targetAverageUtilization" validate:"numeric,min=0"`
+	// Metrics are the custom metrics from Prometheus, the replicas are the max of the ones proposed by each metric
+	Metrics  []AutoscalerMetric  `bson:"metrics,omitempty" json:"metrics,omitempty" validate:"omitempty,dive,required"`
+	Behavior *AutoscalerBehavior `bson:"behavior,omitempty" json:"behavior,omitempty" validate:"omitempty"`
+}
+
+const (
+	// AutoscalerPodsMetric is averaged across the pods of the deployment, e.g. the received packets per second
+	AutoscalerPodsMetric = "Pods"
+	// AutoscalerObjectMetric describes another object in the namespace, e.g. the requests per second of the service
+	AutoscalerObjectMetric = "Object"
+)
+
+// AutoscalerMetric is the structure for the custom metric of the autoscaler, it's served by the custom metrics API of Prometheus
+type AutoscalerMetric struct {
+	// Type is Pods or Object
+	Type       string `bson:"type" json:"type" validate:"required,eq=Pods|eq=Object"`
+	MetricName string `bson:"metricName" json:"metricName" validate:"required,promname"`
+	// TargetAverageValue is the target of the Pods metric
+	TargetAverageValue string `bson:"targetAverageValue,omitempty" json:"targetAverageValue,omitempty" validate:"omitempty,k8squantity"`
+	// Target and TargetValue are the described object and the target of the Object metric
+	Target      *AutoscalerObjectReference `bson:"target,omitempty" json:"target,omitempty" validate:"omitempty"`
+	TargetValue string                     `bson:"targetValue,omitempty" json:"targetValue,omitempty" validate:"omitempty,k8squantity"`
+}
+
+// AutoscalerObjectReference is the structure for the object described by the Object metric
+type AutoscalerObjectReference struct {
+	Kind       string `bson:"kind" json:"kind" validate:"required"`
+	Name       string `bson:"name" json:"name" validate:"required,k8sname"`
+	APIVersion string `bson:"apiVersion,omitempty" json:"apiVersion,omitempty" validate:"-"`
+}
+
+// AutoscalerBehavior is the structure for how fast the autoscaler scales up and down
+type AutoscalerBehavior struct {
+	ScaleUp   *AutoscalerScalingRules `bson:"scaleUp,omitempty" json:"scaleUp,omitempty" validate:"omitempty"`
+	ScaleDown *AutoscalerScalingRules `bson:"scaleDown,omitempty" json:"scaleDown,omitempty" validate:"omitempty"`
+}
+
+// AutoscalerScalingRules is the structure for the scaling rules in one direction
+type AutoscalerScalingRules struct {
+	// StabilizationWindowSeconds is how long the past recommendations are considered to prevent the flapping
+	StabilizationWindowSeconds *int32 `bson:"stabilizationWindowSeconds,omitempty" json:"stabilizationWindowSeconds,omitempty" validate:"omitempty,min=0,max=3600"`
+	// SelectPolicy is Max, Min or Disabled, Max if it's empty
+	SelectPolicy string                    `bson:"selectPolicy,omitempty" json:"selectPolicy,omitempty" validate:"omitempty,eq=Max|eq=Min|eq=Disabled"`
+	Policies     []AutoscalerScalingPolicy `bson:"policies,omitempty" json:"policies,omitempty" validate:"omitempty,dive,required"`
+}
+
+// AutoscalerScalingPolicy is the structure for the max change of the replicas in the period
+type AutoscalerScalingPolicy struct {
+	// Type is Pods or Percent
+	Type          string `bson:"type" json:"type" validate:"required,eq=Pods|eq=Percent"`
+	Value         int32  `bson:"value" json:"value" validate:"required,min=1"`
+	PeriodSeconds int32  `bson:"periodSeconds" json:"periodSeconds" validate:"required,min=1,max=1800"`
 }
 
 // GetCollection - get model mongo collection name.
@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// DeploymentRevisionCollectionName's const
+const (
+	DeploymentRevisionCollectionName string = "deployment_revisions"
+)
+
+// DeploymentRevision is the structure for a spec applied to a deployment, the revisions are numbered from 1
+type DeploymentRevision struct {
+	ID           bson.ObjectId `bson:"_id,omitempty" json:"id"`
+	DeploymentID bson.ObjectId `bson:"deploymentID" json:"deploymentID"`
+	Revision     int           `bson:"revision" json:"revision"`
+	// ReplicaSetRevision is the revision Kubernetes assigns to the replica set of the spec
+	ReplicaSetRevision string `bson:"replicaSetRevision,omitempty" json:"replicaSetRevision,omitempty"`
+	// RollbackOf is the revision the spec is rolled back to
+	RollbackOf int           `bson:"rollbackOf,omitempty" json:"rollbackOf,omitempty"`
+	Spec       Deployment    `bson:"spec" json:"spec"`
+	AppliedBy  bson.ObjectId `bson:"appliedBy,omitempty" json:"appliedBy,omitempty"`
+	CreatedAt  time.Time     `bson:"createdAt" json:"createdAt"`
+}
+
+// GetCollection - get model mongo collection name.
+func (r DeploymentRevision) GetCollection() string {
+	return DeploymentRevisionCollectionName
+}
@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"time"
+)
+
+// DeploymentCondition is the structure for the condition of the running deployment
+type DeploymentCondition struct {
+	Type               string    `json:"type"`
+	Status             string    `json:"status"`
+	Reason             string    `json:"reason"`
+	Message            string    `json:"message"`
+	LastUpdateTime     time.Time `json:"lastUpdateTime"`
+	LastTransitionTime time.Time `json:"lastTransitionTime"`
+}
+
+// ContainerStatus is the structure for the state of the container in the pod
+type ContainerStatus struct {
+	Name         string `json:"name"`
+	Image        string `json:"image"`
+	Ready        bool   `json:"ready"`
+	RestartCount int32  `json:"restartCount"`
+	// State is waiting, running or terminated
+	State    string `json:"state"`
+	Reason   string `json:"reason,omitempty"`
+	Message  string `json:"message,omitempty"`
+	ExitCode int32  `json:"exitCode,omitempty"`
+}
+
+// DeploymentPodStatus is the structure for the status of the pod created by the deployment
+type DeploymentPodStatus struct {
+	Name           string            `json:"name"`
+	ReplicaSet     string            `json:"replicaSet"`
+	Node           string            `json:"node"`
+	IP             string            `json:"ip"`
+	Phase          string            `json:"phase"`
+	Reason         string            `json:"reason,omitempty"`
+	Message        string            `json:"message,omitempty"`
+	InitContainers []ContainerStatus `json:"initContainers"`
+	Containers     []ContainerStatus `json:"containers"`
+}
+
+// Event is the structure for the Kubernetes event of the object
+type Event struct {
+	Kind           string    `json:"kind"`
+	Name           string    `json:"name"`
+	Type           string    `json:"type"`
+	Reason         string    `json:"reason"`
+	Message        string    `json:"message"`
+	Count          int32     `json:"count"`
+	FirstTimestamp time.Time `json:"firstTimestamp"`
+	LastTimestamp  time.Time `json:"lastTimestamp"`
+}
+
+// DeploymentStatus is the structure for the live rollout status of the deployment
+type DeploymentStatus struct {
+	Name                string                `json:"name"`
+	Namespace           string                `json:"namespace"`
+	Replicas            int32                 `json:"replicas"`
+	UpdatedReplicas     int32                 `json:"updatedReplicas"`
+	ReadyReplicas       int32                 `json:"readyReplicas"`
+	AvailableReplicas   int32                 `json:"availableReplicas"`
+	UnavailableReplicas int32                 `json:"unavailableReplicas"`
+	ObservedGeneration  int64                 `json:"observedGeneration"`
+	Generation          int64                 `json:"generation"`
+	Conditions          []DeploymentCondition `json:"conditions"`
+	Pods                []DeploymentPodStatus `json:"pods"`
+	Events              []Event               `json:"events"`
+}
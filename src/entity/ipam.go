@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// the const for IPAllocationCollectionName
+const (
+	IPAllocationCollectionName string = "ip_allocations"
+)
+
+// IPAllocationRequest is the request of the init container of the pod for the address of its interface
+type IPAllocationRequest struct {
+	Network   string `json:"network" validate:"required"`
+	IfName    string `json:"ifName" validate:"required"`
+	PodName   string `json:"podName" validate:"required"`
+	Namespace string `json:"namespace" validate:"required"`
+	PodUID    string `json:"podUID" validate:"required"`
+}
+
+// IPAllocation is the address of the network allocated to the interface of the pod
+type IPAllocation struct {
+	ID      bson.ObjectId `bson:"_id,omitempty" json:"id"`
+	Network string        `bson:"network" json:"network"`
+	Address string        `bson:"address" json:"address"`
+	// CIDR is the address with the prefix length of its subnet, e.g. 10.1.0.2/24
+	CIDR      string     `bson:"cidr" json:"cidr"`
+	Gateway   string     `bson:"gateway,omitempty" json:"gateway,omitempty"`
+	IfName    string     `bson:"ifName" json:"ifName"`
+	PodName   string     `bson:"podName" json:"podName"`
+	Namespace string     `bson:"namespace" json:"namespace"`
+	PodUID    string     `bson:"podUID" json:"podUID"`
+	CreatedAt *time.Time `bson:"createdAt,omitempty" json:"createdAt,omitempty"`
+}
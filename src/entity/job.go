@@ -0,0 +1,139 @@
+package entity
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	// JobCollectionName is a const string
+	JobCollectionName string = "jobs"
+	// CronJobCollectionName is a const string
+	CronJobCollectionName string = "cronjobs"
+	// JobRunning means the job has not completed or failed yet
+	JobRunning = "Running"
+	// JobSucceeded means the job has the successful pods of its completions
+	JobSucceeded = "Succeeded"
+	// JobFailed means the job ran out of its retries or its deadline
+	JobFailed = "Failed"
+	// CronJobAllowConcurrent allows the runs of the CronJob to overlap
+	CronJobAllowConcurrent = "Allow"
+	// CronJobForbidConcurrent skips the new run if the previous one is still running
+	CronJobForbidConcurrent = "Forbid"
+	// CronJobReplaceConcurrent replaces the running run with the new one
+	CronJobReplaceConcurrent = "Replace"
+)
+
+// JobSpec is the structure for the pods of the job and how they are run, it's shared by the Job and the CronJob
+type JobSpec struct {
+	Labels     map[string]string `bson:"labels,omitempty" json:"labels" validate:"required,dive,keys,printascii,endkeys,required,printascii"`
+	EnvVars    map[string]string `bson:"envVars,omitempty" json:"envVars" validate:"required,dive,keys,printascii,endkeys,required,printascii"`
+	Containers []Container       `bson:"containers" json:"containers" validate:"required,dive,required"`
+	Volumes    []PodVolume       `bson:"volumes,omitempty" json:"volumes" validate:"required,dive,required"`
+	Networks   []PodNetwork      `bson:"networks,omitempty" json:"networks" validate:"required,dive,required"`
+	Secrets    []SecretVolume    `bson:"secrets,omitempty" json:"secrets,omitempty" validate:"omitempty,dive,required"`
+	// RestartPolicy is OnFailure or Never, the failed containers are restarted in place or the failed pods are replaced
+	RestartPolicy string   `bson:"restartPolicy" json:"restartPolicy" validate:"required,eq=OnFailure|eq=Never"`
+	Capability    bool     `bson:"capability" json:"capability" validate:"-"`
+	NetworkType   string   `bson:"networkType" json:"networkType" validate:"required,eq=host|eq=cluster|eq=custom"`
+	NodeAffinity  []string `bson:"nodeAffinity" json:"nodeAffinity" validate:"required"`
+
+	// Completions is the number of the pods which must succeed, 1 if it's 0
+	Completions int32 `bson:"completions,omitempty" json:"completions,omitempty" validate:"min=0"`
+	// Parallelism is the max number of the pods running at the same time, 1 if it's 0
+	Parallelism int32 `bson:"parallelism,omitempty" json:"parallelism,omitempty" validate:"min=0"`
+	// BackoffLimit is the number of the retries before the job fails, 6 if it's nil
+	BackoffLimit *int32 `bson:"backoffLimit,omitempty" json:"backoffLimit,omitempty" validate:"omitempty,min=0"`
+	// ActiveDeadlineSeconds is the time limit of the job, no limit if it's 0
+	ActiveDeadlineSeconds int64 `bson:"activeDeadlineSeconds,omitempty" json:"activeDeadlineSeconds,omitempty" validate:"min=0"`
+}
+
+// Pod returns the pod describing the pods of the job
+func (m JobSpec) Pod(name string, namespace string) Pod {
+	return Pod{
+		Name:          name,
+		Namespace:     namespace,
+		Labels:        m.Labels,
+		EnvVars:       m.EnvVars,
+		Containers:    m.Containers,
+		Volumes:       m.Volumes,
+		Networks:      m.Networks,
+		Secrets:       m.Secrets,
+		RestartPolicy: m.RestartPolicy,
+		Capability:    m.Capability,
+		NetworkType:   m.NetworkType,
+		NodeAffinity:  m.NodeAffinity,
+	}
+}
+
+// Job is the structure for the Job info, its pods run to the completion with the retries
+type Job struct {
+	ID        bson.ObjectId `bson:"_id,omitempty" json:"id" validate:"-"`
+	OwnerID   bson.ObjectId `bson:"ownerID,omitempty" json:"ownerID" validate:"-"`
+	Name      string        `bson:"name" json:"name" validate:"required,k8sname"`
+	Namespace string        `bson:"namespace" json:"namespace" validate:"required"`
+	JobSpec   `bson:",inline"`
+	CreatedBy User       `json:"createdBy" validate:"-"`
+	CreatedAt *time.Time `bson:"createdAt,omitempty" json:"createdAt,omitempty" validate:"-"`
+}
+
+// GetCollection - get model mongo collection name.
+func (m Job) GetCollection() string {
+	return JobCollectionName
+}
+
+// CronJob is the structure for the CronJob info, it runs the job on the schedule
+type CronJob struct {
+	ID        bson.ObjectId `bson:"_id,omitempty" json:"id" validate:"-"`
+	OwnerID   bson.ObjectId `bson:"ownerID,omitempty" json:"ownerID" validate:"-"`
+	Name      string        `bson:"name" json:"name" validate:"required,k8sname,max=52"`
+	Namespace string        `bson:"namespace" json:"namespace" validate:"required"`
+	// Schedule is in the cron format, e.g. "*/5 * * * *"
+	Schedule string `bson:"schedule" json:"schedule" validate:"required"`
+	// ConcurrencyPolicy is Allow, Forbid or Replace, Allow if it's empty
+	ConcurrencyPolicy string `bson:"concurrencyPolicy,omitempty" json:"concurrencyPolicy,omitempty" validate:"omitempty,eq=Allow|eq=Forbid|eq=Replace"`
+	// Suspend stops the scheduled runs, the manual runs can still be triggered
+	Suspend bool `bson:"suspend" json:"suspend" validate:"-"`
+	// SuccessfulJobsHistoryLimit and FailedJobsHistoryLimit are the number of the finished runs kept, 3 and 1 if they are nil
+	SuccessfulJobsHistoryLimit *int32 `bson:"successfulJobsHistoryLimit,omitempty" json:"successfulJobsHistoryLimit,omitempty" validate:"omitempty,min=0"`
+	FailedJobsHistoryLimit     *int32 `bson:"failedJobsHistoryLimit,omitempty" json:"failedJobsHistoryLimit,omitempty" validate:"omitempty,min=0"`
+	JobSpec                    `bson:",inline"`
+	CreatedBy                  User       `json:"createdBy" validate:"-"`
+	CreatedAt                  *time.Time `bson:"createdAt,omitempty" json:"createdAt,omitempty" validate:"-"`
+}
+
+// GetCollection - get model mongo collection name.
+func (m CronJob) GetCollection() string {
+	return CronJobCollectionName
+}
+
+// JobPodStatus is the structure for the status of the pod run by the job
+type JobPodStatus struct {
+	Name       string            `json:"name"`
+	Node       string            `json:"node"`
+	Phase      string            `json:"phase"`
+	Reason     string            `json:"reason,omitempty"`
+	Message    string            `json:"message,omitempty"`
+	StartTime  *time.Time        `json:"startTime,omitempty"`
+	Containers []ContainerStatus `json:"containers"`
+}
+
+// JobRun is the structure for the status of the run of the Job or the CronJob
+type JobRun struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Status is Running, Succeeded or Failed
+	Status string `json:"status"`
+	// Reason and Message explain why the run failed
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+	// Manual is true if the run of the CronJob is triggered manually
+	Manual         bool           `json:"manual"`
+	Active         int32          `json:"active"`
+	Succeeded      int32          `json:"succeeded"`
+	Failed         int32          `json:"failed"`
+	StartTime      *time.Time     `json:"startTime,omitempty"`
+	CompletionTime *time.Time     `json:"completionTime,omitempty"`
+	Pods           []JobPodStatus `json:"pods"`
+}
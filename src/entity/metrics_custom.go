@@ -0,0 +1,9 @@
+package entity
+
+// CustomMetric is the structure for the metric in Prometheus the autoscaler scales on
+type CustomMetric struct {
+	MetricName string `json:"metricName"`
+	Namespace  string `json:"namespace"`
+	// Series are the names of the series found, the counter has the "_total" suffix
+	Series []string `json:"series"`
+}
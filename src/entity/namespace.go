@@ -18,9 +18,52 @@ type Namespace struct {
 	Name      string        `bson:"name" json:"name" validate:"required,k8sname"`
 	CreatedAt *time.Time    `bson:"createdAt,omitempty" json:"createdAt,omitempty" validate:"-"`
 	CreatedBy User          `json:"createdBy" validate:"-"`
+	// TeamID is the team owning the namespace, the members of the team can access its resources
+	TeamID bson.ObjectId `bson:"teamID,omitempty" json:"teamID,omitempty" validate:"-"`
+	// Quota and LimitRange are applied to the namespace, the namespace is unlimited without them
+	Quota      *NamespaceQuota      `bson:"quota,omitempty" json:"quota,omitempty" validate:"omitempty"`
+	LimitRange *NamespaceLimitRange `bson:"limitRange,omitempty" json:"limitRange,omitempty" validate:"omitempty"`
 }
 
 // GetCollection - get model mongo collection name.
 func (m Namespace) GetCollection() string {
 	return NamespaceCollectionName
 }
+
+// NamespaceTeamRequest is the structure for moving a namespace to another team
+type NamespaceTeamRequest struct {
+	TeamID string `json:"teamID"`
+}
+
+// NamespaceQuota is the structure for the resource quota of a namespace, the empty fields are unlimited
+type NamespaceQuota struct {
+	// CPU and Memory are the total requests of the containers, e.g. "4" and "8Gi"
+	CPU    string `bson:"cpu,omitempty" json:"cpu,omitempty" validate:"omitempty,k8squantity"`
+	Memory string `bson:"memory,omitempty" json:"memory,omitempty" validate:"omitempty,k8squantity"`
+	Pods   int    `bson:"pods,omitempty" json:"pods,omitempty" validate:"min=0"`
+	// PersistentVolumeClaims is the number of the claims and Storage is their total requested size
+	PersistentVolumeClaims int    `bson:"persistentVolumeClaims,omitempty" json:"persistentVolumeClaims,omitempty" validate:"min=0"`
+	Storage                string `bson:"storage,omitempty" json:"storage,omitempty" validate:"omitempty,k8squantity"`
+}
+
+// NamespaceLimitRange is the structure for the default requests and limits of the containers in a namespace
+type NamespaceLimitRange struct {
+	DefaultCPURequest    string `bson:"defaultCPURequest,omitempty" json:"defaultCPURequest,omitempty" validate:"omitempty,k8squantity"`
+	DefaultMemoryRequest string `bson:"defaultMemoryRequest,omitempty" json:"defaultMemoryRequest,omitempty" validate:"omitempty,k8squantity"`
+	DefaultCPULimit      string `bson:"defaultCPULimit,omitempty" json:"defaultCPULimit,omitempty" validate:"omitempty,k8squantity"`
+	DefaultMemoryLimit   string `bson:"defaultMemoryLimit,omitempty" json:"defaultMemoryLimit,omitempty" validate:"omitempty,k8squantity"`
+}
+
+// NamespaceQuotaRequest is the structure for updating the quota and the limit range of a namespace,
+// the quota or the limit range is removed if it's null
+type NamespaceQuotaRequest struct {
+	Quota      *NamespaceQuota      `json:"quota" validate:"omitempty"`
+	LimitRange *NamespaceLimitRange `json:"limitRange" validate:"omitempty"`
+}
+
+// NamespaceQuotaUsage is the structure for the usage of the namespace against its quota
+type NamespaceQuotaUsage struct {
+	Namespace string            `json:"namespace"`
+	Hard      map[string]string `json:"hard"`
+	Used      map[string]string `json:"used"`
+}
@@ -33,6 +33,17 @@ type Node struct {
 	PhyInterfaces []PhyInterface `bson:"physicalInterfaces" json:"physicalInterfaces" validate:"required,dive,required"`
 }
 
+// Subnet is the structure for the subnet of the network which the addresses of the pods are allocated from
+type Subnet struct {
+	CIDR    string `bson:"cidr" json:"cidr" validate:"required,cidrv4"`
+	Gateway string `bson:"gateway,omitempty" json:"gateway,omitempty" validate:"omitempty,ipv4"`
+	// RangeStart and RangeEnd limit the allocated addresses, the whole subnet is used if they are empty
+	RangeStart string `bson:"rangeStart,omitempty" json:"rangeStart,omitempty" validate:"omitempty,ipv4"`
+	RangeEnd   string `bson:"rangeEnd,omitempty" json:"rangeEnd,omitempty" validate:"omitempty,ipv4"`
+	// Reserved are the addresses never allocated, the gateway is reserved as well
+	Reserved []string `bson:"reserved,omitempty" json:"reserved,omitempty" validate:"omitempty,dive,ipv4"`
+}
+
 // Network is the structure for Network info
 type Network struct {
 	ID         bson.ObjectId `bson:"_id,omitempty" json:"id" validate:"-"`
@@ -43,6 +54,7 @@ type Network struct {
 	VlanTags   []int32       `bson:"vlanTags" json:"vlanTags" validate:"required,dive,max=4095,min=0"`
 	BridgeName string        `bson:"bridgeName" json:"bridgeName" validate:"-"`
 	Nodes      []Node        `bson:"nodes" json:"nodes" validate:"required,dive,required"`
+	Subnets    []Subnet      `bson:"subnets,omitempty" json:"subnets,omitempty" validate:"omitempty,dive"`
 	CreatedBy  User          `json:"createdBy" validate:"-"`
 	CreatedAt  *time.Time    `bson:"createdAt,omitempty" json:"createdAt,omitempty" validate:"-"`
 }
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// OIDCStateCollectionName's const
+const (
+	OIDCStateCollectionName     string = "oidc_states"
+	OIDCLoginCodeCollectionName string = "oidc_login_codes"
+)
+
+// OIDCState is the structure for a pending OpenID Connect login.
+// The state is sent to the provider and checked on the callback, the nonce is checked in the ID token.
+type OIDCState struct {
+	ID        bson.ObjectId `bson:"_id,omitempty" json:"id"`
+	State     string        `bson:"state" json:"state"`
+	Nonce     string        `bson:"nonce" json:"nonce"`
+	ExpiresAt time.Time     `bson:"expiresAt" json:"expiresAt"`
+	CreatedAt *time.Time    `bson:"createdAt,omitempty" json:"createdAt,omitempty"`
+}
+
+// GetCollection - get model mongo collection name.
+func (s OIDCState) GetCollection() string {
+	return OIDCStateCollectionName
+}
+
+// OIDCLoginCode is the structure for the one-time code handed to the web UI after an OpenID Connect login.
+// The web UI exchanges it for the tokens, only the SHA256 of the code is stored.
+type OIDCLoginCode struct {
+	ID        bson.ObjectId `bson:"_id,omitempty" json:"id"`
+	UserID    bson.ObjectId `bson:"userID" json:"userID"`
+	CodeHash  string        `bson:"codeHash" json:"-"`
+	ExpiresAt time.Time     `bson:"expiresAt" json:"expiresAt"`
+	CreatedAt *time.Time    `bson:"createdAt,omitempty" json:"createdAt,omitempty"`
+}
+
+// GetCollection - get model mongo collection name.
+func (c OIDCLoginCode) GetCollection() string {
+	return OIDCLoginCodeCollectionName
+}
+
+// OIDCTokenRequest is the structure for exchanging the one-time code of an OpenID Connect login
+type OIDCTokenRequest struct {
+	Code string `json:"code" validate:"required"`
+}
@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// PasswordResetTokenCollectionName's const
+const (
+	PasswordResetTokenCollectionName string = "password_reset_tokens"
+)
+
+// PasswordResetToken is the structure for a one-time token to set a new password.
+// Only the SHA256 of the token is stored.
+type PasswordResetToken struct {
+	ID     bson.ObjectId `bson:"_id,omitempty" json:"id"`
+	UserID bson.ObjectId `bson:"userID" json:"userID"`
+	// Token is only returned when the token is created
+	Token     string     `bson:"-" json:"token,omitempty"`
+	TokenHash string     `bson:"tokenHash" json:"-"`
+	ExpiresAt time.Time  `bson:"expiresAt" json:"expiresAt"`
+	CreatedAt *time.Time `bson:"createdAt,omitempty" json:"createdAt,omitempty"`
+}
+
+// GetCollection - get model mongo collection name.
+func (t PasswordResetToken) GetCollection() string {
+	return PasswordResetTokenCollectionName
+}
+
+// PasswordResetRequest is the structure for setting a new password with a reset token
+type PasswordResetRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// PasswordForgotRequest is the structure for requesting a password reset mail
+type PasswordForgotRequest struct {
+	Username string `json:"username" validate:"required,email"`
+}
@@ -18,13 +18,83 @@ const (
 	PodCustomNetwork = "custom"
 )
 
+// HTTPGetProbe is the structure for probing the container by the HTTP GET request
+type HTTPGetProbe struct {
+	Path string `bson:"path" json:"path" validate:"required"`
+	Port int32  `bson:"port" json:"port" validate:"required,min=1,max=65535"`
+	// Scheme is HTTP or HTTPS, HTTP if it's empty
+	Scheme string `bson:"scheme,omitempty" json:"scheme,omitempty" validate:"omitempty,eq=HTTP|eq=HTTPS"`
+}
+
+// TCPSocketProbe is the structure for probing the container by opening the TCP socket
+type TCPSocketProbe struct {
+	Port int32 `bson:"port" json:"port" validate:"required,min=1,max=65535"`
+}
+
+// ExecProbe is the structure for probing the container by executing the command in it
+type ExecProbe struct {
+	Command []string `bson:"command" json:"command" validate:"required,min=1,dive,required"`
+}
+
+// Probe is the structure for the liveness or the readiness probe of the container,
+// exactly one of HTTPGet, TCPSocket and Exec is set and the zero values use the Kubernetes defaults
+type Probe struct {
+	HTTPGet             *HTTPGetProbe   `bson:"httpGet,omitempty" json:"httpGet,omitempty"`
+	TCPSocket           *TCPSocketProbe `bson:"tcpSocket,omitempty" json:"tcpSocket,omitempty"`
+	Exec                *ExecProbe      `bson:"exec,omitempty" json:"exec,omitempty"`
+	InitialDelaySeconds int32           `bson:"initialDelaySeconds,omitempty" json:"initialDelaySeconds,omitempty" validate:"min=0"`
+	TimeoutSeconds      int32           `bson:"timeoutSeconds,omitempty" json:"timeoutSeconds,omitempty" validate:"min=0"`
+	PeriodSeconds       int32           `bson:"periodSeconds,omitempty" json:"periodSeconds,omitempty" validate:"min=0"`
+	SuccessThreshold    int32           `bson:"successThreshold,omitempty" json:"successThreshold,omitempty" validate:"min=0"`
+	FailureThreshold    int32           `bson:"failureThreshold,omitempty" json:"failureThreshold,omitempty" validate:"min=0"`
+}
+
+// ContainerPort is the structure for the port exposed by the container
+type ContainerPort struct {
+	Name          string `bson:"name,omitempty" json:"name,omitempty" validate:"omitempty,max=15,k8sname"`
+	ContainerPort int32  `bson:"containerPort" json:"containerPort" validate:"required,min=1,max=65535"`
+	// Protocol is TCP or UDP, TCP if it's empty
+	Protocol string `bson:"protocol,omitempty" json:"protocol,omitempty" validate:"omitempty,eq=TCP|eq=UDP"`
+}
+
+// KeySelector is the structure for selecting the key of the secret or the config map
+type KeySelector struct {
+	Name string `bson:"name" json:"name" validate:"required,k8sname"`
+	Key  string `bson:"key" json:"key" validate:"required"`
+}
+
+// EnvVarSource is the structure for the environment variable from the key of the secret or the config map,
+// exactly one of SecretKeyRef and ConfigMapKeyRef is set
+type EnvVarSource struct {
+	Name            string       `bson:"name" json:"name" validate:"required"`
+	SecretKeyRef    *KeySelector `bson:"secretKeyRef,omitempty" json:"secretKeyRef,omitempty"`
+	ConfigMapKeyRef *KeySelector `bson:"configMapKeyRef,omitempty" json:"configMapKeyRef,omitempty"`
+}
+
 // Container is the structure for init Container info
 type Container struct {
-	Name                  string   `bson:"name" json:"name" validate:"required,k8sname"`
-	Image                 string   `bson:"image" json:"image" validate:"required"`
-	Command               []string `bson:"command" json:"command" validate:"required,dive,required"`
-	ResourceRequestCPU    int      `bson:"resourceRequestCPU" json:"resourceRequestCPU" validate:"-"`
-	ResourceRequestMemory int      `bson:"resourceRequestMemory" json:"resourceRequestMemory" validate:"-"`
+	Name       string            `bson:"name" json:"name" validate:"required,k8sname"`
+	Image      string            `bson:"image" json:"image" validate:"required"`
+	Command    []string          `bson:"command" json:"command" validate:"required,dive,required"`
+	Args       []string          `bson:"args,omitempty" json:"args,omitempty" validate:"omitempty,dive,required"`
+	WorkingDir string            `bson:"workingDir,omitempty" json:"workingDir,omitempty" validate:"-"`
+	Ports      []ContainerPort   `bson:"ports,omitempty" json:"ports,omitempty" validate:"omitempty,dive,required"`
+	EnvVars    map[string]string `bson:"envVars,omitempty" json:"envVars,omitempty" validate:"omitempty,dive,keys,printascii,endkeys,required,printascii"`
+	// EnvVarsFrom are the environment variables from the secrets or the config maps
+	EnvVarsFrom []EnvVarSource `bson:"envVarsFrom,omitempty" json:"envVarsFrom,omitempty" validate:"omitempty,dive,required"`
+	// ImagePullPolicy is Always, IfNotPresent or Never, it's decided by the image tag if it's empty
+	ImagePullPolicy string `bson:"imagePullPolicy,omitempty" json:"imagePullPolicy,omitempty" validate:"omitempty,eq=Always|eq=IfNotPresent|eq=Never"`
+	// The CPU is in millicores, the memory and the ephemeral storage are in MiB, 0 is unset
+	ResourceRequestCPU              int `bson:"resourceRequestCPU" json:"resourceRequestCPU" validate:"-"`
+	ResourceRequestMemory           int `bson:"resourceRequestMemory" json:"resourceRequestMemory" validate:"-"`
+	ResourceRequestEphemeralStorage int `bson:"resourceRequestEphemeralStorage,omitempty" json:"resourceRequestEphemeralStorage,omitempty" validate:"min=0"`
+	ResourceLimitCPU                int `bson:"resourceLimitCPU,omitempty" json:"resourceLimitCPU,omitempty" validate:"min=0"`
+	ResourceLimitMemory             int `bson:"resourceLimitMemory,omitempty" json:"resourceLimitMemory,omitempty" validate:"min=0"`
+	ResourceLimitEphemeralStorage   int `bson:"resourceLimitEphemeralStorage,omitempty" json:"resourceLimitEphemeralStorage,omitempty" validate:"min=0"`
+	// LivenessProbe restarts the container when it fails
+	LivenessProbe *Probe `bson:"livenessProbe,omitempty" json:"livenessProbe,omitempty"`
+	// ReadinessProbe removes the pod from the endpoints of the services when it fails
+	ReadinessProbe *Probe `bson:"readinessProbe,omitempty" json:"readinessProbe,omitempty"`
 }
 
 // PodRouteGw is the structure for add IP routing table with gateway
@@ -70,6 +140,7 @@ type Pod struct {
 	Containers    []Container       `bson:"containers" json:"containers" validate:"required,dive,required"`
 	Volumes       []PodVolume       `bson:"volumes,omitempty" json:"volumes" validate:"required,dive,required"`
 	Networks      []PodNetwork      `bson:"networks,omitempty" json:"networks" validate:"required,dive,required"`
+	Secrets       []SecretVolume    `bson:"secrets,omitempty" json:"secrets,omitempty" validate:"omitempty,dive,required"`
 	RestartPolicy string            `bson:"restartPolicy" json:"restartPolicy" validate:"required,eq=Always|eq=OnFailure|eq=Never"`
 	Capability    bool              `bson:"capability" json:"capability" validate:"-"`
 	NetworkType   string            `bson:"networkType" json:"networkType" validate:"required,eq=host|eq=cluster|eq=custom"`
@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// the const for RegistryCredentialCollectionName
+const (
+	RegistryCredentialCollectionName string = "registry_credentials"
+)
+
+// RegistryCredential is the credential of a private registry, it's applied as a docker config secret
+// to the namespaces of the teams granted the credential
+type RegistryCredential struct {
+	ID      bson.ObjectId `bson:"_id,omitempty" json:"id" validate:"-"`
+	OwnerID bson.ObjectId `bson:"ownerID,omitempty" json:"ownerID" validate:"-"`
+	Name    string        `bson:"name" json:"name" validate:"required,k8sname"`
+	// URL is the registry URL, e.g. https://registry.example.com or docker.io
+	URL      string `bson:"url" json:"url" validate:"required"`
+	Username string `bson:"username" json:"username" validate:"required"`
+	// Password is only kept in the secret of the system namespace, it's never stored or returned
+	Password string `bson:"-" json:"password,omitempty" validate:"required"`
+	// Teams are the teams granted the credential, the members of the teams can read the secrets in their namespaces
+	Teams     []bson.ObjectId `bson:"teams" json:"teams" validate:"-"`
+	CreatedAt *time.Time      `bson:"createdAt,omitempty" json:"createdAt,omitempty" validate:"-"`
+	CreatedBy User            `json:"createdBy" validate:"-"`
+}
+
+// Granted reports whether the team is granted the credential, the namespaces without a team aren't granted any
+func (c RegistryCredential) Granted(teamID bson.ObjectId) bool {
+	for _, t := range c.Teams {
+		if teamID != "" && t == teamID {
+			return true
+		}
+	}
+	return false
+}
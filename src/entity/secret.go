@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// the const for SecretCollectionName
+const (
+	SecretCollectionName string = "secrets"
+)
+
+// Secret is the structure for secret info, the values are only kept by Kubernetes
+type Secret struct {
+	ID        bson.ObjectId `bson:"_id,omitempty" json:"id" validate:"-"`
+	OwnerID   bson.ObjectId `bson:"ownerID,omitempty" json:"ownerID" validate:"-"`
+	Name      string        `bson:"name" json:"name" validate:"required,k8sname"`
+	Namespace string        `bson:"namespace" json:"namespace" validate:"required"`
+	// Type is the Kubernetes secret type, Opaque if it's empty
+	Type string `bson:"type" json:"type" validate:"omitempty,eq=Opaque|eq=kubernetes.io/tls|eq=kubernetes.io/basic-auth|eq=kubernetes.io/ssh-auth"`
+	// Data is only read from the request and never stored or returned
+	Data map[string]string `bson:"-" json:"data,omitempty" validate:"required,min=1,dive,keys,required,endkeys,required"`
+	// Keys are the names of the data
+	Keys      []string   `bson:"keys" json:"keys" validate:"-"`
+	CreatedAt *time.Time `bson:"createdAt,omitempty" json:"createdAt,omitempty" validate:"-"`
+	CreatedBy User       `json:"createdBy" validate:"-"`
+}
+
+// GetCollection - get model mongo collection name.
+func (m Secret) GetCollection() string {
+	return SecretCollectionName
+}
+
+// SecretVolume is the structure for mounting the secret into the containers
+type SecretVolume struct {
+	Name      string `bson:"name" json:"name" validate:"required,k8sname"`
+	MountPath string `bson:"mountPath" json:"mountPath" validate:"required"`
+}
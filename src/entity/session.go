@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// SessionCollectionName's const
+const (
+	SessionCollectionName string = "sessions"
+)
+
+// Session is the structure for a signed in session. The ID is the jti claim of the access token.
+type Session struct {
+	ID         bson.ObjectId `bson:"_id,omitempty" json:"id"`
+	UserID     bson.ObjectId `bson:"userID" json:"userID"`
+	RemoteAddr string        `bson:"remoteAddr" json:"remoteAddr"`
+	UserAgent  string        `bson:"userAgent" json:"userAgent"`
+	ExpiresAt  time.Time     `bson:"expiresAt" json:"expiresAt"`
+	CreatedAt  *time.Time    `bson:"createdAt,omitempty" json:"createdAt,omitempty"`
+	// MustChangePassword is copied from the user, the session can only change the password
+	MustChangePassword bool `bson:"mustChangePassword,omitempty" json:"mustChangePassword,omitempty"`
+}
+
+// GetCollection - get model mongo collection name.
+func (s Session) GetCollection() string {
+	return SessionCollectionName
+}
@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// StatefulSetCollectionName is a const string
+	StatefulSetCollectionName string = "statefulsets"
+	// StatefulSetOrderedReadyPolicy creates and deletes the pods one by one in the order of their ordinals
+	StatefulSetOrderedReadyPolicy = "OrderedReady"
+	// StatefulSetParallelPolicy creates and deletes the pods at the same time
+	StatefulSetParallelPolicy = "Parallel"
+)
+
+// StatefulSetVolumeClaim is the structure for the volume claim template of the StatefulSet,
+// every replica gets its own PVC of the StorageClass of the storage
+type StatefulSetVolumeClaim struct {
+	Name        string                            `bson:"name" json:"name" validate:"required,k8sname"`
+	StorageName string                            `bson:"storageName" json:"storageName" validate:"required"`
+	AccessMode  corev1.PersistentVolumeAccessMode `bson:"accessMode" json:"accessMode" validate:"required"`
+	Capacity    string                            `bson:"capacity" json:"capacity" validate:"required,k8squantity"`
+	MountPath   string                            `bson:"mountPath" json:"mountPath" validate:"required"`
+}
+
+// StatefulSet is the structure for the StatefulSet info, the pods are described like the Deployment.
+// The pods have the stable names through the headless service of the same name,
+// and the static address of each custom network belongs to the pod of ordinal 0, the pod of ordinal n gets the address plus n
+type StatefulSet struct {
+	ID           bson.ObjectId       `bson:"_id,omitempty" json:"id" validate:"-"`
+	OwnerID      bson.ObjectId       `bson:"ownerID,omitempty" json:"ownerID" validate:"-"`
+	Name         string              `bson:"name" json:"name" validate:"required,k8sname"`
+	Namespace    string              `bson:"namespace" json:"namespace" validate:"required"`
+	Labels       map[string]string   `bson:"labels,omitempty" json:"labels" validate:"required,dive,keys,printascii,endkeys,required,printascii"`
+	EnvVars      map[string]string   `bson:"envVars,omitempty" json:"envVars" validate:"required,dive,keys,printascii,endkeys,required,printascii"`
+	Containers   []Container         `bson:"containers" json:"containers" validate:"required,dive,required"`
+	Volumes      []DeploymentVolume  `bson:"volumes,omitempty" json:"volumes" validate:"required,dive,required"`
+	ConfigMaps   []DeploymentConfig  `bson:"configMaps,omitempty" json:"configMaps" validate:"required,dive,required"`
+	Secrets      []SecretVolume      `bson:"secrets,omitempty" json:"secrets,omitempty" validate:"omitempty,dive,required"`
+	Networks     []DeploymentNetwork `bson:"networks,omitempty" json:"networks" validate:"required,dive,required"`
+	Capability   bool                `bson:"capability" json:"capability" validate:"-"`
+	NetworkType  string              `bson:"networkType" json:"networkType" validate:"required,eq=host|eq=cluster|eq=custom"`
+	NodeAffinity []string            `bson:"nodeAffinity" json:"nodeAffinity" validate:"required"`
+	CreatedBy    User                `json:"createdBy" validate:"-"`
+	CreatedAt    *time.Time          `bson:"createdAt,omitempty" json:"createdAt,omitempty" validate:"-"`
+
+	Replicas     int32                    `bson:"replicas" json:"replicas" validate:"required"`
+	VolumeClaims []StatefulSetVolumeClaim `bson:"volumeClaims,omitempty" json:"volumeClaims" validate:"omitempty,dive,required"`
+	// PodManagementPolicy is OrderedReady or Parallel, OrderedReady if it's empty
+	PodManagementPolicy string `bson:"podManagementPolicy,omitempty" json:"podManagementPolicy,omitempty" validate:"omitempty,eq=OrderedReady|eq=Parallel"`
+}
+
+// GetCollection - get model mongo collection name.
+func (m StatefulSet) GetCollection() string {
+	return StatefulSetCollectionName
+}
+
+// PodTemplate returns the deployment describing the pods of the StatefulSet
+func (m StatefulSet) PodTemplate() Deployment {
+	return Deployment{
+		ID:           m.ID,
+		OwnerID:      m.OwnerID,
+		Name:         m.Name,
+		Namespace:    m.Namespace,
+		Labels:       m.Labels,
+		EnvVars:      m.EnvVars,
+		Containers:   m.Containers,
+		Volumes:      m.Volumes,
+		ConfigMaps:   m.ConfigMaps,
+		Secrets:      m.Secrets,
+		Networks:     m.Networks,
+		Capability:   m.Capability,
+		NetworkType:  m.NetworkType,
+		NodeAffinity: m.NodeAffinity,
+		Replicas:     m.Replicas,
+	}
+}
@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// The const for teams
+const (
+	TeamCollectionName string = "teams"
+	// TeamAdminRole can manage the members and write the resources of the team
+	TeamAdminRole string = "admin"
+	// TeamMemberRole can write the resources of the team
+	TeamMemberRole string = "member"
+	// TeamViewerRole can only read the resources of the team
+	TeamViewerRole string = "viewer"
+)
+
+// TeamMember is the structure for a member of the team and the role in the team
+type TeamMember struct {
+	UserID bson.ObjectId `bson:"userID" json:"userID" validate:"required"`
+	Role   string        `bson:"role" json:"role" validate:"required,eq=admin|eq=member|eq=viewer"`
+}
+
+// Team is the structure for a team owning a set of namespaces.
+// The namespaces of the team are the namespaces with its teamID.
+type Team struct {
+	ID          bson.ObjectId `bson:"_id,omitempty" json:"id" validate:"-"`
+	OwnerID     bson.ObjectId `bson:"ownerID,omitempty" json:"ownerID" validate:"-"`
+	Name        string        `bson:"name" json:"name" validate:"required"`
+	Description string        `bson:"description" json:"description" validate:"-"`
+	Members     []TeamMember  `bson:"members" json:"members" validate:"dive"`
+	Namespaces  []string      `bson:"-" json:"namespaces" validate:"-"`
+	CreatedAt   *time.Time    `bson:"createdAt,omitempty" json:"createdAt,omitempty" validate:"-"`
+}
+
+// GetCollection - get model mongo collection name.
+func (t Team) GetCollection() string {
+	return TeamCollectionName
+}
+
+// Role returns the role of the user in the team, or an empty string if the user isn't a member
+func (t Team) Role(userID bson.ObjectId) string {
+	for _, member := range t.Members {
+		if member.UserID == userID {
+			return member.Role
+		}
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// RefreshTokenCollectionName's const
+const (
+	RefreshTokenCollectionName string = "refresh_tokens"
+)
+
+// RefreshToken is the structure for a long-lived refresh token.
+// Only the SHA256 of the token is stored.
+type RefreshToken struct {
+	ID        bson.ObjectId `bson:"_id,omitempty" json:"id"`
+	UserID    bson.ObjectId `bson:"userID" json:"userID"`
+	SessionID bson.ObjectId `bson:"sessionID" json:"sessionID"`
+	TokenHash string        `bson:"tokenHash" json:"-"`
+	ExpiresAt time.Time     `bson:"expiresAt" json:"expiresAt"`
+	CreatedAt *time.Time    `bson:"createdAt,omitempty" json:"createdAt,omitempty"`
+}
+
+// GetCollection - get model mongo collection name.
+func (t RefreshToken) GetCollection() string {
+	return RefreshTokenCollectionName
+}
+
+// RefreshTokenRequest is the structure for the refresh token request
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+// TokenResponse is the structure for the tokens issued at sign in.
+// The access token is also put in the message for the existing clients.
+type TokenResponse struct {
+	Error        bool   `json:"error"`
+	Message      string `json:"message"`
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	// ExpiresAt is the unix time when the access token expires
+	ExpiresAt int64 `json:"expiresAt"`
+
+	// TwoFactorRequired is set instead of the tokens when the user has to pass the challenge,
+	// EnrollmentRequired when the user has to enroll TOTP with the challenge token first
+	TwoFactorRequired  bool   `json:"twoFactorRequired,omitempty"`
+	EnrollmentRequired bool   `json:"enrollmentRequired,omitempty"`
+	ChallengeToken     string `json:"challengeToken,omitempty"`
+	// RecoveryCodes are returned once when the enrollment is completed at sign in
+	RecoveryCodes []string `json:"recoveryCodes,omitempty"`
+}
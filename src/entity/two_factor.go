@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// The collection names of the two-factor authentication
+const (
+	TwoFactorChallengeCollectionName string = "two_factor_challenges"
+	PolicyCollectionName             string = "policies"
+	// TwoFactorPolicyID is the document ID of the two-factor policy in the policies collection
+	TwoFactorPolicyID string = "two_factor"
+)
+
+// TwoFactorChallenge is the structure for a sign in waiting for the second factor.
+// Only the SHA256 of the challenge token is stored.
+type TwoFactorChallenge struct {
+	ID        bson.ObjectId `bson:"_id,omitempty" json:"id"`
+	UserID    bson.ObjectId `bson:"userID" json:"userID"`
+	TokenHash string        `bson:"tokenHash" json:"-"`
+	// Attempts is the number of the wrong codes
+	Attempts  int        `bson:"attempts" json:"attempts"`
+	ExpiresAt time.Time  `bson:"expiresAt" json:"expiresAt"`
+	CreatedAt *time.Time `bson:"createdAt,omitempty" json:"createdAt,omitempty"`
+}
+
+// GetCollection - get model mongo collection name.
+func (c TwoFactorChallenge) GetCollection() string {
+	return TwoFactorChallengeCollectionName
+}
+
+// TwoFactorPolicy is the structure for the two-factor authentication policy
+type TwoFactorPolicy struct {
+	ID string `bson:"_id" json:"-"`
+	// RequireForRoot makes the root users enroll and pass the two-factor authentication at sign in
+	RequireForRoot bool       `bson:"requireForRoot" json:"requireForRoot"`
+	UpdatedAt      *time.Time `bson:"updatedAt,omitempty" json:"updatedAt,omitempty"`
+}
+
+// GetCollection - get model mongo collection name.
+func (p TwoFactorPolicy) GetCollection() string {
+	return PolicyCollectionName
+}
+
+// TwoFactorRequest is the structure for the second step of sign in
+type TwoFactorRequest struct {
+	ChallengeToken string `json:"challengeToken" validate:"required"`
+	// Code is either a TOTP code or a recovery code
+	Code string `json:"code"`
+}
+
+// TOTPCodeRequest is the structure for the requests confirmed by a TOTP code
+type TOTPCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// TOTPEnrollment is the structure for the secret of a pending TOTP enrollment
+type TOTPEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioningURI"`
+}
+
+// RecoveryCodesResponse is the structure for the new recovery codes, which are only shown once
+type RecoveryCodesResponse struct {
+	Error         bool     `json:"error"`
+	Message       string   `json:"message"`
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
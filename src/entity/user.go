@@ -37,10 +37,38 @@ type User struct {
 	FirstName       string          `bson:"firstname" json:"firstName" validate:"required"`
 	LastName        string          `bson:"lastName" json:"lastName" validate:"required"`
 	PhoneNumber     string          `bson:"phoneNumber" json:"phoneNumber" validate:"required,numeric"`
-	CreatedAt       *time.Time      `bson:"createdAt,omitempty" json:"createdAt,omitempty" validate:"-"`
+	AuthProvider    string          `bson:"authProvider,omitempty" json:"authProvider,omitempty" validate:"-"`
+	// TOTPEnabled is set once the user confirms the TOTP enrollment
+	TOTPEnabled bool `bson:"totpEnabled" json:"totpEnabled" validate:"-"`
+	// TOTPSecret is also set by a pending enrollment, TOTPCounter is the time step of the last used code
+	TOTPSecret  string `bson:"totpSecret,omitempty" json:"-" validate:"-"`
+	TOTPCounter int64  `bson:"totpCounter,omitempty" json:"-" validate:"-"`
+	// RecoveryCodes are the SHA256 of the unused recovery codes
+	RecoveryCodes []string `bson:"recoveryCodes,omitempty" json:"-" validate:"-"`
+	// MustChangePassword blocks the other APIs until the user changes the password
+	MustChangePassword bool `bson:"mustChangePassword" json:"mustChangePassword" validate:"-"`
+	// PasswordHistory are the hashes of the previous passwords, the latest first
+	PasswordHistory []string `bson:"passwordHistory,omitempty" json:"-" validate:"-"`
+	// FailedLogins is the number of the failed sign in since FailedLoginAt
+	FailedLogins  int        `bson:"failedLogins,omitempty" json:"-" validate:"-"`
+	FailedLoginAt *time.Time `bson:"failedLoginAt,omitempty" json:"-" validate:"-"`
+	LockedAt      *time.Time `bson:"lockedAt,omitempty" json:"lockedAt,omitempty" validate:"-"`
+	// Disabled users can't sign in and their tokens are rejected
+	Disabled  bool       `bson:"disabled" json:"disabled" validate:"-"`
+	CreatedAt *time.Time `bson:"createdAt,omitempty" json:"createdAt,omitempty" validate:"-"`
 }
 
 // GetCollection - get model mongo collection name.
 func (u User) GetCollection() string {
 	return UserCollectionName
 }
+
+// UserUpdateRequest is the structure for root to update the profile, role and state of a user
+type UserUpdateRequest struct {
+	DisplayName string `json:"displayName" validate:"required"`
+	Role        string `json:"role" validate:"required,eq=root|eq=user|eq=guest"`
+	FirstName   string `json:"firstName" validate:"required"`
+	LastName    string `json:"lastName" validate:"required"`
+	PhoneNumber string `json:"phoneNumber" validate:"required,numeric"`
+	Disabled    bool   `json:"disabled"`
+}
@@ -0,0 +1,287 @@
+package ipam
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/linkernetworks/logger"
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/utils/timeutils"
+	"github.com/linkernetworks/vortex/src/cache"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/kubeutils"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// DefaultServerURL is the URL of the vortex server in the cluster
+const DefaultServerURL = "http://vortex-server.vortex:7890"
+
+// DefaultReleaseInterval is how often the addresses of the deleted pods are released
+const DefaultReleaseInterval = time.Minute
+
+// staleReleaseInterval is how often an exhausted network releases the addresses of the deleted pods
+const staleReleaseInterval = 10 * time.Second
+
+// staleReleases are the networks which released the addresses of the deleted pods in the interval
+var staleReleases = cache.New(staleReleaseInterval)
+
+var (
+	// ErrServiceAccountMismatch is returned when the caller isn't the service account of the requesting pod
+	ErrServiceAccountMismatch = fmt.Errorf("The service account doesn't run the pod")
+	// ErrPodMismatch is returned when the requesting pod isn't running
+	ErrPodMismatch = fmt.Errorf("The pod doesn't match the running pod")
+	// ErrUndeclaredInterface is returned when the pod has no init container requesting the interface in the network
+	ErrUndeclaredInterface = fmt.Errorf("The pod doesn't declare the interface of the network")
+	// ErrNoSubnet is returned when the network has no subnet to allocate from
+	ErrNoSubnet = fmt.Errorf("The network has no subnet")
+	// ErrExhausted is returned when all the addresses of the network are allocated
+	ErrExhausted = fmt.Errorf("The addresses of the network are exhausted")
+)
+
+// ServerURL returns the URL of the vortex server the init containers request the addresses from
+func ServerURL(cf *config.IPAMConfig) string {
+	if cf == nil || cf.ServerURL == "" {
+		return DefaultServerURL
+	}
+	return cf.ServerURL
+}
+
+// ReleaseInterval returns how often the addresses of the deleted pods are released
+func ReleaseInterval(cf *config.IPAMConfig) time.Duration {
+	if cf == nil || cf.ReleaseInterval == "" {
+		return DefaultReleaseInterval
+	}
+	interval, err := time.ParseDuration(cf.ReleaseInterval)
+	if err != nil || interval <= 0 {
+		logger.Warnf("Invalid IPAM release interval %s, %s is used: %v", cf.ReleaseInterval, DefaultReleaseInterval, err)
+		return DefaultReleaseInterval
+	}
+	return interval
+}
+
+// ensureIndex makes an address allocated once in the network and an interface of the pod get one address
+func ensureIndex(c *mgo.Collection) {
+	c.EnsureIndex(mgo.Index{
+		Key:    []string{"network", "address"},
+		Unique: true,
+	})
+	c.EnsureIndex(mgo.Index{
+		Key:    []string{"podUID", "network", "ifName"},
+		Unique: true,
+	})
+}
+
+// Allocate allocates an address of the network to the interface of the running pod, the caller is the service account
+// running the pod and the interface has to be declared by an init container of the pod.
+// The same address is returned if the interface has got one.
+func Allocate(sp *serviceprovider.Container, request *entity.IPAllocationRequest, namespace string, serviceAccount string) (*entity.IPAllocation, error) {
+	if request.Namespace != namespace {
+		return nil, ErrServiceAccountMismatch
+	}
+	pod, err := sp.KubeCtl.GetPod(request.PodName, request.Namespace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, ErrPodMismatch
+		}
+		return nil, err
+	}
+	if string(pod.UID) != request.PodUID {
+		return nil, ErrPodMismatch
+	}
+	if podServiceAccount(pod) != serviceAccount {
+		return nil, ErrServiceAccountMismatch
+	}
+	if !kubeutils.HasIPAMContainer(pod, request.Network, request.IfName) {
+		return nil, ErrUndeclaredInterface
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+	ensureIndex(session.C(entity.IPAllocationCollectionName))
+
+	allocation := entity.IPAllocation{}
+	err = session.FindOne(entity.IPAllocationCollectionName, bson.M{"podUID": request.PodUID, "network": request.Network, "ifName": request.IfName}, &allocation)
+	if err == nil {
+		return &allocation, nil
+	} else if err != mgo.ErrNotFound {
+		return nil, err
+	}
+
+	network := entity.Network{}
+	if err := session.FindOne(entity.NetworkCollectionName, bson.M{"name": request.Network}, &network); err != nil {
+		return nil, err
+	}
+	if len(network.Subnets) == 0 {
+		return nil, ErrNoSubnet
+	}
+
+	result, err := allocate(session, &network, request)
+	// the addresses of the deleted pods may not be released yet, they are checked once in the interval
+	if _, released := staleReleases.Get(network.Name); err == ErrExhausted && !released {
+		staleReleases.Set(network.Name, true)
+		if _, err := ReleaseStale(sp, network.Name); err != nil {
+			return nil, err
+		}
+		result, err = allocate(session, &network, request)
+	}
+	return result, err
+}
+
+// podServiceAccount returns the service account running the pod, the pods without one run by the default service account
+func podServiceAccount(pod *corev1.Pod) string {
+	if pod.Spec.ServiceAccountName != "" {
+		return pod.Spec.ServiceAccountName
+	}
+	return "default"
+}
+
+func allocate(session *mongo.Session, network *entity.Network, request *entity.IPAllocationRequest) (*entity.IPAllocation, error) {
+	allocations := []entity.IPAllocation{}
+	if err := session.C(entity.IPAllocationCollectionName).Find(bson.M{"network": network.Name}).All(&allocations); err != nil {
+		return nil, err
+	}
+	used := map[string]bool{}
+	for _, a := range allocations {
+		used[a.Address] = true
+	}
+
+	for _, subnet := range network.Subnets {
+		_, ipnet, err := net.ParseCIDR(subnet.CIDR)
+		if err != nil {
+			return nil, err
+		}
+		prefix, _ := ipnet.Mask.Size()
+		used[subnet.Gateway] = true
+		for _, address := range subnet.Reserved {
+			used[address] = true
+		}
+
+		first, last := AddressRange(&subnet)
+		for n := first; n >= first && n <= last; n++ {
+			address := uint32ToIP(n).String()
+			if used[address] {
+				continue
+			}
+			allocation := entity.IPAllocation{
+				ID:        bson.NewObjectId(),
+				Network:   network.Name,
+				Address:   address,
+				CIDR:      fmt.Sprintf("%s/%d", address, prefix),
+				Gateway:   subnet.Gateway,
+				IfName:    request.IfName,
+				PodName:   request.PodName,
+				Namespace: request.Namespace,
+				PodUID:    request.PodUID,
+				CreatedAt: timeutils.Now(),
+			}
+			if err := session.Insert(entity.IPAllocationCollectionName, &allocation); err != nil {
+				// allocated by another pod at the same time
+				if mgo.IsDup(err) {
+					used[address] = true
+					continue
+				}
+				return nil, err
+			}
+			return &allocation, nil
+		}
+	}
+	return nil, ErrExhausted
+}
+
+// AddressRange returns the first and the last allocatable addresses of the subnet,
+// the network and the broadcast addresses are excluded if the range isn't given
+func AddressRange(subnet *entity.Subnet) (uint32, uint32) {
+	_, ipnet, err := net.ParseCIDR(subnet.CIDR)
+	if err != nil {
+		return 1, 0
+	}
+	network := ipToUint32(ipnet.IP)
+	broadcast := network | ^ipToUint32(net.IP(ipnet.Mask))
+	first, last := network, broadcast
+	// the /31 and /32 subnets have no network and broadcast addresses
+	if broadcast-network > 1 {
+		first, last = network+1, broadcast-1
+	}
+	if ip := net.ParseIP(subnet.RangeStart); ip != nil {
+		first = ipToUint32(ip)
+	}
+	if ip := net.ParseIP(subnet.RangeEnd); ip != nil {
+		last = ipToUint32(ip)
+	}
+	return first, last
+}
+
+// ReleaseStale releases the addresses of the pods which are deleted, all the networks are checked if the network is empty
+func ReleaseStale(sp *serviceprovider.Container, network string) (int, error) {
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	selector := bson.M{}
+	if network != "" {
+		selector["network"] = network
+	}
+	allocations := []entity.IPAllocation{}
+	if err := session.C(entity.IPAllocationCollectionName).Find(selector).All(&allocations); err != nil {
+		return 0, err
+	}
+
+	// the UIDs of the running pods by name, the pods of a namespace are listed once
+	podUIDs := map[string]map[string]string{}
+	released := 0
+	for _, a := range allocations {
+		uids, ok := podUIDs[a.Namespace]
+		if !ok {
+			pods, err := sp.KubeCtl.GetPods(a.Namespace)
+			if err != nil {
+				return released, err
+			}
+			uids = map[string]string{}
+			for _, pod := range pods {
+				uids[pod.Name] = string(pod.UID)
+			}
+			podUIDs[a.Namespace] = uids
+		}
+		// the address is released if the pod is deleted or replaced by another pod with the same name
+		if uids[a.PodName] == a.PodUID {
+			continue
+		}
+		if err := session.Remove(entity.IPAllocationCollectionName, "_id", a.ID); err != nil && err != mgo.ErrNotFound {
+			return released, err
+		}
+		released++
+	}
+	return released, nil
+}
+
+// ReleaseLoop releases the addresses of the deleted pods periodically
+func ReleaseLoop(sp *serviceprovider.Container, interval time.Duration) {
+	for range time.Tick(interval) {
+		released, err := ReleaseStale(sp, "")
+		if err != nil {
+			logger.Warnf("Failed to release the addresses of the deleted pods: %v", err)
+		} else if released > 0 {
+			logger.Infof("%d addresses of the deleted pods are released", released)
+		}
+	}
+}
+
+func ipToUint32(ip net.IP) uint32 {
+	if ip4 := ip.To4(); ip4 != nil {
+		return binary.BigEndian.Uint32(ip4)
+	}
+	return 0
+}
+
+func uint32ToIP(n uint32) net.IP {
+	ip := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(ip, n)
+	return ip
+}
@@ -0,0 +1,216 @@
+package ipam
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/kubeutils"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func init() {
+	rand.Seed(time.Now().UnixNano())
+}
+
+type IPAMTestSuite struct {
+	suite.Suite
+	sp *serviceprovider.Container
+}
+
+func (suite *IPAMTestSuite) SetupSuite() {
+	cf := config.MustRead("../../config/testing.json")
+	suite.sp = serviceprovider.NewForTesting(cf)
+}
+
+func (suite *IPAMTestSuite) TearDownSuite() {
+}
+
+func TestIPAMSuite(t *testing.T) {
+	suite.Run(t, new(IPAMTestSuite))
+}
+
+func TestAddressRange(t *testing.T) {
+	first, last := AddressRange(&entity.Subnet{CIDR: "10.1.0.0/24"})
+	assert.Equal(t, "10.1.0.1", uint32ToIP(first).String())
+	assert.Equal(t, "10.1.0.254", uint32ToIP(last).String())
+
+	first, last = AddressRange(&entity.Subnet{CIDR: "10.1.0.0/24", RangeStart: "10.1.0.100", RangeEnd: "10.1.0.110"})
+	assert.Equal(t, "10.1.0.100", uint32ToIP(first).String())
+	assert.Equal(t, "10.1.0.110", uint32ToIP(last).String())
+
+	first, last = AddressRange(&entity.Subnet{CIDR: "10.1.0.8/31"})
+	assert.Equal(t, "10.1.0.8", uint32ToIP(first).String())
+	assert.Equal(t, "10.1.0.9", uint32ToIP(last).String())
+}
+
+func TestServerURL(t *testing.T) {
+	assert.Equal(t, DefaultServerURL, ServerURL(nil))
+	assert.Equal(t, "http://vortex:7890", ServerURL(&config.IPAMConfig{ServerURL: "http://vortex:7890"}))
+}
+
+func TestReleaseInterval(t *testing.T) {
+	assert.Equal(t, DefaultReleaseInterval, ReleaseInterval(nil))
+	assert.Equal(t, 30*time.Second, ReleaseInterval(&config.IPAMConfig{ReleaseInterval: "30s"}))
+	assert.Equal(t, DefaultReleaseInterval, ReleaseInterval(&config.IPAMConfig{ReleaseInterval: "awesome"}))
+}
+
+func (suite *IPAMTestSuite) createPod(network string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: namesgenerator.GetRandomName(0),
+			UID:  types.UID(bson.NewObjectId().Hex()),
+		},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{
+				kubeutils.GenerateIPAMContainer(0, DefaultServerURL, network, "eth1"),
+			},
+		},
+	}
+	_, err := suite.sp.KubeCtl.CreatePod(pod, "default")
+	suite.NoError(err)
+	return pod
+}
+
+func (suite *IPAMTestSuite) TestAllocate() {
+	session := suite.sp.Mongo.NewSession()
+	defer session.Close()
+
+	network := entity.Network{
+		ID:   bson.NewObjectId(),
+		Name: namesgenerator.GetRandomName(0),
+		Subnets: []entity.Subnet{
+			{CIDR: "10.1.0.0/24", Gateway: "10.1.0.1", RangeStart: "10.1.0.1", RangeEnd: "10.1.0.3", Reserved: []string{"10.1.0.2"}},
+			{CIDR: "10.2.0.0/30"},
+		},
+	}
+	session.Insert(entity.NetworkCollectionName, network)
+	defer session.Remove(entity.NetworkCollectionName, "_id", network.ID)
+	defer session.C(entity.IPAllocationCollectionName).RemoveAll(bson.M{"network": network.Name})
+
+	expected := []string{"10.1.0.3/24", "10.2.0.1/30", "10.2.0.2/30"}
+	pods := []*corev1.Pod{}
+	for _, cidr := range expected {
+		pod := suite.createPod(network.Name)
+		defer suite.sp.KubeCtl.DeletePod(pod.Name, "default")
+		pods = append(pods, pod)
+
+		allocation, err := Allocate(suite.sp, &entity.IPAllocationRequest{
+			Network:   network.Name,
+			IfName:    "eth1",
+			PodName:   pod.Name,
+			Namespace: "default",
+			PodUID:    string(pod.UID),
+		}, "default", "default")
+		suite.NoError(err)
+		suite.Equal(cidr, allocation.CIDR)
+	}
+
+	// the interface gets the same address again
+	request := &entity.IPAllocationRequest{
+		Network:   network.Name,
+		IfName:    "eth1",
+		PodName:   pods[0].Name,
+		Namespace: "default",
+		PodUID:    string(pods[0].UID),
+	}
+	allocation, err := Allocate(suite.sp, request, "default", "default")
+	suite.NoError(err)
+	suite.Equal("10.1.0.3/24", allocation.CIDR)
+
+	// the caller isn't the service account of the pod
+	_, err = Allocate(suite.sp, request, "default", "awesome")
+	suite.Equal(ErrServiceAccountMismatch, err)
+	_, err = Allocate(suite.sp, request, "vortex", "default")
+	suite.Equal(ErrServiceAccountMismatch, err)
+
+	// the pod isn't running
+	request.PodUID = "awesome"
+	_, err = Allocate(suite.sp, request, "default", "default")
+	suite.Equal(ErrPodMismatch, err)
+
+	// the interface isn't declared by the init containers of the pod
+	request.PodUID = string(pods[0].UID)
+	request.IfName = "eth2"
+	_, err = Allocate(suite.sp, request, "default", "default")
+	suite.Equal(ErrUndeclaredInterface, err)
+
+	// the address of the deleted pod is reused
+	pod := suite.createPod(network.Name)
+	defer suite.sp.KubeCtl.DeletePod(pod.Name, "default")
+	request = &entity.IPAllocationRequest{
+		Network:   network.Name,
+		IfName:    "eth1",
+		PodName:   pod.Name,
+		Namespace: "default",
+		PodUID:    string(pod.UID),
+	}
+	_, err = Allocate(suite.sp, request, "default", "default")
+	suite.Equal(ErrExhausted, err)
+	suite.NoError(suite.sp.KubeCtl.DeletePod(pods[1].Name, "default"))
+	// the stale addresses were released in the interval
+	_, err = Allocate(suite.sp, request, "default", "default")
+	suite.Equal(ErrExhausted, err)
+	staleReleases.Delete(network.Name)
+	allocation, err = Allocate(suite.sp, request, "default", "default")
+	suite.NoError(err)
+	suite.Equal("10.2.0.1/30", allocation.CIDR)
+}
+
+func (suite *IPAMTestSuite) TestAllocateWithoutSubnet() {
+	session := suite.sp.Mongo.NewSession()
+	defer session.Close()
+
+	network := entity.Network{
+		ID:   bson.NewObjectId(),
+		Name: namesgenerator.GetRandomName(0),
+	}
+	session.Insert(entity.NetworkCollectionName, network)
+	defer session.Remove(entity.NetworkCollectionName, "_id", network.ID)
+
+	pod := suite.createPod(network.Name)
+	defer suite.sp.KubeCtl.DeletePod(pod.Name, "default")
+	_, err := Allocate(suite.sp, &entity.IPAllocationRequest{
+		Network:   network.Name,
+		IfName:    "eth1",
+		PodName:   pod.Name,
+		Namespace: "default",
+		PodUID:    string(pod.UID),
+	}, "default", "default")
+	suite.Equal(ErrNoSubnet, err)
+}
+
+func (suite *IPAMTestSuite) TestReleaseStale() {
+	session := suite.sp.Mongo.NewSession()
+	defer session.Close()
+
+	network := namesgenerator.GetRandomName(0)
+	pod := suite.createPod(network)
+	defer suite.sp.KubeCtl.DeletePod(pod.Name, "default")
+
+	running := entity.IPAllocation{ID: bson.NewObjectId(), Network: network, Address: "10.1.0.1", PodName: pod.Name, Namespace: "default", PodUID: string(pod.UID)}
+	// the pod with the same name is recreated
+	replaced := entity.IPAllocation{ID: bson.NewObjectId(), Network: network, Address: "10.1.0.2", PodName: pod.Name, Namespace: "default", PodUID: "awesome"}
+	deleted := entity.IPAllocation{ID: bson.NewObjectId(), Network: network, Address: "10.1.0.3", PodName: namesgenerator.GetRandomName(1), Namespace: "default", PodUID: "awesome"}
+	for _, a := range []entity.IPAllocation{running, replaced, deleted} {
+		session.Insert(entity.IPAllocationCollectionName, a)
+	}
+	defer session.C(entity.IPAllocationCollectionName).RemoveAll(bson.M{"network": network})
+
+	released, err := ReleaseStale(suite.sp, network)
+	suite.NoError(err)
+	suite.Equal(2, released)
+	count, err := session.Count(entity.IPAllocationCollectionName, bson.M{"network": network})
+	suite.NoError(err)
+	suite.Equal(1, count)
+}
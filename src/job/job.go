@@ -0,0 +1,341 @@
+package job
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/kubeutils"
+	"github.com/linkernetworks/vortex/src/pod"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ManualAnnotation marks the jobs triggered manually, the same one kubectl sets with "create job --from=cronjob"
+const ManualAnnotation = "cronjob.kubernetes.io/instantiate"
+
+// ErrRunActive is returned when the CronJob forbids the concurrent runs and one is still running
+var ErrRunActive = fmt.Errorf("The CronJob forbids the concurrent runs and the previous run is still running")
+
+// checkJobSpec will check the pods of the Job or the CronJob
+func checkJobSpec(sp *serviceprovider.Container, name string, namespace string, spec *entity.JobSpec) error {
+	p := spec.Pod(name, namespace)
+	if err := pod.CheckPodParameter(sp, &p); err != nil {
+		return err
+	}
+	// the pods running at the same time can't share the static addresses
+	if spec.NetworkType == entity.PodCustomNetwork && len(spec.Networks) != 0 && spec.Parallelism > 1 {
+		return fmt.Errorf("the parallelism can't be greater than 1 when the pods have the static addresses of the custom networks")
+	}
+	return nil
+}
+
+// CheckJobParameter will check the parameters of the Job
+func CheckJobParameter(sp *serviceprovider.Container, job *entity.Job) error {
+	return checkJobSpec(sp, job.Name, job.Namespace, &job.JobSpec)
+}
+
+// CheckCronJobParameter will check the parameters of the CronJob
+func CheckCronJobParameter(sp *serviceprovider.Container, cronJob *entity.CronJob) error {
+	if err := checkJobSpec(sp, cronJob.Name, cronJob.Namespace, &cronJob.JobSpec); err != nil {
+		return err
+	}
+	// the runs overlapping each other can't share the static addresses either
+	if cronJob.NetworkType == entity.PodCustomNetwork && len(cronJob.Networks) != 0 && cronJob.ConcurrencyPolicy != entity.CronJobForbidConcurrent {
+		return fmt.Errorf("the concurrency policy must be %s when the pods have the static addresses of the custom networks", entity.CronJobForbidConcurrent)
+	}
+	return nil
+}
+
+// generateJobSpec generates the kubernetes JobSpec, the pods are generated in the same way as the Pod
+func generateJobSpec(session *mongo.Session, name string, namespace string, spec *entity.JobSpec) (batchv1.JobSpec, error) {
+	p := spec.Pod(name, namespace)
+	podSpec, err := pod.GeneratePodSpec(session, &p)
+	if err != nil {
+		return batchv1.JobSpec{}, err
+	}
+
+	jobSpec := batchv1.JobSpec{
+		BackoffLimit: spec.BackoffLimit,
+		Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: spec.Labels,
+			},
+			Spec: podSpec,
+		},
+	}
+	if spec.Completions > 0 {
+		completions := spec.Completions
+		jobSpec.Completions = &completions
+	}
+	if spec.Parallelism > 0 {
+		parallelism := spec.Parallelism
+		jobSpec.Parallelism = &parallelism
+	}
+	if spec.ActiveDeadlineSeconds > 0 {
+		deadline := spec.ActiveDeadlineSeconds
+		jobSpec.ActiveDeadlineSeconds = &deadline
+	}
+	return jobSpec, nil
+}
+
+// generateCronJob generates the kubernetes CronJob of the CronJob
+func generateCronJob(session *mongo.Session, cronJob *entity.CronJob) (*batchv1beta1.CronJob, error) {
+	jobSpec, err := generateJobSpec(session, cronJob.Name, cronJob.Namespace, &cronJob.JobSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	suspend := cronJob.Suspend
+	c := batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   cronJob.Name,
+			Labels: cronJob.Labels,
+		},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule:                   cronJob.Schedule,
+			ConcurrencyPolicy:          batchv1beta1.ConcurrencyPolicy(cronJob.ConcurrencyPolicy),
+			Suspend:                    &suspend,
+			SuccessfulJobsHistoryLimit: cronJob.SuccessfulJobsHistoryLimit,
+			FailedJobsHistoryLimit:     cronJob.FailedJobsHistoryLimit,
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: cronJob.Labels,
+				},
+				Spec: jobSpec,
+			},
+		},
+	}
+	if c.Spec.ConcurrencyPolicy == "" {
+		c.Spec.ConcurrencyPolicy = batchv1beta1.AllowConcurrent
+	}
+	return &c, nil
+}
+
+// CreateJob will create the Job
+func CreateJob(sp *serviceprovider.Container, job *entity.Job) error {
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	jobSpec, err := generateJobSpec(session, job.Name, job.Namespace, &job.JobSpec)
+	if err != nil {
+		return err
+	}
+	j := batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   job.Name,
+			Labels: job.Labels,
+		},
+		Spec: jobSpec,
+	}
+	_, err = sp.KubeCtl.CreateJob(&j, job.Namespace)
+	return err
+}
+
+// DeleteJob will delete the Job and its pods
+func DeleteJob(sp *serviceprovider.Container, job *entity.Job) error {
+	return sp.KubeCtl.DeleteJob(job.Name, job.Namespace)
+}
+
+// GetJobRun gets the status of the Job and its pods
+func GetJobRun(sp *serviceprovider.Container, job *entity.Job) (*entity.JobRun, error) {
+	current, err := sp.KubeCtl.GetJob(job.Name, job.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := sp.KubeCtl.GetPods(job.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	run := generateJobRun(current, pods)
+	return &run, nil
+}
+
+// CreateCronJob will create the CronJob
+func CreateCronJob(sp *serviceprovider.Container, cronJob *entity.CronJob) error {
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	c, err := generateCronJob(session, cronJob)
+	if err != nil {
+		return err
+	}
+	_, err = sp.KubeCtl.CreateCronJob(c, cronJob.Namespace)
+	return err
+}
+
+// UpdateCronJob will update the schedule and the job template of the CronJob, the running jobs aren't changed
+func UpdateCronJob(sp *serviceprovider.Container, cronJob *entity.CronJob) error {
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	c, err := generateCronJob(session, cronJob)
+	if err != nil {
+		return err
+	}
+	current, err := sp.KubeCtl.GetCronJob(cronJob.Name, cronJob.Namespace)
+	if err != nil {
+		return err
+	}
+
+	current.Labels = c.Labels
+	current.Spec = c.Spec
+	_, err = sp.KubeCtl.UpdateCronJob(current, cronJob.Namespace)
+	return err
+}
+
+// DeleteCronJob will delete the CronJob and its jobs
+func DeleteCronJob(sp *serviceprovider.Container, cronJob *entity.CronJob) error {
+	return sp.KubeCtl.DeleteCronJob(cronJob.Name, cronJob.Namespace)
+}
+
+// ListCronJobRuns lists the jobs of the CronJob, the newest first.
+// The finished ones are kept as many as the history limits.
+func ListCronJobRuns(sp *serviceprovider.Container, cronJob *entity.CronJob) ([]entity.JobRun, error) {
+	current, err := sp.KubeCtl.GetCronJob(cronJob.Name, cronJob.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := sp.KubeCtl.GetJobs(cronJob.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := sp.KubeCtl.GetPods(cronJob.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := []entity.JobRun{}
+	for _, j := range jobs {
+		if isControlledBy(&j.ObjectMeta, "CronJob", &current.ObjectMeta) {
+			runs = append(runs, generateJobRun(j, pods))
+		}
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		if runs[i].StartTime == nil || runs[j].StartTime == nil {
+			return runs[i].StartTime == nil && runs[j].StartTime != nil
+		}
+		return runs[i].StartTime.After(*runs[j].StartTime)
+	})
+	return runs, nil
+}
+
+// TriggerCronJob runs the job of the CronJob now, regardless of its schedule and whether it's suspended.
+// The job is owned by the CronJob, so it's counted in the history of the CronJob.
+func TriggerCronJob(sp *serviceprovider.Container, cronJob *entity.CronJob) (*entity.JobRun, error) {
+	current, err := sp.KubeCtl.GetCronJob(cronJob.Name, cronJob.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if current.Spec.ConcurrencyPolicy == batchv1beta1.ForbidConcurrent {
+		jobs, err := sp.KubeCtl.GetJobs(cronJob.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, j := range jobs {
+			if isControlledBy(&j.ObjectMeta, "CronJob", &current.ObjectMeta) && jobStatus(j) == entity.JobRunning {
+				return nil, ErrRunActive
+			}
+		}
+	}
+
+	isController := true
+	j := batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: current.Name + "-",
+			Labels:       current.Spec.JobTemplate.Labels,
+			Annotations:  map[string]string{ManualAnnotation: "manual"},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "batch/v1beta1",
+					Kind:       "CronJob",
+					Name:       current.Name,
+					UID:        current.UID,
+					Controller: &isController,
+				},
+			},
+		},
+		Spec: current.Spec.JobTemplate.Spec,
+	}
+	created, err := sp.KubeCtl.CreateJob(&j, cronJob.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	run := generateJobRun(created, []*corev1.Pod{})
+	return &run, nil
+}
+
+// isControlledBy checks the controller of the object by its kind, name and UID,
+// the objects of the deleted owner with the same name aren't controlled by the new one
+func isControlledBy(object *metav1.ObjectMeta, kind string, owner *metav1.ObjectMeta) bool {
+	controller := metav1.GetControllerOf(object)
+	return controller != nil && controller.Kind == kind && controller.Name == owner.Name && controller.UID == owner.UID
+}
+
+func jobStatus(job *batchv1.Job) string {
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobFailed:
+			return entity.JobFailed
+		case batchv1.JobComplete:
+			return entity.JobSucceeded
+		}
+	}
+	return entity.JobRunning
+}
+
+func generateJobRun(job *batchv1.Job, pods []*corev1.Pod) entity.JobRun {
+	run := entity.JobRun{
+		Name:      job.Name,
+		Namespace: job.Namespace,
+		Status:    jobStatus(job),
+		Manual:    job.Annotations[ManualAnnotation] == "manual",
+		Active:    job.Status.Active,
+		Succeeded: job.Status.Succeeded,
+		Failed:    job.Status.Failed,
+		Pods:      []entity.JobPodStatus{},
+	}
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+			run.Reason = c.Reason
+			run.Message = c.Message
+		}
+	}
+	if job.Status.StartTime != nil {
+		run.StartTime = &job.Status.StartTime.Time
+	}
+	if job.Status.CompletionTime != nil {
+		run.CompletionTime = &job.Status.CompletionTime.Time
+	}
+
+	for _, p := range pods {
+		if !isControlledBy(&p.ObjectMeta, "Job", &job.ObjectMeta) {
+			continue
+		}
+		status := entity.JobPodStatus{
+			Name:       p.Name,
+			Node:       p.Spec.NodeName,
+			Phase:      string(p.Status.Phase),
+			Reason:     p.Status.Reason,
+			Message:    p.Status.Message,
+			Containers: kubeutils.GenerateContainerStatuses(p.Status.ContainerStatuses),
+		}
+		if p.Status.StartTime != nil {
+			status.StartTime = &p.Status.StartTime.Time
+		}
+		run.Pods = append(run.Pods, status)
+	}
+	sort.Slice(run.Pods, func(i, j int) bool {
+		return run.Pods[i].Name < run.Pods[j].Name
+	})
+	return run
+}
@@ -0,0 +1,270 @@
+package job
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/suite"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func init() {
+	rand.Seed(time.Now().UnixNano())
+}
+
+type JobTestSuite struct {
+	suite.Suite
+	sp      *serviceprovider.Container
+	network entity.Network
+}
+
+func (suite *JobTestSuite) SetupSuite() {
+	cf := config.MustRead("../../config/testing.json")
+	suite.sp = serviceprovider.NewForTesting(cf)
+
+	suite.network = entity.Network{
+		ID:         bson.NewObjectId(),
+		Name:       namesgenerator.GetRandomName(0),
+		BridgeName: namesgenerator.GetRandomName(0),
+		Nodes: []entity.Node{
+			{Name: "node1"},
+		},
+	}
+	session := suite.sp.Mongo.NewSession()
+	defer session.Close()
+	session.Insert(entity.NetworkCollectionName, suite.network)
+}
+
+func (suite *JobTestSuite) TearDownSuite() {
+	session := suite.sp.Mongo.NewSession()
+	defer session.Close()
+	session.Remove(entity.NetworkCollectionName, "_id", suite.network.ID)
+}
+
+func TestJobSuite(t *testing.T) {
+	suite.Run(t, new(JobTestSuite))
+}
+
+func newJobSpec() entity.JobSpec {
+	return entity.JobSpec{
+		Containers: []entity.Container{
+			{
+				Name:    namesgenerator.GetRandomName(0),
+				Image:   "busybox",
+				Command: []string{"echo", "done"},
+			},
+		},
+		RestartPolicy: "Never",
+		NetworkType:   entity.PodClusterNetwork,
+	}
+}
+
+func (suite *JobTestSuite) customNetworks() []entity.PodNetwork {
+	return []entity.PodNetwork{
+		{
+			Name:      suite.network.Name,
+			IfName:    "eth1",
+			IPAddress: "10.1.0.10",
+			Netmask:   "255.255.255.0",
+		},
+	}
+}
+
+func (suite *JobTestSuite) TestCheckJobParameter() {
+	job := &entity.Job{
+		Name:      namesgenerator.GetRandomName(0),
+		Namespace: "default",
+		JobSpec:   newJobSpec(),
+	}
+	job.Parallelism = 3
+	suite.NoError(CheckJobParameter(suite.sp, job))
+
+	// the parallel pods can't share the static address
+	job.NetworkType = entity.PodCustomNetwork
+	job.Networks = suite.customNetworks()
+	suite.Error(CheckJobParameter(suite.sp, job))
+	job.Parallelism = 1
+	suite.NoError(CheckJobParameter(suite.sp, job))
+
+	job.Networks[0].Name = namesgenerator.GetRandomName(0)
+	suite.Error(CheckJobParameter(suite.sp, job))
+}
+
+func (suite *JobTestSuite) TestCheckCronJobParameter() {
+	cronJob := &entity.CronJob{
+		Name:      namesgenerator.GetRandomName(0),
+		Namespace: "default",
+		Schedule:  "*/5 * * * *",
+		JobSpec:   newJobSpec(),
+	}
+	suite.NoError(CheckCronJobParameter(suite.sp, cronJob))
+
+	// the overlapping runs can't share the static address
+	cronJob.NetworkType = entity.PodCustomNetwork
+	cronJob.Networks = suite.customNetworks()
+	suite.Error(CheckCronJobParameter(suite.sp, cronJob))
+	cronJob.ConcurrencyPolicy = entity.CronJobForbidConcurrent
+	suite.NoError(CheckCronJobParameter(suite.sp, cronJob))
+}
+
+func (suite *JobTestSuite) TestGenerateJobSpec() {
+	session := suite.sp.Mongo.NewSession()
+	defer session.Close()
+
+	spec := newJobSpec()
+	s, err := generateJobSpec(session, "job", "default", &spec)
+	suite.NoError(err)
+	suite.Nil(s.Completions)
+	suite.Nil(s.Parallelism)
+	suite.Nil(s.ActiveDeadlineSeconds)
+	suite.Equal(corev1.RestartPolicyNever, s.Template.Spec.RestartPolicy)
+
+	var backoffLimit int32 = 2
+	spec.Completions = 5
+	spec.Parallelism = 2
+	spec.BackoffLimit = &backoffLimit
+	spec.ActiveDeadlineSeconds = 600
+	s, err = generateJobSpec(session, "job", "default", &spec)
+	suite.NoError(err)
+	suite.Equal(int32(5), *s.Completions)
+	suite.Equal(int32(2), *s.Parallelism)
+	suite.Equal(int32(2), *s.BackoffLimit)
+	suite.Equal(int64(600), *s.ActiveDeadlineSeconds)
+
+	spec.NetworkType = entity.PodCustomNetwork
+	spec.Networks = suite.customNetworks()
+	s, err = generateJobSpec(session, "job", "default", &spec)
+	suite.NoError(err)
+	suite.Len(s.Template.Spec.InitContainers, 1)
+	suite.Equal([]string{"node1"}, s.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions[0].Values)
+}
+
+func (suite *JobTestSuite) TestJobStatus() {
+	job := &batchv1.Job{}
+	suite.Equal(entity.JobRunning, jobStatus(job))
+
+	job.Status.Conditions = []batchv1.JobCondition{
+		{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+	}
+	suite.Equal(entity.JobSucceeded, jobStatus(job))
+
+	job.Status.Conditions = []batchv1.JobCondition{
+		{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded"},
+	}
+	suite.Equal(entity.JobFailed, jobStatus(job))
+	run := generateJobRun(job, []*corev1.Pod{})
+	suite.Equal("BackoffLimitExceeded", run.Reason)
+}
+
+func (suite *JobTestSuite) TestIsControlledBy() {
+	isController := true
+	owner := metav1.ObjectMeta{Name: "awesome", UID: types.UID(bson.NewObjectId().Hex())}
+	job := metav1.ObjectMeta{
+		OwnerReferences: []metav1.OwnerReference{
+			{Kind: "CronJob", Name: owner.Name, UID: owner.UID, Controller: &isController},
+		},
+	}
+	suite.True(isControlledBy(&job, "CronJob", &owner))
+	suite.False(isControlledBy(&job, "Job", &owner))
+
+	// the CronJob is recreated with the same name
+	recreated := metav1.ObjectMeta{Name: owner.Name, UID: types.UID(bson.NewObjectId().Hex())}
+	suite.False(isControlledBy(&job, "CronJob", &recreated))
+}
+
+func (suite *JobTestSuite) TestCreateJob() {
+	job := &entity.Job{
+		Name:      namesgenerator.GetRandomName(0),
+		Namespace: "default",
+		JobSpec:   newJobSpec(),
+	}
+	err := CreateJob(suite.sp, job)
+	suite.NoError(err)
+
+	isController := true
+	p := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: job.Name + "-abcde",
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "Job", Name: job.Name, Controller: &isController},
+			},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	_, err = suite.sp.KubeCtl.CreatePod(&p, job.Namespace)
+	suite.NoError(err)
+	defer suite.sp.KubeCtl.DeletePod(p.Name, job.Namespace)
+
+	run, err := GetJobRun(suite.sp, job)
+	suite.NoError(err)
+	suite.Equal(entity.JobRunning, run.Status)
+	suite.False(run.Manual)
+	suite.Len(run.Pods, 1)
+	suite.Equal(p.Name, run.Pods[0].Name)
+
+	err = DeleteJob(suite.sp, job)
+	suite.NoError(err)
+	_, err = GetJobRun(suite.sp, job)
+	suite.Error(err)
+}
+
+func (suite *JobTestSuite) TestCronJob() {
+	cronJob := &entity.CronJob{
+		Name:      namesgenerator.GetRandomName(0),
+		Namespace: "default",
+		Schedule:  "*/5 * * * *",
+		JobSpec:   newJobSpec(),
+	}
+	err := CreateCronJob(suite.sp, cronJob)
+	suite.NoError(err)
+	defer DeleteCronJob(suite.sp, cronJob)
+
+	cronJob.Schedule = "0 * * * *"
+	cronJob.Suspend = true
+	cronJob.ConcurrencyPolicy = entity.CronJobForbidConcurrent
+	err = UpdateCronJob(suite.sp, cronJob)
+	suite.NoError(err)
+	current, err := suite.sp.KubeCtl.GetCronJob(cronJob.Name, cronJob.Namespace)
+	suite.NoError(err)
+	suite.Equal("0 * * * *", current.Spec.Schedule)
+	suite.True(*current.Spec.Suspend)
+
+	runs, err := ListCronJobRuns(suite.sp, cronJob)
+	suite.NoError(err)
+	suite.Empty(runs)
+
+	// the suspended CronJob can still be triggered manually
+	run, err := TriggerCronJob(suite.sp, cronJob)
+	suite.NoError(err)
+	suite.True(run.Manual)
+	defer suite.sp.KubeCtl.DeleteJob(run.Name, cronJob.Namespace)
+
+	runs, err = ListCronJobRuns(suite.sp, cronJob)
+	suite.NoError(err)
+	suite.Len(runs, 1)
+	suite.Equal(run.Name, runs[0].Name)
+
+	// the previous run is still running
+	_, err = TriggerCronJob(suite.sp, cronJob)
+	suite.Equal(ErrRunActive, err)
+}
+
+func (suite *JobTestSuite) TestTriggerCronJobFail() {
+	cronJob := &entity.CronJob{
+		Name:      namesgenerator.GetRandomName(0),
+		Namespace: "default",
+	}
+	_, err := TriggerCronJob(suite.sp, cronJob)
+	suite.Error(err)
+}
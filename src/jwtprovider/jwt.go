@@ -0,0 +1,167 @@
+package jwtprovider
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/linkernetworks/vortex/src/entity"
+)
+
+const (
+	defaultTokenExpiry        = time.Hour
+	defaultRefreshTokenExpiry = 30 * 24 * time.Hour
+	defaultSessionCacheTTL    = 30 * time.Second
+)
+
+// knownSecrets are the secrets published with the earlier configs, anyone can sign tokens with them
+var knownSecrets = map[string]bool{
+	"linkernetworks": true,
+}
+
+// KeyConfig is the structure for a JWT signing key
+type KeyConfig struct {
+	// ID is put into the kid header of the tokens signed by this key
+	ID     string `json:"kid"`
+	Secret string `json:"secret"`
+	// SecretEnv is the environment variable holding the secret, e.g. set from a Kubernetes secret.
+	// It takes precedence over Secret, which is only meant for the local development.
+	SecretEnv string `json:"secretEnv"`
+}
+
+// JWTConfig is the structure for JWT Config
+type JWTConfig struct {
+	// Keys are the active keys. The first key signs new tokens, the others are
+	// kept to verify the tokens issued before the key rotation
+	Keys []KeyConfig `json:"keys"`
+	// TokenExpiry is the lifetime of an access token, e.g. "1h"
+	TokenExpiry string `json:"tokenExpiry"`
+	// RefreshTokenExpiry is the lifetime of a refresh token, e.g. "720h"
+	RefreshTokenExpiry string `json:"refreshTokenExpiry"`
+	// SessionCacheTTL is how long a session lookup is cached, a revoked
+	// session may be accepted by other replicas for at most this duration
+	SessionCacheTTL string `json:"sessionCacheTTL"`
+}
+
+// Service is the structure for Service
+type Service struct {
+	keys         map[string][]byte
+	signingKeyID string
+
+	TokenExpiry        time.Duration
+	RefreshTokenExpiry time.Duration
+	SessionCacheTTL    time.Duration
+}
+
+// New will return a new service
+func New(cf *JWTConfig) (*Service, error) {
+	if cf == nil || len(cf.Keys) == 0 {
+		return nil, fmt.Errorf("At least one JWT signing key is required")
+	}
+
+	service := &Service{
+		keys:               map[string][]byte{},
+		signingKeyID:       cf.Keys[0].ID,
+		TokenExpiry:        defaultTokenExpiry,
+		RefreshTokenExpiry: defaultRefreshTokenExpiry,
+		SessionCacheTTL:    defaultSessionCacheTTL,
+	}
+	for _, key := range cf.Keys {
+		if key.SecretEnv != "" {
+			key.Secret = os.Getenv(key.SecretEnv)
+		}
+		if key.ID == "" || key.Secret == "" {
+			return nil, fmt.Errorf("The JWT signing key must have both kid and secret")
+		}
+		if knownSecrets[key.Secret] {
+			return nil, fmt.Errorf("The JWT signing key %s uses a published secret, generate a random one", key.ID)
+		}
+		if _, ok := service.keys[key.ID]; ok {
+			return nil, fmt.Errorf("Duplicate JWT signing key: %s", key.ID)
+		}
+		service.keys[key.ID] = []byte(key.Secret)
+	}
+
+	var err error
+	if cf.TokenExpiry != "" {
+		if service.TokenExpiry, err = time.ParseDuration(cf.TokenExpiry); err != nil {
+			return nil, fmt.Errorf("Invalid token expiry: %v", err)
+		}
+	}
+	if cf.RefreshTokenExpiry != "" {
+		if service.RefreshTokenExpiry, err = time.ParseDuration(cf.RefreshTokenExpiry); err != nil {
+			return nil, fmt.Errorf("Invalid refresh token expiry: %v", err)
+		}
+	}
+	if cf.SessionCacheTTL != "" {
+		if service.SessionCacheTTL, err = time.ParseDuration(cf.SessionCacheTTL); err != nil {
+			return nil, fmt.Errorf("Invalid session cache ttl: %v", err)
+		}
+	}
+	return service, nil
+}
+
+// GenerateToken is for generating token, the sessionID is put into the jti claim
+func (s *Service) GenerateToken(userID string, user entity.User, sessionID string) (string, error) {
+	now := time.Now()
+	token := jwt.New(jwt.SigningMethodHS256)
+	token.Header["kid"] = s.signingKeyID
+	token.Claims = jwt.MapClaims{
+		// expiration time
+		"exp": now.Add(s.TokenExpiry).Unix(),
+		// issued-at time
+		"iat": now.Unix(),
+		// user role
+		"role": user.Role,
+		// user email
+		"username": user.LoginCredential.Username,
+		// user display name
+		"displayName": user.DisplayName,
+		// the subject of this token. This is the user associated with the relevant action
+		"sub": userID,
+		// the session of this token, used to revoke it
+		"jti": sessionID,
+	}
+	return token.SignedString(s.keys[s.signingKeyID])
+}
+
+// ParseToken verifies the signature, exp and iat of the token and returns its claims
+func (s *Service) ParseToken(tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, s.keyFunc)
+	if err != nil {
+		return nil, err
+	}
+	return s.claims(token)
+}
+
+func (s *Service) keyFunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
+	}
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		return nil, fmt.Errorf("The token has no kid")
+	}
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("Unknown signing key: %s", kid)
+	}
+	return key, nil
+}
+
+func (s *Service) claims(token *jwt.Token) (jwt.MapClaims, error) {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("Token is invalid")
+	}
+	// jwt-go only checks exp and iat when they exist, both are required here
+	now := time.Now().Unix()
+	if !claims.VerifyExpiresAt(now, true) {
+		return nil, fmt.Errorf("Token is expired")
+	}
+	if !claims.VerifyIssuedAt(now, true) {
+		return nil, fmt.Errorf("Token used before issued")
+	}
+	return claims, nil
+}
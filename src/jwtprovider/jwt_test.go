@@ -0,0 +1,129 @@
+package jwtprovider
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestService(t *testing.T) *Service {
+	service, err := New(&JWTConfig{
+		Keys: []KeyConfig{
+			{ID: "current", Secret: "current-secret"},
+			{ID: "previous", Secret: "previous-secret"},
+		},
+		TokenExpiry:        "10m",
+		RefreshTokenExpiry: "24h",
+		SessionCacheTTL:    "10s",
+	})
+	assert.NoError(t, err)
+	return service
+}
+
+func TestNew(t *testing.T) {
+	service := newTestService(t)
+	assert.Equal(t, 10*time.Minute, service.TokenExpiry)
+	assert.Equal(t, 24*time.Hour, service.RefreshTokenExpiry)
+	assert.Equal(t, 10*time.Second, service.SessionCacheTTL)
+
+	_, err := New(nil)
+	assert.Error(t, err)
+	_, err = New(&JWTConfig{Keys: []KeyConfig{{ID: "current"}}})
+	assert.Error(t, err)
+	_, err = New(&JWTConfig{Keys: []KeyConfig{{ID: "current", Secret: "a"}, {ID: "current", Secret: "b"}}})
+	assert.Error(t, err)
+	_, err = New(&JWTConfig{Keys: []KeyConfig{{ID: "current", Secret: "a"}}, TokenExpiry: "forever"})
+	assert.Error(t, err)
+	_, err = New(&JWTConfig{Keys: []KeyConfig{{ID: "current", Secret: "linkernetworks"}}})
+	assert.Error(t, err)
+}
+
+func TestNewWithSecretEnv(t *testing.T) {
+	os.Unsetenv("VORTEX_TEST_JWT_SECRET")
+	_, err := New(&JWTConfig{Keys: []KeyConfig{{ID: "current", SecretEnv: "VORTEX_TEST_JWT_SECRET"}}})
+	assert.Error(t, err)
+
+	os.Setenv("VORTEX_TEST_JWT_SECRET", "secret-from-env")
+	defer os.Unsetenv("VORTEX_TEST_JWT_SECRET")
+	service, err := New(&JWTConfig{Keys: []KeyConfig{{ID: "current", SecretEnv: "VORTEX_TEST_JWT_SECRET"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("secret-from-env"), service.keys["current"])
+}
+
+func TestGenerateToken(t *testing.T) {
+	service := newTestService(t)
+	user := entity.User{
+		LoginCredential: entity.LoginCredential{
+			Username: "admin@linkernetworks.com",
+		},
+		DisplayName: "admin",
+		Role:        "root",
+		FirstName:   "john",
+		LastName:    "lin",
+		PhoneNumber: "123456789",
+	}
+	tokenString, err := service.GenerateToken("234243353535330", user, "5b7a0b4a6b3f5a0001a1b2c3")
+	assert.NotEmpty(t, tokenString)
+	assert.NoError(t, err)
+
+	claims, err := service.ParseToken(tokenString)
+	assert.NoError(t, err)
+	assert.Equal(t, "234243353535330", claims["sub"])
+	assert.Equal(t, "root", claims["role"])
+	assert.Equal(t, "5b7a0b4a6b3f5a0001a1b2c3", claims["jti"])
+}
+
+func TestParseRotatedToken(t *testing.T) {
+	service := newTestService(t)
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "234243353535330",
+		"exp": time.Now().Add(time.Minute).Unix(),
+		"iat": time.Now().Unix(),
+	})
+	token.Header["kid"] = "previous"
+	tokenString, err := token.SignedString([]byte("previous-secret"))
+	assert.NoError(t, err)
+
+	_, err = service.ParseToken(tokenString)
+	assert.NoError(t, err)
+}
+
+func TestParseInvalidToken(t *testing.T) {
+	service := newTestService(t)
+	now := time.Now()
+
+	testCases := []struct {
+		caseName string
+		kid      string
+		secret   string
+		claims   jwt.MapClaims
+	}{
+		{"expired", "current", "current-secret", jwt.MapClaims{"exp": now.Add(-time.Minute).Unix(), "iat": now.Add(-time.Hour).Unix()}},
+		{"withoutExp", "current", "current-secret", jwt.MapClaims{"iat": now.Unix()}},
+		{"withoutIat", "current", "current-secret", jwt.MapClaims{"exp": now.Add(time.Minute).Unix()}},
+		{"issuedInFuture", "current", "current-secret", jwt.MapClaims{"exp": now.Add(time.Hour).Unix(), "iat": now.Add(time.Minute).Unix()}},
+		{"unknownKid", "retired", "current-secret", jwt.MapClaims{"exp": now.Add(time.Minute).Unix(), "iat": now.Unix()}},
+		{"withoutKid", "", "current-secret", jwt.MapClaims{"exp": now.Add(time.Minute).Unix(), "iat": now.Unix()}},
+		{"wrongSecret", "current", "previous-secret", jwt.MapClaims{"exp": now.Add(time.Minute).Unix(), "iat": now.Unix()}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.caseName, func(t *testing.T) {
+			token := jwt.NewWithClaims(jwt.SigningMethodHS256, tc.claims)
+			if tc.kid != "" {
+				token.Header["kid"] = tc.kid
+			}
+			tokenString, err := token.SignedString([]byte(tc.secret))
+			assert.NoError(t, err)
+
+			_, err = service.ParseToken(tokenString)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := service.ParseToken("fakeToken")
+	assert.Error(t, err)
+}
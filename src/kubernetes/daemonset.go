@@ -0,0 +1,40 @@
+package kubernetes
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreateDaemonSet will create the daemonset
+func (kc *KubeCtl) CreateDaemonSet(daemonSet *appsv1.DaemonSet, namespace string) (*appsv1.DaemonSet, error) {
+	return kc.Clientset.AppsV1().DaemonSets(namespace).Create(daemonSet)
+}
+
+// GetDaemonSet will get the daemonset
+func (kc *KubeCtl) GetDaemonSet(name string, namespace string) (*appsv1.DaemonSet, error) {
+	return kc.Clientset.AppsV1().DaemonSets(namespace).Get(name, metav1.GetOptions{})
+}
+
+// GetDaemonSets will get the daemonsets
+func (kc *KubeCtl) GetDaemonSets(namespace string) ([]*appsv1.DaemonSet, error) {
+	daemonSets := []*appsv1.DaemonSet{}
+	daemonSetList, err := kc.Clientset.AppsV1().DaemonSets(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return daemonSets, err
+	}
+	for i := range daemonSetList.Items {
+		daemonSets = append(daemonSets, &daemonSetList.Items[i])
+	}
+	return daemonSets, nil
+}
+
+// DeleteDaemonSet will delete the daemonset and its pods
+func (kc *KubeCtl) DeleteDaemonSet(name string, namespace string) error {
+	propagation := metav1.DeletePropagationForeground
+	return kc.Clientset.AppsV1().DaemonSets(namespace).Delete(name, &metav1.DeleteOptions{PropagationPolicy: &propagation})
+}
+
+// UpdateDaemonSet will update the daemonset
+func (kc *KubeCtl) UpdateDaemonSet(daemonSet *appsv1.DaemonSet, namespace string) (*appsv1.DaemonSet, error) {
+	return kc.Clientset.AppsV1().DaemonSets(namespace).Update(daemonSet)
+}
@@ -0,0 +1,91 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/suite"
+	appsv1 "k8s.io/api/apps/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+)
+
+type KubeCtlDaemonSetTestSuite struct {
+	suite.Suite
+	kubectl    *KubeCtl
+	fakeclient *fakeclientset.Clientset
+}
+
+func (suite *KubeCtlDaemonSetTestSuite) SetupSuite() {
+	suite.fakeclient = fakeclientset.NewSimpleClientset()
+	suite.kubectl = New(suite.fakeclient)
+}
+
+func (suite *KubeCtlDaemonSetTestSuite) TearDownSuite() {}
+func (suite *KubeCtlDaemonSetTestSuite) TestCreateDaemonSet() {
+	namespace := "default"
+	name := namesgenerator.GetRandomName(0)
+	daemonSet := appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			MinReadySeconds: 5,
+		},
+	}
+	ret, err := suite.kubectl.CreateDaemonSet(&daemonSet, namespace)
+	suite.NoError(err)
+	suite.NotNil(ret)
+
+	d, err := suite.kubectl.GetDaemonSet(name, namespace)
+	suite.NoError(err)
+	suite.Equal(int32(5), d.Spec.MinReadySeconds)
+
+	daemonSets, err := suite.kubectl.GetDaemonSets(namespace)
+	suite.NoError(err)
+	suite.NotEmpty(daemonSets)
+}
+
+func (suite *KubeCtlDaemonSetTestSuite) TestDeleteDaemonSet() {
+	namespace := "default"
+	name := namesgenerator.GetRandomName(0)
+	daemonSet := appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+	}
+	_, err := suite.kubectl.CreateDaemonSet(&daemonSet, namespace)
+	suite.NoError(err)
+
+	err = suite.kubectl.DeleteDaemonSet(name, namespace)
+	suite.NoError(err)
+	d, err := suite.kubectl.GetDaemonSet(name, namespace)
+	suite.Error(err)
+	suite.Nil(d)
+}
+
+func (suite *KubeCtlDaemonSetTestSuite) TestUpdateDaemonSet() {
+	namespace := "default"
+	name := namesgenerator.GetRandomName(0)
+	daemonSet := appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+	}
+	_, err := suite.kubectl.CreateDaemonSet(&daemonSet, namespace)
+	suite.NoError(err)
+
+	daemonSet.Spec.MinReadySeconds = 10
+	ret, err := suite.kubectl.UpdateDaemonSet(&daemonSet, namespace)
+	suite.NoError(err)
+	suite.NotNil(ret)
+
+	d, err := suite.kubectl.GetDaemonSet(name, namespace)
+	suite.NoError(err)
+	suite.Equal(int32(10), d.Spec.MinReadySeconds)
+}
+
+func TestDaemonSetTestSuite(t *testing.T) {
+	suite.Run(t, new(KubeCtlDaemonSetTestSuite))
+}
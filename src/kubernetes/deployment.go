@@ -33,3 +33,8 @@ func (kc *KubeCtl) DeleteDeployment(name string, namespace string) error {
 	propagation := metav1.DeletePropagationForeground
 	return kc.Clientset.AppsV1().Deployments(namespace).Delete(name, &metav1.DeleteOptions{PropagationPolicy: &propagation})
 }
+
+// UpdateDeployment will update deploy
+func (kc *KubeCtl) UpdateDeployment(deployment *appsv1.Deployment, namespace string) (*appsv1.Deployment, error) {
+	return kc.Clientset.AppsV1().Deployments(namespace).Update(deployment)
+}
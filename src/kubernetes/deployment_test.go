@@ -88,6 +88,33 @@ func (suite *KubeCtlDeploymentTestSuite) TestDeleteDeployment() {
 	suite.Nil(deploy)
 }
 
+func (suite *KubeCtlDeploymentTestSuite) TestUpdateDeployment() {
+	namespace := "default"
+	var replicas int32
+	replicas = 3
+	name := namesgenerator.GetRandomName(0)
+	deployment := appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+		},
+		Status: appsv1.DeploymentStatus{},
+	}
+	_, err := suite.kubectl.CreateDeployment(&deployment, namespace)
+	suite.NoError(err)
+
+	replicas = 5
+	ret, err := suite.kubectl.UpdateDeployment(&deployment, namespace)
+	suite.NoError(err)
+	suite.NotNil(ret)
+
+	deploy, err := suite.kubectl.GetDeployment(name, namespace)
+	suite.NoError(err)
+	suite.Equal(int32(5), *deploy.Spec.Replicas)
+}
+
 func TestDeploymentTestSuite(t *testing.T) {
 	suite.Run(t, new(KubeCtlDeploymentTestSuite))
 }
@@ -0,0 +1,24 @@
+package kubernetes
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+// GetEvents will get the events of the involved object from the namespace
+func (kc *KubeCtl) GetEvents(namespace string, kind string, name string) ([]*corev1.Event, error) {
+	events := []*corev1.Event{}
+	selector := fields.Set{
+		"involvedObject.kind": kind,
+		"involvedObject.name": name,
+	}.AsSelector().String()
+	eventsList, err := kc.Clientset.CoreV1().Events(namespace).List(metav1.ListOptions{FieldSelector: selector})
+	if err != nil {
+		return events, err
+	}
+	for i := 0; i < len(eventsList.Items); i++ {
+		events = append(events, &eventsList.Items[i])
+	}
+	return events, nil
+}
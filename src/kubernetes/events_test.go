@@ -0,0 +1,48 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+)
+
+type KubeCtlEventTestSuite struct {
+	suite.Suite
+	kubectl    *KubeCtl
+	fakeclient *fakeclientset.Clientset
+}
+
+func (suite *KubeCtlEventTestSuite) SetupSuite() {
+	suite.fakeclient = fakeclientset.NewSimpleClientset()
+	suite.kubectl = New(suite.fakeclient)
+}
+
+func (suite *KubeCtlEventTestSuite) TestGetEvents() {
+	namespace := "default"
+	event := corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "K8S-Event-1",
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind: "Pod",
+			Name: "K8S-Pod-1",
+		},
+		Reason: "Failed",
+	}
+	_, err := suite.fakeclient.CoreV1().Events(namespace).Create(&event)
+	suite.NoError(err)
+
+	events, err := suite.kubectl.GetEvents(namespace, "Pod", "K8S-Pod-1")
+	suite.NoError(err)
+	suite.Len(events, 1)
+	suite.Equal("K8S-Pod-1", events[0].InvolvedObject.Name)
+	suite.Equal("Failed", events[0].Reason)
+}
+
+func TestKubeEventTestSuite(t *testing.T) {
+	suite.Run(t, new(KubeCtlEventTestSuite))
+}
@@ -0,0 +1,57 @@
+package kubernetes
+
+import (
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreateJob will create the job
+func (kc *KubeCtl) CreateJob(job *batchv1.Job, namespace string) (*batchv1.Job, error) {
+	return kc.Clientset.BatchV1().Jobs(namespace).Create(job)
+}
+
+// GetJob will get the job
+func (kc *KubeCtl) GetJob(name string, namespace string) (*batchv1.Job, error) {
+	return kc.Clientset.BatchV1().Jobs(namespace).Get(name, metav1.GetOptions{})
+}
+
+// GetJobs will get the jobs
+func (kc *KubeCtl) GetJobs(namespace string) ([]*batchv1.Job, error) {
+	jobs := []*batchv1.Job{}
+	jobList, err := kc.Clientset.BatchV1().Jobs(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return jobs, err
+	}
+	for i := range jobList.Items {
+		jobs = append(jobs, &jobList.Items[i])
+	}
+	return jobs, nil
+}
+
+// DeleteJob will delete the job and its pods
+func (kc *KubeCtl) DeleteJob(name string, namespace string) error {
+	propagation := metav1.DeletePropagationForeground
+	return kc.Clientset.BatchV1().Jobs(namespace).Delete(name, &metav1.DeleteOptions{PropagationPolicy: &propagation})
+}
+
+// CreateCronJob will create the cronjob
+func (kc *KubeCtl) CreateCronJob(cronJob *batchv1beta1.CronJob, namespace string) (*batchv1beta1.CronJob, error) {
+	return kc.Clientset.BatchV1beta1().CronJobs(namespace).Create(cronJob)
+}
+
+// GetCronJob will get the cronjob
+func (kc *KubeCtl) GetCronJob(name string, namespace string) (*batchv1beta1.CronJob, error) {
+	return kc.Clientset.BatchV1beta1().CronJobs(namespace).Get(name, metav1.GetOptions{})
+}
+
+// UpdateCronJob will update the cronjob
+func (kc *KubeCtl) UpdateCronJob(cronJob *batchv1beta1.CronJob, namespace string) (*batchv1beta1.CronJob, error) {
+	return kc.Clientset.BatchV1beta1().CronJobs(namespace).Update(cronJob)
+}
+
+// DeleteCronJob will delete the cronjob and its jobs
+func (kc *KubeCtl) DeleteCronJob(name string, namespace string) error {
+	propagation := metav1.DeletePropagationForeground
+	return kc.Clientset.BatchV1beta1().CronJobs(namespace).Delete(name, &metav1.DeleteOptions{PropagationPolicy: &propagation})
+}
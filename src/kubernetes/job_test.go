@@ -0,0 +1,88 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/suite"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+)
+
+type KubeCtlJobTestSuite struct {
+	suite.Suite
+	kubectl    *KubeCtl
+	fakeclient *fakeclientset.Clientset
+}
+
+func (suite *KubeCtlJobTestSuite) SetupSuite() {
+	suite.fakeclient = fakeclientset.NewSimpleClientset()
+	suite.kubectl = New(suite.fakeclient)
+}
+
+func (suite *KubeCtlJobTestSuite) TearDownSuite() {}
+func (suite *KubeCtlJobTestSuite) TestCreateJob() {
+	namespace := "default"
+	var completions int32
+	completions = 3
+	name := namesgenerator.GetRandomName(0)
+	job := batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: batchv1.JobSpec{
+			Completions: &completions,
+		},
+	}
+	ret, err := suite.kubectl.CreateJob(&job, namespace)
+	suite.NoError(err)
+	suite.NotNil(ret)
+
+	j, err := suite.kubectl.GetJob(name, namespace)
+	suite.NoError(err)
+	suite.Equal(completions, *j.Spec.Completions)
+
+	jobs, err := suite.kubectl.GetJobs(namespace)
+	suite.NoError(err)
+	suite.NotEmpty(jobs)
+
+	err = suite.kubectl.DeleteJob(name, namespace)
+	suite.NoError(err)
+	_, err = suite.kubectl.GetJob(name, namespace)
+	suite.Error(err)
+}
+
+func (suite *KubeCtlJobTestSuite) TestCronJob() {
+	namespace := "default"
+	name := namesgenerator.GetRandomName(0)
+	cronJob := batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule: "*/5 * * * *",
+		},
+	}
+	ret, err := suite.kubectl.CreateCronJob(&cronJob, namespace)
+	suite.NoError(err)
+	suite.NotNil(ret)
+
+	cronJob.Spec.Schedule = "0 * * * *"
+	_, err = suite.kubectl.UpdateCronJob(&cronJob, namespace)
+	suite.NoError(err)
+	c, err := suite.kubectl.GetCronJob(name, namespace)
+	suite.NoError(err)
+	suite.Equal("0 * * * *", c.Spec.Schedule)
+
+	err = suite.kubectl.DeleteCronJob(name, namespace)
+	suite.NoError(err)
+	_, err = suite.kubectl.GetCronJob(name, namespace)
+	suite.Error(err)
+}
+
+func TestJobTestSuite(t *testing.T) {
+	suite.Run(t, new(KubeCtlJobTestSuite))
+}
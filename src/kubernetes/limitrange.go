@@ -0,0 +1,26 @@
+package kubernetes
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetLimitRange will get the limit range object by the name
+func (kc *KubeCtl) GetLimitRange(name string, namespace string) (*corev1.LimitRange, error) {
+	return kc.Clientset.CoreV1().LimitRanges(namespace).Get(name, metav1.GetOptions{})
+}
+
+// CreateLimitRange will create the limit range by the limit range object
+func (kc *KubeCtl) CreateLimitRange(limitRange *corev1.LimitRange, namespace string) (*corev1.LimitRange, error) {
+	return kc.Clientset.CoreV1().LimitRanges(namespace).Create(limitRange)
+}
+
+// UpdateLimitRange will update the limit range by the limit range object
+func (kc *KubeCtl) UpdateLimitRange(limitRange *corev1.LimitRange, namespace string) (*corev1.LimitRange, error) {
+	return kc.Clientset.CoreV1().LimitRanges(namespace).Update(limitRange)
+}
+
+// DeleteLimitRange will delete the limit range by the name
+func (kc *KubeCtl) DeleteLimitRange(name string, namespace string) error {
+	return kc.Clientset.CoreV1().LimitRanges(namespace).Delete(name, &metav1.DeleteOptions{})
+}
@@ -0,0 +1,69 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+)
+
+type KubeCtlLimitRangeTestSuite struct {
+	suite.Suite
+	kubectl    *KubeCtl
+	fakeclient *fakeclientset.Clientset
+}
+
+func (suite *KubeCtlLimitRangeTestSuite) SetupSuite() {
+	suite.fakeclient = fakeclientset.NewSimpleClientset()
+	suite.kubectl = New(suite.fakeclient)
+}
+
+func (suite *KubeCtlLimitRangeTestSuite) TestLimitRange() {
+	namespace := "default"
+	limitRange := corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "K8S-LimitRange-1",
+		},
+		Spec: corev1.LimitRangeSpec{
+			Limits: []corev1.LimitRangeItem{
+				{
+					Type: corev1.LimitTypeContainer,
+					DefaultRequest: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("100m"),
+					},
+				},
+			},
+		},
+	}
+	_, err := suite.kubectl.CreateLimitRange(&limitRange, namespace)
+	suite.NoError(err)
+
+	result, err := suite.kubectl.GetLimitRange("K8S-LimitRange-1", namespace)
+	suite.NoError(err)
+	suite.Len(result.Spec.Limits, 1)
+
+	result.Spec.Limits[0].Default = corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("500m"),
+	}
+	_, err = suite.kubectl.UpdateLimitRange(result, namespace)
+	suite.NoError(err)
+	result, err = suite.kubectl.GetLimitRange("K8S-LimitRange-1", namespace)
+	suite.NoError(err)
+	cpu := result.Spec.Limits[0].Default[corev1.ResourceCPU]
+	suite.Equal("500m", cpu.String())
+
+	err = suite.kubectl.DeleteLimitRange("K8S-LimitRange-1", namespace)
+	suite.NoError(err)
+	_, err = suite.kubectl.GetLimitRange("K8S-LimitRange-1", namespace)
+	suite.Error(err)
+}
+
+func (suite *KubeCtlLimitRangeTestSuite) TearDownSuite() {}
+
+func TestKubeLimitRangeTestSuite(t *testing.T) {
+	suite.Run(t, new(KubeCtlLimitRangeTestSuite))
+}
@@ -24,6 +24,20 @@ func (kc *KubeCtl) GetPods(namespace string) ([]*corev1.Pod, error) {
 	return pods, nil
 }
 
+// GetPodsBySelector will get the pods matching the label selector from the namespace
+func (kc *KubeCtl) GetPodsBySelector(namespace string, selector string) ([]*corev1.Pod, error) {
+	pods := []*corev1.Pod{}
+	podsList, err := kc.Clientset.CoreV1().Pods(namespace).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return pods, err
+	}
+
+	for i := 0; i < len(podsList.Items); i++ {
+		pods = append(pods, &podsList.Items[i])
+	}
+	return pods, nil
+}
+
 // CreatePod will create the pod by the pod object
 func (kc *KubeCtl) CreatePod(pod *corev1.Pod, namespace string) (*corev1.Pod, error) {
 	return kc.Clientset.CoreV1().Pods(namespace).Create(pod)
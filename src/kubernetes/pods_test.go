@@ -72,6 +72,25 @@ func (suite *KubeCtlPodTestSuite) TestGetPods() {
 	suite.NotEqual(0, len(pods))
 }
 
+func (suite *KubeCtlPodTestSuite) TestGetPodsBySelector() {
+	namespace := "selector"
+	for _, name := range []string{"K8S-Pod-4", "K8S-Pod-5"} {
+		pod := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{"app": name},
+			},
+		}
+		_, err := suite.fakeclient.CoreV1().Pods(namespace).Create(&pod)
+		suite.NoError(err)
+	}
+
+	pods, err := suite.kubectl.GetPodsBySelector(namespace, "app=K8S-Pod-4")
+	suite.NoError(err)
+	suite.Len(pods, 1)
+	suite.Equal("K8S-Pod-4", pods[0].Name)
+}
+
 func (suite *KubeCtlPodTestSuite) TestCreateDeletePod() {
 	namespace := "default"
 	pod := corev1.Pod{
@@ -0,0 +1,25 @@
+package kubernetes
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetReplicaSet will get the replica set by the name
+func (kc *KubeCtl) GetReplicaSet(name string, namespace string) (*appsv1.ReplicaSet, error) {
+	return kc.Clientset.AppsV1().ReplicaSets(namespace).Get(name, metav1.GetOptions{})
+}
+
+// GetReplicaSets will get the replica sets matching the label selector from the namespace,
+// the empty selector matches all replica sets
+func (kc *KubeCtl) GetReplicaSets(namespace string, selector string) ([]*appsv1.ReplicaSet, error) {
+	replicaSets := []*appsv1.ReplicaSet{}
+	replicaSetsList, err := kc.Clientset.AppsV1().ReplicaSets(namespace).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return replicaSets, err
+	}
+	for i := 0; i < len(replicaSetsList.Items); i++ {
+		replicaSets = append(replicaSets, &replicaSetsList.Items[i])
+	}
+	return replicaSets, nil
+}
@@ -0,0 +1,70 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+)
+
+type KubeCtlReplicaSetTestSuite struct {
+	suite.Suite
+	kubectl    *KubeCtl
+	fakeclient *fakeclientset.Clientset
+}
+
+func (suite *KubeCtlReplicaSetTestSuite) SetupSuite() {
+	suite.fakeclient = fakeclientset.NewSimpleClientset()
+	suite.kubectl = New(suite.fakeclient)
+}
+
+func (suite *KubeCtlReplicaSetTestSuite) TestGetReplicaSet() {
+	namespace := "default"
+	replicaSet := appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "K8S-ReplicaSet-1",
+		},
+	}
+	_, err := suite.fakeclient.AppsV1().ReplicaSets(namespace).Create(&replicaSet)
+	suite.NoError(err)
+
+	result, err := suite.kubectl.GetReplicaSet("K8S-ReplicaSet-1", namespace)
+	suite.NoError(err)
+	suite.Equal(replicaSet.GetName(), result.GetName())
+}
+
+func (suite *KubeCtlReplicaSetTestSuite) TestGetReplicaSetFail() {
+	namespace := "default"
+	_, err := suite.kubectl.GetReplicaSet("Unknown_Name", namespace)
+	suite.Error(err)
+}
+
+func (suite *KubeCtlReplicaSetTestSuite) TestGetReplicaSets() {
+	namespace := "replicasets"
+	for _, name := range []string{"K8S-ReplicaSet-2", "K8S-ReplicaSet-3"} {
+		replicaSet := appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{"app": name},
+			},
+		}
+		_, err := suite.fakeclient.AppsV1().ReplicaSets(namespace).Create(&replicaSet)
+		suite.NoError(err)
+	}
+
+	replicaSets, err := suite.kubectl.GetReplicaSets(namespace, "")
+	suite.NoError(err)
+	suite.Len(replicaSets, 2)
+
+	replicaSets, err = suite.kubectl.GetReplicaSets(namespace, "app=K8S-ReplicaSet-2")
+	suite.NoError(err)
+	suite.Len(replicaSets, 1)
+	suite.Equal("K8S-ReplicaSet-2", replicaSets[0].Name)
+}
+
+func TestKubeReplicaSetTestSuite(t *testing.T) {
+	suite.Run(t, new(KubeCtlReplicaSetTestSuite))
+}
@@ -0,0 +1,26 @@
+package kubernetes
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetResourceQuota will get the resource quota object by the name
+func (kc *KubeCtl) GetResourceQuota(name string, namespace string) (*corev1.ResourceQuota, error) {
+	return kc.Clientset.CoreV1().ResourceQuotas(namespace).Get(name, metav1.GetOptions{})
+}
+
+// CreateResourceQuota will create the resource quota by the resource quota object
+func (kc *KubeCtl) CreateResourceQuota(quota *corev1.ResourceQuota, namespace string) (*corev1.ResourceQuota, error) {
+	return kc.Clientset.CoreV1().ResourceQuotas(namespace).Create(quota)
+}
+
+// UpdateResourceQuota will update the resource quota by the resource quota object
+func (kc *KubeCtl) UpdateResourceQuota(quota *corev1.ResourceQuota, namespace string) (*corev1.ResourceQuota, error) {
+	return kc.Clientset.CoreV1().ResourceQuotas(namespace).Update(quota)
+}
+
+// DeleteResourceQuota will delete the resource quota by the name
+func (kc *KubeCtl) DeleteResourceQuota(name string, namespace string) error {
+	return kc.Clientset.CoreV1().ResourceQuotas(namespace).Delete(name, &metav1.DeleteOptions{})
+}
@@ -0,0 +1,61 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+)
+
+type KubeCtlResourceQuotaTestSuite struct {
+	suite.Suite
+	kubectl    *KubeCtl
+	fakeclient *fakeclientset.Clientset
+}
+
+func (suite *KubeCtlResourceQuotaTestSuite) SetupSuite() {
+	suite.fakeclient = fakeclientset.NewSimpleClientset()
+	suite.kubectl = New(suite.fakeclient)
+}
+
+func (suite *KubeCtlResourceQuotaTestSuite) TestResourceQuota() {
+	namespace := "default"
+	quota := corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "K8S-ResourceQuota-1",
+		},
+		Spec: corev1.ResourceQuotaSpec{
+			Hard: corev1.ResourceList{
+				corev1.ResourcePods: resource.MustParse("10"),
+			},
+		},
+	}
+	_, err := suite.kubectl.CreateResourceQuota(&quota, namespace)
+	suite.NoError(err)
+
+	result, err := suite.kubectl.GetResourceQuota("K8S-ResourceQuota-1", namespace)
+	suite.NoError(err)
+	suite.Equal(int64(10), result.Spec.Hard.Pods().Value())
+
+	result.Spec.Hard[corev1.ResourcePods] = resource.MustParse("20")
+	_, err = suite.kubectl.UpdateResourceQuota(result, namespace)
+	suite.NoError(err)
+	result, err = suite.kubectl.GetResourceQuota("K8S-ResourceQuota-1", namespace)
+	suite.NoError(err)
+	suite.Equal(int64(20), result.Spec.Hard.Pods().Value())
+
+	err = suite.kubectl.DeleteResourceQuota("K8S-ResourceQuota-1", namespace)
+	suite.NoError(err)
+	_, err = suite.kubectl.GetResourceQuota("K8S-ResourceQuota-1", namespace)
+	suite.Error(err)
+}
+
+func (suite *KubeCtlResourceQuotaTestSuite) TearDownSuite() {}
+
+func TestKubeResourceQuotaTestSuite(t *testing.T) {
+	suite.Run(t, new(KubeCtlResourceQuotaTestSuite))
+}
@@ -0,0 +1,39 @@
+package kubernetes
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetSecret will get the secret object by the secret name
+func (kc *KubeCtl) GetSecret(name string, namespace string) (*corev1.Secret, error) {
+	return kc.Clientset.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+}
+
+// GetSecrets will get all secrets from the k8s cluster
+func (kc *KubeCtl) GetSecrets(namespace string) ([]*corev1.Secret, error) {
+	secrets := []*corev1.Secret{}
+	secretsList, err := kc.Clientset.CoreV1().Secrets(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return secrets, err
+	}
+	for i := 0; i < len(secretsList.Items); i++ {
+		secrets = append(secrets, &secretsList.Items[i])
+	}
+	return secrets, nil
+}
+
+// CreateSecret will create the secret by the secret object
+func (kc *KubeCtl) CreateSecret(secret *corev1.Secret, namespace string) (*corev1.Secret, error) {
+	return kc.Clientset.CoreV1().Secrets(namespace).Create(secret)
+}
+
+// UpdateSecret will update the secret by the secret object
+func (kc *KubeCtl) UpdateSecret(secret *corev1.Secret, namespace string) (*corev1.Secret, error) {
+	return kc.Clientset.CoreV1().Secrets(namespace).Update(secret)
+}
+
+// DeleteSecret will delete the secret by the secret name
+func (kc *KubeCtl) DeleteSecret(name string, namespace string) error {
+	return kc.Clientset.CoreV1().Secrets(namespace).Delete(name, &metav1.DeleteOptions{})
+}
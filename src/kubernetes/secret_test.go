@@ -0,0 +1,97 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+)
+
+type KubeCtlSecretTestSuite struct {
+	suite.Suite
+	kubectl    *KubeCtl
+	fakeclient *fakeclientset.Clientset
+}
+
+func (suite *KubeCtlSecretTestSuite) SetupSuite() {
+	suite.fakeclient = fakeclientset.NewSimpleClientset()
+	suite.kubectl = New(suite.fakeclient)
+}
+
+func (suite *KubeCtlSecretTestSuite) TestGetSecret() {
+	namespace := "default"
+	secret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "K8S-Secret-1",
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			"password": []byte("awesome"),
+		},
+	}
+	_, err := suite.fakeclient.CoreV1().Secrets(namespace).Create(&secret)
+	suite.NoError(err)
+
+	result, err := suite.kubectl.GetSecret("K8S-Secret-1", namespace)
+	suite.NoError(err)
+	suite.Equal(secret.GetName(), result.GetName())
+	suite.Equal("awesome", string(result.Data["password"]))
+}
+
+func (suite *KubeCtlSecretTestSuite) TestGetSecretFail() {
+	namespace := "default"
+	_, err := suite.kubectl.GetSecret("Unknown_Name", namespace)
+	suite.Error(err)
+}
+
+func (suite *KubeCtlSecretTestSuite) TestGetSecrets() {
+	namespace := "secrets"
+	for _, name := range []string{"K8S-Secret-2", "K8S-Secret-3"} {
+		secret := corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+		}
+		_, err := suite.fakeclient.CoreV1().Secrets(namespace).Create(&secret)
+		suite.NoError(err)
+	}
+
+	secrets, err := suite.kubectl.GetSecrets(namespace)
+	suite.NoError(err)
+	suite.Len(secrets, 2)
+}
+
+func (suite *KubeCtlSecretTestSuite) TestCreateUpdateDeleteSecret() {
+	namespace := "default"
+	secret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "K8S-Secret-4",
+			Namespace: namespace,
+		},
+		Data: map[string][]byte{
+			"password": []byte("awesome"),
+		},
+	}
+	_, err := suite.kubectl.CreateSecret(&secret, namespace)
+	suite.NoError(err)
+
+	secret.Data["password"] = []byte("cool")
+	_, err = suite.kubectl.UpdateSecret(&secret, namespace)
+	suite.NoError(err)
+	result, err := suite.kubectl.GetSecret("K8S-Secret-4", namespace)
+	suite.NoError(err)
+	suite.Equal("cool", string(result.Data["password"]))
+
+	err = suite.kubectl.DeleteSecret("K8S-Secret-4", namespace)
+	suite.NoError(err)
+	_, err = suite.kubectl.GetSecret("K8S-Secret-4", namespace)
+	suite.Error(err)
+}
+
+func TestKubeSecretTestSuite(t *testing.T) {
+	suite.Run(t, new(KubeCtlSecretTestSuite))
+}
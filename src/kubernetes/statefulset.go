@@ -0,0 +1,40 @@
+package kubernetes
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreateStatefulSet will create the statefulset
+func (kc *KubeCtl) CreateStatefulSet(statefulSet *appsv1.StatefulSet, namespace string) (*appsv1.StatefulSet, error) {
+	return kc.Clientset.AppsV1().StatefulSets(namespace).Create(statefulSet)
+}
+
+// GetStatefulSet will get the statefulset
+func (kc *KubeCtl) GetStatefulSet(name string, namespace string) (*appsv1.StatefulSet, error) {
+	return kc.Clientset.AppsV1().StatefulSets(namespace).Get(name, metav1.GetOptions{})
+}
+
+// GetStatefulSets will get the statefulsets
+func (kc *KubeCtl) GetStatefulSets(namespace string) ([]*appsv1.StatefulSet, error) {
+	statefulSets := []*appsv1.StatefulSet{}
+	statefulSetList, err := kc.Clientset.AppsV1().StatefulSets(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return statefulSets, err
+	}
+	for i := range statefulSetList.Items {
+		statefulSets = append(statefulSets, &statefulSetList.Items[i])
+	}
+	return statefulSets, nil
+}
+
+// DeleteStatefulSet will delete the statefulset and its pods, the PVCs of the replicas are kept
+func (kc *KubeCtl) DeleteStatefulSet(name string, namespace string) error {
+	propagation := metav1.DeletePropagationForeground
+	return kc.Clientset.AppsV1().StatefulSets(namespace).Delete(name, &metav1.DeleteOptions{PropagationPolicy: &propagation})
+}
+
+// UpdateStatefulSet will update the statefulset
+func (kc *KubeCtl) UpdateStatefulSet(statefulSet *appsv1.StatefulSet, namespace string) (*appsv1.StatefulSet, error) {
+	return kc.Clientset.AppsV1().StatefulSets(namespace).Update(statefulSet)
+}
@@ -0,0 +1,100 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/suite"
+	appsv1 "k8s.io/api/apps/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+)
+
+type KubeCtlStatefulSetTestSuite struct {
+	suite.Suite
+	kubectl    *KubeCtl
+	fakeclient *fakeclientset.Clientset
+}
+
+func (suite *KubeCtlStatefulSetTestSuite) SetupSuite() {
+	suite.fakeclient = fakeclientset.NewSimpleClientset()
+	suite.kubectl = New(suite.fakeclient)
+}
+
+func (suite *KubeCtlStatefulSetTestSuite) TearDownSuite() {}
+func (suite *KubeCtlStatefulSetTestSuite) TestCreateStatefulSet() {
+	namespace := "default"
+	var replicas int32
+	replicas = 3
+	name := namesgenerator.GetRandomName(0)
+	statefulSet := appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    &replicas,
+			ServiceName: name,
+		},
+	}
+	ret, err := suite.kubectl.CreateStatefulSet(&statefulSet, namespace)
+	suite.NoError(err)
+	suite.NotNil(ret)
+
+	s, err := suite.kubectl.GetStatefulSet(name, namespace)
+	suite.NoError(err)
+	suite.Equal(replicas, *s.Spec.Replicas)
+	suite.Equal(name, s.Spec.ServiceName)
+
+	statefulSets, err := suite.kubectl.GetStatefulSets(namespace)
+	suite.NoError(err)
+	suite.NotEmpty(statefulSets)
+}
+
+func (suite *KubeCtlStatefulSetTestSuite) TestDeleteStatefulSet() {
+	namespace := "default"
+	name := namesgenerator.GetRandomName(0)
+	statefulSet := appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+	}
+	_, err := suite.kubectl.CreateStatefulSet(&statefulSet, namespace)
+	suite.NoError(err)
+
+	err = suite.kubectl.DeleteStatefulSet(name, namespace)
+	suite.NoError(err)
+	s, err := suite.kubectl.GetStatefulSet(name, namespace)
+	suite.Error(err)
+	suite.Nil(s)
+}
+
+func (suite *KubeCtlStatefulSetTestSuite) TestUpdateStatefulSet() {
+	namespace := "default"
+	var replicas int32
+	replicas = 3
+	name := namesgenerator.GetRandomName(0)
+	statefulSet := appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+		},
+	}
+	_, err := suite.kubectl.CreateStatefulSet(&statefulSet, namespace)
+	suite.NoError(err)
+
+	replicas = 5
+	ret, err := suite.kubectl.UpdateStatefulSet(&statefulSet, namespace)
+	suite.NoError(err)
+	suite.NotNil(ret)
+
+	s, err := suite.kubectl.GetStatefulSet(name, namespace)
+	suite.NoError(err)
+	suite.Equal(int32(5), *s.Spec.Replicas)
+}
+
+func TestStatefulSetTestSuite(t *testing.T) {
+	suite.Run(t, new(KubeCtlStatefulSetTestSuite))
+}
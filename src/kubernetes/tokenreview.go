@@ -0,0 +1,34 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+)
+
+// serviceAccountPrefix is the prefix of the user names Kubernetes gives to the service accounts
+const serviceAccountPrefix = "system:serviceaccount:"
+
+// AuthenticateServiceAccount reviews the token by the TokenReview of Kubernetes and returns the namespace
+// and the name of the service account the token belongs to
+func (kc *KubeCtl) AuthenticateServiceAccount(token string) (string, string, error) {
+	review, err := kc.Clientset.AuthenticationV1().TokenReviews().Create(&authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token: token,
+		},
+	})
+	if err != nil {
+		return "", "", err
+	}
+	if !review.Status.Authenticated {
+		return "", "", fmt.Errorf("The token isn't authenticated: %s", review.Status.Error)
+	}
+
+	// the user name of the service account is system:serviceaccount:<namespace>:<name>
+	parts := strings.Split(strings.TrimPrefix(review.Status.User.Username, serviceAccountPrefix), ":")
+	if !strings.HasPrefix(review.Status.User.Username, serviceAccountPrefix) || len(parts) != 2 {
+		return "", "", fmt.Errorf("The token of %s doesn't belong to a service account", review.Status.User.Username)
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,60 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+)
+
+type KubeCtlTokenReviewTestSuite struct {
+	suite.Suite
+	kubectl    *KubeCtl
+	fakeclient *fakeclientset.Clientset
+}
+
+func (suite *KubeCtlTokenReviewTestSuite) SetupSuite() {
+	suite.fakeclient = fakeclientset.NewSimpleClientset()
+	suite.kubectl = New(suite.fakeclient)
+
+	// the fake clientset doesn't review the tokens, the token is the user name here
+	suite.fakeclient.PrependReactor("create", "tokenreviews", func(action core.Action) (bool, runtime.Object, error) {
+		review := action.(core.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		review.Status.Authenticated = review.Spec.Token != ""
+		review.Status.User.Username = review.Spec.Token
+		return true, review, nil
+	})
+}
+
+func (suite *KubeCtlTokenReviewTestSuite) TestAuthenticateServiceAccount() {
+	namespace, name, err := suite.kubectl.AuthenticateServiceAccount("system:serviceaccount:default:awesome")
+	suite.NoError(err)
+	suite.Equal("default", namespace)
+	suite.Equal("awesome", name)
+}
+
+func (suite *KubeCtlTokenReviewTestSuite) TestAuthenticateServiceAccountFail() {
+	testCases := []struct {
+		caseName string
+		token    string
+	}{
+		{"Unauthenticated", ""},
+		{"User", "awesome"},
+		{"InvalidServiceAccount", "system:serviceaccount:default"},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.caseName, func(t *testing.T) {
+			_, _, err := suite.kubectl.AuthenticateServiceAccount(tc.token)
+			suite.Error(err)
+		})
+	}
+}
+
+func TestKubeCtlTokenReviewSuite(t *testing.T) {
+	suite.Run(t, new(KubeCtlTokenReviewTestSuite))
+}
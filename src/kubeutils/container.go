@@ -0,0 +1,160 @@
+package kubeutils
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/linkernetworks/vortex/src/entity"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// GenerateContainer generates the Kubernetes container of the pod or the deployment,
+// the envVars are shared by all the containers and the variables of the container override them
+func GenerateContainer(container entity.Container, envVars map[string]string, volumeMounts []corev1.VolumeMount, securityContext *corev1.SecurityContext) corev1.Container {
+	return corev1.Container{
+		Name:            container.Name,
+		Image:           container.Image,
+		Command:         container.Command,
+		Args:            container.Args,
+		WorkingDir:      container.WorkingDir,
+		Ports:           GeneratePorts(container.Ports),
+		VolumeMounts:    volumeMounts,
+		SecurityContext: securityContext,
+		Env:             append(GenerateEnvVars(envVars, container.EnvVars), GenerateEnvVarsFrom(container.EnvVarsFrom)...),
+		Resources:       GenerateResources(container),
+		LivenessProbe:   GenerateProbe(container.LivenessProbe),
+		ReadinessProbe:  GenerateProbe(container.ReadinessProbe),
+		ImagePullPolicy: corev1.PullPolicy(container.ImagePullPolicy),
+	}
+}
+
+// GenerateEnvVars merges the environment variables sorted by the name,
+// the sorted order keeps the pod template the same if the variables are not changed
+func GenerateEnvVars(shared map[string]string, own map[string]string) []corev1.EnvVar {
+	merged := map[string]string{}
+	for k, v := range shared {
+		merged[k] = v
+	}
+	for k, v := range own {
+		merged[k] = v
+	}
+
+	names := []string{}
+	for k := range merged {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	envVars := []corev1.EnvVar{}
+	for _, name := range names {
+		envVars = append(envVars, corev1.EnvVar{
+			Name:  name,
+			Value: merged[name],
+		})
+	}
+	return envVars
+}
+
+// GenerateEnvVarsFrom generates the environment variables from the keys of the secrets or the config maps
+func GenerateEnvVarsFrom(sources []entity.EnvVarSource) []corev1.EnvVar {
+	envVars := []corev1.EnvVar{}
+	for _, source := range sources {
+		valueFrom := &corev1.EnvVarSource{}
+		switch {
+		case source.SecretKeyRef != nil:
+			valueFrom.SecretKeyRef = &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: source.SecretKeyRef.Name},
+				Key:                  source.SecretKeyRef.Key,
+			}
+		case source.ConfigMapKeyRef != nil:
+			valueFrom.ConfigMapKeyRef = &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: source.ConfigMapKeyRef.Name},
+				Key:                  source.ConfigMapKeyRef.Key,
+			}
+		}
+		envVars = append(envVars, corev1.EnvVar{
+			Name:      source.Name,
+			ValueFrom: valueFrom,
+		})
+	}
+	return envVars
+}
+
+// GeneratePorts generates the ports exposed by the container
+func GeneratePorts(ports []entity.ContainerPort) []corev1.ContainerPort {
+	if len(ports) == 0 {
+		return nil
+	}
+	ret := []corev1.ContainerPort{}
+	for _, port := range ports {
+		ret = append(ret, corev1.ContainerPort{
+			Name:          port.Name,
+			ContainerPort: port.ContainerPort,
+			Protocol:      corev1.Protocol(port.Protocol),
+		})
+	}
+	return ret
+}
+
+// GenerateResources generates the requests and the limits of the container,
+// the CPU is in millicores, the memory and the ephemeral storage are in MiB and 0 is unset
+func GenerateResources(container entity.Container) corev1.ResourceRequirements {
+	resources := corev1.ResourceRequirements{}
+	requests := generateResourceList(container.ResourceRequestCPU, container.ResourceRequestMemory, container.ResourceRequestEphemeralStorage)
+	if len(requests) != 0 {
+		resources.Requests = requests
+	}
+	limits := generateResourceList(container.ResourceLimitCPU, container.ResourceLimitMemory, container.ResourceLimitEphemeralStorage)
+	if len(limits) != 0 {
+		resources.Limits = limits
+	}
+	return resources
+}
+
+func generateResourceList(cpu int, memory int, ephemeralStorage int) corev1.ResourceList {
+	list := corev1.ResourceList{}
+	if cpu != 0 {
+		list[corev1.ResourceCPU] = resource.MustParse(strconv.Itoa(cpu) + "m")
+	}
+	if memory != 0 {
+		list[corev1.ResourceMemory] = resource.MustParse(strconv.Itoa(memory) + "Mi")
+	}
+	if ephemeralStorage != 0 {
+		list[corev1.ResourceEphemeralStorage] = resource.MustParse(strconv.Itoa(ephemeralStorage) + "Mi")
+	}
+	return list
+}
+
+// GenerateProbe generates the liveness or the readiness probe of the container
+func GenerateProbe(probe *entity.Probe) *corev1.Probe {
+	if probe == nil {
+		return nil
+	}
+	p := &corev1.Probe{
+		InitialDelaySeconds: probe.InitialDelaySeconds,
+		TimeoutSeconds:      probe.TimeoutSeconds,
+		PeriodSeconds:       probe.PeriodSeconds,
+		SuccessThreshold:    probe.SuccessThreshold,
+		FailureThreshold:    probe.FailureThreshold,
+	}
+	switch {
+	case probe.HTTPGet != nil:
+		p.HTTPGet = &corev1.HTTPGetAction{
+			Path:   probe.HTTPGet.Path,
+			Port:   intstr.FromInt(int(probe.HTTPGet.Port)),
+			Scheme: corev1.URIScheme(probe.HTTPGet.Scheme),
+		}
+	case probe.TCPSocket != nil:
+		p.TCPSocket = &corev1.TCPSocketAction{
+			Port: intstr.FromInt(int(probe.TCPSocket.Port)),
+		}
+	case probe.Exec != nil:
+		p.Exec = &corev1.ExecAction{
+			Command: probe.Exec.Command,
+		}
+	}
+	return p
+}
@@ -0,0 +1,136 @@
+package kubeutils
+
+import (
+	"testing"
+
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/stretchr/testify/assert"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGenerateContainer(t *testing.T) {
+	container := entity.Container{
+		Name:            "awesome",
+		Image:           "nginx:1.15",
+		Command:         []string{"nginx"},
+		Args:            []string{"-g", "daemon off;"},
+		WorkingDir:      "/usr/share/nginx",
+		Ports:           []entity.ContainerPort{{Name: "http", ContainerPort: 80}},
+		EnvVars:         map[string]string{"MODE": "debug"},
+		ImagePullPolicy: "IfNotPresent",
+		EnvVarsFrom:     []entity.EnvVarSource{{Name: "TOKEN", SecretKeyRef: &entity.KeySelector{Name: "api", Key: "token"}}},
+		ReadinessProbe:  &entity.Probe{TCPSocket: &entity.TCPSocketProbe{Port: 80}},
+	}
+	volumeMounts := []corev1.VolumeMount{{Name: "volume-0", MountPath: "/data"}}
+	securityContext := &corev1.SecurityContext{}
+
+	c := GenerateContainer(container, map[string]string{"MODE": "release", "LANG": "C"}, volumeMounts, securityContext)
+	assert.Equal(t, "awesome", c.Name)
+	assert.Equal(t, "nginx:1.15", c.Image)
+	assert.Equal(t, []string{"nginx"}, c.Command)
+	assert.Equal(t, []string{"-g", "daemon off;"}, c.Args)
+	assert.Equal(t, "/usr/share/nginx", c.WorkingDir)
+	assert.Equal(t, []corev1.ContainerPort{{Name: "http", ContainerPort: 80}}, c.Ports)
+	assert.Equal(t, corev1.PullIfNotPresent, c.ImagePullPolicy)
+	assert.Equal(t, volumeMounts, c.VolumeMounts)
+	assert.Equal(t, securityContext, c.SecurityContext)
+	assert.NotNil(t, c.ReadinessProbe)
+	assert.Nil(t, c.LivenessProbe)
+	// the variable of the container overrides the shared one and the variables from the secrets come last
+	assert.Len(t, c.Env, 3)
+	assert.Equal(t, corev1.EnvVar{Name: "LANG", Value: "C"}, c.Env[0])
+	assert.Equal(t, corev1.EnvVar{Name: "MODE", Value: "debug"}, c.Env[1])
+	assert.Equal(t, "TOKEN", c.Env[2].Name)
+	assert.Equal(t, "api", c.Env[2].ValueFrom.SecretKeyRef.Name)
+}
+
+func TestGenerateContainerWithOldFields(t *testing.T) {
+	container := entity.Container{
+		Name:               "awesome",
+		Image:              "busybox",
+		Command:            []string{"sleep", "3600"},
+		ResourceRequestCPU: 100,
+	}
+	c := GenerateContainer(container, nil, nil, nil)
+	assert.Nil(t, c.Args)
+	assert.Nil(t, c.Ports)
+	assert.Equal(t, corev1.PullPolicy(""), c.ImagePullPolicy)
+	assert.Equal(t, []corev1.EnvVar{}, c.Env)
+	assert.Equal(t, "100m", c.Resources.Requests.Cpu().String())
+	assert.Nil(t, c.Resources.Limits)
+}
+
+func TestGenerateEnvVars(t *testing.T) {
+	envVars := GenerateEnvVars(map[string]string{"B": "1", "A": "2"}, map[string]string{"C": "3"})
+	assert.Equal(t, []corev1.EnvVar{{Name: "A", Value: "2"}, {Name: "B", Value: "1"}, {Name: "C", Value: "3"}}, envVars)
+}
+
+func TestGeneratePorts(t *testing.T) {
+	assert.Nil(t, GeneratePorts(nil))
+
+	ports := GeneratePorts([]entity.ContainerPort{
+		{ContainerPort: 8080},
+		{Name: "dns", ContainerPort: 53, Protocol: "UDP"},
+	})
+	assert.Len(t, ports, 2)
+	assert.Equal(t, int32(8080), ports[0].ContainerPort)
+	assert.Equal(t, "dns", ports[1].Name)
+	assert.Equal(t, corev1.ProtocolUDP, ports[1].Protocol)
+}
+
+func TestGenerateResources(t *testing.T) {
+	resources := GenerateResources(entity.Container{})
+	assert.Nil(t, resources.Requests)
+	assert.Nil(t, resources.Limits)
+
+	resources = GenerateResources(entity.Container{ResourceRequestMemory: 64})
+	assert.Len(t, resources.Requests, 1)
+	assert.Equal(t, "64Mi", resources.Requests.Memory().String())
+	assert.Nil(t, resources.Limits)
+
+	resources = GenerateResources(entity.Container{
+		ResourceRequestCPU:              250,
+		ResourceRequestMemory:           128,
+		ResourceRequestEphemeralStorage: 512,
+		ResourceLimitCPU:                500,
+		ResourceLimitMemory:             256,
+		ResourceLimitEphemeralStorage:   1024,
+	})
+	assert.Equal(t, "250m", resources.Requests.Cpu().String())
+	assert.Equal(t, "128Mi", resources.Requests.Memory().String())
+	requestStorage := resources.Requests[corev1.ResourceEphemeralStorage]
+	assert.Equal(t, "512Mi", requestStorage.String())
+	assert.Equal(t, "500m", resources.Limits.Cpu().String())
+	assert.Equal(t, "256Mi", resources.Limits.Memory().String())
+	limitStorage := resources.Limits[corev1.ResourceEphemeralStorage]
+	assert.Equal(t, "1Gi", limitStorage.String())
+}
+
+func TestGenerateProbe(t *testing.T) {
+	assert.Nil(t, GenerateProbe(nil))
+
+	probe := GenerateProbe(&entity.Probe{
+		HTTPGet:             &entity.HTTPGetProbe{Path: "/healthz", Port: 8080, Scheme: "HTTPS"},
+		InitialDelaySeconds: 5,
+		PeriodSeconds:       10,
+		FailureThreshold:    3,
+	})
+	assert.NotNil(t, probe.HTTPGet)
+	assert.Nil(t, probe.TCPSocket)
+	assert.Nil(t, probe.Exec)
+	assert.Equal(t, "/healthz", probe.HTTPGet.Path)
+	assert.Equal(t, 8080, probe.HTTPGet.Port.IntValue())
+	assert.Equal(t, corev1.URISchemeHTTPS, probe.HTTPGet.Scheme)
+	assert.Equal(t, int32(5), probe.InitialDelaySeconds)
+	assert.Equal(t, int32(10), probe.PeriodSeconds)
+	assert.Equal(t, int32(3), probe.FailureThreshold)
+
+	probe = GenerateProbe(&entity.Probe{TCPSocket: &entity.TCPSocketProbe{Port: 6379}})
+	assert.Nil(t, probe.HTTPGet)
+	assert.Equal(t, 6379, probe.TCPSocket.Port.IntValue())
+
+	probe = GenerateProbe(&entity.Probe{Exec: &entity.ExecProbe{Command: []string{"cat", "/tmp/healthy"}}})
+	assert.Nil(t, probe.HTTPGet)
+	assert.Equal(t, []string{"cat", "/tmp/healthy"}, probe.Exec.Command)
+}
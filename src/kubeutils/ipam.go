@@ -0,0 +1,123 @@
+package kubeutils
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// IPAMVolumeName is the name of the volume sharing the allocated addresses between the init containers
+const IPAMVolumeName = "vortex-ipam"
+
+// IPAMMountPath is where the allocated addresses are written
+const IPAMMountPath = "/vortex-ipam"
+
+// IPAMContainerPrefix is the prefix of the names of the init containers requesting the addresses
+const IPAMContainerPrefix = "init-ipam-"
+
+// IPAMImage is the image of the init container requesting the addresses
+const IPAMImage = "busybox:1.29"
+
+// NetworkClientImage is the image of the init containers setting up the interfaces, the wrapped commands
+// of the clients are run by its /bin/sh so the image can't be replaced by one without the shell
+const NetworkClientImage = "sdnvortex/network-controller:v0.4.9"
+
+// ServiceAccountTokenFile is the token of the service account Kubernetes mounts into the containers of the pod
+const ServiceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// ipamScript requests the address of the interface with the token of the service account of the pod
+// and writes its CIDR to the file named by the interface
+const ipamScript = `set -e
+wget -q -O "$ADDRESS_FILE.json" --header "Content-Type: application/json" \
+  --header "Authorization: Bearer $(cat "$TOKEN_FILE")" \
+  --post-data "{\"network\":\"$NETWORK\",\"ifName\":\"$IF_NAME\",\"podName\":\"$POD_NAME\",\"namespace\":\"$POD_NAMESPACE\",\"podUID\":\"$POD_UID\"}" \
+  "$IPAM_URL/v1/ipam/allocations"
+sed -n 's/.*"cidr": *"\([^"]*\)".*/\1/p' "$ADDRESS_FILE.json" > "$ADDRESS_FILE"
+test -s "$ADDRESS_FILE"`
+
+// IPAMAddressFile returns the file of the allocated address of the interface
+func IPAMAddressFile(ifName string) string {
+	return path.Join(IPAMMountPath, ifName)
+}
+
+// GenerateIPAMVolume generates the volume sharing the allocated addresses
+func GenerateIPAMVolume() (corev1.Volume, corev1.VolumeMount) {
+	volume := corev1.Volume{
+		Name: IPAMVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	}
+	volumeMount := corev1.VolumeMount{
+		Name:      IPAMVolumeName,
+		MountPath: IPAMMountPath,
+	}
+	return volume, volumeMount
+}
+
+// GenerateIPAMContainer generates the init container requesting the address of the interface from the vortex server
+func GenerateIPAMContainer(index int, serverURL string, network string, ifName string) corev1.Container {
+	_, mount := GenerateIPAMVolume()
+	return corev1.Container{
+		Name:    fmt.Sprintf("%s%d", IPAMContainerPrefix, index),
+		Image:   IPAMImage,
+		Command: []string{"/bin/sh", "-c", ipamScript},
+		Env: []corev1.EnvVar{
+			{Name: "IPAM_URL", Value: serverURL},
+			{Name: "NETWORK", Value: network},
+			{Name: "IF_NAME", Value: ifName},
+			{Name: "ADDRESS_FILE", Value: IPAMAddressFile(ifName)},
+			{Name: "TOKEN_FILE", Value: ServiceAccountTokenFile},
+			{
+				Name: "POD_NAME",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "metadata.name",
+					},
+				},
+			},
+			{
+				Name: "POD_NAMESPACE",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "metadata.namespace",
+					},
+				},
+			},
+			{
+				Name: "POD_UID",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "metadata.uid",
+					},
+				},
+			},
+		},
+		VolumeMounts: []corev1.VolumeMount{mount},
+	}
+}
+
+// HasIPAMContainer reports whether the pod has the init container requesting the address of the interface in the network
+func HasIPAMContainer(pod *corev1.Pod, network string, ifName string) bool {
+	for _, c := range pod.Spec.InitContainers {
+		if !strings.HasPrefix(c.Name, IPAMContainerPrefix) {
+			continue
+		}
+		env := map[string]string{}
+		for _, e := range c.Env {
+			env[e.Name] = e.Value
+		}
+		if env["NETWORK"] == network && env["IF_NAME"] == ifName {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateIPAMClientCommand wraps the command of the network client to pass the allocated address of the interface,
+// the wrapper needs /bin/sh in the image of the client, see NetworkClientImage
+func GenerateIPAMClientCommand(client string, ifName string) []string {
+	return []string{"/bin/sh", "-c", fmt.Sprintf(`exec %s "$@" --ip="$(cat %s)"`, client, IPAMAddressFile(ifName)), client}
+}
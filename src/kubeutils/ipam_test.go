@@ -0,0 +1,43 @@
+package kubeutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGenerateIPAMContainer(t *testing.T) {
+	container := GenerateIPAMContainer(1, "http://vortex:7890", "awesome", "eth1")
+	assert.Equal(t, "init-ipam-1", container.Name)
+	assert.Equal(t, IPAMImage, container.Image)
+	assert.Contains(t, container.Env, corev1.EnvVar{Name: "IPAM_URL", Value: "http://vortex:7890"})
+	assert.Contains(t, container.Env, corev1.EnvVar{Name: "NETWORK", Value: "awesome"})
+	assert.Contains(t, container.Env, corev1.EnvVar{Name: "ADDRESS_FILE", Value: "/vortex-ipam/eth1"})
+	assert.Contains(t, container.Env, corev1.EnvVar{Name: "TOKEN_FILE", Value: ServiceAccountTokenFile})
+	assert.Equal(t, []corev1.VolumeMount{{Name: IPAMVolumeName, MountPath: IPAMMountPath}}, container.VolumeMounts)
+}
+
+func TestHasIPAMContainer(t *testing.T) {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{
+				{Name: "init", Env: []corev1.EnvVar{{Name: "NETWORK", Value: "awesome"}, {Name: "IF_NAME", Value: "eth2"}}},
+				GenerateIPAMContainer(0, "http://vortex-server.vortex:7890", "awesome", "eth1"),
+			},
+		},
+	}
+	assert.True(t, HasIPAMContainer(pod, "awesome", "eth1"))
+	assert.False(t, HasIPAMContainer(pod, "awesome", "eth2"))
+	assert.False(t, HasIPAMContainer(pod, "other", "eth1"))
+}
+
+func TestGenerateIPAMClientCommand(t *testing.T) {
+	command := GenerateIPAMClientCommand("/go/bin/client", "eth1")
+	assert.Equal(t, []string{
+		"/bin/sh",
+		"-c",
+		`exec /go/bin/client "$@" --ip="$(cat /vortex-ipam/eth1)"`,
+		"/go/bin/client",
+	}, command)
+}
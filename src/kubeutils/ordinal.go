@@ -0,0 +1,31 @@
+package kubeutils
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+)
+
+// ordinalScript adds the ordinal of the pod, the suffix of its name, to the base address of the interface
+const ordinalScript = `n=$(( %d + ${POD_NAME##*-} ))
+exec %s "$@" --ip="$(( n >> 24 & 255 )).$(( n >> 16 & 255 )).$(( n >> 8 & 255 )).$(( n & 255 ))/%d"`
+
+// OrdinalAddress returns the address of the pod of the ordinal, the pod of ordinal 0 has the base address
+func OrdinalAddress(base string, ordinal int32) (string, error) {
+	ip := net.ParseIP(base).To4()
+	if ip == nil {
+		return "", fmt.Errorf("invalid IPv4 address %s", base)
+	}
+	addr := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(addr, binary.BigEndian.Uint32(ip)+uint32(ordinal))
+	return addr.String(), nil
+}
+
+// GenerateOrdinalClientCommand wraps the command of the network client to pass the address of the pod
+// derived from its ordinal, e.g. the replica web-2 of the StatefulSet gets the base address plus 2
+func GenerateOrdinalClientCommand(client string, base string, netmask string) []string {
+	ip := net.ParseIP(base).To4()
+	size, _ := net.IPMask(net.ParseIP(netmask).To4()).Size()
+	script := fmt.Sprintf(ordinalScript, binary.BigEndian.Uint32(ip), client, size)
+	return []string{"/bin/sh", "-c", script, client}
+}
@@ -0,0 +1,31 @@
+package kubeutils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrdinalAddress(t *testing.T) {
+	addr, err := OrdinalAddress("10.1.0.254", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.1.0.254", addr)
+
+	addr, err = OrdinalAddress("10.1.0.254", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.1.1.1", addr)
+
+	_, err = OrdinalAddress("10.1.0", 1)
+	assert.Error(t, err)
+}
+
+func TestGenerateOrdinalClientCommand(t *testing.T) {
+	command := GenerateOrdinalClientCommand("/go/bin/client", "10.1.0.10", "255.255.255.0")
+	assert.Equal(t, []string{
+		"/bin/sh",
+		"-c",
+		`n=$(( 167837706 + ${POD_NAME##*-} ))
+exec /go/bin/client "$@" --ip="$(( n >> 24 & 255 )).$(( n >> 16 & 255 )).$(( n >> 8 & 255 )).$(( n & 255 ))/24"`,
+		"/go/bin/client",
+	}, command)
+}
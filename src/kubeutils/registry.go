@@ -0,0 +1,98 @@
+package kubeutils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/entity"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// RegistrySecretNamePrefix is the prefix of the names of the registry credential secrets
+const RegistrySecretNamePrefix = "registry"
+
+// RegistryCredentialLabel labels the secrets created by vortex with the name of their registry credential
+const RegistryCredentialLabel = "vortex/registry-credential"
+
+// DockerHubHost is the registry host of the images without a registry, e.g. busybox or library/busybox
+const DockerHubHost = "docker.io"
+
+// RegistrySecretName returns the name of the secret of the registry credential
+func RegistrySecretName(credentialName string) string {
+	return fmt.Sprintf("%s-%s", RegistrySecretNamePrefix, credentialName)
+}
+
+// IsRegistrySecretName reports whether the secret name is reserved for the registry credential secrets
+func IsRegistrySecretName(name string) bool {
+	return strings.HasPrefix(name, RegistrySecretNamePrefix+"-")
+}
+
+// ImageRegistryHost returns the registry host of the image
+func ImageRegistryHost(image string) string {
+	i := strings.Index(image, "/")
+	if i == -1 {
+		return DockerHubHost
+	}
+	// the first component is a registry only if it looks like a host, e.g. library/busybox is on Docker Hub
+	host := image[:i]
+	if !strings.ContainsAny(host, ".:") && host != "localhost" {
+		return DockerHubHost
+	}
+	return normalizeRegistryHost(host)
+}
+
+// RegistryHost returns the registry host of the registry URL, e.g. registry.example.com:5000 of https://registry.example.com:5000/v2
+func RegistryHost(url string) string {
+	host := url
+	if i := strings.Index(host, "://"); i != -1 {
+		host = host[i+3:]
+	}
+	if i := strings.Index(host, "/"); i != -1 {
+		host = host[:i]
+	}
+	return normalizeRegistryHost(host)
+}
+
+func normalizeRegistryHost(host string) string {
+	host = strings.ToLower(host)
+	switch host {
+	case "index.docker.io", "registry-1.docker.io", "registry.hub.docker.com":
+		return DockerHubHost
+	}
+	return host
+}
+
+// GenerateImagePullSecrets returns the secrets of the registry credentials of the registries the images of the containers
+// are pulled from, only the credentials granted to the team of the namespace are copied to it
+func GenerateImagePullSecrets(session *mongo.Session, namespace string, containers []entity.Container) ([]corev1.LocalObjectReference, error) {
+	hosts := map[string]bool{}
+	for _, c := range containers {
+		hosts[ImageRegistryHost(c.Image)] = true
+	}
+
+	n := entity.Namespace{}
+	if err := session.FindOne(entity.NamespaceCollectionName, bson.M{"name": namespace}, &n); err != nil && err != mgo.ErrNotFound {
+		return nil, fmt.Errorf("Load the namespace %s error:%v", namespace, err)
+	}
+
+	credentials := []entity.RegistryCredential{}
+	if err := session.C(entity.RegistryCredentialCollectionName).Find(bson.M{}).All(&credentials); err != nil {
+		return nil, fmt.Errorf("Load the registry credentials error:%v", err)
+	}
+	sort.Slice(credentials, func(i, j int) bool {
+		return credentials[i].Name < credentials[j].Name
+	})
+
+	secrets := []corev1.LocalObjectReference{}
+	for _, c := range credentials {
+		if hosts[RegistryHost(c.URL)] && c.Granted(n.TeamID) {
+			secrets = append(secrets, corev1.LocalObjectReference{Name: RegistrySecretName(c.Name)})
+		}
+	}
+	return secrets, nil
+}
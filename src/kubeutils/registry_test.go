@@ -0,0 +1,104 @@
+package kubeutils
+
+import (
+	"testing"
+
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestImageRegistryHost(t *testing.T) {
+	testCases := map[string]string{
+		"busybox":                                "docker.io",
+		"library/busybox:1.29":                   "docker.io",
+		"docker.io/library/busybox":              "docker.io",
+		"index.docker.io/library/busybox":        "docker.io",
+		"registry.example.com/team/app:v1":       "registry.example.com",
+		"Registry.Example.com:5000/app@sha256:1": "registry.example.com:5000",
+		"localhost/app":                          "localhost",
+	}
+	for image, host := range testCases {
+		assert.Equal(t, host, ImageRegistryHost(image), image)
+	}
+}
+
+func TestRegistryHost(t *testing.T) {
+	testCases := map[string]string{
+		"https://index.docker.io/v1/":          "docker.io",
+		"docker.io":                            "docker.io",
+		"https://registry.example.com:5000/v2": "registry.example.com:5000",
+		"registry.example.com":                 "registry.example.com",
+	}
+	for url, host := range testCases {
+		assert.Equal(t, host, RegistryHost(url), url)
+	}
+}
+
+func TestIsRegistrySecretName(t *testing.T) {
+	assert.True(t, IsRegistrySecretName(RegistrySecretName("private")))
+	assert.False(t, IsRegistrySecretName("registry"))
+	assert.False(t, IsRegistrySecretName("my-registry"))
+}
+
+func (suite *StatusTestSuite) TestGenerateImagePullSecrets() {
+	session := suite.sp.Mongo.NewSession()
+	defer session.Close()
+
+	namespace := entity.Namespace{
+		ID:     bson.NewObjectId(),
+		Name:   namesgenerator.GetRandomName(0),
+		TeamID: bson.NewObjectId(),
+	}
+	session.Insert(entity.NamespaceCollectionName, namespace)
+	defer session.Remove(entity.NamespaceCollectionName, "_id", namespace.ID)
+
+	private := entity.RegistryCredential{
+		ID:    bson.NewObjectId(),
+		Name:  namesgenerator.GetRandomName(0),
+		URL:   "https://registry.example.com/v2",
+		Teams: []bson.ObjectId{namespace.TeamID},
+	}
+	hub := entity.RegistryCredential{
+		ID:    bson.NewObjectId(),
+		Name:  namesgenerator.GetRandomName(1),
+		URL:   "https://index.docker.io/v1/",
+		Teams: []bson.ObjectId{namespace.TeamID},
+	}
+	for _, c := range []entity.RegistryCredential{private, hub} {
+		session.Insert(entity.RegistryCredentialCollectionName, c)
+		defer session.Remove(entity.RegistryCredentialCollectionName, "_id", c.ID)
+	}
+
+	secrets, err := GenerateImagePullSecrets(session, namespace.Name, []entity.Container{
+		{Image: "registry.example.com/team/app:v1"},
+	})
+	suite.NoError(err)
+	suite.Contains(secrets, corev1.LocalObjectReference{Name: RegistrySecretName(private.Name)})
+	suite.NotContains(secrets, corev1.LocalObjectReference{Name: RegistrySecretName(hub.Name)})
+
+	secrets, err = GenerateImagePullSecrets(session, namespace.Name, []entity.Container{
+		{Image: "busybox"},
+		{Image: "registry.example.com/team/app:v1"},
+	})
+	suite.NoError(err)
+	suite.Contains(secrets, corev1.LocalObjectReference{Name: RegistrySecretName(private.Name)})
+	suite.Contains(secrets, corev1.LocalObjectReference{Name: RegistrySecretName(hub.Name)})
+
+	// the namespaces of the other teams aren't granted the credentials
+	secrets, err = GenerateImagePullSecrets(session, "default", []entity.Container{
+		{Image: "registry.example.com/team/app:v1"},
+	})
+	suite.NoError(err)
+	suite.Empty(secrets)
+}
+
+func TestRegistryCredentialGranted(t *testing.T) {
+	teamID := bson.NewObjectId()
+	credential := entity.RegistryCredential{Teams: []bson.ObjectId{teamID}}
+	assert.True(t, credential.Granted(teamID))
+	assert.False(t, credential.Granted(bson.NewObjectId()))
+	assert.False(t, credential.Granted(""))
+}
@@ -0,0 +1,65 @@
+package kubeutils
+
+import (
+	"fmt"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/entity"
+
+	"gopkg.in/mgo.v2/bson"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// SecretVolumeNamePrefix is the prefix of the names of the secret volumes
+const SecretVolumeNamePrefix = "secret"
+
+// CheckSecrets checks the secrets mounted or referred by the environment variables of the containers exist in the namespace
+func CheckSecrets(session *mongo.Session, namespace string, secrets []entity.SecretVolume, containers []entity.Container) error {
+	if namespace == "" {
+		namespace = "default"
+	}
+	names := []string{}
+	for _, s := range secrets {
+		names = append(names, s.Name)
+	}
+	for _, c := range containers {
+		for _, source := range c.EnvVarsFrom {
+			if source.SecretKeyRef != nil {
+				names = append(names, source.SecretKeyRef.Name)
+			}
+		}
+	}
+
+	for _, name := range names {
+		count, err := session.Count(entity.SecretCollectionName, bson.M{"name": name, "namespace": namespace})
+		if err != nil {
+			return fmt.Errorf("Check the secret name error:%v", err)
+		} else if count == 0 {
+			return fmt.Errorf("The secret %s doesn't exist in the namespace %s", name, namespace)
+		}
+	}
+	return nil
+}
+
+// GenerateSecretVolumes generates the volumes and the mounts of the secrets
+func GenerateSecretVolumes(secrets []entity.SecretVolume) ([]corev1.Volume, []corev1.VolumeMount) {
+	volumes := []corev1.Volume{}
+	volumeMounts := []corev1.VolumeMount{}
+	for i, s := range secrets {
+		vName := fmt.Sprintf("%s-%d", SecretVolumeNamePrefix, i)
+		volumes = append(volumes, corev1.Volume{
+			Name: vName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: s.Name,
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      vName,
+			MountPath: s.MountPath,
+			ReadOnly:  true,
+		})
+	}
+	return volumes, volumeMounts
+}
@@ -0,0 +1,79 @@
+package kubeutils
+
+import (
+	"testing"
+
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func (suite *StatusTestSuite) TestCheckSecrets() {
+	session := suite.sp.Mongo.NewSession()
+	defer session.Close()
+
+	secret := entity.Secret{
+		ID:        bson.NewObjectId(),
+		Name:      namesgenerator.GetRandomName(0),
+		Namespace: "default",
+	}
+	session.Insert(entity.SecretCollectionName, secret)
+	defer session.Remove(entity.SecretCollectionName, "_id", secret.ID)
+
+	containers := []entity.Container{
+		{
+			EnvVarsFrom: []entity.EnvVarSource{
+				{Name: "PASSWORD", SecretKeyRef: &entity.KeySelector{Name: secret.Name, Key: "password"}},
+				// the config maps are not checked
+				{Name: "MODE", ConfigMapKeyRef: &entity.KeySelector{Name: namesgenerator.GetRandomName(0), Key: "mode"}},
+			},
+		},
+	}
+	volumes := []entity.SecretVolume{{Name: secret.Name, MountPath: "/etc/secret"}}
+	suite.NoError(CheckSecrets(session, "", volumes, containers))
+	suite.NoError(CheckSecrets(session, "default", nil, nil))
+
+	// the secret is in another namespace
+	suite.Error(CheckSecrets(session, "kube-system", volumes, nil))
+	suite.Error(CheckSecrets(session, "default", nil, []entity.Container{
+		{
+			EnvVarsFrom: []entity.EnvVarSource{
+				{Name: "PASSWORD", SecretKeyRef: &entity.KeySelector{Name: namesgenerator.GetRandomName(0), Key: "password"}},
+			},
+		},
+	}))
+}
+
+func TestGenerateSecretVolumes(t *testing.T) {
+	volumes, volumeMounts := GenerateSecretVolumes(nil)
+	assert.Len(t, volumes, 0)
+	assert.Len(t, volumeMounts, 0)
+
+	volumes, volumeMounts = GenerateSecretVolumes([]entity.SecretVolume{
+		{Name: "database", MountPath: "/etc/database"},
+		{Name: "tls", MountPath: "/etc/tls"},
+	})
+	assert.Len(t, volumes, 2)
+	assert.Equal(t, "secret-1", volumes[1].Name)
+	assert.Equal(t, "tls", volumes[1].Secret.SecretName)
+	assert.Len(t, volumeMounts, 2)
+	assert.Equal(t, "secret-1", volumeMounts[1].Name)
+	assert.Equal(t, "/etc/tls", volumeMounts[1].MountPath)
+	assert.True(t, volumeMounts[1].ReadOnly)
+}
+
+func TestGenerateEnvVarsFrom(t *testing.T) {
+	envVars := GenerateEnvVarsFrom([]entity.EnvVarSource{
+		{Name: "PASSWORD", SecretKeyRef: &entity.KeySelector{Name: "database", Key: "password"}},
+		{Name: "MODE", ConfigMapKeyRef: &entity.KeySelector{Name: "settings", Key: "mode"}},
+	})
+	assert.Len(t, envVars, 2)
+	assert.Equal(t, "PASSWORD", envVars[0].Name)
+	assert.Equal(t, "", envVars[0].Value)
+	assert.Equal(t, "database", envVars[0].ValueFrom.SecretKeyRef.Name)
+	assert.Equal(t, "password", envVars[0].ValueFrom.SecretKeyRef.Key)
+	assert.Nil(t, envVars[0].ValueFrom.ConfigMapKeyRef)
+	assert.Equal(t, "settings", envVars[1].ValueFrom.ConfigMapKeyRef.Name)
+	assert.Equal(t, "mode", envVars[1].ValueFrom.ConfigMapKeyRef.Key)
+}
@@ -5,6 +5,8 @@ import (
 	"github.com/linkernetworks/vortex/src/entity"
 	"github.com/linkernetworks/vortex/src/serviceprovider"
 
+	corev1 "k8s.io/api/core/v1"
+
 	"gopkg.in/mgo.v2/bson"
 )
 
@@ -34,3 +36,40 @@ func GetNonCompletedPods(sp *serviceprovider.Container, query bson.M) ([]entity.
 
 	return ret, nil
 }
+
+// GenerateContainerStatuses converts the states of the containers in the pod
+func GenerateContainerStatuses(statuses []corev1.ContainerStatus) []entity.ContainerStatus {
+	ret := []entity.ContainerStatus{}
+	for _, s := range statuses {
+		container := entity.ContainerStatus{
+			Name:         s.Name,
+			Image:        s.Image,
+			Ready:        s.Ready,
+			RestartCount: s.RestartCount,
+		}
+		switch {
+		case s.State.Running != nil:
+			container.State = "running"
+		case s.State.Terminated != nil:
+			container.State = "terminated"
+			container.Reason = s.State.Terminated.Reason
+			container.Message = s.State.Terminated.Message
+			container.ExitCode = s.State.Terminated.ExitCode
+		default:
+			container.State = "waiting"
+			if s.State.Waiting != nil {
+				container.Reason = s.State.Waiting.Reason
+				container.Message = s.State.Waiting.Message
+			}
+			// the container waits to restart after it failed, e.g. CrashLoopBackOff
+			if last := s.LastTerminationState.Terminated; last != nil {
+				if last.Message != "" {
+					container.Message = last.Message
+				}
+				container.ExitCode = last.ExitCode
+			}
+		}
+		ret = append(ret, container)
+	}
+	return ret
+}
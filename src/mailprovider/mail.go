@@ -0,0 +1,116 @@
+package mailprovider
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultDialTimeout = 10 * time.Second
+
+// SMTPConfig is the structure for the SMTP server sending the mails
+type SMTPConfig struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+	// Username and Password are used for the PLAIN authentication if the username is set
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// From is the sender address, e.g. "Vortex <noreply@linkernetworks.com>"
+	From string `json:"from"`
+	// StartTLS upgrades the connection before the authentication
+	StartTLS bool `json:"startTLS"`
+}
+
+// Sender is the interface for sending the mails
+type Sender interface {
+	// Send sends a plain text mail to the address
+	Send(to string, subject string, body string) error
+}
+
+// SMTPSender sends the mails through the SMTP server
+type SMTPSender struct {
+	cf SMTPConfig
+}
+
+// New returns the SMTP sender of the config, or nil if SMTP isn't configured
+func New(cf *SMTPConfig) Sender {
+	if cf == nil || cf.Host == "" {
+		return nil
+	}
+	return &SMTPSender{cf: *cf}
+}
+
+// Send sends a plain text mail to the address
+func (s *SMTPSender) Send(to string, subject string, body string) error {
+	if strings.ContainsAny(to+subject, "\r\n") {
+		return fmt.Errorf("Invalid mail header")
+	}
+
+	addr := net.JoinHostPort(s.cf.Host, strconv.Itoa(s.cf.Port))
+	conn, err := net.DialTimeout("tcp", addr, defaultDialTimeout)
+	if err != nil {
+		return fmt.Errorf("Failed to connect the SMTP server: %v", err)
+	}
+	client, err := smtp.NewClient(conn, s.cf.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("Failed to connect the SMTP server: %v", err)
+	}
+	defer client.Close()
+
+	if s.cf.StartTLS {
+		if err := client.StartTLS(&tls.Config{ServerName: s.cf.Host}); err != nil {
+			return fmt.Errorf("Failed to start TLS: %v", err)
+		}
+	}
+	if s.cf.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.cf.Username, s.cf.Password, s.cf.Host)); err != nil {
+			return fmt.Errorf("Failed to authenticate to the SMTP server: %v", err)
+		}
+	}
+
+	if err := client.Mail(address(s.cf.From)); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message(s.cf.From, to, subject, body)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// address returns the address part of "Name <address>"
+func address(from string) string {
+	if i := strings.LastIndex(from, "<"); i >= 0 {
+		return strings.TrimSuffix(from[i+1:], ">")
+	}
+	return from
+}
+
+func message(from, to, subject, body string) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.Replace(strings.Replace(body, "\r\n", "\n", -1), "\n", "\r\n", -1))
+	return buf.Bytes()
+}
@@ -0,0 +1,48 @@
+package mailprovider_test
+
+import (
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/linkernetworks/vortex/src/mailprovider"
+	"github.com/linkernetworks/vortex/src/mailprovider/smtptest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSend(t *testing.T) {
+	server, err := smtptest.NewServer()
+	assert.NoError(t, err)
+	defer server.Close()
+
+	sender := mailprovider.New(server.Config())
+	err = sender.Send("hello@linkernetworks.com", "Hello", "first line\n.second line\n")
+	assert.NoError(t, err)
+
+	messages := server.Messages()
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "noreply@linkernetworks.com", messages[0].From)
+	assert.Equal(t, []string{"hello@linkernetworks.com"}, messages[0].To)
+	assert.Equal(t, "first line\n.second line\n", messages[0].Body())
+
+	msg, err := mail.ReadMessage(strings.NewReader(messages[0].Data))
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", msg.Header.Get("Subject"))
+	assert.Equal(t, "hello@linkernetworks.com", msg.Header.Get("To"))
+}
+
+func TestSendInvalidHeader(t *testing.T) {
+	server, err := smtptest.NewServer()
+	assert.NoError(t, err)
+	defer server.Close()
+
+	sender := mailprovider.New(server.Config())
+	err = sender.Send("hello@linkernetworks.com", "Hello\r\nBcc: evil@linkernetworks.com", "body")
+	assert.Error(t, err)
+	assert.Empty(t, server.Messages())
+}
+
+func TestNewWithoutConfig(t *testing.T) {
+	assert.Nil(t, mailprovider.New(nil))
+	assert.Nil(t, mailprovider.New(&mailprovider.SMTPConfig{}))
+}
@@ -0,0 +1,175 @@
+// Package smtptest provides an in-process SMTP server for the tests of the mails
+package smtptest
+
+import (
+	"bufio"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/linkernetworks/vortex/src/mailprovider"
+)
+
+// Message is a mail received by the server
+type Message struct {
+	From string
+	To   []string
+	// Data is the raw message with the headers
+	Data string
+}
+
+// Body returns the body of the message
+func (m Message) Body() string {
+	msg, err := mail.ReadMessage(strings.NewReader(m.Data))
+	if err != nil {
+		return ""
+	}
+	var body strings.Builder
+	scanner := bufio.NewScanner(msg.Body)
+	for scanner.Scan() {
+		body.WriteString(scanner.Text())
+		body.WriteString("\n")
+	}
+	return body.String()
+}
+
+// Server is an SMTP server accepting every mail without authentication
+type Server struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	messages []Message
+	wg       sync.WaitGroup
+}
+
+// NewServer starts the server on a random local port
+func NewServer() (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{listener: listener}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Config returns the SMTP config sending the mails to the server
+func (s *Server) Config() *mailprovider.SMTPConfig {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	return &mailprovider.SMTPConfig{
+		Host: host,
+		Port: portNumber,
+		From: "Vortex <noreply@linkernetworks.com>",
+	}
+}
+
+// Messages returns the received mails
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message{}, s.messages...)
+}
+
+// Close stops the server
+func (s *Server) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) {
+		conn.Write([]byte(line + "\r\n"))
+	}
+
+	reply("220 smtptest ESMTP")
+	msg := Message{}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"):
+			reply("250-smtptest")
+			reply("250 8BITMIME")
+		case strings.HasPrefix(command, "HELO"):
+			reply("250 smtptest")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			msg = Message{From: trimAddress(line[len("MAIL FROM:"):])}
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			msg.To = append(msg.To, trimAddress(line[len("RCPT TO:"):]))
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			data, err := readData(reader)
+			if err != nil {
+				return
+			}
+			msg.Data = data
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			reply("250 OK")
+		case command == "RSET":
+			msg = Message{}
+			reply("250 OK")
+		case command == "NOOP":
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func readData(reader *bufio.Reader) (string, error) {
+	var data strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "." {
+			return data.String(), nil
+		}
+		// undo the dot-stuffing
+		line = strings.TrimPrefix(line, ".")
+		data.WriteString(line)
+		data.WriteString("\r\n")
+	}
+}
+
+func trimAddress(address string) string {
+	address = strings.TrimSpace(address)
+	if i := strings.Index(address, " "); i >= 0 {
+		address = address[:i]
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(address, "<"), ">")
+}
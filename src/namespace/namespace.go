@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/registry"
 	"github.com/linkernetworks/vortex/src/serviceprovider"
 
 	corev1 "k8s.io/api/core/v1"
@@ -12,15 +13,39 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
-// CreateNamespace will create namespace by serviceprovider container
+// CreateNamespace will create namespace by serviceprovider container, the quota and the limit range are attached if given
+// and the registry credentials are applied to it
 func CreateNamespace(sp *serviceprovider.Container, namespace *entity.Namespace) error {
 	n := corev1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: namespace.Name,
 		},
 	}
-	_, err := sp.KubeCtl.CreateNamespace(&n)
-	return err
+	if _, err := sp.KubeCtl.CreateNamespace(&n); err != nil {
+		return err
+	}
+	if err := applyLimits(sp, namespace); err != nil {
+		// don't leave a namespace without its limits
+		sp.KubeCtl.DeleteNamespace(namespace.Name)
+		return err
+	}
+	if err := registry.ApplyNamespace(sp, namespace.Name, namespace.TeamID); err != nil {
+		sp.KubeCtl.DeleteNamespace(namespace.Name)
+		return err
+	}
+	return nil
+}
+
+func applyLimits(sp *serviceprovider.Container, namespace *entity.Namespace) error {
+	if namespace.Quota != nil {
+		if err := ApplyQuota(sp, namespace); err != nil {
+			return err
+		}
+	}
+	if namespace.LimitRange != nil {
+		return ApplyLimitRange(sp, namespace)
+	}
+	return nil
 }
 
 // DeleteNamespace will delete namespace
@@ -46,3 +46,62 @@ func (suite *NamespaceTestSuite) TestCreateDeleteNamespace() {
 	err = DeleteNamespace(suite.sp, namespace)
 	suite.NoError(err)
 }
+
+func (suite *NamespaceTestSuite) TestNamespaceQuota() {
+	namespace := &entity.Namespace{
+		ID:   bson.NewObjectId(),
+		Name: namesgenerator.GetRandomName(0),
+		Quota: &entity.NamespaceQuota{
+			CPU:  "4",
+			Pods: 10,
+		},
+		LimitRange: &entity.NamespaceLimitRange{
+			DefaultCPURequest: "100m",
+			DefaultCPULimit:   "500m",
+		},
+	}
+	err := CreateNamespace(suite.sp, namespace)
+	suite.NoError(err)
+	defer DeleteNamespace(suite.sp, namespace)
+
+	usage, err := GetQuotaUsage(suite.sp, namespace)
+	suite.NoError(err)
+	suite.Equal("4", usage.Hard["requests.cpu"])
+	suite.Equal("10", usage.Hard["pods"])
+
+	limitRange, err := suite.sp.KubeCtl.GetLimitRange(LimitRangeName, namespace.Name)
+	suite.NoError(err)
+	cpu := limitRange.Spec.Limits[0].DefaultRequest["cpu"]
+	suite.Equal("100m", cpu.String())
+
+	// update and remove the limits
+	namespace.Quota = &entity.NamespaceQuota{Memory: "8Gi"}
+	namespace.LimitRange = nil
+	suite.NoError(ApplyQuota(suite.sp, namespace))
+	suite.NoError(ApplyLimitRange(suite.sp, namespace))
+
+	usage, err = GetQuotaUsage(suite.sp, namespace)
+	suite.NoError(err)
+	suite.Equal(map[string]string{"requests.memory": "8Gi"}, usage.Hard)
+	_, err = suite.sp.KubeCtl.GetLimitRange(LimitRangeName, namespace.Name)
+	suite.Error(err)
+
+	namespace.Quota = nil
+	suite.NoError(ApplyQuota(suite.sp, namespace))
+	usage, err = GetQuotaUsage(suite.sp, namespace)
+	suite.NoError(err)
+	suite.Empty(usage.Hard)
+}
+
+func (suite *NamespaceTestSuite) TestNamespaceQuotaFail() {
+	namespace := &entity.Namespace{
+		ID:    bson.NewObjectId(),
+		Name:  namesgenerator.GetRandomName(0),
+		Quota: &entity.NamespaceQuota{CPU: "four"},
+	}
+	err := CreateNamespace(suite.sp, namespace)
+	suite.Error(err)
+	// the namespace is rolled back
+	_, err = suite.sp.KubeCtl.GetNamespace(namespace.Name)
+	suite.Error(err)
+}
@@ -0,0 +1,163 @@
+package namespace
+
+import (
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// the names of the resource quota and the limit range managed by vortex in every namespace
+const (
+	QuotaName      = "vortex-quota"
+	LimitRangeName = "vortex-limit-range"
+)
+
+// ApplyQuota creates, updates or removes the resource quota of the namespace
+func ApplyQuota(sp *serviceprovider.Container, namespace *entity.Namespace) error {
+	if namespace.Quota == nil {
+		err := sp.KubeCtl.DeleteResourceQuota(QuotaName, namespace.Name)
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	hard, err := quotaResources(namespace.Quota)
+	if err != nil {
+		return err
+	}
+	quota, err := sp.KubeCtl.GetResourceQuota(QuotaName, namespace.Name)
+	if errors.IsNotFound(err) {
+		_, err = sp.KubeCtl.CreateResourceQuota(&corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: QuotaName,
+			},
+			Spec: corev1.ResourceQuotaSpec{
+				Hard: hard,
+			},
+		}, namespace.Name)
+		return err
+	} else if err != nil {
+		return err
+	}
+	quota.Spec.Hard = hard
+	_, err = sp.KubeCtl.UpdateResourceQuota(quota, namespace.Name)
+	return err
+}
+
+// ApplyLimitRange creates, updates or removes the limit range of the namespace
+func ApplyLimitRange(sp *serviceprovider.Container, namespace *entity.Namespace) error {
+	if namespace.LimitRange == nil {
+		err := sp.KubeCtl.DeleteLimitRange(LimitRangeName, namespace.Name)
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	item, err := limitRangeItem(namespace.LimitRange)
+	if err != nil {
+		return err
+	}
+	limitRange, err := sp.KubeCtl.GetLimitRange(LimitRangeName, namespace.Name)
+	if errors.IsNotFound(err) {
+		_, err = sp.KubeCtl.CreateLimitRange(&corev1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: LimitRangeName,
+			},
+			Spec: corev1.LimitRangeSpec{
+				Limits: []corev1.LimitRangeItem{item},
+			},
+		}, namespace.Name)
+		return err
+	} else if err != nil {
+		return err
+	}
+	limitRange.Spec.Limits = []corev1.LimitRangeItem{item}
+	_, err = sp.KubeCtl.UpdateLimitRange(limitRange, namespace.Name)
+	return err
+}
+
+// GetQuotaUsage returns the hard limits of the namespace quota and the current usage against them
+func GetQuotaUsage(sp *serviceprovider.Container, namespace *entity.Namespace) (entity.NamespaceQuotaUsage, error) {
+	usage := entity.NamespaceQuotaUsage{
+		Namespace: namespace.Name,
+		Hard:      map[string]string{},
+		Used:      map[string]string{},
+	}
+	quota, err := sp.KubeCtl.GetResourceQuota(QuotaName, namespace.Name)
+	if errors.IsNotFound(err) {
+		// the namespace is unlimited
+		return usage, nil
+	} else if err != nil {
+		return usage, err
+	}
+	for name, quantity := range quota.Spec.Hard {
+		usage.Hard[string(name)] = quantity.String()
+	}
+	for name, quantity := range quota.Status.Used {
+		usage.Used[string(name)] = quantity.String()
+	}
+	return usage, nil
+}
+
+// quotaResources converts the quota to the hard limits of the resource quota
+func quotaResources(quota *entity.NamespaceQuota) (corev1.ResourceList, error) {
+	resources := corev1.ResourceList{}
+	quantities := map[corev1.ResourceName]string{
+		corev1.ResourceRequestsCPU:     quota.CPU,
+		corev1.ResourceRequestsMemory:  quota.Memory,
+		corev1.ResourceRequestsStorage: quota.Storage,
+	}
+	for name, value := range quantities {
+		if value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, err
+		}
+		resources[name] = quantity
+	}
+	if quota.Pods > 0 {
+		resources[corev1.ResourcePods] = *resource.NewQuantity(int64(quota.Pods), resource.DecimalSI)
+	}
+	if quota.PersistentVolumeClaims > 0 {
+		resources[corev1.ResourcePersistentVolumeClaims] = *resource.NewQuantity(int64(quota.PersistentVolumeClaims), resource.DecimalSI)
+	}
+	return resources, nil
+}
+
+// limitRangeItem converts the limit range to the defaults of the containers
+func limitRangeItem(limitRange *entity.NamespaceLimitRange) (corev1.LimitRangeItem, error) {
+	item := corev1.LimitRangeItem{
+		Type:           corev1.LimitTypeContainer,
+		Default:        corev1.ResourceList{},
+		DefaultRequest: corev1.ResourceList{},
+	}
+	quantities := []struct {
+		list  corev1.ResourceList
+		name  corev1.ResourceName
+		value string
+	}{
+		{item.DefaultRequest, corev1.ResourceCPU, limitRange.DefaultCPURequest},
+		{item.DefaultRequest, corev1.ResourceMemory, limitRange.DefaultMemoryRequest},
+		{item.Default, corev1.ResourceCPU, limitRange.DefaultCPULimit},
+		{item.Default, corev1.ResourceMemory, limitRange.DefaultMemoryLimit},
+	}
+	for _, q := range quantities {
+		if q.value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(q.value)
+		if err != nil {
+			return item, err
+		}
+		q.list[q.name] = quantity
+	}
+	return item, nil
+}
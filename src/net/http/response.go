@@ -117,3 +117,8 @@ func UnprocessableEntity(req *http.Request, resp http.ResponseWriter, errs ...er
 func NotAcceptable(req *http.Request, resp http.ResponseWriter, errs ...error) (int, error) {
 	return WriteStatusAndError(req, resp, http.StatusNotAcceptable, errs...)
 }
+
+// TooManyRequests will set the status code http.StatusTooManyRequests to the HTTP response message
+func TooManyRequests(req *http.Request, resp http.ResponseWriter, errs ...error) (int, error) {
+	return WriteStatusAndError(req, resp, http.StatusTooManyRequests, errs...)
+}
@@ -41,6 +41,17 @@ func DumpPorts(sp *serviceprovider.Container, nodeName string, bridgeName string
 	//We need to mapping the deployment.Networks with Pod's UID and the connection is the label of the Pod is vortex=deployment.name.
 	deployments := []entity.Deployment{}
 	session.FindAll(entity.DeploymentCollectionName, bson.M{"networks.bridgeName": bridgeName}, &deployments)
+	//The pods of the StatefulSets and the DaemonSets are labeled in the same way.
+	statefulSets := []entity.StatefulSet{}
+	session.FindAll(entity.StatefulSetCollectionName, bson.M{"networks.bridgeName": bridgeName}, &statefulSets)
+	for _, v := range statefulSets {
+		deployments = append(deployments, v.PodTemplate())
+	}
+	daemonSets := []entity.DaemonSet{}
+	session.FindAll(entity.DaemonSetCollectionName, bson.M{"networks.bridgeName": bridgeName}, &daemonSets)
+	for _, v := range daemonSets {
+		deployments = append(deployments, v.PodTemplate())
+	}
 	deployMap := map[string]*entity.Deployment{}
 
 	for _, v := range deployments {
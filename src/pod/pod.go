@@ -6,6 +6,7 @@ import (
 
 	"github.com/linkernetworks/mongo"
 	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/kubeutils"
 	"github.com/linkernetworks/vortex/src/serviceprovider"
 	"github.com/linkernetworks/vortex/src/utils"
 
@@ -45,7 +46,8 @@ func CheckPodParameter(sp *serviceprovider.Container, pod *entity.Pod) error {
 		}
 	}
 
-	return nil
+	//Check the secret
+	return kubeutils.CheckSecrets(session, pod.Namespace, pod.Secrets, pod.Containers)
 }
 
 func generateVolume(session *mongo.Session, pod *entity.Pod) ([]corev1.Volume, []corev1.VolumeMount, error) {
@@ -125,7 +127,7 @@ func generateInitContainer(networks []entity.PodNetwork) ([]corev1.Container, er
 	for i, v := range networks {
 		containers = append(containers, corev1.Container{
 			Name:    fmt.Sprintf("init-network-client-%d", i),
-			Image:   "sdnvortex/network-controller:v0.4.9",
+			Image:   kubeutils.NetworkClientImage,
 			Command: []string{"/go/bin/client"},
 			Args:    generateClientCommand(v),
 			Env: []corev1.EnvVar{
@@ -222,26 +224,11 @@ func generateAffinity(nodeNames []string) *corev1.Affinity {
 	}
 }
 
-func generateEnvVars(pod *entity.Pod) []corev1.EnvVar {
-	envVars := []corev1.EnvVar{}
-
-	for k, v := range pod.EnvVars {
-		envVars = append(envVars, corev1.EnvVar{
-			Name:  k,
-			Value: v,
-		})
-	}
-	return envVars
-}
-
-// CreatePod will Create Pod
-func CreatePod(sp *serviceprovider.Container, pod *entity.Pod) error {
-	session := sp.Mongo.NewSession()
-	defer session.Close()
-
+// GeneratePodSpec generates the spec of the pod, the Jobs reuse it for their pods
+func GeneratePodSpec(session *mongo.Session, pod *entity.Pod) (corev1.PodSpec, error) {
 	volumes, volumeMounts, err := generateVolume(session, pod)
 	if err != nil {
-		return err
+		return corev1.PodSpec{}, err
 	}
 
 	nodeAffinity := pod.NodeAffinity
@@ -263,7 +250,7 @@ func CreatePod(sp *serviceprovider.Container, pod *entity.Pod) error {
 	}
 
 	if err != nil {
-		return err
+		return corev1.PodSpec{}, err
 	}
 
 	volumes = append(volumes, corev1.Volume{
@@ -275,18 +262,40 @@ func CreatePod(sp *serviceprovider.Container, pod *entity.Pod) error {
 		},
 	})
 
+	secrets, secretMounts := kubeutils.GenerateSecretVolumes(pod.Secrets)
+	volumes = append(volumes, secrets...)
+	volumeMounts = append(volumeMounts, secretMounts...)
+
 	var containers []corev1.Container
 	securityContext := generateContainerSecurity(pod)
-	envVars := generateEnvVars(pod)
 	for _, container := range pod.Containers {
-		containers = append(containers, corev1.Container{
-			Name:            container.Name,
-			Image:           container.Image,
-			Command:         container.Command,
-			VolumeMounts:    volumeMounts,
-			SecurityContext: securityContext,
-			Env:             envVars,
-		})
+		containers = append(containers, kubeutils.GenerateContainer(container, pod.EnvVars, volumeMounts, securityContext))
+	}
+
+	imagePullSecrets, err := kubeutils.GenerateImagePullSecrets(session, pod.Namespace, pod.Containers)
+	if err != nil {
+		return corev1.PodSpec{}, err
+	}
+
+	return corev1.PodSpec{
+		InitContainers:   initContainers,
+		Containers:       containers,
+		Volumes:          volumes,
+		Affinity:         generateAffinity(nodeAffinity),
+		RestartPolicy:    corev1.RestartPolicy(pod.RestartPolicy),
+		HostNetwork:      hostNetwork,
+		ImagePullSecrets: imagePullSecrets,
+	}, nil
+}
+
+// CreatePod will Create Pod
+func CreatePod(sp *serviceprovider.Container, pod *entity.Pod) error {
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	spec, err := GeneratePodSpec(session, pod)
+	if err != nil {
+		return err
 	}
 
 	p := corev1.Pod{
@@ -294,17 +303,7 @@ func CreatePod(sp *serviceprovider.Container, pod *entity.Pod) error {
 			Name:   pod.Name,
 			Labels: pod.Labels,
 		},
-		Spec: corev1.PodSpec{
-			InitContainers: initContainers,
-			Containers:     containers,
-			Volumes:        volumes,
-			Affinity:       generateAffinity(nodeAffinity),
-			RestartPolicy:  corev1.RestartPolicy(pod.RestartPolicy),
-			HostNetwork:    hostNetwork,
-			ImagePullSecrets: []corev1.LocalObjectReference{
-				{Name: "dockerhub-token"},
-			},
-		},
+		Spec: spec,
 	}
 
 	if pod.Namespace == "" {
@@ -2,6 +2,7 @@ package prometheuscontroller
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -114,6 +115,39 @@ func ListNodeName(sp *serviceprovider.Container, queryLabels map[string]string)
 	return nodeList, nil
 }
 
+var metricNameRegexp = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// ListMetricName will list the names of the series of the metric, the counter is also looked up with the "_total" suffix
+// since the custom metrics API serves it without the suffix
+func ListMetricName(sp *serviceprovider.Container, metricName string, queryLabels map[string]string) ([]string, error) {
+	if !metricNameRegexp.MatchString(metricName) {
+		return nil, fmt.Errorf("invalid metric name %s", metricName)
+	}
+	if queryLabels == nil {
+		queryLabels = map[string]string{}
+	}
+
+	expression := Expression{}
+	expression.Metrics = []string{metricName, metricName + "_total"}
+	expression.QueryLabels = queryLabels
+	expression.SumByLabels = []string{"__name__"}
+
+	str := basicExpr(expression.Metrics)
+	str = queryExpr(str, expression.QueryLabels)
+	str = sumByExpr(str, expression.SumByLabels)
+	results, err := query(sp, str)
+	if err != nil {
+		return nil, err
+	}
+
+	metricList := []string{}
+	for _, result := range results {
+		metricList = append(metricList, string(result.Metric["__name__"]))
+	}
+
+	return metricList, nil
+}
+
 // ListNodeNICs will list node's NICs
 func ListNodeNICs(sp *serviceprovider.Container, id string) (entity.NodeNICsMetrics, error) {
 	nicList := entity.NodeNICsMetrics{}
@@ -105,6 +105,30 @@ func (suite *PrometheusExpressionTestSuite) TestListNodeFail() {
 	suite.Error(err)
 }
 
+func (suite *PrometheusExpressionTestSuite) TestListMetricName() {
+	queryLabels := map[string]string{"namespace": "vortex"}
+
+	metricNameList, err := ListMetricName(suite.sp, "kube_pod_info", queryLabels)
+	suite.NoError(err)
+	suite.Equal([]string{"kube_pod_info"}, metricNameList)
+
+	// the counter is found without the suffix
+	metricNameList, err = ListMetricName(suite.sp, "container_network_receive_packets", queryLabels)
+	suite.NoError(err)
+	suite.Equal([]string{"container_network_receive_packets_total"}, metricNameList)
+}
+
+func (suite *PrometheusExpressionTestSuite) TestListMetricNameFail() {
+	queryLabels := map[string]string{"namespace": "vortex"}
+
+	metricNameList, err := ListMetricName(suite.sp, "no_such_metric", queryLabels)
+	suite.NoError(err)
+	suite.Empty(metricNameList)
+
+	_, err = ListMetricName(suite.sp, `up"} or vector(1)`, queryLabels)
+	suite.Error(err)
+}
+
 func (suite *PrometheusExpressionTestSuite) TestListNodeNICs() {
 	nodes, err := suite.sp.KubeCtl.GetNodes()
 	suite.NoError(err)
@@ -0,0 +1,326 @@
+package registry
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/linkernetworks/logger"
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/kubeutils"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+
+	"gopkg.in/mgo.v2/bson"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// dockerHubConfigKey is the key of Docker Hub in the docker config, kubelet doesn't match docker.io
+const dockerHubConfigKey = "https://index.docker.io/v1/"
+
+// LegacySecretName is the pull secret of the system namespace the pods used before the registry credentials
+const LegacySecretName = "dockerhub-token"
+
+// ErrSecretNotManaged is returned when the secret name of a registry credential is taken by a secret vortex didn't create
+var ErrSecretNotManaged = fmt.Errorf("The secret name of the registry credential is taken by a secret vortex didn't create")
+
+type dockerConfigEntry struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Auth     string `json:"auth"`
+}
+
+type dockerConfigJSON struct {
+	Auths map[string]dockerConfigEntry `json:"auths"`
+}
+
+// GenerateSecret generates the docker config secret of the registry credential in the namespace
+func GenerateSecret(credential *entity.RegistryCredential, namespace string) (*corev1.Secret, error) {
+	key := kubeutils.RegistryHost(credential.URL)
+	if key == kubeutils.DockerHubHost {
+		key = dockerHubConfigKey
+	}
+	config, err := json.Marshal(dockerConfigJSON{
+		Auths: map[string]dockerConfigEntry{
+			key: {
+				Username: credential.Username,
+				Password: credential.Password,
+				Auth:     base64.StdEncoding.EncodeToString([]byte(credential.Username + ":" + credential.Password)),
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      kubeutils.RegistrySecretName(credential.Name),
+			Namespace: namespace,
+			Labels: map[string]string{
+				kubeutils.RegistryCredentialLabel: credential.Name,
+			},
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: config,
+		},
+	}, nil
+}
+
+// ManagedNamespaces returns the default namespace and the namespaces created by vortex
+func ManagedNamespaces(session *mongo.Session) ([]string, error) {
+	namespaces := []entity.Namespace{}
+	if err := session.C(entity.NamespaceCollectionName).Find(bson.M{}).All(&namespaces); err != nil {
+		return nil, err
+	}
+	names := []string{"default"}
+	for _, n := range namespaces {
+		if n.Name != "default" {
+			names = append(names, n.Name)
+		}
+	}
+	return names, nil
+}
+
+// SystemNamespace returns the namespace keeping the source secrets of the registry credentials,
+// the passwords are only stored in them
+func SystemNamespace(sp *serviceprovider.Container) string {
+	if sp.Config.Kubernetes == nil || sp.Config.Kubernetes.SystemNamespace == "" {
+		return "default"
+	}
+	return sp.Config.Kubernetes.SystemNamespace
+}
+
+// namespaceTeams returns the team of every managed namespace, the namespaces without a team have an empty team
+func namespaceTeams(session *mongo.Session) (map[string]bson.ObjectId, error) {
+	namespaces := []entity.Namespace{}
+	if err := session.C(entity.NamespaceCollectionName).Find(bson.M{}).All(&namespaces); err != nil {
+		return nil, err
+	}
+	teams := map[string]bson.ObjectId{"default": ""}
+	for _, n := range namespaces {
+		teams[n.Name] = n.TeamID
+	}
+	return teams, nil
+}
+
+// ApplyCredential creates or updates the secret of the registry credential in the system namespace
+// and copies it to the namespaces of the granted teams, the copies in the other namespaces are deleted
+func ApplyCredential(sp *serviceprovider.Container, credential *entity.RegistryCredential) error {
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	teams, err := namespaceTeams(session)
+	if err != nil {
+		return err
+	}
+	source, err := GenerateSecret(credential, SystemNamespace(sp))
+	if err != nil {
+		return err
+	}
+	if err := applySecret(sp, source); err != nil {
+		return err
+	}
+
+	namespaces := []string{}
+	for namespace := range teams {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		// the system namespace keeps the source secret
+		if namespace == SystemNamespace(sp) {
+			continue
+		}
+		if !credential.Granted(teams[namespace]) {
+			err = deleteSecret(sp, credential, namespace)
+		} else {
+			err = applySecret(sp, copySecret(source, namespace))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyNamespace creates or updates the secrets of the registry credentials granted to the team of the namespace,
+// they are copied from the secrets of the system namespace. The copies of the other credentials are deleted.
+func ApplyNamespace(sp *serviceprovider.Container, namespace string, teamID bson.ObjectId) error {
+	// the system namespace keeps the source secrets
+	if namespace == SystemNamespace(sp) {
+		return nil
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	credentials := []entity.RegistryCredential{}
+	if err := session.C(entity.RegistryCredentialCollectionName).Find(bson.M{}).All(&credentials); err != nil {
+		return err
+	}
+	for _, credential := range credentials {
+		if !credential.Granted(teamID) {
+			if err := deleteSecret(sp, &credential, namespace); err != nil {
+				return err
+			}
+			continue
+		}
+		source, err := sp.KubeCtl.GetSecret(kubeutils.RegistrySecretName(credential.Name), SystemNamespace(sp))
+		if err != nil {
+			return fmt.Errorf("Load the secret of registry credential %s error: %v", credential.Name, err)
+		}
+		if err := applySecret(sp, copySecret(source, namespace)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteCredential deletes the secret of the registry credential from every managed namespace and the system namespace
+func DeleteCredential(sp *serviceprovider.Container, credential *entity.RegistryCredential) error {
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	namespaces, err := ManagedNamespaces(session)
+	if err != nil {
+		return err
+	}
+	for _, namespace := range append(namespaces, SystemNamespace(sp)) {
+		if err := deleteSecret(sp, credential, namespace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportLegacySecret imports the auths of the legacy pull secret of the system namespace, or the default namespace
+// where the helm chart created it, as the registry credentials. The credentials named after the legacy secret
+// are not imported again.
+func ImportLegacySecret(sp *serviceprovider.Container) error {
+	var secret *corev1.Secret
+	for _, namespace := range []string{SystemNamespace(sp), "default"} {
+		s, err := sp.KubeCtl.GetSecret(LegacySecretName, namespace)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		secret = s
+		break
+	}
+	if secret == nil {
+		return nil
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	count, err := session.Count(entity.RegistryCredentialCollectionName, bson.M{"name": bson.RegEx{Pattern: "^" + LegacySecretName}})
+	if err != nil || count > 0 {
+		return err
+	}
+
+	config := dockerConfigJSON{}
+	if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &config); err != nil {
+		return fmt.Errorf("Parse the legacy secret %s error: %v", LegacySecretName, err)
+	}
+	keys := []string{}
+	for key := range config.Auths {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for i, key := range keys {
+		entry := config.Auths[key]
+		if entry.Username == "" {
+			// the auth is the base64 encoded username:password
+			auth, err := base64.StdEncoding.DecodeString(entry.Auth)
+			if err != nil {
+				return fmt.Errorf("Parse the auth of %s in the legacy secret %s error: %v", key, LegacySecretName, err)
+			}
+			parts := strings.SplitN(string(auth), ":", 2)
+			if len(parts) != 2 {
+				return fmt.Errorf("Parse the auth of %s in the legacy secret %s error", key, LegacySecretName)
+			}
+			entry.Username, entry.Password = parts[0], parts[1]
+		}
+		credential := entity.RegistryCredential{
+			ID:       bson.NewObjectId(),
+			Name:     LegacySecretName,
+			URL:      key,
+			Username: entry.Username,
+			Password: entry.Password,
+		}
+		if i > 0 {
+			credential.Name = fmt.Sprintf("%s-%d", LegacySecretName, i+1)
+		}
+		if err := ApplyCredential(sp, &credential); err != nil {
+			return err
+		}
+		if err := session.Insert(entity.RegistryCredentialCollectionName, &credential); err != nil {
+			return err
+		}
+		logger.Infof("The legacy pull secret %s of %s is imported as registry credential %s", LegacySecretName, key, credential.Name)
+	}
+	return nil
+}
+
+// copySecret returns the copy of the secret in the namespace
+func copySecret(source *corev1.Secret, namespace string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      source.Name,
+			Namespace: namespace,
+			Labels:    source.Labels,
+		},
+		Type: source.Type,
+		Data: source.Data,
+	}
+}
+
+// isManaged reports whether the secret is created by vortex for the registry credential
+func isManaged(secret *corev1.Secret, credentialName string) bool {
+	return secret.Labels[kubeutils.RegistryCredentialLabel] == credentialName
+}
+
+// applySecret creates or updates the secret, the existing secret must be created by vortex
+func applySecret(sp *serviceprovider.Container, secret *corev1.Secret) error {
+	namespace := secret.Namespace
+	current, err := sp.KubeCtl.GetSecret(secret.Name, namespace)
+	if errors.IsNotFound(err) {
+		_, err = sp.KubeCtl.CreateSecret(secret, namespace)
+		return err
+	} else if err != nil {
+		return err
+	}
+	if !isManaged(current, secret.Labels[kubeutils.RegistryCredentialLabel]) {
+		logger.Warnf("The secret %s/%s isn't created by vortex", namespace, secret.Name)
+		return ErrSecretNotManaged
+	}
+	current.Type = secret.Type
+	current.Data = secret.Data
+	_, err = sp.KubeCtl.UpdateSecret(current, namespace)
+	return err
+}
+
+// deleteSecret deletes the secret of the registry credential from the namespace, the secrets vortex didn't create are kept
+func deleteSecret(sp *serviceprovider.Container, credential *entity.RegistryCredential, namespace string) error {
+	name := kubeutils.RegistrySecretName(credential.Name)
+	current, err := sp.KubeCtl.GetSecret(name, namespace)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !isManaged(current, credential.Name) {
+		return nil
+	}
+	if err := sp.KubeCtl.DeleteSecret(name, namespace); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
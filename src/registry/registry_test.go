@@ -0,0 +1,234 @@
+package registry
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/kubeutils"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func init() {
+	rand.Seed(time.Now().UnixNano())
+}
+
+type RegistryTestSuite struct {
+	suite.Suite
+	sp *serviceprovider.Container
+}
+
+func (suite *RegistryTestSuite) SetupSuite() {
+	cf := config.MustRead("../../config/testing.json")
+	suite.sp = serviceprovider.NewForTesting(cf)
+}
+
+func (suite *RegistryTestSuite) TearDownSuite() {
+}
+
+func TestRegistrySuite(t *testing.T) {
+	suite.Run(t, new(RegistryTestSuite))
+}
+
+func TestGenerateSecret(t *testing.T) {
+	credential := &entity.RegistryCredential{
+		Name:     "private",
+		URL:      "https://registry.example.com/v2",
+		Username: "admin",
+		Password: "awesome",
+	}
+	secret, err := GenerateSecret(credential, "default")
+	assert.NoError(t, err)
+	assert.Equal(t, "registry-private", secret.Name)
+	assert.Equal(t, corev1.SecretTypeDockerConfigJson, secret.Type)
+
+	config := dockerConfigJSON{}
+	assert.NoError(t, json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &config))
+	assert.Equal(t, dockerConfigEntry{
+		Username: "admin",
+		Password: "awesome",
+		Auth:     "YWRtaW46YXdlc29tZQ==",
+	}, config.Auths["registry.example.com"])
+
+	// kubelet looks up the images of Docker Hub by its v1 index
+	credential.URL = "docker.io"
+	secret, err = GenerateSecret(credential, "default")
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &config))
+	assert.Contains(t, config.Auths, dockerHubConfigKey)
+}
+
+func (suite *RegistryTestSuite) TestApplyDeleteCredential() {
+	session := suite.sp.Mongo.NewSession()
+	defer session.Close()
+
+	namespace := entity.Namespace{
+		ID:     bson.NewObjectId(),
+		Name:   namesgenerator.GetRandomName(0),
+		TeamID: bson.NewObjectId(),
+	}
+	session.Insert(entity.NamespaceCollectionName, namespace)
+	defer session.Remove(entity.NamespaceCollectionName, "_id", namespace.ID)
+	other := entity.Namespace{
+		ID:     bson.NewObjectId(),
+		Name:   namesgenerator.GetRandomName(1),
+		TeamID: bson.NewObjectId(),
+	}
+	session.Insert(entity.NamespaceCollectionName, other)
+	defer session.Remove(entity.NamespaceCollectionName, "_id", other.ID)
+
+	credential := &entity.RegistryCredential{
+		Name:     namesgenerator.GetRandomName(1),
+		URL:      "registry.example.com",
+		Username: "admin",
+		Password: "awesome",
+		Teams:    []bson.ObjectId{namespace.TeamID},
+	}
+	secretName := kubeutils.RegistrySecretName(credential.Name)
+	suite.NoError(ApplyCredential(suite.sp, credential))
+	for _, n := range []string{SystemNamespace(suite.sp), namespace.Name} {
+		secret, err := suite.sp.KubeCtl.GetSecret(secretName, n)
+		suite.NoError(err)
+		suite.Equal(credential.Name, secret.Labels[kubeutils.RegistryCredentialLabel])
+	}
+	// the namespace of the other team isn't granted the credential
+	_, err := suite.sp.KubeCtl.GetSecret(secretName, other.Name)
+	suite.Error(err)
+
+	// the secrets are updated with the new password
+	credential.Password = "changed"
+	suite.NoError(ApplyCredential(suite.sp, credential))
+	secret, err := suite.sp.KubeCtl.GetSecret(secretName, namespace.Name)
+	suite.NoError(err)
+	suite.Contains(string(secret.Data[corev1.DockerConfigJsonKey]), "changed")
+
+	// the grant moves to the other team
+	credential.Teams = []bson.ObjectId{other.TeamID}
+	suite.NoError(ApplyCredential(suite.sp, credential))
+	_, err = suite.sp.KubeCtl.GetSecret(secretName, namespace.Name)
+	suite.Error(err)
+	_, err = suite.sp.KubeCtl.GetSecret(secretName, other.Name)
+	suite.NoError(err)
+
+	suite.NoError(DeleteCredential(suite.sp, credential))
+	for _, n := range []string{SystemNamespace(suite.sp), namespace.Name, other.Name} {
+		_, err := suite.sp.KubeCtl.GetSecret(secretName, n)
+		suite.Error(err)
+	}
+	// the deleted secrets are ignored
+	suite.NoError(DeleteCredential(suite.sp, credential))
+}
+
+func (suite *RegistryTestSuite) TestApplyNamespace() {
+	session := suite.sp.Mongo.NewSession()
+	defer session.Close()
+
+	credential := entity.RegistryCredential{
+		ID:       bson.NewObjectId(),
+		Name:     namesgenerator.GetRandomName(0),
+		URL:      "registry.example.com",
+		Username: "admin",
+		Password: "awesome",
+		Teams:    []bson.ObjectId{bson.NewObjectId()},
+	}
+	suite.NoError(ApplyCredential(suite.sp, &credential))
+	defer DeleteCredential(suite.sp, &credential)
+	session.Insert(entity.RegistryCredentialCollectionName, credential)
+	defer session.Remove(entity.RegistryCredentialCollectionName, "_id", credential.ID)
+
+	// the password isn't stored
+	stored := entity.RegistryCredential{}
+	suite.NoError(session.FindOne(entity.RegistryCredentialCollectionName, bson.M{"_id": credential.ID}, &stored))
+	suite.Empty(stored.Password)
+
+	// the secret is copied from the system namespace to the namespace of the granted team
+	namespace := namesgenerator.GetRandomName(1)
+	secretName := kubeutils.RegistrySecretName(credential.Name)
+	suite.NoError(ApplyNamespace(suite.sp, namespace, credential.Teams[0]))
+	secret, err := suite.sp.KubeCtl.GetSecret(secretName, namespace)
+	suite.NoError(err)
+	suite.Equal(corev1.SecretTypeDockerConfigJson, secret.Type)
+	suite.Contains(string(secret.Data[corev1.DockerConfigJsonKey]), "awesome")
+
+	// the copy is deleted when the namespace moves to another team
+	suite.NoError(ApplyNamespace(suite.sp, namespace, bson.NewObjectId()))
+	_, err = suite.sp.KubeCtl.GetSecret(secretName, namespace)
+	suite.Error(err)
+	suite.NoError(ApplyNamespace(suite.sp, namespace, ""))
+	_, err = suite.sp.KubeCtl.GetSecret(secretName, namespace)
+	suite.Error(err)
+
+	// the source secret of the system namespace is kept
+	suite.NoError(ApplyNamespace(suite.sp, SystemNamespace(suite.sp), ""))
+	_, err = suite.sp.KubeCtl.GetSecret(secretName, SystemNamespace(suite.sp))
+	suite.NoError(err)
+}
+
+func (suite *RegistryTestSuite) TestSecretNotManaged() {
+	credential := &entity.RegistryCredential{
+		Name:     namesgenerator.GetRandomName(0),
+		URL:      "registry.example.com",
+		Username: "admin",
+		Password: "awesome",
+	}
+	secret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: kubeutils.RegistrySecretName(credential.Name)},
+		Data:       map[string][]byte{"token": []byte("mine")},
+	}
+	_, err := suite.sp.KubeCtl.CreateSecret(&secret, "default")
+	suite.NoError(err)
+	defer suite.sp.KubeCtl.DeleteSecret(secret.Name, "default")
+
+	suite.Equal(ErrSecretNotManaged, ApplyCredential(suite.sp, credential))
+	// the secret of the user is kept
+	suite.NoError(DeleteCredential(suite.sp, credential))
+	current, err := suite.sp.KubeCtl.GetSecret(secret.Name, "default")
+	suite.NoError(err)
+	suite.Equal("mine", string(current.Data["token"]))
+}
+
+func (suite *RegistryTestSuite) TestImportLegacySecret() {
+	session := suite.sp.Mongo.NewSession()
+	defer session.Close()
+	defer session.C(entity.RegistryCredentialCollectionName).RemoveAll(bson.M{"name": bson.RegEx{Pattern: "^" + LegacySecretName}})
+
+	// no legacy secret
+	suite.NoError(ImportLegacySecret(suite.sp))
+
+	secret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: LegacySecretName},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: []byte(`{"auths":{"https://index.docker.io/v1/":{"auth":"YWRtaW46YXdlc29tZQ=="}}}`),
+		},
+	}
+	_, err := suite.sp.KubeCtl.CreateSecret(&secret, "default")
+	suite.NoError(err)
+	defer suite.sp.KubeCtl.DeleteSecret(secret.Name, "default")
+
+	suite.NoError(ImportLegacySecret(suite.sp))
+	credential := entity.RegistryCredential{}
+	suite.NoError(session.FindOne(entity.RegistryCredentialCollectionName, bson.M{"name": LegacySecretName}, &credential))
+	defer DeleteCredential(suite.sp, &credential)
+	suite.Equal("admin", credential.Username)
+	suite.Equal(kubeutils.DockerHubHost, kubeutils.RegistryHost(credential.URL))
+	current, err := suite.sp.KubeCtl.GetSecret(kubeutils.RegistrySecretName(LegacySecretName), SystemNamespace(suite.sp))
+	suite.NoError(err)
+	suite.Contains(string(current.Data[corev1.DockerConfigJsonKey]), "awesome")
+
+	// the legacy secret is imported once
+	suite.NoError(ImportLegacySecret(suite.sp))
+	count, err := session.Count(entity.RegistryCredentialCollectionName, bson.M{"name": bson.RegEx{Pattern: "^" + LegacySecretName}})
+	suite.NoError(err)
+	suite.Equal(1, count)
+}
@@ -0,0 +1,53 @@
+package secret
+
+import (
+	"sort"
+
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreateSecret will create the secret by serviceprovider container
+func CreateSecret(sp *serviceprovider.Container, secret *entity.Secret) error {
+	secretType := corev1.SecretTypeOpaque
+	if secret.Type != "" {
+		secretType = corev1.SecretType(secret.Type)
+	}
+	data := map[string][]byte{}
+	for k, v := range secret.Data {
+		data[k] = []byte(v)
+	}
+	n := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secret.Name,
+			Namespace: secret.Namespace,
+		},
+		Type: secretType,
+		Data: data,
+	}
+	_, err := sp.KubeCtl.CreateSecret(&n, secret.Namespace)
+	return err
+}
+
+// DeleteSecret will delete the secret
+func DeleteSecret(sp *serviceprovider.Container, secret *entity.Secret) error {
+	return sp.KubeCtl.DeleteSecret(secret.Name, secret.Namespace)
+}
+
+// Keys returns the sorted names of the data of the Kubernetes secret
+func Keys(secret *corev1.Secret) []string {
+	keys := []string{}
+	for k := range secret.Data {
+		keys = append(keys, k)
+	}
+	for k := range secret.StringData {
+		if _, ok := secret.Data[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,74 @@
+package secret
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func init() {
+	rand.Seed(time.Now().UnixNano())
+}
+
+type SecretTestSuite struct {
+	suite.Suite
+	sp *serviceprovider.Container
+}
+
+func (suite *SecretTestSuite) SetupSuite() {
+	cf := config.MustRead("../../config/testing.json")
+	suite.sp = serviceprovider.NewForTesting(cf)
+}
+
+func (suite *SecretTestSuite) TearDownSuite() {
+}
+
+func TestSecretSuite(t *testing.T) {
+	suite.Run(t, new(SecretTestSuite))
+}
+
+func (suite *SecretTestSuite) TestCreateDeleteSecret() {
+	secret := &entity.Secret{
+		ID:        bson.NewObjectId(),
+		Name:      namesgenerator.GetRandomName(0),
+		Namespace: "default",
+		Data: map[string]string{
+			"username": "admin",
+			"password": "awesome",
+		},
+	}
+
+	err := CreateSecret(suite.sp, secret)
+	suite.NoError(err)
+
+	result, err := suite.sp.KubeCtl.GetSecret(secret.Name, secret.Namespace)
+	suite.NoError(err)
+	suite.Equal(corev1.SecretTypeOpaque, result.Type)
+	suite.Equal("awesome", string(result.Data["password"]))
+
+	err = DeleteSecret(suite.sp, secret)
+	suite.NoError(err)
+}
+
+func (suite *SecretTestSuite) TestKeys() {
+	secret := &corev1.Secret{
+		Data: map[string][]byte{
+			"tls.key": []byte("key"),
+			"tls.crt": []byte("crt"),
+		},
+		StringData: map[string]string{
+			"ca.crt":  "ca",
+			"tls.crt": "crt",
+		},
+	}
+	suite.Equal([]string{"ca.crt", "tls.crt", "tls.key"}, Keys(secret))
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/linkernetworks/logger"
 	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/ipam"
+	"github.com/linkernetworks/vortex/src/registry"
 	"github.com/linkernetworks/vortex/src/serviceprovider"
 )
 
@@ -30,6 +32,10 @@ func (a *App) LoadConfig(configPath string) *App {
 func (a *App) Start(host, port string) error {
 
 	a.InitilizeService()
+	if err := registry.ImportLegacySecret(a.ServiceProvider); err != nil {
+		logger.Warnf("Failed to import the legacy pull secret %s: %v", registry.LegacySecretName, err)
+	}
+	go ipam.ReleaseLoop(a.ServiceProvider, ipam.ReleaseInterval(a.Config.IPAM))
 
 	bind := net.JoinHostPort(host, port)
 
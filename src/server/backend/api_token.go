@@ -0,0 +1,87 @@
+package backend
+
+import (
+	"errors"
+	"time"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/utils/timeutils"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/utils"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// ErrAPITokenExpired is returned when an expired API token, which mongo hasn't removed yet, is used
+var ErrAPITokenExpired = errors.New("API token is expired")
+
+// CreateAPIToken generates the token of the API token and stores the API token
+func CreateAPIToken(session *mongo.Session, apiToken *entity.APIToken) error {
+	token, err := utils.RandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	c := session.C(entity.APITokenCollectionName)
+	c.EnsureIndex(mgo.Index{
+		Key:    []string{"tokenHash"},
+		Unique: true,
+	})
+	// let mongo remove the expired tokens, the tokens without expiresAt are kept
+	c.EnsureIndex(mgo.Index{
+		Key:         []string{"expiresAt"},
+		ExpireAfter: time.Second,
+	})
+
+	apiToken.ID = bson.NewObjectId()
+	apiToken.Token = entity.APITokenPrefix + token
+	apiToken.TokenHash = utils.SHA256String(apiToken.Token)
+	apiToken.CreatedAt = timeutils.Now()
+	return session.Insert(entity.APITokenCollectionName, apiToken)
+}
+
+// ListAPITokens returns the API tokens of the user
+func ListAPITokens(session *mongo.Session, ownerID bson.ObjectId) ([]entity.APIToken, error) {
+	apiTokens := []entity.APIToken{}
+	if err := session.FindAll(entity.APITokenCollectionName, bson.M{"ownerID": ownerID}, &apiTokens); err != nil {
+		return nil, err
+	}
+	return apiTokens, nil
+}
+
+// AuthenticateAPIToken returns the API token and its owner if the token is valid
+func AuthenticateAPIToken(session *mongo.Session, token string) (entity.APIToken, entity.User, error) {
+	apiToken := entity.APIToken{}
+	if err := session.FindOne(
+		entity.APITokenCollectionName,
+		bson.M{"tokenHash": utils.SHA256String(token)},
+		&apiToken,
+	); err != nil {
+		return entity.APIToken{}, entity.User{}, err
+	}
+
+	if apiToken.ExpiresAt != nil && time.Now().After(*apiToken.ExpiresAt) {
+		return entity.APIToken{}, entity.User{}, ErrAPITokenExpired
+	}
+
+	user, err := FindUserByID(session, apiToken.OwnerID)
+	if err != nil {
+		return entity.APIToken{}, entity.User{}, err
+	}
+	if user.Disabled {
+		return entity.APIToken{}, entity.User{}, ErrUserDisabled
+	}
+	return apiToken, user, nil
+}
+
+// RemoveUserAPITokens removes all API tokens of the user and returns the removed tokens
+func RemoveUserAPITokens(session *mongo.Session, ownerID bson.ObjectId) ([]entity.APIToken, error) {
+	apiTokens, err := ListAPITokens(session, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := session.C(entity.APITokenCollectionName).RemoveAll(bson.M{"ownerID": ownerID}); err != nil {
+		return nil, err
+	}
+	return apiTokens, nil
+}
@@ -0,0 +1,110 @@
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type APITokenTestSuite struct {
+	suite.Suite
+	sp      *serviceprovider.Container
+	session *mongo.Session
+	user    entity.User
+}
+
+func (suite *APITokenTestSuite) SetupSuite() {
+	cf := config.MustRead("../../../config/testing.json")
+	sp := serviceprovider.NewForTesting(cf)
+
+	suite.sp = sp
+	// init session
+	suite.session = sp.Mongo.NewSession()
+
+	suite.user = entity.User{
+		ID: bson.NewObjectId(),
+		LoginCredential: entity.LoginCredential{
+			Username: namesgenerator.GetRandomName(0) + "@linkernetworks.com",
+			Password: "p@ssw0rd",
+		},
+		DisplayName: "John Doe",
+		Role:        entity.UserRole,
+		FirstName:   "John",
+		LastName:    "Doe",
+		PhoneNumber: "0900000000",
+	}
+	suite.session.Insert(entity.UserCollectionName, &suite.user)
+}
+
+func (suite *APITokenTestSuite) TearDownSuite() {
+	RemoveUserAPITokens(suite.session, suite.user.ID)
+	suite.session.Remove(entity.UserCollectionName, "_id", suite.user.ID)
+}
+
+func TestAPITokenSuite(t *testing.T) {
+	suite.Run(t, new(APITokenTestSuite))
+}
+
+func (suite *APITokenTestSuite) TestAuthenticateAPIToken() {
+	apiToken := entity.APIToken{
+		OwnerID: suite.user.ID,
+		Name:    "ci",
+		Scope:   entity.APITokenReadWrite,
+	}
+	err := CreateAPIToken(suite.session, &apiToken)
+	suite.NoError(err)
+	suite.NotEmpty(apiToken.Token)
+
+	retAPIToken, user, err := AuthenticateAPIToken(suite.session, apiToken.Token)
+	suite.NoError(err)
+	suite.Equal(apiToken.ID, retAPIToken.ID)
+	suite.Equal(suite.user.ID, user.ID)
+
+	_, _, err = AuthenticateAPIToken(suite.session, entity.APITokenPrefix+"invalid")
+	suite.Error(err)
+}
+
+func (suite *APITokenTestSuite) TestAuthenticateExpiredAPIToken() {
+	expiresAt := time.Now().Add(-time.Minute)
+	apiToken := entity.APIToken{
+		OwnerID:   suite.user.ID,
+		Name:      "expired",
+		Scope:     entity.APITokenReadOnly,
+		ExpiresAt: &expiresAt,
+	}
+	err := CreateAPIToken(suite.session, &apiToken)
+	suite.NoError(err)
+
+	_, _, err = AuthenticateAPIToken(suite.session, apiToken.Token)
+	suite.Equal(ErrAPITokenExpired, err)
+}
+
+func (suite *APITokenTestSuite) TestRemoveUserAPITokens() {
+	ownerID := bson.NewObjectId()
+	apiToken := entity.APIToken{
+		OwnerID: ownerID,
+		Name:    "ci",
+		Scope:   entity.APITokenReadOnly,
+	}
+	err := CreateAPIToken(suite.session, &apiToken)
+	suite.NoError(err)
+
+	apiTokens, err := ListAPITokens(suite.session, ownerID)
+	suite.NoError(err)
+	suite.Len(apiTokens, 1)
+
+	removed, err := RemoveUserAPITokens(suite.session, ownerID)
+	suite.NoError(err)
+	suite.Len(removed, 1)
+
+	apiTokens, err = ListAPITokens(suite.session, ownerID)
+	suite.NoError(err)
+	suite.Len(apiTokens, 0)
+}
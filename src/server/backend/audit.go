@@ -0,0 +1,65 @@
+package backend
+
+import (
+	"time"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/entity"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// EnsureAuditIndex lets mongo remove the audit records out of the retention
+func EnsureAuditIndex(session *mongo.Session) error {
+	return session.C(entity.AuditCollectionName).EnsureIndex(mgo.Index{
+		Key:         []string{"expiresAt"},
+		ExpireAfter: time.Second,
+	})
+}
+
+// CreateAuditRecord stores the audit record, the record is kept until its expiresAt if set
+func CreateAuditRecord(session *mongo.Session, record entity.AuditRecord) error {
+	if record.ID == "" {
+		record.ID = bson.NewObjectId()
+	}
+	return session.Insert(entity.AuditCollectionName, &record)
+}
+
+// AuditSelector returns the mongo selector of the audit query
+func AuditSelector(q entity.AuditQuery) bson.M {
+	selector := bson.M{}
+	if q.UserID != "" {
+		selector["userID"] = q.UserID
+	}
+	if q.Resource != "" {
+		selector["resource"] = q.Resource
+	}
+	if q.Outcome != "" {
+		selector["outcome"] = q.Outcome
+	}
+	createdAt := bson.M{}
+	if !q.Since.IsZero() {
+		createdAt["$gte"] = q.Since
+	}
+	if !q.Until.IsZero() {
+		createdAt["$lt"] = q.Until
+	}
+	if len(createdAt) > 0 {
+		selector["createdAt"] = createdAt
+	}
+	return selector
+}
+
+// ListAuditRecords returns a page of the matched audit records, the latest first, and the number of all matched records
+func ListAuditRecords(session *mongo.Session, q entity.AuditQuery, skip, limit int) ([]entity.AuditRecord, int, error) {
+	selector := AuditSelector(q)
+	records := []entity.AuditRecord{}
+	if err := session.C(entity.AuditCollectionName).Find(selector).Sort("-createdAt", "-_id").Skip(skip).Limit(limit).All(&records); err != nil {
+		return nil, 0, err
+	}
+	count, err := session.Count(entity.AuditCollectionName, selector)
+	if err != nil {
+		return nil, 0, err
+	}
+	return records, count, nil
+}
@@ -0,0 +1,87 @@
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestAuditSelector(t *testing.T) {
+	since := time.Now().Add(-time.Hour)
+	selector := AuditSelector(entity.AuditQuery{
+		UserID:   "5b5b418c760aab15e771bde2",
+		Resource: "deployments",
+		Outcome:  entity.AuditFailure,
+		Since:    since,
+	})
+	assert.Equal(t, bson.M{
+		"userID":    "5b5b418c760aab15e771bde2",
+		"resource":  "deployments",
+		"outcome":   entity.AuditFailure,
+		"createdAt": bson.M{"$gte": since},
+	}, selector)
+	assert.Equal(t, bson.M{}, AuditSelector(entity.AuditQuery{}))
+}
+
+type AuditTestSuite struct {
+	suite.Suite
+	sp      *serviceprovider.Container
+	session *mongo.Session
+}
+
+func (suite *AuditTestSuite) SetupSuite() {
+	cf := config.MustRead("../../../config/testing.json")
+	sp := serviceprovider.NewForTesting(cf)
+
+	suite.sp = sp
+	// init session
+	suite.session = sp.Mongo.NewSession()
+}
+
+func (suite *AuditTestSuite) TearDownSuite() {}
+
+func TestAuditSuite(t *testing.T) {
+	suite.Run(t, new(AuditTestSuite))
+}
+
+func (suite *AuditTestSuite) TestListAuditRecords() {
+	userID := bson.NewObjectId().Hex()
+	defer suite.session.C(entity.AuditCollectionName).RemoveAll(bson.M{"userID": userID})
+
+	now := time.Now()
+	for i, outcome := range []string{entity.AuditSuccess, entity.AuditFailure, entity.AuditSuccess} {
+		err := CreateAuditRecord(suite.session, entity.AuditRecord{
+			UserID:    userID,
+			Method:    "DELETE",
+			Route:     "/v1/deployments/{id}",
+			Resource:  "deployments",
+			Outcome:   outcome,
+			CreatedAt: now.Add(time.Duration(i) * time.Minute),
+		})
+		suite.NoError(err)
+	}
+
+	records, count, err := ListAuditRecords(suite.session, entity.AuditQuery{UserID: userID}, 0, 2)
+	suite.NoError(err)
+	suite.Equal(3, count)
+	suite.Len(records, 2)
+	// the latest first
+	suite.True(records[0].CreatedAt.After(records[1].CreatedAt))
+
+	records, count, err = ListAuditRecords(suite.session, entity.AuditQuery{UserID: userID, Outcome: entity.AuditFailure}, 0, 10)
+	suite.NoError(err)
+	suite.Equal(1, count)
+	suite.Len(records, 1)
+
+	records, count, err = ListAuditRecords(suite.session, entity.AuditQuery{UserID: userID, Since: now.Add(30 * time.Second)}, 0, 10)
+	suite.NoError(err)
+	suite.Equal(2, count)
+	suite.Len(records, 2)
+}
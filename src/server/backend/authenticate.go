@@ -7,12 +7,7 @@ import (
 	"gopkg.in/mgo.v2/bson"
 )
 
-// SecreKey is not a real secret key. Using the linker option to replace
-var (
-	SecretKey = "linkernetworks"
-)
-
-// Authenticate is a user authenticate function
+// Authenticate is a user authenticate function, the disabled users get ErrUserDisabled
 func Authenticate(session *mongo.Session, credential entity.LoginCredential) (entity.User, bool, error) {
 	authenticatedUser := entity.User{}
 	if err := session.FindOne(
@@ -24,6 +19,9 @@ func Authenticate(session *mongo.Session, credential entity.LoginCredential) (en
 	}
 	hashedPassword := authenticatedUser.LoginCredential.Password
 	if utils.CheckPasswordHash(credential.Password, hashedPassword) {
+		if authenticatedUser.Disabled {
+			return entity.User{}, false, ErrUserDisabled
+		}
 		return authenticatedUser, true, nil
 	}
 	return entity.User{}, false, nil
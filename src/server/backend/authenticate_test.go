@@ -85,3 +85,26 @@ func (suite *AuthenticateTestSuite) TestFailedAuthenticate() {
 	suite.NoError(err)
 	suite.False(passed)
 }
+
+func (suite *AuthenticateTestSuite) TestDisabledAuthenticate() {
+	hashedPassword, err := utils.HashPassword(suite.plainTextPassword)
+	suite.NoError(err)
+	user := entity.User{
+		ID: bson.NewObjectId(),
+		LoginCredential: entity.LoginCredential{
+			Username: "disabled@linkernetworks.com",
+			Password: hashedPassword,
+		},
+		DisplayName: "John Doe",
+		Disabled:    true,
+	}
+	suite.NoError(suite.session.Insert(entity.UserCollectionName, &user))
+	defer suite.session.Remove(entity.UserCollectionName, "_id", user.ID)
+
+	_, passed, err := Authenticate(suite.session, entity.LoginCredential{
+		Username: "disabled@linkernetworks.com",
+		Password: suite.plainTextPassword,
+	})
+	suite.Equal(ErrUserDisabled, err)
+	suite.False(passed)
+}
@@ -0,0 +1,81 @@
+package backend
+
+import (
+	"time"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/entity"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// CreateDeploymentRevision stores the revision with the next revision number of the deployment
+func CreateDeploymentRevision(session *mongo.Session, revision entity.DeploymentRevision) (entity.DeploymentRevision, error) {
+	c := session.C(entity.DeploymentRevisionCollectionName)
+	c.EnsureIndex(mgo.Index{
+		Key:    []string{"deploymentID", "revision"},
+		Unique: true,
+	})
+
+	// the spec is owned by the deployment
+	revision.Spec.CreatedBy = entity.User{}
+	revision.CreatedAt = time.Now()
+	for {
+		latest := entity.DeploymentRevision{}
+		err := c.Find(bson.M{"deploymentID": revision.DeploymentID}).Sort("-revision").Select(bson.M{"revision": 1}).One(&latest)
+		if err != nil && err != mgo.ErrNotFound {
+			return revision, err
+		}
+		revision.ID = bson.NewObjectId()
+		revision.Revision = latest.Revision + 1
+		err = c.Insert(&revision)
+		// retry with the next number if another revision took it
+		if mgo.IsDup(err) {
+			continue
+		}
+		return revision, err
+	}
+}
+
+// ListDeploymentRevisions returns the revisions of the deployment, the latest first
+func ListDeploymentRevisions(session *mongo.Session, deploymentID bson.ObjectId) ([]entity.DeploymentRevision, error) {
+	revisions := []entity.DeploymentRevision{}
+	if err := session.C(entity.DeploymentRevisionCollectionName).Find(bson.M{"deploymentID": deploymentID}).Sort("-revision").All(&revisions); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// LatestDeploymentRevision returns the latest revision of the deployment, mgo.ErrNotFound if it has none
+func LatestDeploymentRevision(session *mongo.Session, deploymentID bson.ObjectId) (entity.DeploymentRevision, error) {
+	r := entity.DeploymentRevision{}
+	if err := session.C(entity.DeploymentRevisionCollectionName).Find(bson.M{"deploymentID": deploymentID}).Sort("-revision").One(&r); err != nil {
+		return entity.DeploymentRevision{}, err
+	}
+	return r, nil
+}
+
+// FindDeploymentRevision returns the revision of the deployment
+func FindDeploymentRevision(session *mongo.Session, deploymentID bson.ObjectId, revision int) (entity.DeploymentRevision, error) {
+	r := entity.DeploymentRevision{}
+	if err := session.FindOne(entity.DeploymentRevisionCollectionName, bson.M{
+		"deploymentID": deploymentID,
+		"revision":     revision,
+	}, &r); err != nil {
+		return entity.DeploymentRevision{}, err
+	}
+	return r, nil
+}
+
+// SetReplicaSetRevision records the replica set revision Kubernetes assigned to the revision
+func SetReplicaSetRevision(session *mongo.Session, ID bson.ObjectId, replicaSetRevision string) error {
+	return session.C(entity.DeploymentRevisionCollectionName).UpdateId(ID, bson.M{
+		"$set": bson.M{"replicaSetRevision": replicaSetRevision},
+	})
+}
+
+// RemoveDeploymentRevisions removes all the revisions of the deployment
+func RemoveDeploymentRevisions(session *mongo.Session, deploymentID bson.ObjectId) error {
+	_, err := session.C(entity.DeploymentRevisionCollectionName).RemoveAll(bson.M{"deploymentID": deploymentID})
+	return err
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/linkernetworks/vortex/src/serviceprovider"
 	"github.com/moby/moby/pkg/namesgenerator"
 	"github.com/stretchr/testify/suite"
+	mgo "gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 )
 
@@ -83,3 +84,51 @@ func (suite *DeploymentTestSuite) TestFindDeploymentByName() {
 	deploy, err = FindDeploymentByName(suite.session, "nonono")
 	suite.Error(err)
 }
+
+func (suite *DeploymentTestSuite) TestDeploymentRevisions() {
+	deploymentID := bson.NewObjectId()
+	defer RemoveDeploymentRevisions(suite.session, deploymentID)
+
+	for _, image := range []string{"busybox:1.28", "busybox:1.29"} {
+		revision, err := CreateDeploymentRevision(suite.session, entity.DeploymentRevision{
+			DeploymentID: deploymentID,
+			Spec: entity.Deployment{
+				ID:         deploymentID,
+				Containers: []entity.Container{{Name: "busybox", Image: image}},
+			},
+		})
+		suite.NoError(err)
+		suite.Equal(image, revision.Spec.Containers[0].Image)
+	}
+
+	revisions, err := ListDeploymentRevisions(suite.session, deploymentID)
+	suite.NoError(err)
+	suite.Len(revisions, 2)
+	suite.Equal(2, revisions[0].Revision)
+	suite.Equal(1, revisions[1].Revision)
+
+	latest, err := LatestDeploymentRevision(suite.session, deploymentID)
+	suite.NoError(err)
+	suite.Equal(2, latest.Revision)
+	_, err = LatestDeploymentRevision(suite.session, bson.NewObjectId())
+	suite.Equal(mgo.ErrNotFound, err)
+
+	revision, err := FindDeploymentRevision(suite.session, deploymentID, 1)
+	suite.NoError(err)
+	suite.Equal("busybox:1.28", revision.Spec.Containers[0].Image)
+
+	err = SetReplicaSetRevision(suite.session, revision.ID, "1")
+	suite.NoError(err)
+	revision, err = FindDeploymentRevision(suite.session, deploymentID, 1)
+	suite.NoError(err)
+	suite.Equal("1", revision.ReplicaSetRevision)
+
+	_, err = FindDeploymentRevision(suite.session, deploymentID, 3)
+	suite.Error(err)
+
+	err = RemoveDeploymentRevisions(suite.session, deploymentID)
+	suite.NoError(err)
+	revisions, err = ListDeploymentRevisions(suite.session, deploymentID)
+	suite.NoError(err)
+	suite.Empty(revisions)
+}
@@ -0,0 +1,97 @@
+package backend
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const defaultLockoutWindow = 15 * time.Minute
+
+// lockoutDurations parses the window and the lock duration, the duration is 0 if root has to unlock the user
+func lockoutDurations(cf *config.LockoutConfig) (time.Duration, time.Duration, error) {
+	window := defaultLockoutWindow
+	var duration time.Duration
+	var err error
+	if cf.Window != "" {
+		if window, err = time.ParseDuration(cf.Window); err != nil {
+			return 0, 0, fmt.Errorf("Invalid lockout window: %v", err)
+		}
+	}
+	if cf.Duration != "" {
+		if duration, err = time.ParseDuration(cf.Duration); err != nil {
+			return 0, 0, fmt.Errorf("Invalid lockout duration: %v", err)
+		}
+	}
+	return window, duration, nil
+}
+
+// IsLockedOut reports whether the user is locked out now
+func IsLockedOut(cf *config.LockoutConfig, user entity.User) (bool, error) {
+	if cf == nil || cf.MaxAttempts <= 0 || user.LockedAt == nil {
+		return false, nil
+	}
+	_, duration, err := lockoutDurations(cf)
+	if err != nil {
+		return false, err
+	}
+	return duration == 0 || time.Now().Before(user.LockedAt.Add(duration)), nil
+}
+
+// RecordFailedLogin counts a failed sign in of the username and locks the user out at the max attempts.
+// The count restarts when the window of the first failure has passed or the previous lock has expired.
+func RecordFailedLogin(session *mongo.Session, cf *config.LockoutConfig, username string) error {
+	if cf == nil || cf.MaxAttempts <= 0 {
+		return nil
+	}
+	window, _, err := lockoutDurations(cf)
+	if err != nil {
+		return err
+	}
+
+	user := entity.User{}
+	if err := session.FindOne(entity.UserCollectionName, bson.M{"loginCredential.username": username}, &user); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+
+	now := time.Now()
+	failedLogins := 1
+	if user.FailedLoginAt == nil || now.Sub(*user.FailedLoginAt) > window || user.LockedAt != nil {
+		if err := session.C(entity.UserCollectionName).UpdateId(user.ID, bson.M{
+			"$set":   bson.M{"failedLogins": 1, "failedLoginAt": now},
+			"$unset": bson.M{"lockedAt": ""},
+		}); err != nil {
+			return err
+		}
+	} else {
+		// count the concurrent failures atomically
+		updated := entity.User{}
+		if _, err := session.C(entity.UserCollectionName).FindId(user.ID).Apply(mgo.Change{
+			Update:    bson.M{"$inc": bson.M{"failedLogins": 1}},
+			ReturnNew: true,
+		}, &updated); err != nil {
+			return err
+		}
+		failedLogins = updated.FailedLogins
+	}
+
+	if failedLogins >= cf.MaxAttempts {
+		return session.C(entity.UserCollectionName).UpdateId(user.ID, bson.M{"$set": bson.M{"lockedAt": now}})
+	}
+	return nil
+}
+
+// UnlockUser clears the lock and the failed sign in of the user
+func UnlockUser(session *mongo.Session, userID bson.ObjectId) error {
+	return session.C(entity.UserCollectionName).UpdateId(userID, bson.M{
+		"$unset": bson.M{"failedLogins": "", "failedLoginAt": "", "lockedAt": ""},
+	})
+}
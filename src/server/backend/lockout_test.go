@@ -0,0 +1,102 @@
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type LockoutTestSuite struct {
+	suite.Suite
+	sp      *serviceprovider.Container
+	session *mongo.Session
+}
+
+func (suite *LockoutTestSuite) SetupSuite() {
+	cf := config.MustRead("../../../config/testing.json")
+	sp := serviceprovider.NewForTesting(cf)
+
+	suite.sp = sp
+	// init session
+	suite.session = sp.Mongo.NewSession()
+}
+
+func (suite *LockoutTestSuite) TearDownSuite() {}
+
+func TestLockoutSuite(t *testing.T) {
+	suite.Run(t, new(LockoutTestSuite))
+}
+
+func (suite *LockoutTestSuite) createUser() entity.User {
+	user := entity.User{
+		ID: bson.NewObjectId(),
+		LoginCredential: entity.LoginCredential{
+			Username: namesgenerator.GetRandomName(0) + "@linkernetworks.com",
+		},
+		Role: entity.UserRole,
+	}
+	suite.NoError(suite.session.Insert(entity.UserCollectionName, &user))
+	return user
+}
+
+func (suite *LockoutTestSuite) findUser(id bson.ObjectId) entity.User {
+	user := entity.User{}
+	suite.NoError(suite.session.FindOne(entity.UserCollectionName, bson.M{"_id": id}, &user))
+	return user
+}
+
+func (suite *LockoutTestSuite) TestLockAndUnlock() {
+	user := suite.createUser()
+	defer suite.session.Remove(entity.UserCollectionName, "_id", user.ID)
+
+	cf := &config.LockoutConfig{MaxAttempts: 3, Window: "15m"}
+	for i := 0; i < 2; i++ {
+		suite.NoError(RecordFailedLogin(suite.session, cf, user.LoginCredential.Username))
+	}
+	locked, err := IsLockedOut(cf, suite.findUser(user.ID))
+	suite.NoError(err)
+	suite.False(locked)
+
+	suite.NoError(RecordFailedLogin(suite.session, cf, user.LoginCredential.Username))
+	locked, err = IsLockedOut(cf, suite.findUser(user.ID))
+	suite.NoError(err)
+	suite.True(locked)
+
+	suite.NoError(UnlockUser(suite.session, user.ID))
+	unlocked := suite.findUser(user.ID)
+	suite.Nil(unlocked.LockedAt)
+	suite.Equal(0, unlocked.FailedLogins)
+	locked, err = IsLockedOut(cf, unlocked)
+	suite.NoError(err)
+	suite.False(locked)
+}
+
+func (suite *LockoutTestSuite) TestLockExpires() {
+	cf := &config.LockoutConfig{MaxAttempts: 1, Duration: "10m"}
+	lockedAt := time.Now().Add(-time.Minute)
+	locked, err := IsLockedOut(cf, entity.User{LockedAt: &lockedAt})
+	suite.NoError(err)
+	suite.True(locked)
+
+	lockedAt = time.Now().Add(-time.Hour)
+	locked, err = IsLockedOut(cf, entity.User{LockedAt: &lockedAt})
+	suite.NoError(err)
+	suite.False(locked)
+
+	// the lockout is disabled
+	locked, err = IsLockedOut(nil, entity.User{LockedAt: &lockedAt})
+	suite.NoError(err)
+	suite.False(locked)
+}
+
+func (suite *LockoutTestSuite) TestUnknownUser() {
+	cf := &config.LockoutConfig{MaxAttempts: 3}
+	suite.NoError(RecordFailedLogin(suite.session, cf, "nobody@linkernetworks.com"))
+}
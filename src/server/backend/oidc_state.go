@@ -0,0 +1,101 @@
+package backend
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/utils/timeutils"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/utils"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// CreateOIDCState stores the state and nonce of a new OpenID Connect login
+func CreateOIDCState(session *mongo.Session, expiry time.Duration) (entity.OIDCState, error) {
+	state, err := utils.RandomToken(16)
+	if err != nil {
+		return entity.OIDCState{}, err
+	}
+	nonce, err := utils.RandomToken(16)
+	if err != nil {
+		return entity.OIDCState{}, err
+	}
+
+	// let mongo remove the abandoned logins
+	session.C(entity.OIDCStateCollectionName).EnsureIndex(mgo.Index{
+		Key:         []string{"expiresAt"},
+		ExpireAfter: time.Second,
+	})
+
+	oidcState := entity.OIDCState{
+		ID:        bson.NewObjectId(),
+		State:     state,
+		Nonce:     nonce,
+		ExpiresAt: time.Now().Add(expiry),
+		CreatedAt: timeutils.Now(),
+	}
+	if err := session.Insert(entity.OIDCStateCollectionName, &oidcState); err != nil {
+		return entity.OIDCState{}, err
+	}
+	return oidcState, nil
+}
+
+// ConsumeOIDCState removes the state and returns it if it's not expired.
+// A state can only be used once.
+func ConsumeOIDCState(session *mongo.Session, state string) (entity.OIDCState, error) {
+	oidcState := entity.OIDCState{}
+	if _, err := session.C(entity.OIDCStateCollectionName).Find(
+		bson.M{"state": state},
+	).Apply(mgo.Change{Remove: true}, &oidcState); err != nil {
+		return entity.OIDCState{}, err
+	}
+
+	if time.Now().After(oidcState.ExpiresAt) {
+		return entity.OIDCState{}, fmt.Errorf("OIDC state is expired")
+	}
+	return oidcState, nil
+}
+
+// CreateOIDCLoginCode stores a one-time code of the user and returns the code
+func CreateOIDCLoginCode(session *mongo.Session, userID bson.ObjectId, expiry time.Duration) (string, error) {
+	code, err := utils.RandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	// let mongo remove the unused codes
+	session.C(entity.OIDCLoginCodeCollectionName).EnsureIndex(mgo.Index{
+		Key:         []string{"expiresAt"},
+		ExpireAfter: time.Second,
+	})
+
+	loginCode := entity.OIDCLoginCode{
+		ID:        bson.NewObjectId(),
+		UserID:    userID,
+		CodeHash:  utils.SHA256String(code),
+		ExpiresAt: time.Now().Add(expiry),
+		CreatedAt: timeutils.Now(),
+	}
+	if err := session.Insert(entity.OIDCLoginCodeCollectionName, &loginCode); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// ConsumeOIDCLoginCode removes the code and returns it if it's not expired.
+// A code can only be used once.
+func ConsumeOIDCLoginCode(session *mongo.Session, code string) (entity.OIDCLoginCode, error) {
+	loginCode := entity.OIDCLoginCode{}
+	if _, err := session.C(entity.OIDCLoginCodeCollectionName).Find(
+		bson.M{"codeHash": utils.SHA256String(code)},
+	).Apply(mgo.Change{Remove: true}, &loginCode); err != nil {
+		return entity.OIDCLoginCode{}, err
+	}
+
+	if time.Now().After(loginCode.ExpiresAt) {
+		return entity.OIDCLoginCode{}, fmt.Errorf("OIDC login code is expired")
+	}
+	return loginCode, nil
+}
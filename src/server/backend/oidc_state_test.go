@@ -0,0 +1,76 @@
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type OIDCStateTestSuite struct {
+	suite.Suite
+	sp      *serviceprovider.Container
+	session *mongo.Session
+}
+
+func (suite *OIDCStateTestSuite) SetupSuite() {
+	cf := config.MustRead("../../../config/testing.json")
+	sp := serviceprovider.NewForTesting(cf)
+
+	suite.sp = sp
+	// init session
+	suite.session = sp.Mongo.NewSession()
+}
+
+func (suite *OIDCStateTestSuite) TearDownSuite() {}
+
+func TestOIDCStateSuite(t *testing.T) {
+	suite.Run(t, new(OIDCStateTestSuite))
+}
+
+func (suite *OIDCStateTestSuite) TestConsumeOIDCState() {
+	oidcState, err := CreateOIDCState(suite.session, time.Minute)
+	suite.NoError(err)
+	suite.NotEmpty(oidcState.State)
+	suite.NotEmpty(oidcState.Nonce)
+
+	consumed, err := ConsumeOIDCState(suite.session, oidcState.State)
+	suite.NoError(err)
+	suite.Equal(oidcState.Nonce, consumed.Nonce)
+
+	// consumed already
+	_, err = ConsumeOIDCState(suite.session, oidcState.State)
+	suite.Error(err)
+}
+
+func (suite *OIDCStateTestSuite) TestConsumeExpiredOIDCState() {
+	oidcState, err := CreateOIDCState(suite.session, -time.Minute)
+	suite.NoError(err)
+
+	_, err = ConsumeOIDCState(suite.session, oidcState.State)
+	suite.Error(err)
+}
+
+func (suite *OIDCStateTestSuite) TestConsumeOIDCLoginCode() {
+	userID := bson.NewObjectId()
+	code, err := CreateOIDCLoginCode(suite.session, userID, time.Minute)
+	suite.NoError(err)
+	suite.NotEmpty(code)
+
+	loginCode, err := ConsumeOIDCLoginCode(suite.session, code)
+	suite.NoError(err)
+	suite.Equal(userID, loginCode.UserID)
+
+	// consumed already
+	_, err = ConsumeOIDCLoginCode(suite.session, code)
+	suite.Error(err)
+
+	code, err = CreateOIDCLoginCode(suite.session, userID, -time.Minute)
+	suite.NoError(err)
+	_, err = ConsumeOIDCLoginCode(suite.session, code)
+	suite.Error(err)
+}
@@ -0,0 +1,98 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/utils"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// CheckPasswordPolicy checks the new password of the user against the policy and the previous passwords.
+// Every password is accepted if there is no policy.
+func CheckPasswordPolicy(policy *config.PasswordPolicyConfig, user entity.User, password string) error {
+	if policy == nil {
+		return nil
+	}
+
+	problems := []string{}
+	if len([]rune(password)) < policy.MinLength {
+		problems = append(problems, fmt.Sprintf("at least %d characters", policy.MinLength))
+	}
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if policy.RequireUpper && !upper {
+		problems = append(problems, "an upper case letter")
+	}
+	if policy.RequireLower && !lower {
+		problems = append(problems, "a lower case letter")
+	}
+	if policy.RequireDigit && !digit {
+		problems = append(problems, "a digit")
+	}
+	if policy.RequireSymbol && !symbol {
+		problems = append(problems, "a symbol")
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("The password must contain %s", strings.Join(problems, ", "))
+	}
+
+	if policy.HistorySize > 0 {
+		previous := append([]string{user.LoginCredential.Password}, user.PasswordHistory...)
+		for i, hash := range previous {
+			if i >= policy.HistorySize {
+				break
+			}
+			if hash != "" && utils.CheckPasswordHash(password, hash) {
+				return fmt.Errorf("The password can't be one of the last %d passwords", policy.HistorySize)
+			}
+		}
+	}
+	return nil
+}
+
+// UpdatePassword sets the new password of the user, keeps the current one in the history
+// and clears the must change password flag of the user and the sessions
+func UpdatePassword(session *mongo.Session, user entity.User, password string, historySize int) error {
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	// the new password is the first of the last passwords
+	history := []string{}
+	if historySize > 1 && user.LoginCredential.Password != "" {
+		history = append([]string{user.LoginCredential.Password}, user.PasswordHistory...)
+		if len(history) > historySize-1 {
+			history = history[:historySize-1]
+		}
+	}
+
+	if err := session.C(entity.UserCollectionName).UpdateId(user.ID, bson.M{"$set": bson.M{
+		"loginCredential.password": hashedPassword,
+		"passwordHistory":          history,
+		"mustChangePassword":       false,
+	}}); err != nil {
+		return err
+	}
+	_, err = session.C(entity.SessionCollectionName).UpdateAll(
+		bson.M{"userID": user.ID},
+		bson.M{"$unset": bson.M{"mustChangePassword": ""}},
+	)
+	return err
+}
@@ -0,0 +1,83 @@
+package backend
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/utils/timeutils"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/utils"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// CreatePasswordResetToken generates a one-time password reset token of the user.
+// The previous reset tokens of the user are removed.
+func CreatePasswordResetToken(session *mongo.Session, userID bson.ObjectId, expiry time.Duration) (entity.PasswordResetToken, error) {
+	token, err := utils.RandomToken(32)
+	if err != nil {
+		return entity.PasswordResetToken{}, err
+	}
+
+	c := session.C(entity.PasswordResetTokenCollectionName)
+	c.EnsureIndex(mgo.Index{
+		Key:    []string{"tokenHash"},
+		Unique: true,
+	})
+	// let mongo remove the unused tokens
+	c.EnsureIndex(mgo.Index{
+		Key:         []string{"expiresAt"},
+		ExpireAfter: time.Second,
+	})
+
+	if _, err := c.RemoveAll(bson.M{"userID": userID}); err != nil {
+		return entity.PasswordResetToken{}, err
+	}
+
+	resetToken := entity.PasswordResetToken{
+		ID:        bson.NewObjectId(),
+		UserID:    userID,
+		Token:     token,
+		TokenHash: utils.SHA256String(token),
+		ExpiresAt: time.Now().Add(expiry),
+		CreatedAt: timeutils.Now(),
+	}
+	if err := session.Insert(entity.PasswordResetTokenCollectionName, &resetToken); err != nil {
+		return entity.PasswordResetToken{}, err
+	}
+	return resetToken, nil
+}
+
+// FindPasswordResetToken returns the reset token if it's not expired
+func FindPasswordResetToken(session *mongo.Session, token string) (entity.PasswordResetToken, error) {
+	resetToken := entity.PasswordResetToken{}
+	if err := session.FindOne(
+		entity.PasswordResetTokenCollectionName,
+		bson.M{"tokenHash": utils.SHA256String(token)},
+		&resetToken,
+	); err != nil {
+		return entity.PasswordResetToken{}, err
+	}
+
+	if time.Now().After(resetToken.ExpiresAt) {
+		return entity.PasswordResetToken{}, fmt.Errorf("Password reset token is expired")
+	}
+	return resetToken, nil
+}
+
+// ConsumePasswordResetToken removes the reset token and returns it if it's not expired.
+// A reset token can only be used once.
+func ConsumePasswordResetToken(session *mongo.Session, token string) (entity.PasswordResetToken, error) {
+	resetToken := entity.PasswordResetToken{}
+	if _, err := session.C(entity.PasswordResetTokenCollectionName).Find(
+		bson.M{"tokenHash": utils.SHA256String(token)},
+	).Apply(mgo.Change{Remove: true}, &resetToken); err != nil {
+		return entity.PasswordResetToken{}, err
+	}
+
+	if time.Now().After(resetToken.ExpiresAt) {
+		return entity.PasswordResetToken{}, fmt.Errorf("Password reset token is expired")
+	}
+	return resetToken, nil
+}
@@ -0,0 +1,83 @@
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type PasswordResetTestSuite struct {
+	suite.Suite
+	sp      *serviceprovider.Container
+	session *mongo.Session
+}
+
+func (suite *PasswordResetTestSuite) SetupSuite() {
+	cf := config.MustRead("../../../config/testing.json")
+	sp := serviceprovider.NewForTesting(cf)
+
+	suite.sp = sp
+	// init session
+	suite.session = sp.Mongo.NewSession()
+}
+
+func (suite *PasswordResetTestSuite) TearDownSuite() {}
+
+func TestPasswordResetSuite(t *testing.T) {
+	suite.Run(t, new(PasswordResetTestSuite))
+}
+
+func (suite *PasswordResetTestSuite) TestConsumePasswordResetToken() {
+	userID := bson.NewObjectId()
+	defer suite.session.C(entity.PasswordResetTokenCollectionName).RemoveAll(bson.M{"userID": userID})
+
+	resetToken, err := CreatePasswordResetToken(suite.session, userID, time.Hour)
+	suite.NoError(err)
+	suite.NotEmpty(resetToken.Token)
+
+	found, err := FindPasswordResetToken(suite.session, resetToken.Token)
+	suite.NoError(err)
+	suite.Equal(userID, found.UserID)
+
+	consumed, err := ConsumePasswordResetToken(suite.session, resetToken.Token)
+	suite.NoError(err)
+	suite.Equal(userID, consumed.UserID)
+
+	// a reset token can only be used once
+	_, err = ConsumePasswordResetToken(suite.session, resetToken.Token)
+	suite.Error(err)
+}
+
+func (suite *PasswordResetTestSuite) TestOnlyLatestToken() {
+	userID := bson.NewObjectId()
+	defer suite.session.C(entity.PasswordResetTokenCollectionName).RemoveAll(bson.M{"userID": userID})
+
+	first, err := CreatePasswordResetToken(suite.session, userID, time.Hour)
+	suite.NoError(err)
+	second, err := CreatePasswordResetToken(suite.session, userID, time.Hour)
+	suite.NoError(err)
+
+	_, err = FindPasswordResetToken(suite.session, first.Token)
+	suite.Error(err)
+	_, err = FindPasswordResetToken(suite.session, second.Token)
+	suite.NoError(err)
+}
+
+func (suite *PasswordResetTestSuite) TestExpiredToken() {
+	userID := bson.NewObjectId()
+	defer suite.session.C(entity.PasswordResetTokenCollectionName).RemoveAll(bson.M{"userID": userID})
+
+	resetToken, err := CreatePasswordResetToken(suite.session, userID, -time.Minute)
+	suite.NoError(err)
+
+	_, err = FindPasswordResetToken(suite.session, resetToken.Token)
+	suite.Error(err)
+	_, err = ConsumePasswordResetToken(suite.session, resetToken.Token)
+	suite.Error(err)
+}
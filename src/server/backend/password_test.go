@@ -0,0 +1,112 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/linkernetworks/vortex/src/utils"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestCheckPasswordPolicy(t *testing.T) {
+	policy := &config.PasswordPolicyConfig{
+		MinLength:     8,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+	}
+	user := entity.User{}
+
+	assert.NoError(t, CheckPasswordPolicy(nil, user, "a"))
+	assert.NoError(t, CheckPasswordPolicy(policy, user, "P@ssw0rd"))
+	assert.Error(t, CheckPasswordPolicy(policy, user, "P@ssw0"))
+	assert.Error(t, CheckPasswordPolicy(policy, user, "p@ssw0rd"))
+	assert.Error(t, CheckPasswordPolicy(policy, user, "P@SSW0RD"))
+	assert.Error(t, CheckPasswordPolicy(policy, user, "P@ssword"))
+	assert.Error(t, CheckPasswordPolicy(policy, user, "Passw0rd"))
+}
+
+func TestCheckPasswordPolicyHistory(t *testing.T) {
+	current, err := utils.HashPassword("current")
+	assert.NoError(t, err)
+	previous, err := utils.HashPassword("previous")
+	assert.NoError(t, err)
+	oldest, err := utils.HashPassword("oldest")
+	assert.NoError(t, err)
+
+	user := entity.User{
+		LoginCredential: entity.LoginCredential{Password: current},
+		PasswordHistory: []string{previous, oldest},
+	}
+	policy := &config.PasswordPolicyConfig{HistorySize: 2}
+	assert.Error(t, CheckPasswordPolicy(policy, user, "current"))
+	assert.Error(t, CheckPasswordPolicy(policy, user, "previous"))
+	assert.NoError(t, CheckPasswordPolicy(policy, user, "oldest"))
+	assert.NoError(t, CheckPasswordPolicy(policy, user, "new"))
+}
+
+type PasswordTestSuite struct {
+	suite.Suite
+	sp      *serviceprovider.Container
+	session *mongo.Session
+}
+
+func (suite *PasswordTestSuite) SetupSuite() {
+	cf := config.MustRead("../../../config/testing.json")
+	sp := serviceprovider.NewForTesting(cf)
+
+	suite.sp = sp
+	// init session
+	suite.session = sp.Mongo.NewSession()
+}
+
+func (suite *PasswordTestSuite) TearDownSuite() {}
+
+func TestPasswordSuite(t *testing.T) {
+	suite.Run(t, new(PasswordTestSuite))
+}
+
+func (suite *PasswordTestSuite) TestUpdatePassword() {
+	hashedPassword, err := utils.HashPassword("first")
+	suite.NoError(err)
+	user := entity.User{
+		ID: bson.NewObjectId(),
+		LoginCredential: entity.LoginCredential{
+			Username: namesgenerator.GetRandomName(0) + "@linkernetworks.com",
+			Password: hashedPassword,
+		},
+		Role:               entity.UserRole,
+		MustChangePassword: true,
+	}
+	suite.NoError(suite.session.Insert(entity.UserCollectionName, &user))
+	defer suite.session.Remove(entity.UserCollectionName, "_id", user.ID)
+
+	userSession := entity.Session{ID: bson.NewObjectId(), UserID: user.ID, MustChangePassword: true}
+	suite.NoError(CreateSession(suite.session, userSession))
+	defer suite.session.Remove(entity.SessionCollectionName, "_id", userSession.ID)
+
+	policy := &config.PasswordPolicyConfig{HistorySize: 2}
+	for _, password := range []string{"second", "third"} {
+		suite.NoError(suite.session.FindOne(entity.UserCollectionName, bson.M{"_id": user.ID}, &user))
+		suite.NoError(CheckPasswordPolicy(policy, user, password))
+		suite.NoError(UpdatePassword(suite.session, user, password, policy.HistorySize))
+	}
+
+	suite.NoError(suite.session.FindOne(entity.UserCollectionName, bson.M{"_id": user.ID}, &user))
+	suite.True(utils.CheckPasswordHash("third", user.LoginCredential.Password))
+	suite.False(user.MustChangePassword)
+	suite.Len(user.PasswordHistory, 1)
+	suite.Error(CheckPasswordPolicy(policy, user, "second"))
+	suite.NoError(CheckPasswordPolicy(policy, user, "first"))
+
+	updated := entity.Session{}
+	suite.NoError(suite.session.FindOne(entity.SessionCollectionName, bson.M{"_id": userSession.ID}, &updated))
+	suite.False(updated.MustChangePassword)
+}
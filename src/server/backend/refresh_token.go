@@ -0,0 +1,62 @@
+package backend
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/utils/timeutils"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/utils"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// CreateRefreshToken stores a new refresh token of the user session and returns the token
+func CreateRefreshToken(session *mongo.Session, userID bson.ObjectId, sessionID bson.ObjectId, expiry time.Duration) (string, error) {
+	token, err := utils.RandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	// let mongo remove the expired tokens
+	session.C(entity.RefreshTokenCollectionName).EnsureIndex(mgo.Index{
+		Key:         []string{"expiresAt"},
+		ExpireAfter: time.Second,
+	})
+
+	refreshToken := entity.RefreshToken{
+		ID:        bson.NewObjectId(),
+		UserID:    userID,
+		SessionID: sessionID,
+		TokenHash: utils.SHA256String(token),
+		ExpiresAt: time.Now().Add(expiry),
+		CreatedAt: timeutils.Now(),
+	}
+	if err := session.Insert(entity.RefreshTokenCollectionName, &refreshToken); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// ConsumeRefreshToken removes the refresh token and returns it if it's not expired.
+// A refresh token can only be used once.
+func ConsumeRefreshToken(session *mongo.Session, token string) (entity.RefreshToken, error) {
+	refreshToken := entity.RefreshToken{}
+	if _, err := session.C(entity.RefreshTokenCollectionName).Find(
+		bson.M{"tokenHash": utils.SHA256String(token)},
+	).Apply(mgo.Change{Remove: true}, &refreshToken); err != nil {
+		return entity.RefreshToken{}, err
+	}
+
+	if time.Now().After(refreshToken.ExpiresAt) {
+		return entity.RefreshToken{}, fmt.Errorf("Refresh token is expired")
+	}
+	return refreshToken, nil
+}
+
+// RemoveRefreshTokens removes all refresh tokens of the user
+func RemoveRefreshTokens(session *mongo.Session, userID bson.ObjectId) error {
+	_, err := session.C(entity.RefreshTokenCollectionName).RemoveAll(bson.M{"userID": userID})
+	return err
+}
@@ -0,0 +1,78 @@
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type RefreshTokenTestSuite struct {
+	suite.Suite
+	sp      *serviceprovider.Container
+	session *mongo.Session
+}
+
+func (suite *RefreshTokenTestSuite) SetupSuite() {
+	cf := config.MustRead("../../../config/testing.json")
+	sp := serviceprovider.NewForTesting(cf)
+
+	suite.sp = sp
+	// init session
+	suite.session = sp.Mongo.NewSession()
+}
+
+func (suite *RefreshTokenTestSuite) TearDownSuite() {}
+
+func TestRefreshTokenSuite(t *testing.T) {
+	suite.Run(t, new(RefreshTokenTestSuite))
+}
+
+func (suite *RefreshTokenTestSuite) TestConsumeRefreshToken() {
+	userID := bson.NewObjectId()
+	defer RemoveRefreshTokens(suite.session, userID)
+
+	token, err := CreateRefreshToken(suite.session, userID, bson.NewObjectId(), time.Hour)
+	suite.NoError(err)
+	suite.NotEmpty(token)
+
+	refreshToken, err := ConsumeRefreshToken(suite.session, token)
+	suite.NoError(err)
+	suite.Equal(userID, refreshToken.UserID)
+
+	// consumed already
+	_, err = ConsumeRefreshToken(suite.session, token)
+	suite.Error(err)
+}
+
+func (suite *RefreshTokenTestSuite) TestConsumeExpiredRefreshToken() {
+	userID := bson.NewObjectId()
+	defer RemoveRefreshTokens(suite.session, userID)
+
+	token, err := CreateRefreshToken(suite.session, userID, bson.NewObjectId(), -time.Hour)
+	suite.NoError(err)
+
+	_, err = ConsumeRefreshToken(suite.session, token)
+	suite.Error(err)
+}
+
+func (suite *RefreshTokenTestSuite) TestRemoveRefreshTokens() {
+	userID := bson.NewObjectId()
+	token, err := CreateRefreshToken(suite.session, userID, bson.NewObjectId(), time.Hour)
+	suite.NoError(err)
+
+	err = RemoveRefreshTokens(suite.session, userID)
+	suite.NoError(err)
+
+	count, err := suite.session.Count(entity.RefreshTokenCollectionName, bson.M{"userID": userID})
+	suite.NoError(err)
+	suite.Equal(0, count)
+
+	_, err = ConsumeRefreshToken(suite.session, token)
+	suite.Error(err)
+}
@@ -0,0 +1,88 @@
+package backend
+
+import (
+	"time"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/entity"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// CreateSession stores a new session
+func CreateSession(session *mongo.Session, s entity.Session) error {
+	// let mongo remove the expired sessions
+	session.C(entity.SessionCollectionName).EnsureIndex(mgo.Index{
+		Key:         []string{"expiresAt"},
+		ExpireAfter: time.Second,
+	})
+	return session.Insert(entity.SessionCollectionName, &s)
+}
+
+// IsSessionActive checks the session exists and is not expired
+func IsSessionActive(session *mongo.Session, ID bson.ObjectId) (bool, error) {
+	count, err := session.Count(entity.SessionCollectionName, bson.M{
+		"_id":       ID,
+		"expiresAt": bson.M{"$gt": time.Now()},
+	})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// FindActiveSession returns the session if it exists and is not expired
+func FindActiveSession(session *mongo.Session, ID bson.ObjectId) (entity.Session, error) {
+	s := entity.Session{}
+	if err := session.FindOne(entity.SessionCollectionName, bson.M{
+		"_id":       ID,
+		"expiresAt": bson.M{"$gt": time.Now()},
+	}, &s); err != nil {
+		return entity.Session{}, err
+	}
+	return s, nil
+}
+
+// ListActiveSessions returns the sessions of the user which are not expired
+func ListActiveSessions(session *mongo.Session, userID bson.ObjectId) ([]entity.Session, error) {
+	sessions := []entity.Session{}
+	if err := session.FindAll(entity.SessionCollectionName, bson.M{
+		"userID":    userID,
+		"expiresAt": bson.M{"$gt": time.Now()},
+	}, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// RevokeSession removes the session and the refresh tokens issued with it
+func RevokeSession(session *mongo.Session, ID bson.ObjectId) error {
+	if _, err := session.C(entity.RefreshTokenCollectionName).RemoveAll(bson.M{"sessionID": ID}); err != nil {
+		return err
+	}
+	// the session may be removed by the TTL index already
+	_, err := session.C(entity.SessionCollectionName).RemoveAll(bson.M{"_id": ID})
+	return err
+}
+
+// RevokeUserSessions removes all sessions and refresh tokens of the user and
+// returns the IDs of the removed sessions
+func RevokeUserSessions(session *mongo.Session, userID bson.ObjectId) ([]bson.ObjectId, error) {
+	if err := RemoveRefreshTokens(session, userID); err != nil {
+		return nil, err
+	}
+
+	sessions := []entity.Session{}
+	if err := session.C(entity.SessionCollectionName).Find(bson.M{"userID": userID}).Select(bson.M{"_id": 1}).All(&sessions); err != nil {
+		return nil, err
+	}
+	if _, err := session.C(entity.SessionCollectionName).RemoveAll(bson.M{"userID": userID}); err != nil {
+		return nil, err
+	}
+
+	IDs := []bson.ObjectId{}
+	for _, s := range sessions {
+		IDs = append(IDs, s.ID)
+	}
+	return IDs, nil
+}
@@ -0,0 +1,112 @@
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type SessionTestSuite struct {
+	suite.Suite
+	sp      *serviceprovider.Container
+	session *mongo.Session
+}
+
+func (suite *SessionTestSuite) SetupSuite() {
+	cf := config.MustRead("../../../config/testing.json")
+	sp := serviceprovider.NewForTesting(cf)
+
+	suite.sp = sp
+	// init session
+	suite.session = sp.Mongo.NewSession()
+}
+
+func (suite *SessionTestSuite) TearDownSuite() {}
+
+func TestSessionSuite(t *testing.T) {
+	suite.Run(t, new(SessionTestSuite))
+}
+
+func (suite *SessionTestSuite) TestRevokeSession() {
+	userID := bson.NewObjectId()
+	defer RevokeUserSessions(suite.session, userID)
+
+	s := entity.Session{
+		ID:        bson.NewObjectId(),
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	err := CreateSession(suite.session, s)
+	suite.NoError(err)
+	token, err := CreateRefreshToken(suite.session, userID, s.ID, time.Hour)
+	suite.NoError(err)
+
+	active, err := IsSessionActive(suite.session, s.ID)
+	suite.NoError(err)
+	suite.True(active)
+
+	sessions, err := ListActiveSessions(suite.session, userID)
+	suite.NoError(err)
+	suite.Len(sessions, 1)
+
+	err = RevokeSession(suite.session, s.ID)
+	suite.NoError(err)
+
+	active, err = IsSessionActive(suite.session, s.ID)
+	suite.NoError(err)
+	suite.False(active)
+
+	_, err = ConsumeRefreshToken(suite.session, token)
+	suite.Error(err)
+
+	// revoke again
+	err = RevokeSession(suite.session, s.ID)
+	suite.NoError(err)
+}
+
+func (suite *SessionTestSuite) TestExpiredSession() {
+	userID := bson.NewObjectId()
+	defer RevokeUserSessions(suite.session, userID)
+
+	s := entity.Session{
+		ID:        bson.NewObjectId(),
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}
+	err := CreateSession(suite.session, s)
+	suite.NoError(err)
+
+	active, err := IsSessionActive(suite.session, s.ID)
+	suite.NoError(err)
+	suite.False(active)
+
+	sessions, err := ListActiveSessions(suite.session, userID)
+	suite.NoError(err)
+	suite.Len(sessions, 0)
+}
+
+func (suite *SessionTestSuite) TestRevokeUserSessions() {
+	userID := bson.NewObjectId()
+	for i := 0; i < 2; i++ {
+		err := CreateSession(suite.session, entity.Session{
+			ID:        bson.NewObjectId(),
+			UserID:    userID,
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+		suite.NoError(err)
+	}
+
+	sessionIDs, err := RevokeUserSessions(suite.session, userID)
+	suite.NoError(err)
+	suite.Len(sessionIDs, 2)
+
+	sessions, err := ListActiveSessions(suite.session, userID)
+	suite.NoError(err)
+	suite.Len(sessions, 0)
+}
@@ -0,0 +1,123 @@
+package backend
+
+import (
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/utils/timeutils"
+	"github.com/linkernetworks/vortex/src/entity"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// CreateTeam stores the new team, the team name is unique
+func CreateTeam(session *mongo.Session, team *entity.Team) error {
+	session.C(entity.TeamCollectionName).EnsureIndex(mgo.Index{
+		Key:    []string{"name"},
+		Unique: true,
+	})
+
+	team.ID = bson.NewObjectId()
+	team.CreatedAt = timeutils.Now()
+	if team.Members == nil {
+		team.Members = []entity.TeamMember{}
+	}
+	return session.Insert(entity.TeamCollectionName, team)
+}
+
+// FindTeam returns the team with its namespaces
+func FindTeam(session *mongo.Session, ID bson.ObjectId) (entity.Team, error) {
+	team := entity.Team{}
+	if err := session.FindOne(entity.TeamCollectionName, bson.M{"_id": ID}, &team); err != nil {
+		return entity.Team{}, err
+	}
+	namespaces, err := teamNamespaces(session, team.ID)
+	if err != nil {
+		return entity.Team{}, err
+	}
+	team.Namespaces = namespaces
+	return team, nil
+}
+
+// ListTeams returns the teams with their namespaces. Only the teams of the member are returned if the memberID is set.
+func ListTeams(session *mongo.Session, memberID bson.ObjectId) ([]entity.Team, error) {
+	selector := bson.M{}
+	if memberID != "" {
+		selector["members.userID"] = memberID
+	}
+	teams := []entity.Team{}
+	if err := session.C(entity.TeamCollectionName).Find(selector).Sort("_id").All(&teams); err != nil {
+		return nil, err
+	}
+	for i := range teams {
+		namespaces, err := teamNamespaces(session, teams[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		teams[i].Namespaces = namespaces
+	}
+	return teams, nil
+}
+
+// DeleteTeam removes the team and its grants of the registry credentials, its namespaces are left without a team
+func DeleteTeam(session *mongo.Session, ID bson.ObjectId) error {
+	if err := session.Remove(entity.TeamCollectionName, "_id", ID); err != nil {
+		return err
+	}
+	if _, err := session.C(entity.NamespaceCollectionName).UpdateAll(
+		bson.M{"teamID": ID},
+		bson.M{"$unset": bson.M{"teamID": ""}},
+	); err != nil {
+		return err
+	}
+	_, err := session.C(entity.RegistryCredentialCollectionName).UpdateAll(
+		bson.M{"teams": ID},
+		bson.M{"$pull": bson.M{"teams": ID}},
+	)
+	return err
+}
+
+// SetTeamMember adds the member to the team or changes the role of the member
+func SetTeamMember(session *mongo.Session, teamID bson.ObjectId, member entity.TeamMember) error {
+	c := session.C(entity.TeamCollectionName)
+	if err := c.UpdateId(teamID, bson.M{"$pull": bson.M{"members": bson.M{"userID": member.UserID}}}); err != nil {
+		return err
+	}
+	return c.UpdateId(teamID, bson.M{"$push": bson.M{"members": member}})
+}
+
+// RemoveTeamMember removes the member from the team
+func RemoveTeamMember(session *mongo.Session, teamID bson.ObjectId, userID bson.ObjectId) error {
+	return session.C(entity.TeamCollectionName).UpdateId(teamID, bson.M{"$pull": bson.M{"members": bson.M{"userID": userID}}})
+}
+
+// TeamNamespaces returns the namespaces of the teams the user belongs to and the team role of each namespace
+func TeamNamespaces(session *mongo.Session, userID bson.ObjectId) (map[string]string, error) {
+	teams := []entity.Team{}
+	if err := session.FindAll(entity.TeamCollectionName, bson.M{"members.userID": userID}, &teams); err != nil {
+		return nil, err
+	}
+
+	roles := map[string]string{}
+	for _, team := range teams {
+		namespaces, err := teamNamespaces(session, team.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, namespace := range namespaces {
+			roles[namespace] = team.Role(userID)
+		}
+	}
+	return roles, nil
+}
+
+// teamNamespaces returns the names of the namespaces of the team
+func teamNamespaces(session *mongo.Session, teamID bson.ObjectId) ([]string, error) {
+	namespaces := []entity.Namespace{}
+	if err := session.C(entity.NamespaceCollectionName).Find(bson.M{"teamID": teamID}).Select(bson.M{"name": 1}).All(&namespaces); err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, namespace := range namespaces {
+		names = append(names, namespace.Name)
+	}
+	return names, nil
+}
@@ -0,0 +1,100 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type TeamTestSuite struct {
+	suite.Suite
+	sp      *serviceprovider.Container
+	session *mongo.Session
+}
+
+func (suite *TeamTestSuite) SetupSuite() {
+	cf := config.MustRead("../../../config/testing.json")
+	sp := serviceprovider.NewForTesting(cf)
+
+	suite.sp = sp
+	// init session
+	suite.session = sp.Mongo.NewSession()
+}
+
+func (suite *TeamTestSuite) TearDownSuite() {}
+
+func TestTeamSuite(t *testing.T) {
+	suite.Run(t, new(TeamTestSuite))
+}
+
+func (suite *TeamTestSuite) createNamespace(teamID bson.ObjectId) entity.Namespace {
+	namespace := entity.Namespace{
+		ID:     bson.NewObjectId(),
+		Name:   namesgenerator.GetRandomName(0),
+		TeamID: teamID,
+	}
+	suite.NoError(suite.session.Insert(entity.NamespaceCollectionName, &namespace))
+	return namespace
+}
+
+func (suite *TeamTestSuite) TestTeamNamespaces() {
+	admin := bson.NewObjectId()
+	viewer := bson.NewObjectId()
+	team := entity.Team{
+		Name:    namesgenerator.GetRandomName(0),
+		Members: []entity.TeamMember{{UserID: admin, Role: entity.TeamAdminRole}},
+	}
+	suite.NoError(CreateTeam(suite.session, &team))
+	defer suite.session.Remove(entity.TeamCollectionName, "_id", team.ID)
+
+	namespace := suite.createNamespace(team.ID)
+	defer suite.session.Remove(entity.NamespaceCollectionName, "_id", namespace.ID)
+	other := suite.createNamespace("")
+	defer suite.session.Remove(entity.NamespaceCollectionName, "_id", other.ID)
+
+	suite.NoError(SetTeamMember(suite.session, team.ID, entity.TeamMember{UserID: viewer, Role: entity.TeamMemberRole}))
+	// change the role of the member
+	suite.NoError(SetTeamMember(suite.session, team.ID, entity.TeamMember{UserID: viewer, Role: entity.TeamViewerRole}))
+
+	found, err := FindTeam(suite.session, team.ID)
+	suite.NoError(err)
+	suite.Len(found.Members, 2)
+	suite.Equal(entity.TeamViewerRole, found.Role(viewer))
+	suite.Equal([]string{namespace.Name}, found.Namespaces)
+
+	roles, err := TeamNamespaces(suite.session, viewer)
+	suite.NoError(err)
+	suite.Equal(map[string]string{namespace.Name: entity.TeamViewerRole}, roles)
+
+	teams, err := ListTeams(suite.session, admin)
+	suite.NoError(err)
+	suite.Len(teams, 1)
+
+	suite.NoError(RemoveTeamMember(suite.session, team.ID, viewer))
+	roles, err = TeamNamespaces(suite.session, viewer)
+	suite.NoError(err)
+	suite.Empty(roles)
+}
+
+func (suite *TeamTestSuite) TestDeleteTeam() {
+	team := entity.Team{Name: namesgenerator.GetRandomName(0)}
+	suite.NoError(CreateTeam(suite.session, &team))
+
+	namespace := suite.createNamespace(team.ID)
+	defer suite.session.Remove(entity.NamespaceCollectionName, "_id", namespace.ID)
+
+	suite.NoError(DeleteTeam(suite.session, team.ID))
+
+	_, err := FindTeam(suite.session, team.ID)
+	suite.Error(err)
+	// the namespace is left without a team
+	retNamespace := entity.Namespace{}
+	suite.NoError(suite.session.FindOne(entity.NamespaceCollectionName, bson.M{"_id": namespace.ID}, &retNamespace))
+	suite.Equal(bson.ObjectId(""), retNamespace.TeamID)
+}
@@ -0,0 +1,212 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/utils/timeutils"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/totp"
+	"github.com/linkernetworks/vortex/src/utils"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// RecoveryCodeCount is the number of the recovery codes generated at once
+const RecoveryCodeCount = 10
+
+// MaxTwoFactorAttempts is the number of the wrong codes allowed for a challenge
+const MaxTwoFactorAttempts = 5
+
+// SetTOTPSecret stores the secret of a pending enrollment, which is enabled by EnableTOTP
+func SetTOTPSecret(session *mongo.Session, userID bson.ObjectId, secret string) error {
+	return session.C(entity.UserCollectionName).Update(
+		bson.M{"_id": userID, "totpEnabled": bson.M{"$ne": true}},
+		bson.M{"$set": bson.M{"totpSecret": secret}},
+	)
+}
+
+// EnableTOTP enables the TOTP of the user and returns the new recovery codes
+func EnableTOTP(session *mongo.Session, userID bson.ObjectId) ([]string, error) {
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := session.C(entity.UserCollectionName).Update(
+		bson.M{"_id": userID, "totpSecret": bson.M{"$exists": true}},
+		bson.M{"$set": bson.M{"totpEnabled": true, "recoveryCodes": hashes}},
+	); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTOTP removes the TOTP secret and the recovery codes of the user
+func DisableTOTP(session *mongo.Session, userID bson.ObjectId) error {
+	return session.C(entity.UserCollectionName).UpdateId(userID, bson.M{
+		"$set":   bson.M{"totpEnabled": false},
+		"$unset": bson.M{"totpSecret": "", "totpCounter": "", "recoveryCodes": ""},
+	})
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the user
+func RegenerateRecoveryCodes(session *mongo.Session, userID bson.ObjectId) ([]string, error) {
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := session.C(entity.UserCollectionName).Update(
+		bson.M{"_id": userID, "totpEnabled": true},
+		bson.M{"$set": bson.M{"recoveryCodes": hashes}},
+	); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// VerifyTOTP checks the TOTP code of the user's secret. A code can only be used once.
+func VerifyTOTP(session *mongo.Session, user entity.User, code string) (bool, error) {
+	if user.TOTPSecret == "" {
+		return false, nil
+	}
+	counter, ok := totp.Validate(user.TOTPSecret, code, time.Now())
+	if !ok || counter <= user.TOTPCounter {
+		return false, nil
+	}
+
+	// only one request can move the counter forward
+	err := session.C(entity.UserCollectionName).Update(
+		bson.M{"_id": user.ID, "$or": []bson.M{
+			{"totpCounter": bson.M{"$lt": counter}},
+			{"totpCounter": bson.M{"$exists": false}},
+		}},
+		bson.M{"$set": bson.M{"totpCounter": counter}},
+	)
+	if err == mgo.ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ConsumeRecoveryCode removes the recovery code of the user if it's unused
+func ConsumeRecoveryCode(session *mongo.Session, userID bson.ObjectId, code string) (bool, error) {
+	hash := utils.SHA256String(normalizeRecoveryCode(code))
+	err := session.C(entity.UserCollectionName).Update(
+		bson.M{"_id": userID, "recoveryCodes": hash},
+		bson.M{"$pull": bson.M{"recoveryCodes": hash}},
+	)
+	if err == mgo.ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// generateRecoveryCodes returns the codes in the form of xxxxx-xxxxx and their SHA256
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := []string{}
+	hashes := []string{}
+	for i := 0; i < RecoveryCodeCount; i++ {
+		token, err := utils.RandomToken(5)
+		if err != nil {
+			return nil, nil, err
+		}
+		code := token[:5] + "-" + token[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, utils.SHA256String(normalizeRecoveryCode(code)))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.Replace(strings.TrimSpace(code), "-", "", -1))
+}
+
+// CreateTwoFactorChallenge stores a new challenge of the user and returns the challenge token
+func CreateTwoFactorChallenge(session *mongo.Session, userID bson.ObjectId, expiry time.Duration) (string, error) {
+	token, err := utils.RandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	// let mongo remove the expired challenges
+	session.C(entity.TwoFactorChallengeCollectionName).EnsureIndex(mgo.Index{
+		Key:         []string{"expiresAt"},
+		ExpireAfter: time.Second,
+	})
+
+	challenge := entity.TwoFactorChallenge{
+		ID:        bson.NewObjectId(),
+		UserID:    userID,
+		TokenHash: utils.SHA256String(token),
+		ExpiresAt: time.Now().Add(expiry),
+		CreatedAt: timeutils.Now(),
+	}
+	if err := session.Insert(entity.TwoFactorChallengeCollectionName, &challenge); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// GetTwoFactorChallenge returns the challenge of the token if it's not expired and has attempts left
+func GetTwoFactorChallenge(session *mongo.Session, token string) (entity.TwoFactorChallenge, error) {
+	challenge := entity.TwoFactorChallenge{}
+	if err := session.FindOne(
+		entity.TwoFactorChallengeCollectionName,
+		bson.M{"tokenHash": utils.SHA256String(token)},
+		&challenge,
+	); err != nil {
+		return entity.TwoFactorChallenge{}, err
+	}
+
+	if time.Now().After(challenge.ExpiresAt) {
+		return entity.TwoFactorChallenge{}, fmt.Errorf("Challenge token is expired")
+	}
+	if challenge.Attempts >= MaxTwoFactorAttempts {
+		return entity.TwoFactorChallenge{}, fmt.Errorf("Too many attempts of the challenge")
+	}
+	return challenge, nil
+}
+
+// AttemptTwoFactorChallenge counts an attempt of the challenge before the code is checked and returns
+// the challenge if it's not expired and had attempts left. The concurrent attempts are counted atomically.
+func AttemptTwoFactorChallenge(session *mongo.Session, token string) (entity.TwoFactorChallenge, error) {
+	challenge := entity.TwoFactorChallenge{}
+	if _, err := session.C(entity.TwoFactorChallengeCollectionName).Find(bson.M{
+		"tokenHash": utils.SHA256String(token),
+		"expiresAt": bson.M{"$gt": time.Now()},
+		"attempts":  bson.M{"$lt": MaxTwoFactorAttempts},
+	}).Apply(mgo.Change{
+		Update:    bson.M{"$inc": bson.M{"attempts": 1}},
+		ReturnNew: true,
+	}, &challenge); err != nil {
+		return entity.TwoFactorChallenge{}, err
+	}
+	return challenge, nil
+}
+
+// RemoveTwoFactorChallenge removes the passed challenge
+func RemoveTwoFactorChallenge(session *mongo.Session, challengeID bson.ObjectId) error {
+	return session.Remove(entity.TwoFactorChallengeCollectionName, "_id", challengeID)
+}
+
+// GetTwoFactorPolicy returns the two-factor policy, which requires nothing if it's never set
+func GetTwoFactorPolicy(session *mongo.Session) (entity.TwoFactorPolicy, error) {
+	policy := entity.TwoFactorPolicy{}
+	err := session.FindOne(entity.PolicyCollectionName, bson.M{"_id": entity.TwoFactorPolicyID}, &policy)
+	if err == mgo.ErrNotFound {
+		return entity.TwoFactorPolicy{ID: entity.TwoFactorPolicyID}, nil
+	}
+	return policy, err
+}
+
+// UpdateTwoFactorPolicy stores the two-factor policy
+func UpdateTwoFactorPolicy(session *mongo.Session, policy entity.TwoFactorPolicy) (entity.TwoFactorPolicy, error) {
+	policy.ID = entity.TwoFactorPolicyID
+	policy.UpdatedAt = timeutils.Now()
+	if _, err := session.C(entity.PolicyCollectionName).UpsertId(policy.ID, &policy); err != nil {
+		return entity.TwoFactorPolicy{}, err
+	}
+	return policy, nil
+}
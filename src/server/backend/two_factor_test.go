@@ -0,0 +1,162 @@
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/linkernetworks/vortex/src/totp"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type TwoFactorTestSuite struct {
+	suite.Suite
+	sp      *serviceprovider.Container
+	session *mongo.Session
+}
+
+func (suite *TwoFactorTestSuite) SetupSuite() {
+	cf := config.MustRead("../../../config/testing.json")
+	sp := serviceprovider.NewForTesting(cf)
+
+	suite.sp = sp
+	// init session
+	suite.session = sp.Mongo.NewSession()
+}
+
+func (suite *TwoFactorTestSuite) TearDownSuite() {}
+
+func TestTwoFactorSuite(t *testing.T) {
+	suite.Run(t, new(TwoFactorTestSuite))
+}
+
+func (suite *TwoFactorTestSuite) createUser() entity.User {
+	user := entity.User{
+		ID: bson.NewObjectId(),
+		LoginCredential: entity.LoginCredential{
+			Username: namesgenerator.GetRandomName(0) + "@linkernetworks.com",
+		},
+		Role: entity.UserRole,
+	}
+	suite.NoError(suite.session.Insert(entity.UserCollectionName, &user))
+	return user
+}
+
+func (suite *TwoFactorTestSuite) TestEnableTOTP() {
+	user := suite.createUser()
+	defer suite.session.Remove(entity.UserCollectionName, "_id", user.ID)
+
+	// a pending enrollment is required
+	_, err := EnableTOTP(suite.session, user.ID)
+	suite.Error(err)
+
+	secret, err := totp.GenerateSecret()
+	suite.NoError(err)
+	suite.NoError(SetTOTPSecret(suite.session, user.ID, secret))
+	codes, err := EnableTOTP(suite.session, user.ID)
+	suite.NoError(err)
+	suite.Len(codes, RecoveryCodeCount)
+
+	user, err = FindUserByID(suite.session, user.ID)
+	suite.NoError(err)
+	suite.True(user.TOTPEnabled)
+	suite.Equal(secret, user.TOTPSecret)
+
+	// the secret can't be replaced once enabled
+	suite.Error(SetTOTPSecret(suite.session, user.ID, "OTHER"))
+
+	suite.NoError(DisableTOTP(suite.session, user.ID))
+	user, err = FindUserByID(suite.session, user.ID)
+	suite.NoError(err)
+	suite.False(user.TOTPEnabled)
+	suite.Empty(user.TOTPSecret)
+	suite.Empty(user.RecoveryCodes)
+}
+
+func (suite *TwoFactorTestSuite) TestVerifyTOTP() {
+	user := suite.createUser()
+	defer suite.session.Remove(entity.UserCollectionName, "_id", user.ID)
+
+	secret, err := totp.GenerateSecret()
+	suite.NoError(err)
+	suite.NoError(SetTOTPSecret(suite.session, user.ID, secret))
+	user, err = FindUserByID(suite.session, user.ID)
+	suite.NoError(err)
+
+	code, err := totp.Code(secret, totp.Counter(time.Now()))
+	suite.NoError(err)
+	passed, err := VerifyTOTP(suite.session, user, code)
+	suite.NoError(err)
+	suite.True(passed)
+
+	// a code can't be replayed
+	user, err = FindUserByID(suite.session, user.ID)
+	suite.NoError(err)
+	passed, err = VerifyTOTP(suite.session, user, code)
+	suite.NoError(err)
+	suite.False(passed)
+
+	passed, err = VerifyTOTP(suite.session, user, "000000")
+	suite.NoError(err)
+	suite.False(passed)
+}
+
+func (suite *TwoFactorTestSuite) TestConsumeRecoveryCode() {
+	user := suite.createUser()
+	defer suite.session.Remove(entity.UserCollectionName, "_id", user.ID)
+
+	suite.NoError(SetTOTPSecret(suite.session, user.ID, "JBSWY3DPEHPK3PXP"))
+	codes, err := EnableTOTP(suite.session, user.ID)
+	suite.NoError(err)
+
+	passed, err := ConsumeRecoveryCode(suite.session, user.ID, codes[0])
+	suite.NoError(err)
+	suite.True(passed)
+
+	// a recovery code can only be used once
+	passed, err = ConsumeRecoveryCode(suite.session, user.ID, codes[0])
+	suite.NoError(err)
+	suite.False(passed)
+
+	newCodes, err := RegenerateRecoveryCodes(suite.session, user.ID)
+	suite.NoError(err)
+	passed, err = ConsumeRecoveryCode(suite.session, user.ID, codes[1])
+	suite.NoError(err)
+	suite.False(passed)
+	passed, err = ConsumeRecoveryCode(suite.session, user.ID, newCodes[1])
+	suite.NoError(err)
+	suite.True(passed)
+}
+
+func (suite *TwoFactorTestSuite) TestTwoFactorChallenge() {
+	userID := bson.NewObjectId()
+	token, err := CreateTwoFactorChallenge(suite.session, userID, time.Minute)
+	suite.NoError(err)
+
+	challenge, err := GetTwoFactorChallenge(suite.session, token)
+	suite.NoError(err)
+	suite.Equal(userID, challenge.UserID)
+	defer RemoveTwoFactorChallenge(suite.session, challenge.ID)
+
+	for i := 0; i < MaxTwoFactorAttempts; i++ {
+		attempted, err := AttemptTwoFactorChallenge(suite.session, token)
+		suite.NoError(err)
+		suite.Equal(i+1, attempted.Attempts)
+	}
+	_, err = AttemptTwoFactorChallenge(suite.session, token)
+	suite.Error(err)
+	_, err = GetTwoFactorChallenge(suite.session, token)
+	suite.Error(err)
+
+	expired, err := CreateTwoFactorChallenge(suite.session, userID, -time.Minute)
+	suite.NoError(err)
+	_, err = GetTwoFactorChallenge(suite.session, expired)
+	suite.Error(err)
+	_, err = AttemptTwoFactorChallenge(suite.session, expired)
+	suite.Error(err)
+}
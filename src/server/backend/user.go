@@ -1,11 +1,16 @@
 package backend
 
 import (
+	"errors"
+
 	"github.com/linkernetworks/mongo"
 	"github.com/linkernetworks/vortex/src/entity"
 	"gopkg.in/mgo.v2/bson"
 )
 
+// ErrUserDisabled is returned when a disabled user signs in
+var ErrUserDisabled = errors.New("The user is disabled")
+
 func FindUserByID(session *mongo.Session, ID bson.ObjectId) (entity.User, error) {
 	var user entity.User
 	if err := session.FindOne(
@@ -17,3 +22,18 @@ func FindUserByID(session *mongo.Session, ID bson.ObjectId) (entity.User, error)
 	}
 	return user, nil
 }
+
+// UpdateUser updates the profile, role and state of the user and returns the updated user
+func UpdateUser(session *mongo.Session, ID bson.ObjectId, update entity.UserUpdateRequest) (entity.User, error) {
+	if err := session.C(entity.UserCollectionName).UpdateId(ID, bson.M{"$set": bson.M{
+		"displayName": update.DisplayName,
+		"role":        update.Role,
+		"firstname":   update.FirstName,
+		"lastName":    update.LastName,
+		"phoneNumber": update.PhoneNumber,
+		"disabled":    update.Disabled,
+	}}); err != nil {
+		return entity.User{}, err
+	}
+	return FindUserByID(session, ID)
+}
@@ -69,3 +69,39 @@ func (suite *UserTestSuite) TestFindUserByID() {
 	user, err = FindUserByID(suite.session, "nonono")
 	suite.Error(err)
 }
+
+func (suite *UserTestSuite) TestUpdateUser() {
+	user := entity.User{
+		ID: bson.NewObjectId(),
+		LoginCredential: entity.LoginCredential{
+			Username: namesgenerator.GetRandomName(0) + "@linkernetworks.com",
+			Password: "p@ssw0rd",
+		},
+		DisplayName: "John Doe",
+		Role:        entity.UserRole,
+		FirstName:   "John",
+		LastName:    "Doe",
+		PhoneNumber: "0900000000",
+	}
+	suite.NoError(suite.session.Insert(entity.UserCollectionName, &user))
+	defer suite.session.Remove(entity.UserCollectionName, "_id", user.ID)
+
+	updatedUser, err := UpdateUser(suite.session, user.ID, entity.UserUpdateRequest{
+		DisplayName: "Jane Doe",
+		Role:        entity.GuestRole,
+		FirstName:   "Jane",
+		LastName:    "Doe",
+		PhoneNumber: "0911111111",
+		Disabled:    true,
+	})
+	suite.NoError(err)
+	suite.Equal("Jane Doe", updatedUser.DisplayName)
+	suite.Equal("Jane", updatedUser.FirstName)
+	suite.Equal(entity.GuestRole, updatedUser.Role)
+	suite.True(updatedUser.Disabled)
+	// the credential is kept
+	suite.Equal(user.LoginCredential, updatedUser.LoginCredential)
+
+	_, err = UpdateUser(suite.session, bson.NewObjectId(), entity.UserUpdateRequest{})
+	suite.Error(err)
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/linkernetworks/vortex/src/entity"
+	response "github.com/linkernetworks/vortex/src/net/http"
+	"github.com/linkernetworks/vortex/src/server/backend"
+	"github.com/linkernetworks/vortex/src/web"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func createAPITokenHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	// API tokens can only be managed with a signed in session
+	if _, ok := req.Attribute("APITokenID").(string); ok {
+		response.Forbidden(req.Request, resp.ResponseWriter, fmt.Errorf("API tokens can't create API tokens"))
+		return
+	}
+
+	userID, ok := req.Attribute("UserID").(string)
+	if !ok {
+		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: User ID is empty"))
+		return
+	}
+
+	apiToken := entity.APIToken{}
+	if err := req.ReadEntity(&apiToken); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	if err := sp.Validator.Struct(apiToken); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	if apiToken.ExpiresAt != nil && apiToken.ExpiresAt.Before(time.Now()) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("The expiry time of the API token has passed"))
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	apiToken.OwnerID = bson.ObjectIdHex(userID)
+	if err := backend.CreateAPIToken(session, &apiToken); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	resp.WriteHeaderAndEntity(http.StatusCreated, apiToken)
+}
+
+func listAPITokenHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	userID, ok := req.Attribute("UserID").(string)
+	if !ok {
+		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: User ID is empty"))
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	apiTokens, err := backend.ListAPITokens(session, bson.ObjectIdHex(userID))
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	resp.WriteEntity(apiTokens)
+}
+
+func deleteAPITokenHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	id := req.PathParameter("id")
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	apiToken := entity.APIToken{}
+	if err := session.FindOne(entity.APITokenCollectionName, bson.M{"_id": bson.ObjectIdHex(id)}, &apiToken); err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+
+	if err := session.Remove(entity.APITokenCollectionName, "_id", apiToken.ID); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	// the cached API token is keyed by its hash
+	sp.SessionCache.Delete(apiToken.TokenHash)
+
+	resp.WriteEntity(response.ActionResponse{
+		Error:   false,
+		Message: "API Token Deleted Success",
+	})
+}
@@ -0,0 +1,256 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type APITokenTestSuite struct {
+	suite.Suite
+	sp        *serviceprovider.Container
+	wc        *restful.Container
+	session   *mongo.Session
+	JWTBearer string
+}
+
+func (suite *APITokenTestSuite) SetupSuite() {
+	cf := config.MustRead("../../config/testing.json")
+	sp := serviceprovider.NewForTesting(cf)
+
+	suite.sp = sp
+	// init session
+	suite.session = sp.Mongo.NewSession()
+	// init restful container
+	suite.wc = restful.NewContainer()
+
+	userService := newUserService(suite.sp)
+	podService := newPodService(suite.sp)
+
+	suite.wc.Add(secureService(suite.sp, userService))
+	suite.wc.Add(secureService(suite.sp, podService))
+
+	token, _ := loginGetToken(suite.wc)
+	suite.NotEmpty(token)
+	suite.JWTBearer = "Bearer " + token
+}
+
+func (suite *APITokenTestSuite) TearDownSuite() {}
+
+func TestAPITokenSuite(t *testing.T) {
+	suite.Run(t, new(APITokenTestSuite))
+}
+
+func (suite *APITokenTestSuite) createAPIToken(apiToken entity.APIToken) entity.APIToken {
+	bodyBytes, err := json.MarshalIndent(apiToken, "", "  ")
+	suite.NoError(err)
+
+	httpRequest, err := http.NewRequest("POST", "http://localhost:7890/v1/users/tokens", strings.NewReader(string(bodyBytes)))
+	suite.NoError(err)
+
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", suite.JWTBearer)
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusCreated, httpWriter)
+
+	created := entity.APIToken{}
+	err = json.Unmarshal(httpWriter.Body.Bytes(), &created)
+	suite.NoError(err)
+	return created
+}
+
+func (suite *APITokenTestSuite) TestCreateAPIToken() {
+	apiToken := suite.createAPIToken(entity.APIToken{
+		Name:  namesgenerator.GetRandomName(0),
+		Scope: entity.APITokenReadWrite,
+	})
+	defer suite.session.Remove(entity.APITokenCollectionName, "_id", apiToken.ID)
+	suite.True(strings.HasPrefix(apiToken.Token, entity.APITokenPrefix))
+
+	// only the hash is stored
+	retAPIToken := entity.APIToken{}
+	err := suite.session.FindOne(entity.APITokenCollectionName, bson.M{"_id": apiToken.ID}, &retAPIToken)
+	suite.NoError(err)
+	suite.Equal(apiToken.Name, retAPIToken.Name)
+	suite.NotEqual(apiToken.Token, retAPIToken.TokenHash)
+
+	// the token is accepted like a session JWT
+	httpRequest, err := http.NewRequest("GET", "http://localhost:7890/v1/users/verify/auth", nil)
+	suite.NoError(err)
+
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", "Bearer "+apiToken.Token)
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusSeeOther, httpWriter)
+
+	// API tokens can't create API tokens
+	bodyBytes, err := json.Marshal(entity.APIToken{Name: "nested", Scope: entity.APITokenReadWrite})
+	suite.NoError(err)
+
+	httpRequest, err = http.NewRequest("POST", "http://localhost:7890/v1/users/tokens", strings.NewReader(string(bodyBytes)))
+	suite.NoError(err)
+
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", "Bearer "+apiToken.Token)
+	httpWriter = httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusForbidden, httpWriter)
+}
+
+func (suite *APITokenTestSuite) TestCreateAPITokenFail() {
+	expiresAt := time.Now().Add(-time.Hour)
+	testCases := []struct {
+		caseName string
+		apiToken entity.APIToken
+	}{
+		{"withoutName", entity.APIToken{Scope: entity.APITokenReadOnly}},
+		{"invalidScope", entity.APIToken{Name: "ci", Scope: "admin"}},
+		{"invalidNamespace", entity.APIToken{Name: "ci", Scope: entity.APITokenReadOnly, Namespaces: []string{"Not_A_Namespace"}}},
+		{"expired", entity.APIToken{Name: "ci", Scope: entity.APITokenReadOnly, ExpiresAt: &expiresAt}},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.caseName, func(t *testing.T) {
+			bodyBytes, err := json.MarshalIndent(tc.apiToken, "", "  ")
+			suite.NoError(err)
+
+			httpRequest, err := http.NewRequest("POST", "http://localhost:7890/v1/users/tokens", strings.NewReader(string(bodyBytes)))
+			suite.NoError(err)
+
+			httpRequest.Header.Add("Content-Type", "application/json")
+			httpRequest.Header.Add("Authorization", suite.JWTBearer)
+			httpWriter := httptest.NewRecorder()
+			suite.wc.Dispatch(httpWriter, httpRequest)
+			assertResponseCode(t, http.StatusBadRequest, httpWriter)
+		})
+	}
+}
+
+func (suite *APITokenTestSuite) TestReadOnlyAPIToken() {
+	apiToken := suite.createAPIToken(entity.APIToken{
+		Name:  namesgenerator.GetRandomName(0),
+		Scope: entity.APITokenReadOnly,
+	})
+	defer suite.session.Remove(entity.APITokenCollectionName, "_id", apiToken.ID)
+
+	httpRequest, err := http.NewRequest("GET", "http://localhost:7890/v1/pods/", nil)
+	suite.NoError(err)
+
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", "Bearer "+apiToken.Token)
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	httpRequest, err = http.NewRequest("POST", "http://localhost:7890/v1/pods", strings.NewReader("{}"))
+	suite.NoError(err)
+
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", "Bearer "+apiToken.Token)
+	httpWriter = httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusForbidden, httpWriter)
+}
+
+func (suite *APITokenTestSuite) TestNamespaceRestrictedAPIToken() {
+	apiToken := suite.createAPIToken(entity.APIToken{
+		Name:       namesgenerator.GetRandomName(0),
+		Scope:      entity.APITokenReadWrite,
+		Namespaces: []string{"ci"},
+	})
+	defer suite.session.Remove(entity.APITokenCollectionName, "_id", apiToken.ID)
+
+	pod := entity.Pod{
+		Name:      namesgenerator.GetRandomName(0),
+		Namespace: "default",
+		Containers: []entity.Container{
+			{
+				Name:    namesgenerator.GetRandomName(0),
+				Image:   "busybox",
+				Command: []string{"sleep", "3600"},
+			},
+		},
+	}
+	bodyBytes, err := json.MarshalIndent(pod, "", "  ")
+	suite.NoError(err)
+
+	httpRequest, err := http.NewRequest("POST", "http://localhost:7890/v1/pods", strings.NewReader(string(bodyBytes)))
+	suite.NoError(err)
+
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", "Bearer "+apiToken.Token)
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusForbidden, httpWriter)
+
+	httpRequest, err = http.NewRequest("DELETE", "http://localhost:7890/v1/pods/default/"+pod.Name, nil)
+	suite.NoError(err)
+
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", "Bearer "+apiToken.Token)
+	httpWriter = httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusForbidden, httpWriter)
+}
+
+func (suite *APITokenTestSuite) TestListAndDeleteAPIToken() {
+	apiToken := suite.createAPIToken(entity.APIToken{
+		Name:  namesgenerator.GetRandomName(0),
+		Scope: entity.APITokenReadOnly,
+	})
+	defer suite.session.Remove(entity.APITokenCollectionName, "_id", apiToken.ID)
+
+	httpRequest, err := http.NewRequest("GET", "http://localhost:7890/v1/users/tokens", nil)
+	suite.NoError(err)
+
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", suite.JWTBearer)
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	apiTokens := []entity.APIToken{}
+	err = json.Unmarshal(httpWriter.Body.Bytes(), &apiTokens)
+	suite.NoError(err)
+	found := false
+	for _, t := range apiTokens {
+		if t.ID == apiToken.ID {
+			found = true
+			// the token is never listed
+			suite.Empty(t.Token)
+		}
+	}
+	suite.True(found)
+
+	httpRequest, err = http.NewRequest("DELETE", "http://localhost:7890/v1/users/tokens/"+apiToken.ID.Hex(), nil)
+	suite.NoError(err)
+
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", suite.JWTBearer)
+	httpWriter = httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	// the deleted token is rejected
+	httpRequest, err = http.NewRequest("GET", "http://localhost:7890/v1/users/verify/auth", nil)
+	suite.NoError(err)
+
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", "Bearer "+apiToken.Token)
+	httpWriter = httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusUnauthorized, httpWriter)
+}
@@ -60,6 +60,10 @@ func createAppHandler(ctx *web.Context) {
 		response.BadRequest(req.Request, resp.ResponseWriter, err)
 		return
 	}
+	if err := deployment.CheckStaticAddressReplicas(nil, &p.Deployment); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
 
 	//Bond to same namespace
 	p.Service.Namespace = p.Deployment.Namespace
@@ -43,8 +43,8 @@ func (suite *AppHandlerTestSuite) SetupSuite() {
 	appService := newAppService(suite.sp)
 	userService := newUserService(suite.sp)
 
-	suite.wc.Add(appService)
-	suite.wc.Add(userService)
+	suite.wc.Add(secureService(suite.sp, appService))
+	suite.wc.Add(secureService(suite.sp, userService))
 
 	token, _ := loginGetToken(suite.wc)
 	suite.NotEmpty(token)
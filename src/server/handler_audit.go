@@ -0,0 +1,78 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/linkernetworks/vortex/src/entity"
+	response "github.com/linkernetworks/vortex/src/net/http"
+	"github.com/linkernetworks/vortex/src/net/http/query"
+	"github.com/linkernetworks/vortex/src/server/backend"
+	"github.com/linkernetworks/vortex/src/web"
+)
+
+// listAuditHandler lists the audit records, the latest first. The role must to have admin permission to access it.
+func listAuditHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	var pageSize = 1024
+	query := query.New(req.Request.URL.Query())
+
+	page, err := query.Int("page", 1)
+	if err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	pageSize, err = query.Int("page_size", pageSize)
+	if err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	auditQuery, err := parseAuditQuery(query)
+	if err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	records, count, err := backend.ListAuditRecords(session, auditQuery, (page-1)*pageSize, pageSize)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	totalPages := int(math.Ceil(float64(count) / float64(pageSize)))
+	resp.AddHeader("X-Total-Count", strconv.Itoa(count))
+	resp.AddHeader("X-Total-Pages", strconv.Itoa(totalPages))
+	resp.WriteEntity(records)
+}
+
+// parseAuditQuery reads the user, resource, outcome, since and until filters of the audit records
+func parseAuditQuery(query *query.QueryUrl) (entity.AuditQuery, error) {
+	auditQuery := entity.AuditQuery{}
+	auditQuery.UserID, _ = query.Str("user")
+	auditQuery.Resource, _ = query.Str("resource")
+	if outcome, ok := query.Str("outcome"); ok {
+		if outcome != entity.AuditSuccess && outcome != entity.AuditFailure {
+			return auditQuery, fmt.Errorf("Invalid outcome %s, it must be %s or %s", outcome, entity.AuditSuccess, entity.AuditFailure)
+		}
+		auditQuery.Outcome = outcome
+	}
+
+	var err error
+	if since, ok := query.Str("since"); ok {
+		if auditQuery.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			return auditQuery, fmt.Errorf("Invalid since time: %v", err)
+		}
+	}
+	if until, ok := query.Str("until"); ok {
+		if auditQuery.Until, err = time.Parse(time.RFC3339, until); err != nil {
+			return auditQuery, fmt.Errorf("Invalid until time: %v", err)
+		}
+	}
+	return auditQuery, nil
+}
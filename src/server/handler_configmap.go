@@ -144,7 +144,7 @@ func listConfigMapHandler(ctx *web.Context) {
 	var c = session.C(entity.ConfigMapCollectionName)
 	var q *mgo.Query
 
-	selector := bson.M{}
+	selector := namespaceSelector(req, "namespace")
 	q = c.Find(selector).Sort("_id").Skip((page - 1) * pageSize).Limit(pageSize)
 
 	if err := q.All(&configMaps); err != nil {
@@ -163,7 +163,7 @@ func listConfigMapHandler(ctx *web.Context) {
 		// find owner in user entity
 		configMaps[i].CreatedBy, _ = backend.FindUserByID(session, configMap.OwnerID)
 	}
-	count, err := session.Count(entity.ConfigMapCollectionName, bson.M{})
+	count, err := session.Count(entity.ConfigMapCollectionName, selector)
 	if err != nil {
 		response.InternalServerError(req.Request, resp.ResponseWriter, err)
 		return
@@ -50,8 +50,8 @@ func (suite *ConfigMapTestSuite) SetupSuite() {
 	configMapService := newConfigMapService(suite.sp)
 	userService := newUserService(suite.sp)
 
-	suite.wc.Add(configMapService)
-	suite.wc.Add(userService)
+	suite.wc.Add(secureService(suite.sp, configMapService))
+	suite.wc.Add(secureService(suite.sp, userService))
 
 	token, _ := loginGetToken(suite.wc)
 	suite.NotEmpty(token)
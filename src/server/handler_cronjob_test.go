@@ -0,0 +1,200 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/suite"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+type CronJobTestSuite struct {
+	ServerTestSuite
+	network entity.Network
+}
+
+func (suite *CronJobTestSuite) SetupSuite() {
+	suite.setupServices(newCronJobService)
+
+	suite.network = entity.Network{
+		ID:         bson.NewObjectId(),
+		Name:       namesgenerator.GetRandomName(0),
+		BridgeName: namesgenerator.GetRandomName(0),
+		Nodes: []entity.Node{
+			{Name: "node1"},
+		},
+	}
+	suite.session.Insert(entity.NetworkCollectionName, suite.network)
+}
+
+func (suite *CronJobTestSuite) TearDownSuite() {
+	suite.session.Remove(entity.NetworkCollectionName, "_id", suite.network.ID)
+}
+
+func TestCronJobSuite(t *testing.T) {
+	suite.Run(t, new(CronJobTestSuite))
+}
+
+func (suite *CronJobTestSuite) newCronJob() entity.CronJob {
+	return entity.CronJob{
+		Name:      namesgenerator.GetRandomName(0),
+		Namespace: "default",
+		Schedule:  "*/5 * * * *",
+		JobSpec: entity.JobSpec{
+			Labels:  map[string]string{},
+			EnvVars: map[string]string{},
+			Containers: []entity.Container{
+				{
+					Name:    namesgenerator.GetRandomName(0),
+					Image:   "busybox",
+					Command: []string{"echo", "done"},
+				},
+			},
+			Volumes:       []entity.PodVolume{},
+			Networks:      []entity.PodNetwork{},
+			RestartPolicy: "OnFailure",
+			NetworkType:   entity.PodClusterNetwork,
+			NodeAffinity:  []string{},
+		},
+	}
+}
+
+func (suite *CronJobTestSuite) TestCreateCronJob() {
+	c := suite.newCronJob()
+	httpWriter := suite.request("POST", "/v1/cronjobs", c)
+	assertResponseCode(suite.T(), http.StatusCreated, httpWriter)
+	defer suite.session.Remove(entity.CronJobCollectionName, "name", c.Name)
+
+	created := entity.CronJob{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &created))
+	suite.Equal(c.Name, created.Name)
+
+	current, err := suite.sp.KubeCtl.GetCronJob(c.Name, c.Namespace)
+	suite.NoError(err)
+	suite.Equal(c.Schedule, current.Spec.Schedule)
+
+	// the name is used
+	httpWriter = suite.request("POST", "/v1/cronjobs", c)
+	assertResponseCode(suite.T(), http.StatusConflict, httpWriter)
+
+	httpWriter = suite.request("GET", "/v1/cronjobs/"+created.ID.Hex(), nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	httpWriter = suite.request("GET", "/v1/cronjobs/?namespace=default", nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	cronJobs := []entity.CronJob{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &cronJobs))
+	suite.NotEmpty(cronJobs)
+
+	httpWriter = suite.request("DELETE", "/v1/cronjobs/"+created.ID.Hex(), nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	_, err = suite.sp.KubeCtl.GetCronJob(c.Name, c.Namespace)
+	suite.Error(err)
+
+	httpWriter = suite.request("DELETE", "/v1/cronjobs/"+created.ID.Hex(), nil)
+	assertResponseCode(suite.T(), http.StatusNotFound, httpWriter)
+}
+
+func (suite *CronJobTestSuite) TestCreateCronJobFail() {
+	// the overlapping runs can't share the static address
+	c := suite.newCronJob()
+	c.NetworkType = entity.PodCustomNetwork
+	c.Networks = []entity.PodNetwork{
+		{
+			Name:       suite.network.Name,
+			IfName:     "eth1",
+			IPAddress:  "10.1.0.10",
+			Netmask:    "255.255.255.0",
+			RoutesGw:   []entity.PodRouteGw{},
+			RoutesIntf: []entity.PodRouteIntf{},
+		},
+	}
+	httpWriter := suite.request("POST", "/v1/cronjobs", c)
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+
+	c = suite.newCronJob()
+	c.ConcurrencyPolicy = "unknown"
+	httpWriter = suite.request("POST", "/v1/cronjobs", c)
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+
+	c = suite.newCronJob()
+	c.Schedule = ""
+	httpWriter = suite.request("POST", "/v1/cronjobs", c)
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+}
+
+func (suite *CronJobTestSuite) TestUpdateCronJob() {
+	c := suite.newCronJob()
+	httpWriter := suite.request("POST", "/v1/cronjobs", c)
+	assertResponseCode(suite.T(), http.StatusCreated, httpWriter)
+	defer suite.session.Remove(entity.CronJobCollectionName, "name", c.Name)
+	created := entity.CronJob{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &created))
+	defer suite.sp.KubeCtl.DeleteCronJob(c.Name, c.Namespace)
+	path := fmt.Sprintf("/v1/cronjobs/%s", created.ID.Hex())
+
+	c.Schedule = "0 * * * *"
+	c.Suspend = true
+	c.ConcurrencyPolicy = entity.CronJobForbidConcurrent
+	httpWriter = suite.request("PUT", path, c)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	current, err := suite.sp.KubeCtl.GetCronJob(c.Name, c.Namespace)
+	suite.NoError(err)
+	suite.Equal("0 * * * *", current.Spec.Schedule)
+	suite.True(*current.Spec.Suspend)
+
+	c.Namespace = namesgenerator.GetRandomName(0)
+	httpWriter = suite.request("PUT", path, c)
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+
+	httpWriter = suite.request("PUT", "/v1/cronjobs/"+bson.NewObjectId().Hex(), c)
+	assertResponseCode(suite.T(), http.StatusNotFound, httpWriter)
+}
+
+func (suite *CronJobTestSuite) TestTriggerCronJob() {
+	c := suite.newCronJob()
+	c.Suspend = true
+	c.ConcurrencyPolicy = entity.CronJobForbidConcurrent
+	httpWriter := suite.request("POST", "/v1/cronjobs", c)
+	assertResponseCode(suite.T(), http.StatusCreated, httpWriter)
+	defer suite.session.Remove(entity.CronJobCollectionName, "name", c.Name)
+	created := entity.CronJob{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &created))
+	defer suite.sp.KubeCtl.DeleteCronJob(c.Name, c.Namespace)
+	path := fmt.Sprintf("/v1/cronjobs/%s/runs", created.ID.Hex())
+
+	// the suspended CronJob can still be triggered manually
+	httpWriter = suite.request("POST", path, nil)
+	assertResponseCode(suite.T(), http.StatusCreated, httpWriter)
+	run := entity.JobRun{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &run))
+	suite.True(run.Manual)
+	defer suite.sp.KubeCtl.DeleteJob(run.Name, c.Namespace)
+
+	httpWriter = suite.request("GET", path, nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	runs := []entity.JobRun{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &runs))
+	suite.Len(runs, 1)
+
+	// the previous run is still running
+	httpWriter = suite.request("POST", path, nil)
+	assertResponseCode(suite.T(), http.StatusConflict, httpWriter)
+
+	httpWriter = suite.request("POST", "/v1/cronjobs/"+bson.NewObjectId().Hex()+"/runs", nil)
+	assertResponseCode(suite.T(), http.StatusNotFound, httpWriter)
+}
+
+func (suite *CronJobTestSuite) TestGetCronJobWithInvalidID() {
+	httpWriter := suite.request("GET", "/v1/cronjobs/"+bson.NewObjectId().Hex(), nil)
+	assertResponseCode(suite.T(), http.StatusNotFound, httpWriter)
+
+	httpWriter = suite.request("GET", "/v1/cronjobs/invalid/runs", nil)
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+}
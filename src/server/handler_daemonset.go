@@ -0,0 +1,280 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/linkernetworks/logger"
+	"github.com/linkernetworks/utils/timeutils"
+	"github.com/linkernetworks/vortex/src/daemonset"
+	"github.com/linkernetworks/vortex/src/entity"
+	response "github.com/linkernetworks/vortex/src/net/http"
+	"github.com/linkernetworks/vortex/src/net/http/query"
+	"github.com/linkernetworks/vortex/src/server/backend"
+	"github.com/linkernetworks/vortex/src/web"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// ensureDaemonSetIndex makes the DaemonSet names unique in the namespace
+func ensureDaemonSetIndex(c *mgo.Collection) {
+	c.EnsureIndex(mgo.Index{
+		Key:    []string{"namespace", "name"},
+		Unique: true,
+	})
+}
+
+func createDaemonSetHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+	userID, ok := req.Attribute("UserID").(string)
+	if !ok {
+		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: User ID is empty"))
+		return
+	}
+
+	p := entity.DaemonSet{}
+	if err := req.ReadEntity(&p); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	if err := sp.Validator.Struct(p); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+	ensureDaemonSetIndex(session.C(entity.DaemonSetCollectionName))
+
+	p.ID = bson.NewObjectId()
+	p.CreatedAt = timeutils.Now()
+	if err := daemonset.CheckDaemonSetParameter(sp, &p); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	if err := daemonset.CreateDaemonSet(sp, &p); err != nil {
+		if errors.IsAlreadyExists(err) {
+			response.Conflict(req.Request, resp.ResponseWriter, fmt.Errorf("DaemonSet Name: %s already existed", p.Name))
+		} else if errors.IsConflict(err) {
+			response.Conflict(req.Request, resp.ResponseWriter, fmt.Errorf("Create setting has conflict: %v", err))
+		} else if errors.IsInvalid(err) {
+			response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Create setting is invalid: %v", err))
+		} else {
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+
+	p.OwnerID = bson.ObjectIdHex(userID)
+	if err := session.Insert(entity.DaemonSetCollectionName, &p); err != nil {
+		if mgo.IsDup(err) {
+			response.Conflict(req.Request, resp.ResponseWriter, fmt.Errorf("DaemonSet Name: %s already existed", p.Name))
+		} else {
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+	p.CreatedBy, _ = backend.FindUserByID(session, p.OwnerID)
+	resp.WriteHeaderAndEntity(http.StatusCreated, p)
+}
+
+func deleteDaemonSetHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	id := req.PathParameter("id")
+	if !bson.IsObjectIdHex(id) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid DaemonSet ID: %s", id))
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	p := entity.DaemonSet{}
+	if err := session.FindOne(entity.DaemonSetCollectionName, bson.M{"_id": bson.ObjectIdHex(id)}, &p); err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+
+	if err := daemonset.DeleteDaemonSet(sp, &p); err != nil {
+		if errors.IsNotFound(err) {
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		} else {
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+
+	if err := session.Remove(entity.DaemonSetCollectionName, "_id", bson.ObjectIdHex(id)); err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+			return
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+			return
+		}
+	}
+
+	resp.WriteEntity(response.ActionResponse{
+		Error:   false,
+		Message: "Delete success",
+	})
+}
+
+func listDaemonSetHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	var pageSize = 1024
+	query := query.New(req.Request.URL.Query())
+
+	page, err := query.Int("page", 1)
+	if err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	pageSize, err = query.Int("page_size", pageSize)
+	if err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	daemonSets := []entity.DaemonSet{}
+	selector := namespaceSelector(req, "namespace")
+	q := session.C(entity.DaemonSetCollectionName).Find(selector).Sort("_id").Skip((page - 1) * pageSize).Limit(pageSize)
+	if err := q.All(&daemonSets); err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+			return
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+			return
+		}
+	}
+
+	// insert users entity
+	for i, s := range daemonSets {
+		// find owner in user entity
+		daemonSets[i].CreatedBy, _ = backend.FindUserByID(session, s.OwnerID)
+	}
+	count, err := session.Count(entity.DaemonSetCollectionName, selector)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	totalPages := int(math.Ceil(float64(count) / float64(pageSize)))
+	resp.AddHeader("X-Total-Count", strconv.Itoa(count))
+	resp.AddHeader("X-Total-Pages", strconv.Itoa(totalPages))
+	resp.WriteEntity(daemonSets)
+}
+
+func getDaemonSetHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	id := req.PathParameter("id")
+	if !bson.IsObjectIdHex(id) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid DaemonSet ID: %s", id))
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	var s entity.DaemonSet
+	if err := session.FindOne(entity.DaemonSetCollectionName, bson.M{"_id": bson.ObjectIdHex(id)}, &s); err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+			return
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+			return
+		}
+	}
+	// find owner in user entity
+	s.CreatedBy, _ = backend.FindUserByID(session, s.OwnerID)
+	resp.WriteEntity(s)
+}
+
+// updateDaemonSetHandler updates the pods of the running DaemonSet, they are replaced node by node.
+// The name and the namespace can't be changed.
+func updateDaemonSetHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	id := req.PathParameter("id")
+	if !bson.IsObjectIdHex(id) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid DaemonSet ID: %s", id))
+		return
+	}
+
+	p := entity.DaemonSet{}
+	if err := req.ReadEntity(&p); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if err := sp.Validator.Struct(p); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	stored := entity.DaemonSet{}
+	if err := session.FindOne(entity.DaemonSetCollectionName, bson.M{"_id": bson.ObjectIdHex(id)}, &stored); err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+	if p.Name != stored.Name || p.Namespace != stored.Namespace {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("The name and the namespace of the DaemonSet can't be changed"))
+		return
+	}
+	if err := daemonset.CheckDaemonSetParameter(sp, &p); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	p.ID = stored.ID
+	p.OwnerID = stored.OwnerID
+	p.CreatedAt = stored.CreatedAt
+	if err := daemonset.UpdateDaemonSet(sp, &p); err != nil {
+		if errors.IsNotFound(err) {
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		} else if errors.IsConflict(err) {
+			response.Conflict(req.Request, resp.ResponseWriter, fmt.Errorf("Update setting has conflict: %v", err))
+		} else if errors.IsInvalid(err) {
+			response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Update setting is invalid: %v", err))
+		} else {
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+	logger.Infof("DaemonSet %s/%s is updated", p.Namespace, p.Name)
+
+	if err := session.Update(entity.DaemonSetCollectionName, bson.M{"_id": p.ID}, bson.M{"$set": p}); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	p.CreatedBy, _ = backend.FindUserByID(session, p.OwnerID)
+	resp.WriteEntity(p)
+}
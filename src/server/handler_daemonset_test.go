@@ -0,0 +1,160 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/suite"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+type DaemonSetTestSuite struct {
+	ServerTestSuite
+	network entity.Network
+}
+
+func (suite *DaemonSetTestSuite) SetupSuite() {
+	suite.setupServices(newDaemonSetService)
+
+	suite.network = entity.Network{
+		ID:         bson.NewObjectId(),
+		Name:       namesgenerator.GetRandomName(0),
+		BridgeName: namesgenerator.GetRandomName(0),
+		Nodes: []entity.Node{
+			{Name: "node1"},
+			{Name: "node2"},
+		},
+		Subnets: []entity.Subnet{{CIDR: "10.1.0.0/24"}},
+	}
+	suite.session.Insert(entity.NetworkCollectionName, suite.network)
+}
+
+func (suite *DaemonSetTestSuite) TearDownSuite() {
+	suite.session.Remove(entity.NetworkCollectionName, "_id", suite.network.ID)
+}
+
+func TestDaemonSetSuite(t *testing.T) {
+	suite.Run(t, new(DaemonSetTestSuite))
+}
+
+func (suite *DaemonSetTestSuite) newDaemonSet() entity.DaemonSet {
+	return entity.DaemonSet{
+		Name:      namesgenerator.GetRandomName(0),
+		Namespace: "default",
+		Labels:    map[string]string{},
+		EnvVars:   map[string]string{},
+		Containers: []entity.Container{
+			{
+				Name:    namesgenerator.GetRandomName(0),
+				Image:   "busybox",
+				Command: []string{"sleep", "3600"},
+			},
+		},
+		Volumes:    []entity.DeploymentVolume{},
+		ConfigMaps: []entity.DeploymentConfig{},
+		Networks: []entity.DeploymentNetwork{
+			{
+				Name:       suite.network.Name,
+				IfName:     "eth1",
+				RoutesGw:   []entity.DeploymentRouteGw{},
+				RoutesIntf: []entity.DeploymentRouteIntf{},
+			},
+		},
+		NetworkType:  entity.DeploymentCustomNetwork,
+		NodeAffinity: []string{},
+	}
+}
+
+func (suite *DaemonSetTestSuite) TestCreateDaemonSet() {
+	d := suite.newDaemonSet()
+	httpWriter := suite.request("POST", "/v1/daemonsets", d)
+	assertResponseCode(suite.T(), http.StatusCreated, httpWriter)
+	defer suite.session.Remove(entity.DaemonSetCollectionName, "name", d.Name)
+
+	created := entity.DaemonSet{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &created))
+	suite.Equal(d.Name, created.Name)
+
+	current, err := suite.sp.KubeCtl.GetDaemonSet(d.Name, d.Namespace)
+	suite.NoError(err)
+	suite.Equal([]string{"node1", "node2"}, current.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions[0].Values)
+
+	// the name is used
+	httpWriter = suite.request("POST", "/v1/daemonsets", d)
+	assertResponseCode(suite.T(), http.StatusConflict, httpWriter)
+
+	httpWriter = suite.request("GET", "/v1/daemonsets/"+created.ID.Hex(), nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	httpWriter = suite.request("GET", "/v1/daemonsets/?namespace=default", nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	daemonSets := []entity.DaemonSet{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &daemonSets))
+	suite.NotEmpty(daemonSets)
+
+	httpWriter = suite.request("DELETE", "/v1/daemonsets/"+created.ID.Hex(), nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	_, err = suite.sp.KubeCtl.GetDaemonSet(d.Name, d.Namespace)
+	suite.Error(err)
+
+	httpWriter = suite.request("DELETE", "/v1/daemonsets/"+created.ID.Hex(), nil)
+	assertResponseCode(suite.T(), http.StatusNotFound, httpWriter)
+}
+
+func (suite *DaemonSetTestSuite) TestCreateDaemonSetFail() {
+	// the pods on the nodes can't share the static address
+	d := suite.newDaemonSet()
+	d.Networks[0].IPAddress = "10.1.0.10"
+	d.Networks[0].Netmask = "255.255.255.0"
+	httpWriter := suite.request("POST", "/v1/daemonsets", d)
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+
+	// no node has the network
+	d = suite.newDaemonSet()
+	d.NodeAffinity = []string{"node3"}
+	httpWriter = suite.request("POST", "/v1/daemonsets", d)
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+
+	d = suite.newDaemonSet()
+	d.NetworkType = "unknown"
+	httpWriter = suite.request("POST", "/v1/daemonsets", d)
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+}
+
+func (suite *DaemonSetTestSuite) TestUpdateDaemonSet() {
+	d := suite.newDaemonSet()
+	httpWriter := suite.request("POST", "/v1/daemonsets", d)
+	assertResponseCode(suite.T(), http.StatusCreated, httpWriter)
+	defer suite.session.Remove(entity.DaemonSetCollectionName, "name", d.Name)
+	created := entity.DaemonSet{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &created))
+	path := fmt.Sprintf("/v1/daemonsets/%s", created.ID.Hex())
+
+	d.Containers[0].Image = "busybox:1.29"
+	httpWriter = suite.request("PUT", path, d)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	current, err := suite.sp.KubeCtl.GetDaemonSet(d.Name, d.Namespace)
+	suite.NoError(err)
+	suite.Equal("busybox:1.29", current.Spec.Template.Spec.Containers[0].Image)
+
+	d.Namespace = namesgenerator.GetRandomName(0)
+	httpWriter = suite.request("PUT", path, d)
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+
+	httpWriter = suite.request("PUT", "/v1/daemonsets/"+bson.NewObjectId().Hex(), d)
+	assertResponseCode(suite.T(), http.StatusNotFound, httpWriter)
+}
+
+func (suite *DaemonSetTestSuite) TestGetDaemonSetWithInvalidID() {
+	httpWriter := suite.request("GET", "/v1/daemonsets/"+bson.NewObjectId().Hex(), nil)
+	assertResponseCode(suite.T(), http.StatusNotFound, httpWriter)
+
+	httpWriter = suite.request("GET", "/v1/daemonsets/invalid", nil)
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+}
@@ -8,12 +8,14 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/linkernetworks/logger"
 	"github.com/linkernetworks/utils/timeutils"
 	"github.com/linkernetworks/vortex/src/deployment"
 	"github.com/linkernetworks/vortex/src/entity"
 	"github.com/linkernetworks/vortex/src/kubernetes"
 	response "github.com/linkernetworks/vortex/src/net/http"
 	"github.com/linkernetworks/vortex/src/net/http/query"
+	pc "github.com/linkernetworks/vortex/src/prometheuscontroller"
 	"github.com/linkernetworks/vortex/src/server/backend"
 	"github.com/linkernetworks/vortex/src/web"
 	appv1 "k8s.io/api/apps/v1"
@@ -50,8 +52,8 @@ func createDeploymentHandler(ctx *web.Context) {
 		}
 	}
 
-	if p.NetworkType == entity.DeploymentCustomNetwork {
-		// clear the array
+	if deployment.HasStaticAddress(&p) {
+		// the replicas can't share the static addresses, clear the array
 		p.AutoscalerInfo.IsCapableAutoscaleResources = [2]string{}
 	}
 
@@ -74,6 +76,10 @@ func createDeploymentHandler(ctx *web.Context) {
 		response.BadRequest(req.Request, resp.ResponseWriter, err)
 		return
 	}
+	if err := deployment.CheckStaticAddressReplicas(nil, &p); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
 
 	p.OwnerID = bson.ObjectIdHex(userID)
 	// find owner in user entity
@@ -108,6 +114,7 @@ func createDeploymentHandler(ctx *web.Context) {
 		}
 		return
 	}
+	recordDeploymentRevision(sp, session, p, userID, 0)
 	p.CreatedBy = ownerUser
 	resp.WriteHeaderAndEntity(http.StatusCreated, p)
 }
@@ -146,6 +153,11 @@ func deleteDeploymentHandler(ctx *web.Context) {
 		}
 	}
 
+	// the revisions are useless without the deployment
+	if err := backend.RemoveDeploymentRevisions(session, p.ID); err != nil {
+		logger.Warnf("Failed to remove the revisions of deployment %s/%s: %v", p.Namespace, p.Name, err)
+	}
+
 	autoscaler := entity.AutoscalerInfo{
 		Namespace:          p.Namespace,
 		ScaleTargetRefName: p.Name,
@@ -182,7 +194,7 @@ func listDeploymentHandler(ctx *web.Context) {
 	var c = session.C(entity.DeploymentCollectionName)
 	var q *mgo.Query
 
-	selector := bson.M{}
+	selector := namespaceSelector(req, "namespace")
 	q = c.Find(selector).Sort("_id").Skip((page - 1) * pageSize).Limit(pageSize)
 
 	if err := q.All(&deployments); err != nil {
@@ -201,7 +213,7 @@ func listDeploymentHandler(ctx *web.Context) {
 		// find owner in user entity
 		deployments[i].CreatedBy, _ = backend.FindUserByID(session, deployment.OwnerID)
 	}
-	count, err := session.Count(entity.DeploymentCollectionName, bson.M{})
+	count, err := session.Count(entity.DeploymentCollectionName, selector)
 	if err != nil {
 		response.InternalServerError(req.Request, resp.ResponseWriter, err)
 		return
@@ -236,6 +248,41 @@ func getDeploymentHandler(ctx *web.Context) {
 	resp.WriteEntity(deployment)
 }
 
+func getDeploymentStatusHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	id := req.PathParameter("id")
+	if !bson.IsObjectIdHex(id) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid deployment ID: %s", id))
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	d := entity.Deployment{}
+	if err := session.FindOne(entity.DeploymentCollectionName, bson.M{"_id": bson.ObjectIdHex(id)}, &d); err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+
+	status, err := deployment.GetDeploymentStatus(sp, &d)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			response.NotFound(req.Request, resp.ResponseWriter, fmt.Errorf("Deployment %s/%s is not running: %v", d.Namespace, d.Name, err))
+			return
+		}
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	resp.WriteEntity(status)
+}
+
 func uploadDeploymentYAMLHandler(ctx *web.Context) {
 	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
 	userID, ok := req.Attribute("UserID").(string)
@@ -367,8 +414,25 @@ func updateAutoscalerHandler(ctx *web.Context) {
 		response.BadRequest(req.Request, resp.ResponseWriter, err)
 		return
 	}
+	if autoscalerInfo.Behavior != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, deployment.ErrBehaviorUnsupported)
+		return
+	}
 
 	if enableAutoscaler {
+		// the custom metrics must be in Prometheus, or the autoscaler can't get them
+		for _, metric := range autoscalerInfo.Metrics {
+			metricNameList, err := pc.ListMetricName(sp, metric.MetricName, map[string]string{"namespace": autoscalerInfo.Namespace})
+			if err != nil {
+				response.InternalServerError(req.Request, resp.ResponseWriter, fmt.Errorf("Check the metric %s in Prometheus error: %v", metric.MetricName, err))
+				return
+			}
+			if len(metricNameList) == 0 {
+				response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("The metric %s doesn't exist in Prometheus in the namespace %s", metric.MetricName, autoscalerInfo.Namespace))
+				return
+			}
+		}
+
 		// Create a autoscaler
 		if err := deployment.CreateAutoscaler(sp, autoscalerInfo); err != nil {
 			if errors.IsAlreadyExists(err) {
@@ -414,6 +478,7 @@ func updateAutoscalerHandler(ctx *web.Context) {
 	deployment.AutoscalerInfo.MinReplicas = autoscalerInfo.MinReplicas
 	deployment.AutoscalerInfo.MaxReplicas = autoscalerInfo.MaxReplicas
 	deployment.AutoscalerInfo.TargetAverageUtilization = autoscalerInfo.TargetAverageUtilization
+	deployment.AutoscalerInfo.Metrics = autoscalerInfo.Metrics
 
 	modifier := bson.M{
 		"$set": deployment,
@@ -427,3 +492,97 @@ func updateAutoscalerHandler(ctx *web.Context) {
 	}
 	resp.WriteHeaderAndEntity(http.StatusAccepted, deployment)
 }
+
+// updateDeploymentHandler updates the spec of the running deployment in place, the pods are replaced by its strategy.
+// The name and the namespace can't be changed and the autoscaler is updated by updateAutoscalerHandler.
+func updateDeploymentHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	id := req.PathParameter("id")
+	if !bson.IsObjectIdHex(id) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid deployment ID: %s", id))
+		return
+	}
+
+	p := entity.Deployment{}
+	if err := req.ReadEntity(&p); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if err := sp.Validator.Struct(p); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	stored := entity.Deployment{}
+	if err := session.FindOne(entity.DeploymentCollectionName, bson.M{"_id": bson.ObjectIdHex(id)}, &stored); err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+	if p.Name != stored.Name || p.Namespace != stored.Namespace {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("The name and the namespace of the deployment can't be changed"))
+		return
+	}
+	if err := deployment.CheckDeploymentParameter(sp, &p); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if err := deployment.CheckStaticAddressReplicas(&stored, &p); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	p.ID = stored.ID
+	p.OwnerID = stored.OwnerID
+	p.CreatedAt = stored.CreatedAt
+	p.IsEnableAutoscale = stored.IsEnableAutoscale
+	p.AutoscalerInfo = stored.AutoscalerInfo
+	// keep the owner labels
+	for _, label := range []string{deployment.NotificationEmailAccount, deployment.NotificationEmailDomain} {
+		if value, ok := stored.Labels[label]; ok {
+			p.Labels[label] = value
+		}
+	}
+
+	changed := deployment.ChangedFields(&stored, &p)
+	if len(changed) == 0 {
+		stored.CreatedBy, _ = backend.FindUserByID(session, stored.OwnerID)
+		resp.WriteEntity(stored)
+		return
+	}
+
+	if err := settleDeploymentRevision(sp, session, stored); err != nil {
+		logger.Warnf("Failed to settle the revision of deployment %s/%s: %v", stored.Namespace, stored.Name, err)
+	}
+	if err := deployment.UpdateDeployment(sp, &p); err != nil {
+		if errors.IsNotFound(err) {
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		} else if errors.IsConflict(err) {
+			response.Conflict(req.Request, resp.ResponseWriter, fmt.Errorf("Update setting has conflict: %v", err))
+		} else if errors.IsInvalid(err) {
+			response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Update setting is invalid: %v", err))
+		} else {
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+	logger.Infof("Deployment %s/%s is updated: %s", p.Namespace, p.Name, strings.Join(changed, ", "))
+
+	if err := session.Update(entity.DeploymentCollectionName, bson.M{"_id": p.ID}, bson.M{"$set": p}); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	userID, _ := req.Attribute("UserID").(string)
+	recordDeploymentRevision(sp, session, p, userID, 0)
+
+	p.CreatedBy, _ = backend.FindUserByID(session, p.OwnerID)
+	resp.WriteEntity(p)
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/linkernetworks/logger"
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/deployment"
+	"github.com/linkernetworks/vortex/src/entity"
+	response "github.com/linkernetworks/vortex/src/net/http"
+	"github.com/linkernetworks/vortex/src/server/backend"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/linkernetworks/vortex/src/web"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// recordDeploymentRevision stores the applied spec of the deployment as its next revision,
+// the deployment is already applied so the failure is only logged. The replica set revision
+// of the spec is unknown until Kubernetes creates the replica set, it's recorded by the next
+// change of the deployment, see settleDeploymentRevision.
+func recordDeploymentRevision(sp *serviceprovider.Container, session *mongo.Session, deploy entity.Deployment, userID string, rollbackOf int) {
+	revision := entity.DeploymentRevision{
+		DeploymentID: deploy.ID,
+		RollbackOf:   rollbackOf,
+		Spec:         deploy,
+	}
+	if bson.IsObjectIdHex(userID) {
+		revision.AppliedBy = bson.ObjectIdHex(userID)
+	}
+	if _, err := backend.CreateDeploymentRevision(session, revision); err != nil {
+		logger.Warnf("Failed to record the revision of deployment %s/%s: %v", deploy.Namespace, deploy.Name, err)
+	}
+}
+
+// settleDeploymentRevision runs before the stored deployment is changed. It records the replica set
+// revision of the running spec to the latest revision, and stores the running spec as revision 1
+// of the deployments created before the revisions were recorded.
+func settleDeploymentRevision(sp *serviceprovider.Container, session *mongo.Session, stored entity.Deployment) error {
+	replicaSetRevision, err := deployment.ReplicaSetRevision(sp, &stored)
+	if err != nil {
+		return err
+	}
+	latest, err := backend.LatestDeploymentRevision(session, stored.ID)
+	switch {
+	case err == mgo.ErrNotFound:
+		_, err = backend.CreateDeploymentRevision(session, entity.DeploymentRevision{
+			DeploymentID:       stored.ID,
+			ReplicaSetRevision: replicaSetRevision,
+			Spec:               stored,
+			AppliedBy:          stored.OwnerID,
+		})
+		return err
+	case err != nil:
+		return err
+	case latest.ReplicaSetRevision == "" && replicaSetRevision != "":
+		return backend.SetReplicaSetRevision(session, latest.ID, replicaSetRevision)
+	}
+	return nil
+}
+
+// listDeploymentRevisionHandler lists the revisions of the deployment, the latest first
+func listDeploymentRevisionHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	id := req.PathParameter("id")
+	if !bson.IsObjectIdHex(id) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid deployment ID: %s", id))
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	d := entity.Deployment{}
+	if err := session.FindOne(entity.DeploymentCollectionName, bson.M{"_id": bson.ObjectIdHex(id)}, &d); err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+
+	revisions, err := backend.ListDeploymentRevisions(session, d.ID)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	// the replica set revision of the running spec is recorded by the next change of the deployment
+	if len(revisions) > 0 && revisions[0].ReplicaSetRevision == "" {
+		revisions[0].ReplicaSetRevision, _ = deployment.ReplicaSetRevision(sp, &d)
+	}
+	resp.WriteEntity(revisions)
+}
+
+// rollbackDeploymentHandler re-applies the spec of the revision to the deployment, the rollback is recorded as a new revision
+func rollbackDeploymentHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	id := req.PathParameter("id")
+	if !bson.IsObjectIdHex(id) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid deployment ID: %s", id))
+		return
+	}
+	revisionNumber, err := strconv.Atoi(req.QueryParameter("revision"))
+	if err != nil || revisionNumber < 1 {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid revision: %s", req.QueryParameter("revision")))
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	stored := entity.Deployment{}
+	if err := session.FindOne(entity.DeploymentCollectionName, bson.M{"_id": bson.ObjectIdHex(id)}, &stored); err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+	revision, err := backend.FindDeploymentRevision(session, stored.ID, revisionNumber)
+	if err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, fmt.Errorf("Revision %d of deployment %s doesn't exist", revisionNumber, stored.Name))
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+
+	p := revision.Spec
+	p.ID = stored.ID
+	p.OwnerID = stored.OwnerID
+	p.CreatedAt = stored.CreatedAt
+	p.IsEnableAutoscale = stored.IsEnableAutoscale
+	p.AutoscalerInfo = stored.AutoscalerInfo
+	// the volumes and the networks of the revision may have been removed
+	if err := deployment.CheckDeploymentParameter(sp, &p); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if err := deployment.CheckStaticAddressReplicas(&stored, &p); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	if err := settleDeploymentRevision(sp, session, stored); err != nil {
+		logger.Warnf("Failed to settle the revision of deployment %s/%s: %v", stored.Namespace, stored.Name, err)
+	}
+	if err := deployment.UpdateDeployment(sp, &p); err != nil {
+		if errors.IsNotFound(err) {
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		} else if errors.IsConflict(err) {
+			response.Conflict(req.Request, resp.ResponseWriter, fmt.Errorf("Rollback setting has conflict: %v", err))
+		} else if errors.IsInvalid(err) {
+			response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Rollback setting is invalid: %v", err))
+		} else {
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+	logger.Infof("Deployment %s/%s is rolled back to revision %d", p.Namespace, p.Name, revisionNumber)
+
+	if err := session.Update(entity.DeploymentCollectionName, bson.M{"_id": p.ID}, bson.M{"$set": p}); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	userID, _ := req.Attribute("UserID").(string)
+	recordDeploymentRevision(sp, session, p, userID, revisionNumber)
+
+	p.CreatedBy, _ = backend.FindUserByID(session, p.OwnerID)
+	resp.WriteEntity(p)
+}
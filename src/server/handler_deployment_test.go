@@ -51,8 +51,8 @@ func (suite *DeploymentTestSuite) SetupSuite() {
 	deploymentService := newDeploymentService(suite.sp)
 	userService := newUserService(suite.sp)
 
-	suite.wc.Add(deploymentService)
-	suite.wc.Add(userService)
+	suite.wc.Add(secureService(suite.sp, deploymentService))
+	suite.wc.Add(secureService(suite.sp, userService))
 
 	token, _ := loginGetToken(suite.wc)
 	suite.NotEmpty(token)
@@ -166,6 +166,143 @@ func (suite *DeploymentTestSuite) TestCreateDeploymentFail() {
 	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
 }
 
+func (suite *DeploymentTestSuite) TestCreateDeploymentWithProbes() {
+	tName := namesgenerator.GetRandomName(0)
+	deploy := entity.Deployment{
+		Name:      tName,
+		Namespace: "default",
+		Labels:    map[string]string{},
+		EnvVars:   map[string]string{},
+		Containers: []entity.Container{
+			{
+				Name:    namesgenerator.GetRandomName(0),
+				Image:   "nginx",
+				Command: []string{"nginx", "-g", "daemon off;"},
+				LivenessProbe: &entity.Probe{
+					TCPSocket: &entity.TCPSocketProbe{Port: 80},
+				},
+				ReadinessProbe: &entity.Probe{
+					HTTPGet:       &entity.HTTPGetProbe{Path: "/", Port: 80},
+					PeriodSeconds: 5,
+				},
+			},
+		},
+		Volumes:      []entity.DeploymentVolume{},
+		ConfigMaps:   []entity.DeploymentConfig{},
+		Networks:     []entity.DeploymentNetwork{},
+		NetworkType:  entity.DeploymentClusterNetwork,
+		NodeAffinity: []string{},
+		Replicas:     1,
+	}
+	post := func(deploy entity.Deployment) *httptest.ResponseRecorder {
+		bodyBytes, err := json.MarshalIndent(deploy, "", "  ")
+		suite.NoError(err)
+		httpRequest, err := http.NewRequest("POST", "http://localhost:7890/v1/deployments", bytes.NewReader(bodyBytes))
+		suite.NoError(err)
+		httpRequest.Header.Add("Content-Type", "application/json")
+		httpRequest.Header.Add("Authorization", suite.JWTBearer)
+		httpWriter := httptest.NewRecorder()
+		suite.wc.Dispatch(httpWriter, httpRequest)
+		return httpWriter
+	}
+
+	// the probe has two handlers
+	invalid := deploy
+	invalid.Containers = []entity.Container{deploy.Containers[0]}
+	invalid.Containers[0].LivenessProbe = &entity.Probe{
+		TCPSocket: &entity.TCPSocketProbe{Port: 80},
+		Exec:      &entity.ExecProbe{Command: []string{"true"}},
+	}
+	assertResponseCode(suite.T(), http.StatusBadRequest, post(invalid))
+
+	assertResponseCode(suite.T(), http.StatusCreated, post(deploy))
+	defer suite.session.Remove(entity.DeploymentCollectionName, "name", tName)
+	defer p.DeleteDeployment(suite.sp, &deploy)
+
+	retDeployment := entity.Deployment{}
+	err := suite.session.FindOne(entity.DeploymentCollectionName, bson.M{"name": tName}, &retDeployment)
+	suite.NoError(err)
+	suite.Equal(deploy.Containers[0].ReadinessProbe, retDeployment.Containers[0].ReadinessProbe)
+
+	live, err := suite.sp.KubeCtl.GetDeployment(tName, "default")
+	suite.NoError(err)
+	container := live.Spec.Template.Spec.Containers[0]
+	suite.Equal(80, container.LivenessProbe.TCPSocket.Port.IntValue())
+	suite.Equal("/", container.ReadinessProbe.HTTPGet.Path)
+	suite.Equal(int32(5), container.ReadinessProbe.PeriodSeconds)
+}
+
+func (suite *DeploymentTestSuite) TestCreateDeploymentWithSecrets() {
+	secretName := namesgenerator.GetRandomName(0)
+	err := suite.session.Insert(entity.SecretCollectionName, entity.Secret{
+		ID:        bson.NewObjectId(),
+		Name:      secretName,
+		Namespace: "default",
+		Keys:      []string{"password"},
+	})
+	suite.NoError(err)
+	defer suite.session.Remove(entity.SecretCollectionName, "name", secretName)
+
+	tName := namesgenerator.GetRandomName(0)
+	deploy := entity.Deployment{
+		Name:      tName,
+		Namespace: "default",
+		Labels:    map[string]string{},
+		EnvVars:   map[string]string{},
+		Containers: []entity.Container{
+			{
+				Name:    namesgenerator.GetRandomName(0),
+				Image:   "busybox",
+				Command: []string{"sleep", "3600"},
+				EnvVarsFrom: []entity.EnvVarSource{
+					{Name: "PASSWORD", SecretKeyRef: &entity.KeySelector{Name: secretName, Key: "password"}},
+				},
+			},
+		},
+		Volumes:      []entity.DeploymentVolume{},
+		ConfigMaps:   []entity.DeploymentConfig{},
+		Secrets:      []entity.SecretVolume{{Name: secretName, MountPath: "/etc/secret"}},
+		Networks:     []entity.DeploymentNetwork{},
+		NetworkType:  entity.DeploymentClusterNetwork,
+		NodeAffinity: []string{},
+		Replicas:     1,
+	}
+	post := func(deploy entity.Deployment) *httptest.ResponseRecorder {
+		bodyBytes, err := json.MarshalIndent(deploy, "", "  ")
+		suite.NoError(err)
+		httpRequest, err := http.NewRequest("POST", "http://localhost:7890/v1/deployments", bytes.NewReader(bodyBytes))
+		suite.NoError(err)
+		httpRequest.Header.Add("Content-Type", "application/json")
+		httpRequest.Header.Add("Authorization", suite.JWTBearer)
+		httpWriter := httptest.NewRecorder()
+		suite.wc.Dispatch(httpWriter, httpRequest)
+		return httpWriter
+	}
+
+	// the secret doesn't exist
+	invalid := deploy
+	invalid.Secrets = []entity.SecretVolume{{Name: namesgenerator.GetRandomName(0), MountPath: "/etc/secret"}}
+	assertResponseCode(suite.T(), http.StatusBadRequest, post(invalid))
+
+	assertResponseCode(suite.T(), http.StatusCreated, post(deploy))
+	defer suite.session.Remove(entity.DeploymentCollectionName, "name", tName)
+	defer p.DeleteDeployment(suite.sp, &deploy)
+
+	live, err := suite.sp.KubeCtl.GetDeployment(tName, "default")
+	suite.NoError(err)
+	podSpec := live.Spec.Template.Spec
+	found := false
+	for _, v := range podSpec.Volumes {
+		if v.Secret != nil && v.Secret.SecretName == secretName {
+			found = true
+		}
+	}
+	suite.True(found)
+	env := podSpec.Containers[0].Env
+	suite.Equal("PASSWORD", env[len(env)-1].Name)
+	suite.Equal(secretName, env[len(env)-1].ValueFrom.SecretKeyRef.Name)
+}
+
 func (suite *DeploymentTestSuite) TestDeleteDeployment() {
 	namespace := "default"
 	containers := []entity.Container{
@@ -274,6 +411,62 @@ func (suite *DeploymentTestSuite) TestGetDeploymentWithInvalidID() {
 	assertResponseCode(suite.T(), http.StatusNotFound, httpWriter)
 }
 
+func (suite *DeploymentTestSuite) TestGetDeploymentStatus() {
+	namespace := "default"
+	tName := namesgenerator.GetRandomName(0)
+	deploy := entity.Deployment{
+		ID:        bson.NewObjectId(),
+		Name:      tName,
+		Namespace: namespace,
+		Containers: []entity.Container{
+			{
+				Name:    namesgenerator.GetRandomName(0),
+				Image:   "busybox",
+				Command: []string{"sleep", "3600"},
+			},
+		},
+	}
+
+	//Create data into mongo manually
+	suite.session.C(entity.DeploymentCollectionName).Insert(deploy)
+	defer suite.session.Remove(entity.DeploymentCollectionName, "name", tName)
+
+	getStatus := func() *httptest.ResponseRecorder {
+		httpRequest, err := http.NewRequest("GET", "http://localhost:7890/v1/deployments/"+deploy.ID.Hex()+"/status", nil)
+		suite.NoError(err)
+		httpRequest.Header.Add("Authorization", suite.JWTBearer)
+		httpWriter := httptest.NewRecorder()
+		suite.wc.Dispatch(httpWriter, httpRequest)
+		return httpWriter
+	}
+	// the deployment is not running
+	assertResponseCode(suite.T(), http.StatusNotFound, getStatus())
+
+	err := p.CreateDeployment(suite.sp, &deploy)
+	suite.NoError(err)
+	defer p.DeleteDeployment(suite.sp, &deploy)
+
+	httpWriter := getStatus()
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	status := entity.DeploymentStatus{}
+	err = json.Unmarshal(httpWriter.Body.Bytes(), &status)
+	suite.NoError(err)
+	suite.Equal(tName, status.Name)
+	suite.Equal(namespace, status.Namespace)
+	suite.NotNil(status.Pods)
+	suite.NotNil(status.Events)
+}
+
+func (suite *DeploymentTestSuite) TestGetDeploymentStatusWithInvalidID() {
+	httpRequest, err := http.NewRequest("GET", "http://localhost:7890/v1/deployments/"+bson.NewObjectId().Hex()+"/status", nil)
+	suite.NoError(err)
+
+	httpRequest.Header.Add("Authorization", suite.JWTBearer)
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusNotFound, httpWriter)
+}
+
 func (suite *DeploymentTestSuite) TestListDeployment() {
 	namespace := "default"
 	deployments := []entity.Deployment{}
@@ -627,3 +820,258 @@ func (suite *DeploymentTestSuite) TestDisableDeploymentWithAutoscaler() {
 	suite.False(retDeployment.IsEnableAutoscale)
 	defer p.DeleteAutoscaler(suite.sp, autoscaler)
 }
+
+func (suite *DeploymentTestSuite) TestEnableAutoscalerWithInvalidMetrics() {
+	testCases := []struct {
+		caseName   string
+		autoscaler entity.AutoscalerInfo
+	}{
+		{"noMetric", entity.AutoscalerInfo{
+			Namespace:          "default",
+			ScaleTargetRefName: namesgenerator.GetRandomName(0),
+			MinReplicas:        1,
+			MaxReplicas:        5,
+		}},
+		{"invalidMetricName", entity.AutoscalerInfo{
+			Namespace:          "default",
+			ScaleTargetRefName: namesgenerator.GetRandomName(0),
+			MinReplicas:        1,
+			MaxReplicas:        5,
+			Metrics: []entity.AutoscalerMetric{
+				{Type: entity.AutoscalerPodsMetric, MetricName: "packets per second", TargetAverageValue: "10k"},
+			},
+		}},
+		{"objectWithoutTarget", entity.AutoscalerInfo{
+			Namespace:          "default",
+			ScaleTargetRefName: namesgenerator.GetRandomName(0),
+			MinReplicas:        1,
+			MaxReplicas:        5,
+			Metrics: []entity.AutoscalerMetric{
+				{Type: entity.AutoscalerObjectMetric, MetricName: "requests", TargetValue: "500"},
+			},
+		}},
+		{"maxLessThanMin", entity.AutoscalerInfo{
+			Namespace:                "default",
+			ScaleTargetRefName:       namesgenerator.GetRandomName(0),
+			ResourceName:             corev1.ResourceCPU,
+			MinReplicas:              5,
+			MaxReplicas:              1,
+			TargetAverageUtilization: 30,
+		}},
+	}
+
+	for _, tc := range testCases {
+		suite.T().Run(tc.caseName, func(t *testing.T) {
+			bodyBytes, err := json.MarshalIndent(tc.autoscaler, "", "  ")
+			suite.NoError(err)
+
+			httpRequest, err := http.NewRequest("PUT", "http://localhost:7890/v1/deployments/autoscale?enable=true", bytes.NewReader(bodyBytes))
+			suite.NoError(err)
+			httpRequest.Header.Add("Content-Type", "application/json")
+			httpRequest.Header.Add("Authorization", suite.JWTBearer)
+			httpWriter := httptest.NewRecorder()
+			suite.wc.Dispatch(httpWriter, httpRequest)
+			assertResponseCode(t, http.StatusBadRequest, httpWriter)
+		})
+	}
+}
+
+func (suite *DeploymentTestSuite) putDeployment(id string, deploy entity.Deployment) *httptest.ResponseRecorder {
+	bodyBytes, err := json.MarshalIndent(deploy, "", "  ")
+	suite.NoError(err)
+
+	httpRequest, err := http.NewRequest("PUT", "http://localhost:7890/v1/deployments/"+id, bytes.NewReader(bodyBytes))
+	suite.NoError(err)
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", suite.JWTBearer)
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	return httpWriter
+}
+
+func (suite *DeploymentTestSuite) TestUpdateDeployment() {
+	deploy := entity.Deployment{
+		Name:      namesgenerator.GetRandomName(0),
+		Namespace: "default",
+		Labels:    map[string]string{},
+		EnvVars:   map[string]string{},
+		Containers: []entity.Container{
+			{
+				Name:    namesgenerator.GetRandomName(0),
+				Image:   "busybox:1.28",
+				Command: []string{"sleep", "3600"},
+			},
+		},
+		Volumes:      []entity.DeploymentVolume{},
+		ConfigMaps:   []entity.DeploymentConfig{},
+		Networks:     []entity.DeploymentNetwork{},
+		NetworkType:  entity.DeploymentClusterNetwork,
+		NodeAffinity: []string{},
+		Replicas:     1,
+	}
+	bodyBytes, err := json.MarshalIndent(deploy, "", "  ")
+	suite.NoError(err)
+	httpRequest, err := http.NewRequest("POST", "http://localhost:7890/v1/deployments", bytes.NewReader(bodyBytes))
+	suite.NoError(err)
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", suite.JWTBearer)
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusCreated, httpWriter)
+	defer suite.session.Remove(entity.DeploymentCollectionName, "name", deploy.Name)
+	defer p.DeleteDeployment(suite.sp, &deploy)
+
+	created := entity.Deployment{}
+	err = json.Unmarshal(httpWriter.Body.Bytes(), &created)
+	suite.NoError(err)
+
+	deploy.Containers[0].Image = "busybox:1.29"
+	deploy.EnvVars["DEBUG"] = "true"
+	deploy.Replicas = 3
+	deploy.Strategy = entity.DeploymentStrategy{
+		Type:           entity.DeploymentRollingUpdateStrategy,
+		MaxSurge:       "1",
+		MaxUnavailable: "0",
+	}
+	httpWriter = suite.putDeployment(created.ID.Hex(), deploy)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	retDeployment := entity.Deployment{}
+	err = suite.session.FindOne(entity.DeploymentCollectionName, bson.M{"_id": created.ID}, &retDeployment)
+	suite.NoError(err)
+	suite.Equal(int32(3), retDeployment.Replicas)
+	suite.Equal("busybox:1.29", retDeployment.Containers[0].Image)
+	suite.Equal(deploy.Strategy, retDeployment.Strategy)
+	suite.Equal(created.OwnerID, retDeployment.OwnerID)
+	// the owner labels are kept
+	suite.Equal(created.Labels[p.NotificationEmailAccount], retDeployment.Labels[p.NotificationEmailAccount])
+
+	live, err := suite.sp.KubeCtl.GetDeployment(deploy.Name, deploy.Namespace)
+	suite.NoError(err)
+	suite.Equal(int32(3), *live.Spec.Replicas)
+	suite.Equal("busybox:1.29", live.Spec.Template.Spec.Containers[0].Image)
+	suite.Equal("RollingUpdate", string(live.Spec.Strategy.Type))
+
+	testCases := []struct {
+		cases        string
+		id           string
+		update       func(d entity.Deployment) entity.Deployment
+		expectedCode int
+	}{
+		{"Rename", created.ID.Hex(), func(d entity.Deployment) entity.Deployment {
+			d.Name = namesgenerator.GetRandomName(0)
+			return d
+		}, http.StatusBadRequest},
+		{"InvalidStrategy", created.ID.Hex(), func(d entity.Deployment) entity.Deployment {
+			d.Strategy = entity.DeploymentStrategy{Type: "BlueGreen"}
+			return d
+		}, http.StatusBadRequest},
+		{"InvalidMaxSurge", created.ID.Hex(), func(d entity.Deployment) entity.Deployment {
+			d.Strategy = entity.DeploymentStrategy{Type: entity.DeploymentRollingUpdateStrategy, MaxSurge: "many"}
+			return d
+		}, http.StatusBadRequest},
+		{"NotFound", bson.NewObjectId().Hex(), func(d entity.Deployment) entity.Deployment {
+			return d
+		}, http.StatusNotFound},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.cases, func(t *testing.T) {
+			assertResponseCode(t, tc.expectedCode, suite.putDeployment(tc.id, tc.update(deploy)))
+		})
+	}
+}
+
+func (suite *DeploymentTestSuite) TestDeploymentRollback() {
+	deploy := entity.Deployment{
+		Name:      namesgenerator.GetRandomName(0),
+		Namespace: "default",
+		Labels:    map[string]string{},
+		EnvVars:   map[string]string{},
+		Containers: []entity.Container{
+			{
+				Name:    namesgenerator.GetRandomName(0),
+				Image:   "busybox:1.28",
+				Command: []string{"sleep", "3600"},
+			},
+		},
+		Volumes:      []entity.DeploymentVolume{},
+		ConfigMaps:   []entity.DeploymentConfig{},
+		Networks:     []entity.DeploymentNetwork{},
+		NetworkType:  entity.DeploymentClusterNetwork,
+		NodeAffinity: []string{},
+		Replicas:     1,
+	}
+	bodyBytes, err := json.MarshalIndent(deploy, "", "  ")
+	suite.NoError(err)
+	httpRequest, err := http.NewRequest("POST", "http://localhost:7890/v1/deployments", bytes.NewReader(bodyBytes))
+	suite.NoError(err)
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", suite.JWTBearer)
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusCreated, httpWriter)
+	defer suite.session.Remove(entity.DeploymentCollectionName, "name", deploy.Name)
+	defer p.DeleteDeployment(suite.sp, &deploy)
+
+	created := entity.Deployment{}
+	err = json.Unmarshal(httpWriter.Body.Bytes(), &created)
+	suite.NoError(err)
+	defer suite.session.C(entity.DeploymentRevisionCollectionName).RemoveAll(bson.M{"deploymentID": created.ID})
+
+	deploy.Containers[0].Image = "busybox:1.29"
+	assertResponseCode(suite.T(), http.StatusOK, suite.putDeployment(created.ID.Hex(), deploy))
+
+	listRevisions := func() []entity.DeploymentRevision {
+		httpRequest, err := http.NewRequest("GET", "http://localhost:7890/v1/deployments/"+created.ID.Hex()+"/revisions", nil)
+		suite.NoError(err)
+		httpRequest.Header.Add("Authorization", suite.JWTBearer)
+		httpWriter := httptest.NewRecorder()
+		suite.wc.Dispatch(httpWriter, httpRequest)
+		assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+		revisions := []entity.DeploymentRevision{}
+		suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &revisions))
+		return revisions
+	}
+	revisions := listRevisions()
+	suite.Len(revisions, 2)
+	suite.Equal(2, revisions[0].Revision)
+	suite.Equal("busybox:1.29", revisions[0].Spec.Containers[0].Image)
+	suite.Equal("busybox:1.28", revisions[1].Spec.Containers[0].Image)
+
+	rollback := func(revision string) *httptest.ResponseRecorder {
+		httpRequest, err := http.NewRequest("POST", "http://localhost:7890/v1/deployments/"+created.ID.Hex()+"/rollback?revision="+revision, nil)
+		suite.NoError(err)
+		httpRequest.Header.Add("Authorization", suite.JWTBearer)
+		httpWriter := httptest.NewRecorder()
+		suite.wc.Dispatch(httpWriter, httpRequest)
+		return httpWriter
+	}
+	assertResponseCode(suite.T(), http.StatusOK, rollback("1"))
+
+	retDeployment := entity.Deployment{}
+	err = suite.session.FindOne(entity.DeploymentCollectionName, bson.M{"_id": created.ID}, &retDeployment)
+	suite.NoError(err)
+	suite.Equal("busybox:1.28", retDeployment.Containers[0].Image)
+	live, err := suite.sp.KubeCtl.GetDeployment(deploy.Name, deploy.Namespace)
+	suite.NoError(err)
+	suite.Equal("busybox:1.28", live.Spec.Template.Spec.Containers[0].Image)
+
+	revisions = listRevisions()
+	suite.Len(revisions, 3)
+	suite.Equal(3, revisions[0].Revision)
+	suite.Equal(1, revisions[0].RollbackOf)
+
+	assertResponseCode(suite.T(), http.StatusBadRequest, rollback("latest"))
+	assertResponseCode(suite.T(), http.StatusNotFound, rollback("9"))
+
+	// the running spec of a deployment without revisions is stored as revision 1 on the first update
+	_, err = suite.session.C(entity.DeploymentRevisionCollectionName).RemoveAll(bson.M{"deploymentID": created.ID})
+	suite.NoError(err)
+	deploy.Containers[0].Image = "busybox:1.30"
+	assertResponseCode(suite.T(), http.StatusOK, suite.putDeployment(created.ID.Hex(), deploy))
+	revisions = listRevisions()
+	suite.Len(revisions, 2)
+	suite.Equal("busybox:1.30", revisions[0].Spec.Containers[0].Image)
+	suite.Equal(1, revisions[1].Revision)
+	suite.Equal("busybox:1.28", revisions[1].Spec.Containers[0].Image)
+}
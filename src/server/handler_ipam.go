@@ -0,0 +1,95 @@
+package server
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/ipam"
+	response "github.com/linkernetworks/vortex/src/net/http"
+	"github.com/linkernetworks/vortex/src/net/http/query"
+	"github.com/linkernetworks/vortex/src/web"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// allocateIPHandler is called by the init containers of the pods with the tokens of their service accounts, the address
+// is only allocated to the interface declared by an init container of the running pod with the same UID
+func allocateIPHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	request := entity.IPAllocationRequest{}
+	if err := req.ReadEntity(&request); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	if err := sp.Validator.Struct(request); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	namespace, _ := req.Attribute("ServiceAccountNamespace").(string)
+	serviceAccount, _ := req.Attribute("ServiceAccount").(string)
+	allocation, err := ipam.Allocate(sp, &request, namespace, serviceAccount)
+	if err != nil {
+		switch err {
+		case ipam.ErrServiceAccountMismatch, ipam.ErrPodMismatch, ipam.ErrUndeclaredInterface:
+			response.Forbidden(req.Request, resp.ResponseWriter, err)
+		case ipam.ErrNoSubnet:
+			response.BadRequest(req.Request, resp.ResponseWriter, err)
+		case ipam.ErrExhausted:
+			response.Conflict(req.Request, resp.ResponseWriter, err)
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+	resp.WriteHeaderAndEntity(http.StatusCreated, allocation)
+}
+
+func listIPAllocationHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	var pageSize = 1024
+	query := query.New(req.Request.URL.Query())
+
+	page, err := query.Int("page", 1)
+	if err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	pageSize, err = query.Int("page_size", pageSize)
+	if err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	selector := bson.M{}
+	if network, ok := query.Str("network"); ok {
+		selector["network"] = network
+	}
+	allocations := []entity.IPAllocation{}
+	q := session.C(entity.IPAllocationCollectionName).Find(selector).Sort("_id").Skip((page - 1) * pageSize).Limit(pageSize)
+	if err := q.All(&allocations); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	count, err := session.Count(entity.IPAllocationCollectionName, selector)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	totalPages := int(math.Ceil(float64(count) / float64(pageSize)))
+	resp.AddHeader("X-Total-Count", strconv.Itoa(count))
+	resp.AddHeader("X-Total-Pages", strconv.Itoa(totalPages))
+	resp.WriteEntity(allocations)
+}
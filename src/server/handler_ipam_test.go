@@ -0,0 +1,171 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/kubeutils"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+)
+
+// podBearer is the token of the default service account running the pods of the tests
+const podBearer = "Bearer system:serviceaccount:default:default"
+
+type IPAMTestSuite struct {
+	suite.Suite
+	sp        *serviceprovider.Container
+	wc        *restful.Container
+	session   *mongo.Session
+	JWTBearer string
+	network   entity.Network
+}
+
+func (suite *IPAMTestSuite) SetupSuite() {
+	cf := config.MustRead("../../config/testing.json")
+	sp := serviceprovider.NewForTesting(cf)
+
+	suite.sp = sp
+	// init session
+	suite.session = sp.Mongo.NewSession()
+	// init restful container
+	suite.wc = restful.NewContainer()
+	suite.wc.Add(secureService(suite.sp, newIPAMService(suite.sp)))
+	suite.wc.Add(secureService(suite.sp, newUserService(suite.sp)))
+
+	// the fake clientset doesn't review the tokens, the token is the user name here
+	sp.KubeCtl.Clientset.(*fakeclientset.Clientset).PrependReactor("create", "tokenreviews", func(action core.Action) (bool, runtime.Object, error) {
+		review := action.(core.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		review.Status.Authenticated = review.Spec.Token != ""
+		review.Status.User.Username = review.Spec.Token
+		return true, review, nil
+	})
+
+	token, _ := loginGetToken(suite.wc)
+	suite.NotEmpty(token)
+	suite.JWTBearer = "Bearer " + token
+
+	suite.network = entity.Network{
+		ID:      bson.NewObjectId(),
+		Name:    namesgenerator.GetRandomName(0),
+		Subnets: []entity.Subnet{{CIDR: "10.1.0.0/24", Gateway: "10.1.0.1"}},
+	}
+	suite.session.Insert(entity.NetworkCollectionName, suite.network)
+}
+
+func (suite *IPAMTestSuite) TearDownSuite() {
+	suite.session.Remove(entity.NetworkCollectionName, "_id", suite.network.ID)
+	suite.session.C(entity.IPAllocationCollectionName).RemoveAll(bson.M{"network": suite.network.Name})
+}
+
+func TestIPAMSuite(t *testing.T) {
+	suite.Run(t, new(IPAMTestSuite))
+}
+
+func (suite *IPAMTestSuite) allocate(bearer string, request entity.IPAllocationRequest) *httptest.ResponseRecorder {
+	bodyBytes, err := json.MarshalIndent(request, "", "  ")
+	suite.NoError(err)
+	httpRequest, err := http.NewRequest("POST", "http://localhost:7890/v1/ipam/allocations", bytes.NewReader(bodyBytes))
+	suite.NoError(err)
+	// the init containers have the tokens of the service accounts of their pods
+	httpRequest.Header.Add("Content-Type", "application/json")
+	if bearer != "" {
+		httpRequest.Header.Add("Authorization", bearer)
+	}
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	return httpWriter
+}
+
+func (suite *IPAMTestSuite) createPod() *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: namesgenerator.GetRandomName(0),
+			UID:  types.UID(bson.NewObjectId().Hex()),
+		},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{
+				kubeutils.GenerateIPAMContainer(0, "http://vortex:7890", suite.network.Name, "eth1"),
+			},
+		},
+	}
+	_, err := suite.sp.KubeCtl.CreatePod(pod, "default")
+	suite.NoError(err)
+	return pod
+}
+
+func (suite *IPAMTestSuite) TestAllocateIP() {
+	pod := suite.createPod()
+	defer suite.sp.KubeCtl.DeletePod(pod.Name, "default")
+
+	request := entity.IPAllocationRequest{
+		Network:   suite.network.Name,
+		IfName:    "eth1",
+		PodName:   pod.Name,
+		Namespace: "default",
+		PodUID:    string(pod.UID),
+	}
+	httpWriter := suite.allocate(podBearer, request)
+	assertResponseCode(suite.T(), http.StatusCreated, httpWriter)
+	allocation := entity.IPAllocation{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &allocation))
+	suite.Equal("10.1.0.2/24", allocation.CIDR)
+	suite.Equal("10.1.0.1", allocation.Gateway)
+
+	// list the allocations of the network
+	httpRequest, err := http.NewRequest("GET", "http://localhost:7890/v1/ipam/allocations?network="+suite.network.Name, nil)
+	suite.NoError(err)
+	httpRequest.Header.Add("Authorization", suite.JWTBearer)
+	httpWriter = httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	allocations := []entity.IPAllocation{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &allocations))
+	suite.Len(allocations, 1)
+	suite.Equal(pod.Name, allocations[0].PodName)
+}
+
+func (suite *IPAMTestSuite) TestAllocateIPFail() {
+	pod := suite.createPod()
+	defer suite.sp.KubeCtl.DeletePod(pod.Name, "default")
+
+	request := entity.IPAllocationRequest{
+		Network:   suite.network.Name,
+		IfName:    "eth1",
+		PodName:   pod.Name,
+		Namespace: "default",
+		PodUID:    "awesome",
+	}
+	// the pod UID doesn't match
+	assertResponseCode(suite.T(), http.StatusForbidden, suite.allocate(podBearer, request))
+
+	// the caller has no token or isn't a service account running the pod
+	request.PodUID = string(pod.UID)
+	assertResponseCode(suite.T(), http.StatusUnauthorized, suite.allocate("", request))
+	assertResponseCode(suite.T(), http.StatusUnauthorized, suite.allocate(suite.JWTBearer, request))
+	assertResponseCode(suite.T(), http.StatusForbidden, suite.allocate("Bearer system:serviceaccount:vortex:default", request))
+
+	// the network doesn't exist
+	request.Network = namesgenerator.GetRandomName(0)
+	assertResponseCode(suite.T(), http.StatusNotFound, suite.allocate(podBearer, request))
+
+	request.IfName = ""
+	assertResponseCode(suite.T(), http.StatusBadRequest, suite.allocate(podBearer, request))
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/suite"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+type JobTestSuite struct {
+	ServerTestSuite
+	network entity.Network
+}
+
+func (suite *JobTestSuite) SetupSuite() {
+	suite.setupServices(newJobService)
+
+	suite.network = entity.Network{
+		ID:         bson.NewObjectId(),
+		Name:       namesgenerator.GetRandomName(0),
+		BridgeName: namesgenerator.GetRandomName(0),
+		Nodes: []entity.Node{
+			{Name: "node1"},
+		},
+	}
+	suite.session.Insert(entity.NetworkCollectionName, suite.network)
+}
+
+func (suite *JobTestSuite) TearDownSuite() {
+	suite.session.Remove(entity.NetworkCollectionName, "_id", suite.network.ID)
+}
+
+func TestJobSuite(t *testing.T) {
+	suite.Run(t, new(JobTestSuite))
+}
+
+func (suite *JobTestSuite) newJob() entity.Job {
+	return entity.Job{
+		Name:      namesgenerator.GetRandomName(0),
+		Namespace: "default",
+		JobSpec: entity.JobSpec{
+			Labels:  map[string]string{},
+			EnvVars: map[string]string{},
+			Containers: []entity.Container{
+				{
+					Name:    namesgenerator.GetRandomName(0),
+					Image:   "busybox",
+					Command: []string{"echo", "done"},
+				},
+			},
+			Volumes:       []entity.PodVolume{},
+			Networks:      []entity.PodNetwork{},
+			RestartPolicy: "Never",
+			NetworkType:   entity.PodClusterNetwork,
+			NodeAffinity:  []string{},
+			Completions:   3,
+			Parallelism:   3,
+		},
+	}
+}
+
+func (suite *JobTestSuite) TestCreateJob() {
+	j := suite.newJob()
+	httpWriter := suite.request("POST", "/v1/jobs", j)
+	assertResponseCode(suite.T(), http.StatusCreated, httpWriter)
+	defer suite.session.Remove(entity.JobCollectionName, "name", j.Name)
+
+	created := entity.Job{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &created))
+	suite.Equal(j.Name, created.Name)
+
+	current, err := suite.sp.KubeCtl.GetJob(j.Name, j.Namespace)
+	suite.NoError(err)
+	suite.Equal(int32(3), *current.Spec.Completions)
+
+	// the name is used
+	httpWriter = suite.request("POST", "/v1/jobs", j)
+	assertResponseCode(suite.T(), http.StatusConflict, httpWriter)
+
+	httpWriter = suite.request("GET", "/v1/jobs/"+created.ID.Hex(), nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	httpWriter = suite.request("GET", "/v1/jobs/"+created.ID.Hex()+"/status", nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	run := entity.JobRun{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &run))
+	suite.Equal(entity.JobRunning, run.Status)
+
+	httpWriter = suite.request("GET", "/v1/jobs/?namespace=default", nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	jobs := []entity.Job{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &jobs))
+	suite.NotEmpty(jobs)
+
+	httpWriter = suite.request("DELETE", "/v1/jobs/"+created.ID.Hex(), nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	_, err = suite.sp.KubeCtl.GetJob(j.Name, j.Namespace)
+	suite.Error(err)
+
+	httpWriter = suite.request("DELETE", "/v1/jobs/"+created.ID.Hex(), nil)
+	assertResponseCode(suite.T(), http.StatusNotFound, httpWriter)
+}
+
+func (suite *JobTestSuite) TestCreateJobFail() {
+	// the parallel pods can't share the static address
+	j := suite.newJob()
+	j.NetworkType = entity.PodCustomNetwork
+	j.Networks = []entity.PodNetwork{
+		{
+			Name:       suite.network.Name,
+			IfName:     "eth1",
+			IPAddress:  "10.1.0.10",
+			Netmask:    "255.255.255.0",
+			RoutesGw:   []entity.PodRouteGw{},
+			RoutesIntf: []entity.PodRouteIntf{},
+		},
+	}
+	httpWriter := suite.request("POST", "/v1/jobs", j)
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+
+	j = suite.newJob()
+	j.RestartPolicy = "Always"
+	httpWriter = suite.request("POST", "/v1/jobs", j)
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+}
+
+func (suite *JobTestSuite) TestGetJobWithInvalidID() {
+	httpWriter := suite.request("GET", "/v1/jobs/"+bson.NewObjectId().Hex(), nil)
+	assertResponseCode(suite.T(), http.StatusNotFound, httpWriter)
+
+	httpWriter = suite.request("GET", "/v1/jobs/"+bson.NewObjectId().Hex()+"/status", nil)
+	assertResponseCode(suite.T(), http.StatusNotFound, httpWriter)
+
+	httpWriter = suite.request("GET", "/v1/jobs/invalid", nil)
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/linkernetworks/vortex/src/namespace"
 	response "github.com/linkernetworks/vortex/src/net/http"
 	"github.com/linkernetworks/vortex/src/net/http/query"
+	"github.com/linkernetworks/vortex/src/registry"
 	"github.com/linkernetworks/vortex/src/server/backend"
 	"github.com/linkernetworks/vortex/src/web"
 	"k8s.io/api/core/v1"
@@ -48,6 +49,12 @@ func createNamespaceHandler(ctx *web.Context) {
 	})
 	defer session.Close()
 
+	teamID, ok := namespaceTeam(ctx, session, n.TeamID.Hex())
+	if !ok {
+		return
+	}
+	n.TeamID = teamID
+
 	// Check whether this name has been used
 	n.ID = bson.NewObjectId()
 	n.CreatedAt = timeutils.Now()
@@ -140,7 +147,7 @@ func listNamespaceHandler(ctx *web.Context) {
 	var c = session.C(entity.NamespaceCollectionName)
 	var q *mgo.Query
 
-	selector := bson.M{}
+	selector := namespaceSelector(req, "name")
 	q = c.Find(selector).Sort("_id").Skip((page - 1) * pageSize).Limit(pageSize)
 
 	if err := q.All(&namespaces); err != nil {
@@ -159,7 +166,7 @@ func listNamespaceHandler(ctx *web.Context) {
 		// find owner in user entity
 		namespaces[i].CreatedBy, _ = backend.FindUserByID(session, namespace.OwnerID)
 	}
-	count, err := session.Count(entity.NamespaceCollectionName, bson.M{})
+	count, err := session.Count(entity.NamespaceCollectionName, selector)
 	if err != nil {
 		response.InternalServerError(req.Request, resp.ResponseWriter, err)
 		return
@@ -250,6 +257,12 @@ func uploadNamespaceYAMLHandler(ctx *web.Context) {
 	})
 	defer session.Close()
 
+	teamID, ok := namespaceTeam(ctx, session, req.Request.FormValue("teamID"))
+	if !ok {
+		return
+	}
+	d.TeamID = teamID
+
 	d.CreatedAt = timeutils.Now()
 	_, err = sp.KubeCtl.CreateNamespace(namespaceObj)
 	if err != nil {
@@ -264,6 +277,11 @@ func uploadNamespaceYAMLHandler(ctx *web.Context) {
 		}
 		return
 	}
+	if err := registry.ApplyNamespace(sp, d.Name, d.TeamID); err != nil {
+		sp.KubeCtl.DeleteNamespace(d.Name)
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
 
 	if err := session.Insert(entity.NamespaceCollectionName, &d); err != nil {
 		if mgo.IsDup(err) {
@@ -277,3 +295,147 @@ func uploadNamespaceYAMLHandler(ctx *web.Context) {
 	d.CreatedBy, _ = backend.FindUserByID(session, d.OwnerID)
 	resp.WriteHeaderAndEntity(http.StatusCreated, d)
 }
+
+// assignNamespaceTeamHandler moves the namespace to another team, or leaves it without a team if the team is empty.
+// The role must to have admin permission to access it.
+func assignNamespaceTeamHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	id := req.PathParameter("id")
+	if !bson.IsObjectIdHex(id) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid namespace ID: %s", id))
+		return
+	}
+
+	assignment := entity.NamespaceTeamRequest{}
+	if err := req.ReadEntity(&assignment); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	teamID, ok := namespaceTeam(ctx, session, assignment.TeamID)
+	if !ok {
+		return
+	}
+
+	update := bson.M{"$unset": bson.M{"teamID": ""}}
+	if teamID != "" {
+		update = bson.M{"$set": bson.M{"teamID": teamID}}
+	}
+	if err := session.C(entity.NamespaceCollectionName).UpdateId(bson.ObjectIdHex(id), update); err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+
+	n := entity.Namespace{}
+	if err := session.FindOne(entity.NamespaceCollectionName, bson.M{"_id": bson.ObjectIdHex(id)}, &n); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	// the registry credentials granted to the new team are copied and the others are deleted
+	if err := registry.ApplyNamespace(sp, n.Name, n.TeamID); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	n.CreatedBy, _ = backend.FindUserByID(session, n.OwnerID)
+	resp.WriteEntity(n)
+}
+
+// updateNamespaceQuotaHandler replaces the quota and the limit range of the namespace. The role must to have admin permission to access it.
+func updateNamespaceQuotaHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	id := req.PathParameter("id")
+	if !bson.IsObjectIdHex(id) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid namespace ID: %s", id))
+		return
+	}
+
+	quota := entity.NamespaceQuotaRequest{}
+	if err := req.ReadEntity(&quota); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if err := sp.Validator.Struct(quota); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	n := entity.Namespace{}
+	if err := session.FindOne(entity.NamespaceCollectionName, bson.M{"_id": bson.ObjectIdHex(id)}, &n); err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+
+	n.Quota, n.LimitRange = quota.Quota, quota.LimitRange
+	if err := namespace.ApplyQuota(sp, &n); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if err := namespace.ApplyLimitRange(sp, &n); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	if err := session.C(entity.NamespaceCollectionName).UpdateId(n.ID, bson.M{"$set": bson.M{
+		"quota":      n.Quota,
+		"limitRange": n.LimitRange,
+	}}); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	n.CreatedBy, _ = backend.FindUserByID(session, n.OwnerID)
+	resp.WriteEntity(n)
+}
+
+// getNamespaceQuotaHandler returns the quota of the namespace and the current usage against it
+func getNamespaceQuotaHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	id := req.PathParameter("id")
+	if !bson.IsObjectIdHex(id) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid namespace ID: %s", id))
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	n := entity.Namespace{}
+	if err := session.FindOne(entity.NamespaceCollectionName, bson.M{"_id": bson.ObjectIdHex(id)}, &n); err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+
+	usage, err := namespace.GetQuotaUsage(sp, &n)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		} else {
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+	resp.WriteEntity(usage)
+}
@@ -50,8 +50,8 @@ func (suite *NamespaceTestSuite) SetupSuite() {
 	namespaceService := newNamespaceService(suite.sp)
 	userService := newUserService(suite.sp)
 
-	suite.wc.Add(namespaceService)
-	suite.wc.Add(userService)
+	suite.wc.Add(secureService(suite.sp, namespaceService))
+	suite.wc.Add(secureService(suite.sp, userService))
 
 	token, _ := loginGetToken(suite.wc)
 	suite.NotEmpty(token)
@@ -360,3 +360,94 @@ func (suite *NamespaceTestSuite) TestUploadNamespaceYAMLFail() {
 
 	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
 }
+
+func (suite *NamespaceTestSuite) TestUpdateNamespaceQuota() {
+	namespace := entity.Namespace{
+		ID:   bson.NewObjectId(),
+		Name: namesgenerator.GetRandomName(0),
+	}
+	err := ns.CreateNamespace(suite.sp, &namespace)
+	suite.NoError(err)
+	defer ns.DeleteNamespace(suite.sp, &namespace)
+	err = suite.session.Insert(entity.NamespaceCollectionName, &namespace)
+	suite.NoError(err)
+	defer suite.session.Remove(entity.NamespaceCollectionName, "_id", namespace.ID)
+
+	quota := entity.NamespaceQuotaRequest{
+		Quota: &entity.NamespaceQuota{
+			CPU:                    "2",
+			Memory:                 "4Gi",
+			Pods:                   20,
+			PersistentVolumeClaims: 5,
+			Storage:                "100Gi",
+		},
+		LimitRange: &entity.NamespaceLimitRange{
+			DefaultCPURequest:    "100m",
+			DefaultMemoryRequest: "128Mi",
+		},
+	}
+	bodyBytes, err := json.MarshalIndent(quota, "", "  ")
+	suite.NoError(err)
+
+	httpRequest, err := http.NewRequest("PUT", "http://localhost:7890/v1/namespaces/"+namespace.ID.Hex()+"/quota", bytes.NewReader(bodyBytes))
+	suite.NoError(err)
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", suite.JWTBearer)
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	retNamespace := entity.Namespace{}
+	err = suite.session.FindOne(entity.NamespaceCollectionName, bson.M{"_id": namespace.ID}, &retNamespace)
+	suite.NoError(err)
+	suite.Equal(quota.Quota, retNamespace.Quota)
+	suite.Equal(quota.LimitRange, retNamespace.LimitRange)
+
+	httpRequest, err = http.NewRequest("GET", "http://localhost:7890/v1/namespaces/"+namespace.ID.Hex()+"/quota", nil)
+	suite.NoError(err)
+	httpRequest.Header.Add("Authorization", suite.JWTBearer)
+	httpWriter = httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	usage := entity.NamespaceQuotaUsage{}
+	err = json.Unmarshal(httpWriter.Body.Bytes(), &usage)
+	suite.NoError(err)
+	suite.Equal(namespace.Name, usage.Namespace)
+	suite.Equal("2", usage.Hard["requests.cpu"])
+	suite.Equal("20", usage.Hard["pods"])
+	suite.Equal("100Gi", usage.Hard["requests.storage"])
+}
+
+func (suite *NamespaceTestSuite) TestUpdateNamespaceQuotaFail() {
+	namespace := entity.Namespace{
+		ID:   bson.NewObjectId(),
+		Name: namesgenerator.GetRandomName(0),
+	}
+	err := suite.session.Insert(entity.NamespaceCollectionName, &namespace)
+	suite.NoError(err)
+	defer suite.session.Remove(entity.NamespaceCollectionName, "_id", namespace.ID)
+
+	testCases := []struct {
+		cases        string
+		id           string
+		body         string
+		expectedCode int
+	}{
+		{"InvalidQuantity", namespace.ID.Hex(), `{"quota":{"cpu":"two"}}`, http.StatusBadRequest},
+		{"NegativePods", namespace.ID.Hex(), `{"quota":{"pods":-1}}`, http.StatusBadRequest},
+		{"InvalidID", "invalid", `{}`, http.StatusBadRequest},
+		{"NotFound", bson.NewObjectId().Hex(), `{}`, http.StatusNotFound},
+	}
+	for _, tc := range testCases {
+		suite.T().Run(tc.cases, func(t *testing.T) {
+			httpRequest, err := http.NewRequest("PUT", "http://localhost:7890/v1/namespaces/"+tc.id+"/quota", strings.NewReader(tc.body))
+			suite.NoError(err)
+			httpRequest.Header.Add("Content-Type", "application/json")
+			httpRequest.Header.Add("Authorization", suite.JWTBearer)
+			httpWriter := httptest.NewRecorder()
+			suite.wc.Dispatch(httpWriter, httpRequest)
+			assertResponseCode(t, tc.expectedCode, httpWriter)
+		})
+	}
+}
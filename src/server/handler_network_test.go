@@ -49,8 +49,8 @@ func (suite *NetworkTestSuite) SetupSuite() {
 	networkService := newNetworkService(suite.sp)
 	userService := newUserService(suite.sp)
 
-	suite.wc.Add(networkService)
-	suite.wc.Add(userService)
+	suite.wc.Add(secureService(suite.sp, networkService))
+	suite.wc.Add(secureService(suite.sp, userService))
 
 	token, _ := loginGetToken(suite.wc)
 	suite.NotEmpty(token)
@@ -0,0 +1,209 @@
+package server
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/linkernetworks/vortex/src/authprovider"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	response "github.com/linkernetworks/vortex/src/net/http"
+	"github.com/linkernetworks/vortex/src/server/backend"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/linkernetworks/vortex/src/web"
+)
+
+const (
+	// oidcStateExpiry is how long the user can take to sign in at the provider
+	oidcStateExpiry = 10 * time.Minute
+	// oidcLoginCodeExpiry is how long the web UI can take to exchange the one-time code
+	oidcLoginCodeExpiry = time.Minute
+	// oidcStateCookie binds the state of a login to the browser starting it
+	oidcStateCookie = "vortex_oidc_state"
+)
+
+// setOIDCStateCookie sets the short-lived cookie of the state, an empty state removes it
+func setOIDCStateCookie(resp http.ResponseWriter, cf *config.OIDCConfig, state string) {
+	cookie := &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/v1/users/oidc",
+		MaxAge:   int(oidcStateExpiry.Seconds()),
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(cf.RedirectURL, "https://"),
+		// the provider redirects back with a top-level navigation
+		SameSite: http.SameSiteLaxMode,
+	}
+	if state == "" {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(resp, cookie)
+}
+
+// oidcConfig returns the OIDC config, or nil if the OIDC login is not enabled
+func oidcConfig(sp *serviceprovider.Container) *config.OIDCConfig {
+	if sp.Config.Auth == nil {
+		return nil
+	}
+	return sp.Config.Auth.OIDC
+}
+
+// oidcLoginHandler redirects to the login page of the OIDC provider
+func oidcLoginHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	cf := oidcConfig(sp)
+	if cf == nil {
+		response.NotFound(req.Request, resp.ResponseWriter, fmt.Errorf("OIDC login is not enabled"))
+		return
+	}
+	provider, err := authprovider.NewOIDCProvider(*cf)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	oidcState, err := backend.CreateOIDCState(session, oidcStateExpiry)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	setOIDCStateCookie(resp.ResponseWriter, cf, oidcState.State)
+	http.Redirect(resp.ResponseWriter, req.Request, provider.AuthCodeURL(oidcState.State, oidcState.Nonce), http.StatusFound)
+}
+
+// oidcCallbackHandler exchanges the authorization code for the ID token, provisions the user of it
+// and redirects to the web UI with a one-time code, which is exchanged for the tokens by oidcTokenHandler
+func oidcCallbackHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	cf := oidcConfig(sp)
+	if cf == nil {
+		response.NotFound(req.Request, resp.ResponseWriter, fmt.Errorf("OIDC login is not enabled"))
+		return
+	}
+
+	if providerError := req.QueryParameter("error"); providerError != "" {
+		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: %s %s", providerError, req.QueryParameter("error_description")))
+		return
+	}
+	code, state := req.QueryParameter("code"), req.QueryParameter("state")
+	if code == "" || state == "" {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("The code and state are required"))
+		return
+	}
+	// the login has to be completed by the browser which started it
+	cookie, err := req.Request.Cookie(oidcStateCookie)
+	setOIDCStateCookie(resp.ResponseWriter, cf, "")
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: The state doesn't belong to this browser"))
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	oidcState, err := backend.ConsumeOIDCState(session, state)
+	if err != nil {
+		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: Invalid or expired state"))
+		return
+	}
+
+	provider, err := authprovider.NewOIDCProvider(*cf)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	rawIDToken, err := provider.Exchange(code)
+	if err != nil {
+		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: %v", err))
+		return
+	}
+	claims, err := provider.Verify(rawIDToken, oidcState.Nonce)
+	if err != nil {
+		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: %v", err))
+		return
+	}
+
+	user, passed, err := provider.Provision(session, claims)
+	if err != nil {
+		if err == authprovider.ErrUserNotManaged || err == backend.ErrUserDisabled {
+			response.Forbidden(req.Request, resp.ResponseWriter, err)
+			return
+		}
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if !passed {
+		response.Forbidden(req.Request, resp.ResponseWriter, fmt.Errorf("The user isn't given any role"))
+		return
+	}
+
+	portalURL, err := url.Parse(cf.PortalURL)
+	if err != nil || cf.PortalURL == "" {
+		response.InternalServerError(req.Request, resp.ResponseWriter, fmt.Errorf("The portalURL of the OIDC config is invalid"))
+		return
+	}
+	loginCode, err := backend.CreateOIDCLoginCode(session, user.ID, oidcLoginCodeExpiry)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	// the tokens are never put in the URL, which ends up in the history and the logs
+	query := portalURL.Query()
+	query.Set("code", loginCode)
+	portalURL.RawQuery = query.Encode()
+	http.Redirect(resp.ResponseWriter, req.Request, portalURL.String(), http.StatusFound)
+}
+
+// oidcTokenHandler exchanges the one-time code of the login for the tokens
+func oidcTokenHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	if oidcConfig(sp) == nil {
+		response.NotFound(req.Request, resp.ResponseWriter, fmt.Errorf("OIDC login is not enabled"))
+		return
+	}
+
+	request := entity.OIDCTokenRequest{}
+	if err := req.ReadEntity(&request); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if err := sp.Validator.Struct(request); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	loginCode, err := backend.ConsumeOIDCLoginCode(session, request.Code)
+	if err != nil {
+		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: Invalid, used or expired code"))
+		return
+	}
+	user, err := backend.FindUserByID(session, loginCode.UserID)
+	if err != nil {
+		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: User ID not found"))
+		return
+	}
+	if user.Disabled {
+		response.Forbidden(req.Request, resp.ResponseWriter, backend.ErrUserDisabled)
+		return
+	}
+
+	tokens, err := signInTokens(sp, session, req.Request, user)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	resp.WriteEntity(tokens)
+}
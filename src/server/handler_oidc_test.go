@@ -0,0 +1,191 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	restful "github.com/emicklei/go-restful"
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/authprovider/oidctest"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type OIDCTestSuite struct {
+	suite.Suite
+	sp       *serviceprovider.Container
+	wc       *restful.Container
+	session  *mongo.Session
+	provider *oidctest.Server
+	email    string
+}
+
+func (suite *OIDCTestSuite) SetupSuite() {
+	cf := config.MustRead("../../config/testing.json")
+	suite.provider = oidctest.NewServer("vortex", "secret")
+	cf.Auth = &config.AuthConfig{
+		OIDC: &config.OIDCConfig{
+			Issuer:       suite.provider.URL,
+			ClientID:     "vortex",
+			ClientSecret: "secret",
+			RedirectURL:  "http://localhost:7890/v1/users/oidc/callback",
+			PortalURL:    "http://localhost:32767/login/oidc",
+			GroupRoles: map[string]string{
+				"developers": entity.UserRole,
+			},
+		},
+	}
+	sp := serviceprovider.NewForTesting(cf)
+
+	suite.sp = sp
+	// init session
+	suite.session = sp.Mongo.NewSession()
+	// init restful container
+	suite.wc = restful.NewContainer()
+	suite.wc.Add(secureService(suite.sp, newUserService(suite.sp)))
+
+	suite.email = namesgenerator.GetRandomName(0) + "@linkernetworks.com"
+}
+
+func (suite *OIDCTestSuite) TearDownSuite() {
+	suite.provider.Close()
+	suite.session.Remove(entity.UserCollectionName, "loginCredential.username", suite.email)
+}
+
+func TestOIDCSuite(t *testing.T) {
+	suite.Run(t, new(OIDCTestSuite))
+}
+
+// login goes through the authorization code flow and returns the response of the callback
+func (suite *OIDCTestSuite) login() *httptest.ResponseRecorder {
+	httpRequest, err := http.NewRequest("GET", "http://localhost:7890/v1/users/oidc/login", nil)
+	suite.NoError(err)
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusFound, httpWriter)
+	cookies := httpWriter.Result().Cookies()
+	suite.Len(cookies, 1)
+	suite.True(cookies[0].HttpOnly)
+
+	// the provider signs in the user and redirects back to the callback
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(httpWriter.Header().Get("Location"))
+	suite.NoError(err)
+	suite.Equal(http.StatusFound, resp.StatusCode)
+
+	httpRequest, err = http.NewRequest("GET", resp.Header.Get("Location"), nil)
+	suite.NoError(err)
+	httpRequest.AddCookie(cookies[0])
+	httpWriter = httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	return httpWriter
+}
+
+// exchange exchanges the one-time code in the redirect to the web UI for the tokens
+func (suite *OIDCTestSuite) exchange(callback *httptest.ResponseRecorder) *httptest.ResponseRecorder {
+	assertResponseCode(suite.T(), http.StatusFound, callback)
+	portalURL, err := url.Parse(callback.Header().Get("Location"))
+	suite.NoError(err)
+	suite.Equal("/login/oidc", portalURL.Path)
+	code := portalURL.Query().Get("code")
+	suite.NotEmpty(code)
+
+	body, err := json.Marshal(entity.OIDCTokenRequest{Code: code})
+	suite.NoError(err)
+	httpRequest, err := http.NewRequest("POST", "http://localhost:7890/v1/users/oidc/token", bytes.NewReader(body))
+	suite.NoError(err)
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	return httpWriter
+}
+
+func (suite *OIDCTestSuite) TestLogin() {
+	suite.provider.SetUser(jwt.MapClaims{
+		"sub":    "john",
+		"email":  suite.email,
+		"name":   "John Doe",
+		"groups": []string{"developers"},
+	})
+	callback := suite.login()
+	httpWriter := suite.exchange(callback)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	tokens := entity.TokenResponse{}
+	err := json.Unmarshal(httpWriter.Body.Bytes(), &tokens)
+	suite.NoError(err)
+	suite.NotEmpty(tokens.AccessToken)
+	suite.NotEmpty(tokens.RefreshToken)
+
+	claims, err := suite.sp.JWT.ParseToken(tokens.AccessToken)
+	suite.NoError(err)
+	suite.Equal(entity.UserRole, claims["role"])
+
+	user := entity.User{}
+	err = suite.session.FindOne(entity.UserCollectionName, bson.M{"loginCredential.username": suite.email}, &user)
+	suite.NoError(err)
+	suite.Equal("oidc", user.AuthProvider)
+	suite.Equal("John Doe", user.DisplayName)
+
+	// the code can only be exchanged once
+	httpWriter = suite.exchange(callback)
+	assertResponseCode(suite.T(), http.StatusUnauthorized, httpWriter)
+
+	// the same user signs in again
+	httpWriter = suite.exchange(suite.login())
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	count, err := suite.session.Count(entity.UserCollectionName, bson.M{"loginCredential.username": suite.email})
+	suite.NoError(err)
+	suite.Equal(1, count)
+}
+
+func (suite *OIDCTestSuite) TestLoginWithoutRole() {
+	suite.provider.SetUser(jwt.MapClaims{
+		"sub":   "jane",
+		"email": "jane-" + suite.email,
+	})
+	httpWriter := suite.login()
+	assertResponseCode(suite.T(), http.StatusForbidden, httpWriter)
+}
+
+func (suite *OIDCTestSuite) TestCallbackWithInvalidState() {
+	httpRequest, err := http.NewRequest("GET", "http://localhost:7890/v1/users/oidc/callback?code=code-0&state=unknown", nil)
+	suite.NoError(err)
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusUnauthorized, httpWriter)
+
+	httpRequest, err = http.NewRequest("GET", "http://localhost:7890/v1/users/oidc/callback", nil)
+	suite.NoError(err)
+	httpWriter = httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+}
+
+func (suite *OIDCTestSuite) TestCallbackFromOtherBrowser() {
+	// the state of a login started by another browser
+	httpRequest, err := http.NewRequest("GET", "http://localhost:7890/v1/users/oidc/login", nil)
+	suite.NoError(err)
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusFound, httpWriter)
+	loginURL, err := url.Parse(httpWriter.Header().Get("Location"))
+	suite.NoError(err)
+
+	httpRequest, err = http.NewRequest("GET", "http://localhost:7890/v1/users/oidc/callback?code=code-0&state="+loginURL.Query().Get("state"), nil)
+	suite.NoError(err)
+	httpWriter = httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusUnauthorized, httpWriter)
+}
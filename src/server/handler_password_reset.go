@@ -0,0 +1,278 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/linkernetworks/logger"
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/cache"
+	"github.com/linkernetworks/vortex/src/entity"
+	response "github.com/linkernetworks/vortex/src/net/http"
+	"github.com/linkernetworks/vortex/src/server/backend"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/linkernetworks/vortex/src/web"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const defaultPasswordResetExpiry = 24 * time.Hour
+
+const (
+	// maxResetMailsPerUser is the number of the reset mails sent to a user per hour
+	maxResetMailsPerUser = 3
+	// maxResetRequestsPerAddr is the number of the forgot password requests of a remote address per hour
+	maxResetRequestsPerAddr = 10
+)
+
+// passwordResetRequests counts the forgot password requests of the users and the remote addresses
+var passwordResetRequests = cache.New(time.Hour)
+
+// passwordResetExpiry returns how long a password reset token is valid
+func passwordResetExpiry(sp *serviceprovider.Container) (time.Duration, error) {
+	if sp.Config.Auth == nil || sp.Config.Auth.PasswordResetExpiry == "" {
+		return defaultPasswordResetExpiry, nil
+	}
+	expiry, err := time.ParseDuration(sp.Config.Auth.PasswordResetExpiry)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid password reset expiry: %v", err)
+	}
+	return expiry, nil
+}
+
+// resetUserPasswordHandler issues a one-time password reset token of the user. The role must to have admin permission to access it.
+func resetUserPasswordHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	id := req.PathParameter("id")
+	if !bson.IsObjectIdHex(id) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid user ID: %s", id))
+		return
+	}
+
+	expiry, err := passwordResetExpiry(sp)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	user, err := backend.FindUserByID(session, bson.ObjectIdHex(id))
+	if err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+	if user.AuthProvider != "" {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("The password is managed by %s", user.AuthProvider))
+		return
+	}
+	if user.Disabled {
+		response.BadRequest(req.Request, resp.ResponseWriter, backend.ErrUserDisabled)
+		return
+	}
+
+	resetToken, err := backend.CreatePasswordResetToken(session, user.ID, expiry)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	resp.WriteEntity(resetToken)
+}
+
+// passwordResetLink returns the link of the web UI setting the new password with the token
+func passwordResetLink(sp *serviceprovider.Container, token string) (string, error) {
+	if sp.Config.Auth == nil || sp.Config.Auth.PasswordResetURL == "" {
+		return "", fmt.Errorf("The password reset URL isn't configured")
+	}
+	link, err := url.Parse(sp.Config.Auth.PasswordResetURL)
+	if err != nil {
+		return "", fmt.Errorf("Invalid password reset URL: %v", err)
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String(), nil
+}
+
+// forgotPasswordHandler mails a password reset link to the user.
+// It always succeeds for a valid email, so the registered usernames aren't leaked.
+func forgotPasswordHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	forgotRequest := entity.PasswordForgotRequest{}
+	if err := req.ReadEntity(&forgotRequest); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if err := sp.Validator.Struct(forgotRequest); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	if sp.Mailer == nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, fmt.Errorf("SMTP isn't configured"))
+		return
+	}
+	remoteAddr, _, err := net.SplitHostPort(req.Request.RemoteAddr)
+	if err != nil {
+		remoteAddr = req.Request.RemoteAddr
+	}
+	if passwordResetRequests.Increment("addr:"+remoteAddr) > maxResetRequestsPerAddr {
+		response.TooManyRequests(req.Request, resp.ResponseWriter, fmt.Errorf("Too many password reset requests, try again later"))
+		return
+	}
+	expiry, err := passwordResetExpiry(sp)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	// make sure the link can be made before looking up the user
+	if _, err := passwordResetLink(sp, ""); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	username := strings.ToLower(forgotRequest.Username)
+	user := entity.User{}
+	err = session.FindOne(entity.UserCollectionName, bson.M{"loginCredential.username": username}, &user)
+	switch {
+	case err == mgo.ErrNotFound:
+		logger.Infof("Password reset of unknown user %s", username)
+	case err != nil:
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	case user.AuthProvider != "" || user.Disabled:
+		logger.Infof("Password reset of %s isn't allowed", username)
+	case passwordResetRequests.Increment("user:"+user.ID.Hex()) > maxResetMailsPerUser:
+		// the pending token isn't replaced, the same response hides the limit
+		logger.Infof("Too many password reset requests of %s", username)
+	default:
+		if err := mailPasswordReset(sp, session, user, expiry); err != nil {
+			// the same response hides whether the user exists
+			logger.Errorf("Failed to mail the password reset of %s: %v", username, err)
+		}
+	}
+
+	resp.WriteEntity(response.ActionResponse{
+		Error:   false,
+		Message: "Password reset mail sent",
+	})
+}
+
+func mailPasswordReset(sp *serviceprovider.Container, session *mongo.Session, user entity.User, expiry time.Duration) error {
+	resetToken, err := backend.CreatePasswordResetToken(session, user.ID, expiry)
+	if err != nil {
+		return err
+	}
+	link, err := passwordResetLink(sp, resetToken.Token)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(`Hi %s,
+
+Someone asked to reset the password of your Vortex account %s.
+Open the link below to set a new password before %s:
+
+%s
+
+If it wasn't you, just ignore this mail and your password stays the same.
+`, user.DisplayName, user.LoginCredential.Username, resetToken.ExpiresAt.Format(time.RFC1123), link)
+	return sp.Mailer.Send(user.LoginCredential.Username, "Reset your Vortex password", body)
+}
+
+// resetPasswordHandler sets the new password of the user with a password reset token.
+// All sessions and API tokens of the user are revoked and the lockout is cleared.
+func resetPasswordHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	resetRequest := entity.PasswordResetRequest{}
+	if err := req.ReadEntity(&resetRequest); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if err := sp.Validator.Struct(resetRequest); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	resetToken, err := backend.FindPasswordResetToken(session, resetRequest.Token)
+	if err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: Invalid password reset token"))
+		default:
+			response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: %v", err))
+		}
+		return
+	}
+
+	user, err := backend.FindUserByID(session, resetToken.UserID)
+	if err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+	if user.Disabled {
+		response.Forbidden(req.Request, resp.ResponseWriter, backend.ErrUserDisabled)
+		return
+	}
+
+	policy := passwordPolicy(sp)
+	if err := backend.CheckPasswordPolicy(policy, user, resetRequest.Password); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	// the token is only used up by a valid password
+	if _, err := backend.ConsumePasswordResetToken(session, resetRequest.Token); err != nil {
+		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: Invalid password reset token"))
+		return
+	}
+
+	historySize := 0
+	if policy != nil {
+		historySize = policy.HistorySize
+	}
+	if err := backend.UpdatePassword(session, user, resetRequest.Password, historySize); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if err := backend.UnlockUser(session, user.ID); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	// whoever knew the old password can't keep using the sessions
+	if err := revokeUserSessions(sp, session, user.ID); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if err := revokeUserAPITokens(sp, session, user.ID); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	resp.WriteEntity(response.ActionResponse{
+		Error:   false,
+		Message: "Password Reset Success",
+	})
+}
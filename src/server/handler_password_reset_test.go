@@ -0,0 +1,180 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/mailprovider/smtptest"
+	"github.com/linkernetworks/vortex/src/server/backend"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/linkernetworks/vortex/src/utils"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type PasswordResetTestSuite struct {
+	suite.Suite
+	sp         *serviceprovider.Container
+	wc         *restful.Container
+	session    *mongo.Session
+	smtpServer *smtptest.Server
+}
+
+func (suite *PasswordResetTestSuite) SetupSuite() {
+	cf := config.MustRead("../../config/testing.json")
+	cf.Auth.PasswordResetURL = "http://localhost:7890/reset-password"
+
+	smtpServer, err := smtptest.NewServer()
+	suite.NoError(err)
+	suite.smtpServer = smtpServer
+	cf.SMTP = smtpServer.Config()
+
+	sp := serviceprovider.NewForTesting(cf)
+	suite.sp = sp
+	// init session
+	suite.session = sp.Mongo.NewSession()
+	// init restful container
+	suite.wc = restful.NewContainer()
+	suite.wc.Add(secureService(suite.sp, newUserService(suite.sp)))
+}
+
+func (suite *PasswordResetTestSuite) TearDownSuite() {
+	suite.smtpServer.Close()
+}
+
+func TestPasswordResetSuite(t *testing.T) {
+	suite.Run(t, new(PasswordResetTestSuite))
+}
+
+func (suite *PasswordResetTestSuite) post(path string, body interface{}) *httptest.ResponseRecorder {
+	bodyBytes, err := json.MarshalIndent(body, "", "  ")
+	suite.NoError(err)
+
+	httpRequest, err := http.NewRequest("POST", "http://localhost:7890"+path, strings.NewReader(string(bodyBytes)))
+	suite.NoError(err)
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	return httpWriter
+}
+
+// mailedToken returns the reset token in the link of the last mail to the user
+func (suite *PasswordResetTestSuite) mailedToken(username string) string {
+	messages := suite.smtpServer.Messages()
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].To[0] != username {
+			continue
+		}
+		link := regexp.MustCompile(`http://localhost:7890/reset-password\?\S+`).FindString(messages[i].Body())
+		suite.NotEmpty(link)
+		parsed, err := url.Parse(link)
+		suite.NoError(err)
+		return parsed.Query().Get("token")
+	}
+	return ""
+}
+
+func (suite *PasswordResetTestSuite) TestForgotPassword() {
+	hashedPassword, err := utils.HashPassword("p@ssw0rd")
+	suite.NoError(err)
+	user := entity.User{
+		ID: bson.NewObjectId(),
+		LoginCredential: entity.LoginCredential{
+			Username: namesgenerator.GetRandomName(0) + "@linkernetworks.com",
+			Password: hashedPassword,
+		},
+		DisplayName: "John Doe",
+		Role:        entity.UserRole,
+	}
+	suite.NoError(suite.session.Insert(entity.UserCollectionName, &user))
+	defer suite.session.Remove(entity.UserCollectionName, "_id", user.ID)
+	apiToken := entity.APIToken{OwnerID: user.ID, Name: "ci", Scope: entity.APITokenReadOnly}
+	suite.NoError(backend.CreateAPIToken(suite.session, &apiToken))
+
+	httpWriter := suite.post("/v1/users/password/forgot", entity.PasswordForgotRequest{Username: user.LoginCredential.Username})
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	token := suite.mailedToken(user.LoginCredential.Username)
+	suite.NotEmpty(token)
+
+	httpWriter = suite.post("/v1/users/password/reset", entity.PasswordResetRequest{Token: token, Password: "n3wp@ss"})
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	retUser := entity.User{}
+	suite.NoError(suite.session.FindOne(entity.UserCollectionName, bson.M{"_id": user.ID}, &retUser))
+	suite.True(utils.CheckPasswordHash("n3wp@ss", retUser.LoginCredential.Password))
+	// the API tokens are revoked
+	_, _, err = backend.AuthenticateAPIToken(suite.session, apiToken.Token)
+	suite.Error(err)
+
+	// the token is single-use
+	httpWriter = suite.post("/v1/users/password/reset", entity.PasswordResetRequest{Token: token, Password: "an0ther"})
+	assertResponseCode(suite.T(), http.StatusUnauthorized, httpWriter)
+}
+
+func (suite *PasswordResetTestSuite) TestForgotPasswordUnknownUser() {
+	count := len(suite.smtpServer.Messages())
+
+	// the unknown users get the same response without a mail
+	httpWriter := suite.post("/v1/users/password/forgot", entity.PasswordForgotRequest{Username: namesgenerator.GetRandomName(0) + "@linkernetworks.com"})
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	suite.Len(suite.smtpServer.Messages(), count)
+}
+
+func (suite *PasswordResetTestSuite) TestForgotPasswordLimit() {
+	user := entity.User{
+		ID: bson.NewObjectId(),
+		LoginCredential: entity.LoginCredential{
+			Username: namesgenerator.GetRandomName(0) + "@linkernetworks.com",
+		},
+		Role: entity.UserRole,
+	}
+	suite.NoError(suite.session.Insert(entity.UserCollectionName, &user))
+	defer suite.session.Remove(entity.UserCollectionName, "_id", user.ID)
+
+	count := len(suite.smtpServer.Messages())
+	for i := 0; i <= maxResetMailsPerUser; i++ {
+		// the limited requests get the same response without a mail
+		httpWriter := suite.post("/v1/users/password/forgot", entity.PasswordForgotRequest{Username: user.LoginCredential.Username})
+		assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	}
+	suite.Len(suite.smtpServer.Messages(), count+maxResetMailsPerUser)
+}
+
+func (suite *PasswordResetTestSuite) TestForgotPasswordInvalidEmail() {
+	httpWriter := suite.post("/v1/users/password/forgot", entity.PasswordForgotRequest{Username: "hello"})
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+}
+
+func (suite *PasswordResetTestSuite) TestForgotPasswordWithoutSMTP() {
+	mailer := suite.sp.Mailer
+	suite.sp.Mailer = nil
+	defer func() { suite.sp.Mailer = mailer }()
+
+	httpWriter := suite.post("/v1/users/password/forgot", entity.PasswordForgotRequest{Username: "hello@linkernetworks.com"})
+	assertResponseCode(suite.T(), http.StatusInternalServerError, httpWriter)
+}
+
+func TestPasswordResetLink(t *testing.T) {
+	sp := &serviceprovider.Container{Config: config.Config{Auth: &config.AuthConfig{
+		PasswordResetURL: "https://vortex.linkernetworks.com/reset?lang=en",
+	}}}
+	link, err := passwordResetLink(sp, "a/b+c")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://vortex.linkernetworks.com/reset?lang=en&token=a%2Fb%2Bc", link)
+
+	// the link requires the password reset URL
+	_, err = passwordResetLink(&serviceprovider.Container{}, "token")
+	assert.Error(t, err)
+}
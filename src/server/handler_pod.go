@@ -165,7 +165,7 @@ func listPodHandler(ctx *web.Context) {
 	var c = session.C(entity.PodCollectionName)
 	var q *mgo.Query
 
-	selector := bson.M{}
+	selector := namespaceSelector(req, "namespace")
 	q = c.Find(selector).Sort("_id").Skip((page - 1) * pageSize).Limit(pageSize)
 
 	if err := q.All(&pods); err != nil {
@@ -184,7 +184,7 @@ func listPodHandler(ctx *web.Context) {
 		// find owner in user entity
 		pods[i].CreatedBy, _ = backend.FindUserByID(session, pod.OwnerID)
 	}
-	count, err := session.Count(entity.PodCollectionName, bson.M{})
+	count, err := session.Count(entity.PodCollectionName, selector)
 	if err != nil {
 		response.InternalServerError(req.Request, resp.ResponseWriter, err)
 		return
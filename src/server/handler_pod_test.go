@@ -18,6 +18,8 @@ import (
 	"github.com/moby/moby/pkg/namesgenerator"
 	"github.com/stretchr/testify/suite"
 	"gopkg.in/mgo.v2/bson"
+
+	corev1 "k8s.io/api/core/v1"
 )
 
 func init() {
@@ -45,8 +47,8 @@ func (suite *PodTestSuite) SetupSuite() {
 	podService := newPodService(suite.sp)
 	userService := newUserService(suite.sp)
 
-	suite.wc.Add(podService)
-	suite.wc.Add(userService)
+	suite.wc.Add(secureService(suite.sp, podService))
+	suite.wc.Add(secureService(suite.sp, userService))
 
 	token, _ := loginGetToken(suite.wc)
 	suite.NotEmpty(token)
@@ -125,6 +127,71 @@ func (suite *PodTestSuite) TestCreatePod() {
 	suite.NoError(err)
 }
 
+func (suite *PodTestSuite) TestCreatePodWithContainerSpec() {
+	tName := namesgenerator.GetRandomName(0)
+	pod := entity.Pod{
+		Name:      tName,
+		Namespace: "default",
+		Labels:    map[string]string{},
+		EnvVars:   map[string]string{"LANG": "C", "MODE": "release"},
+		Containers: []entity.Container{
+			{
+				Name:                  namesgenerator.GetRandomName(0),
+				Image:                 "nginx",
+				Command:               []string{"nginx"},
+				Args:                  []string{"-g", "daemon off;"},
+				WorkingDir:            "/usr/share/nginx",
+				Ports:                 []entity.ContainerPort{{Name: "http", ContainerPort: 80}},
+				EnvVars:               map[string]string{"MODE": "debug"},
+				ImagePullPolicy:       "Always",
+				ResourceRequestCPU:    100,
+				ResourceRequestMemory: 64,
+				ResourceLimitCPU:      200,
+				ResourceLimitMemory:   128,
+			},
+		},
+		Volumes:       []entity.PodVolume{},
+		Networks:      []entity.PodNetwork{},
+		RestartPolicy: "Never",
+		NetworkType:   entity.PodClusterNetwork,
+		NodeAffinity:  []string{},
+	}
+	post := func(pod entity.Pod) *httptest.ResponseRecorder {
+		bodyBytes, err := json.MarshalIndent(pod, "", "  ")
+		suite.NoError(err)
+		httpRequest, err := http.NewRequest("POST", "http://localhost:7890/v1/pods", strings.NewReader(string(bodyBytes)))
+		suite.NoError(err)
+		httpRequest.Header.Add("Content-Type", "application/json")
+		httpRequest.Header.Add("Authorization", suite.JWTBearer)
+		httpWriter := httptest.NewRecorder()
+		suite.wc.Dispatch(httpWriter, httpRequest)
+		return httpWriter
+	}
+
+	// the limit is less than the request
+	invalid := pod
+	invalid.Containers = []entity.Container{pod.Containers[0]}
+	invalid.Containers[0].ResourceLimitMemory = 32
+	assertResponseCode(suite.T(), http.StatusBadRequest, post(invalid))
+
+	assertResponseCode(suite.T(), http.StatusCreated, post(pod))
+	defer suite.session.Remove(entity.PodCollectionName, "name", tName)
+	defer p.DeletePod(suite.sp, &pod)
+
+	live, err := suite.sp.KubeCtl.GetPod(tName, "default")
+	suite.NoError(err)
+	container := live.Spec.Containers[0]
+	suite.Equal([]string{"-g", "daemon off;"}, container.Args)
+	suite.Equal("/usr/share/nginx", container.WorkingDir)
+	suite.Equal(int32(80), container.Ports[0].ContainerPort)
+	suite.Equal(corev1.PullAlways, container.ImagePullPolicy)
+	suite.Equal([]corev1.EnvVar{{Name: "LANG", Value: "C"}, {Name: "MODE", Value: "debug"}}, container.Env)
+	suite.Equal("100m", container.Resources.Requests.Cpu().String())
+	suite.Equal("64Mi", container.Resources.Requests.Memory().String())
+	suite.Equal("200m", container.Resources.Limits.Cpu().String())
+	suite.Equal("128Mi", container.Resources.Limits.Memory().String())
+}
+
 func (suite *PodTestSuite) TestCreatePodFail() {
 	namespace := "default"
 	containers := []entity.Container{
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"fmt"
+
 	"github.com/linkernetworks/vortex/src/entity"
 	response "github.com/linkernetworks/vortex/src/net/http"
 	"github.com/linkernetworks/vortex/src/net/http/query"
@@ -312,3 +314,38 @@ func listNodeNicsMetricsHandler(ctx *web.Context) {
 
 	resp.WriteEntity(nicList)
 }
+
+// getCustomMetricHandler checks the metric exists in Prometheus, e.g. before the autoscaler scales on it
+func getCustomMetricHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+	metricName := req.PathParameter("metric")
+
+	query := query.New(req.Request.URL.Query())
+	queryLabels := map[string]string{}
+
+	namespace, ok := query.Str("namespace")
+	if ok {
+		queryLabels["namespace"] = namespace
+	}
+
+	if err := sp.Validator.Var(metricName, "required,promname"); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid metric name %s: %v", metricName, err))
+		return
+	}
+
+	metricNameList, err := pc.ListMetricName(sp, metricName, queryLabels)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if len(metricNameList) == 0 {
+		response.NotFound(req.Request, resp.ResponseWriter, fmt.Errorf("The metric %s doesn't exist in Prometheus", metricName))
+		return
+	}
+
+	resp.WriteEntity(entity.CustomMetric{
+		MetricName: metricName,
+		Namespace:  namespace,
+		Series:     metricNameList,
+	})
+}
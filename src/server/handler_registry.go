@@ -1,11 +1,22 @@
 package server
 
 import (
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/utils/timeutils"
 	"github.com/linkernetworks/vortex/src/entity"
 	response "github.com/linkernetworks/vortex/src/net/http"
+	"github.com/linkernetworks/vortex/src/net/http/query"
+	"github.com/linkernetworks/vortex/src/registry"
+	"github.com/linkernetworks/vortex/src/server/backend"
 	"github.com/linkernetworks/vortex/src/web"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
 )
 
 func registryBasicAuthHandler(ctx *web.Context) {
@@ -36,3 +47,267 @@ func registryBasicAuthHandler(ctx *web.Context) {
 	}
 	resp.WriteHeaderAndEntity(registryResp.StatusCode, credential)
 }
+
+func createRegistryCredentialHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+	userID, ok := req.Attribute("UserID").(string)
+	if !ok {
+		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: User ID is empty"))
+		return
+	}
+
+	c := entity.RegistryCredential{}
+	if err := req.ReadEntity(&c); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	if err := sp.Validator.Struct(c); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+	session.C(entity.RegistryCredentialCollectionName).EnsureIndex(mgo.Index{
+		Key:    []string{"name"},
+		Unique: true,
+	})
+
+	if err := checkRegistryTeams(session, c.Teams); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	// the secrets of the existing credential must not be overwritten
+	count, err := session.Count(entity.RegistryCredentialCollectionName, bson.M{"name": c.Name})
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	} else if count > 0 {
+		response.Conflict(req.Request, resp.ResponseWriter, fmt.Errorf("Registry credential: %s already existed", c.Name))
+		return
+	}
+
+	c.ID = bson.NewObjectId()
+	c.OwnerID = bson.ObjectIdHex(userID)
+	c.CreatedAt = timeutils.Now()
+	if err := registry.ApplyCredential(sp, &c); err != nil {
+		registry.DeleteCredential(sp, &c)
+		if err == registry.ErrSecretNotManaged {
+			response.Conflict(req.Request, resp.ResponseWriter, err)
+		} else {
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+
+	if err := session.Insert(entity.RegistryCredentialCollectionName, &c); err != nil {
+		if mgo.IsDup(err) {
+			response.Conflict(req.Request, resp.ResponseWriter, fmt.Errorf("Registry credential: %s already existed", c.Name))
+		} else {
+			registry.DeleteCredential(sp, &c)
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+	c.Password = ""
+	c.CreatedBy, _ = backend.FindUserByID(session, c.OwnerID)
+	resp.WriteHeaderAndEntity(http.StatusCreated, c)
+}
+
+func updateRegistryCredentialHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	id := req.PathParameter("id")
+	if !bson.IsObjectIdHex(id) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid registry credential ID: %s", id))
+		return
+	}
+
+	update := entity.RegistryCredential{}
+	if err := req.ReadEntity(&update); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	c := entity.RegistryCredential{}
+	if err := session.FindOne(entity.RegistryCredentialCollectionName, bson.M{"_id": bson.ObjectIdHex(id)}, &c); err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+
+	// the name is the name of the secrets and can't be changed
+	update.Name = c.Name
+	if err := sp.Validator.Struct(update); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if err := checkRegistryTeams(session, update.Teams); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	c.URL = update.URL
+	c.Username = update.Username
+	c.Password = update.Password
+	c.Teams = update.Teams
+
+	if err := registry.ApplyCredential(sp, &c); err != nil {
+		if err == registry.ErrSecretNotManaged {
+			response.Conflict(req.Request, resp.ResponseWriter, err)
+		} else {
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+
+	if err := session.Update(entity.RegistryCredentialCollectionName, bson.M{"_id": c.ID}, bson.M{"$set": bson.M{
+		"url":      c.URL,
+		"username": c.Username,
+		"teams":    c.Teams,
+	}}); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	c.Password = ""
+	c.CreatedBy, _ = backend.FindUserByID(session, c.OwnerID)
+	resp.WriteEntity(c)
+}
+
+func deleteRegistryCredentialHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	id := req.PathParameter("id")
+	if !bson.IsObjectIdHex(id) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid registry credential ID: %s", id))
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	c := entity.RegistryCredential{}
+	if err := session.FindOne(entity.RegistryCredentialCollectionName, bson.M{"_id": bson.ObjectIdHex(id)}, &c); err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+
+	if err := registry.DeleteCredential(sp, &c); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	if err := session.Remove(entity.RegistryCredentialCollectionName, "_id", c.ID); err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+
+	resp.WriteEntity(response.ActionResponse{
+		Error:   false,
+		Message: "Delete success",
+	})
+}
+
+func listRegistryCredentialHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	var pageSize = 1024
+	query := query.New(req.Request.URL.Query())
+
+	page, err := query.Int("page", 1)
+	if err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	pageSize, err = query.Int("page_size", pageSize)
+	if err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	credentials := []entity.RegistryCredential{}
+	q := session.C(entity.RegistryCredentialCollectionName).Find(nil).Sort("_id").Skip((page - 1) * pageSize).Limit(pageSize)
+	if err := q.All(&credentials); err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+			return
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+			return
+		}
+	}
+
+	for i, c := range credentials {
+		credentials[i].CreatedBy, _ = backend.FindUserByID(session, c.OwnerID)
+	}
+	count, err := session.Count(entity.RegistryCredentialCollectionName, bson.M{})
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	totalPages := int(math.Ceil(float64(count) / float64(pageSize)))
+	resp.AddHeader("X-Total-Count", strconv.Itoa(count))
+	resp.AddHeader("X-Total-Pages", strconv.Itoa(totalPages))
+	resp.WriteEntity(credentials)
+}
+
+func getRegistryCredentialHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	id := req.PathParameter("id")
+	if !bson.IsObjectIdHex(id) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid registry credential ID: %s", id))
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	c := entity.RegistryCredential{}
+	if err := session.FindOne(entity.RegistryCredentialCollectionName, bson.M{"_id": bson.ObjectIdHex(id)}, &c); err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+	c.CreatedBy, _ = backend.FindUserByID(session, c.OwnerID)
+	resp.WriteEntity(c)
+}
+
+// checkRegistryTeams checks the teams granted the registry credential exist
+func checkRegistryTeams(session *mongo.Session, teams []bson.ObjectId) error {
+	for _, teamID := range teams {
+		count, err := session.Count(entity.TeamCollectionName, bson.M{"_id": teamID})
+		if err != nil {
+			return err
+		} else if count == 0 {
+			return fmt.Errorf("The team %s doesn't exist", teamID.Hex())
+		}
+	}
+	return nil
+}
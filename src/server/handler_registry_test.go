@@ -11,8 +11,13 @@ import (
 	"github.com/linkernetworks/mongo"
 	"github.com/linkernetworks/vortex/src/config"
 	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/kubeutils"
 	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/moby/moby/pkg/namesgenerator"
 	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type RegistryTestSuite struct {
@@ -59,3 +64,131 @@ func (suite *RegistryTestSuite) TestRegistryBasicAuth() {
 	suite.wc.Dispatch(httpWriter, httpRequest)
 	assertResponseCode(suite.T(), http.StatusUnauthorized, httpWriter)
 }
+
+type RegistryCredentialTestSuite struct {
+	ServerTestSuite
+}
+
+func (suite *RegistryCredentialTestSuite) SetupSuite() {
+	suite.setupServices(newRegistryService)
+}
+
+func (suite *RegistryCredentialTestSuite) TearDownSuite() {}
+
+func TestRegistryCredentialSuite(t *testing.T) {
+	suite.Run(t, new(RegistryCredentialTestSuite))
+}
+
+func (suite *RegistryCredentialTestSuite) TestCreateRegistryCredential() {
+	c := entity.RegistryCredential{
+		Name:     namesgenerator.GetRandomName(0),
+		URL:      "https://registry.example.com",
+		Username: "admin",
+		Password: "awesome",
+	}
+	httpWriter := suite.request("POST", "/v1/registry/credentials", c)
+	assertResponseCode(suite.T(), http.StatusCreated, httpWriter)
+	defer suite.session.Remove(entity.RegistryCredentialCollectionName, "name", c.Name)
+
+	created := entity.RegistryCredential{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &created))
+	suite.Equal(c.Name, created.Name)
+	suite.Empty(created.Password)
+	suite.NotContains(httpWriter.Body.String(), "awesome")
+
+	secret, err := suite.sp.KubeCtl.GetSecret(kubeutils.RegistrySecretName(c.Name), "default")
+	suite.NoError(err)
+	suite.Equal(corev1.SecretTypeDockerConfigJson, secret.Type)
+
+	// the name is used
+	httpWriter = suite.request("POST", "/v1/registry/credentials", c)
+	assertResponseCode(suite.T(), http.StatusConflict, httpWriter)
+
+	// get and list don't return the password
+	httpWriter = suite.request("GET", "/v1/registry/credentials/"+created.ID.Hex(), nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	suite.NotContains(httpWriter.Body.String(), "awesome")
+	httpWriter = suite.request("GET", "/v1/registry/credentials", nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	suite.NotContains(httpWriter.Body.String(), "awesome")
+
+	// update the password
+	c.Password = "changed"
+	httpWriter = suite.request("PUT", "/v1/registry/credentials/"+created.ID.Hex(), c)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	secret, err = suite.sp.KubeCtl.GetSecret(kubeutils.RegistrySecretName(c.Name), "default")
+	suite.NoError(err)
+	suite.Contains(string(secret.Data[corev1.DockerConfigJsonKey]), "changed")
+
+	// the secret is only copied to the namespaces of the granted teams
+	team := entity.Team{ID: bson.NewObjectId(), Name: namesgenerator.GetRandomName(0)}
+	suite.NoError(suite.session.Insert(entity.TeamCollectionName, &team))
+	defer suite.session.Remove(entity.TeamCollectionName, "_id", team.ID)
+	namespace := entity.Namespace{ID: bson.NewObjectId(), Name: namesgenerator.GetRandomName(1), TeamID: team.ID}
+	suite.NoError(suite.session.Insert(entity.NamespaceCollectionName, &namespace))
+	defer suite.session.Remove(entity.NamespaceCollectionName, "_id", namespace.ID)
+	_, err = suite.sp.KubeCtl.GetSecret(kubeutils.RegistrySecretName(c.Name), namespace.Name)
+	suite.Error(err)
+
+	c.Teams = []bson.ObjectId{team.ID}
+	httpWriter = suite.request("PUT", "/v1/registry/credentials/"+created.ID.Hex(), c)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	_, err = suite.sp.KubeCtl.GetSecret(kubeutils.RegistrySecretName(c.Name), namespace.Name)
+	suite.NoError(err)
+
+	// the grant is revoked
+	c.Teams = []bson.ObjectId{}
+	httpWriter = suite.request("PUT", "/v1/registry/credentials/"+created.ID.Hex(), c)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	_, err = suite.sp.KubeCtl.GetSecret(kubeutils.RegistrySecretName(c.Name), namespace.Name)
+	suite.Error(err)
+
+	httpWriter = suite.request("DELETE", "/v1/registry/credentials/"+created.ID.Hex(), nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	_, err = suite.sp.KubeCtl.GetSecret(kubeutils.RegistrySecretName(c.Name), "default")
+	suite.Error(err)
+	count, err := suite.session.Count(entity.RegistryCredentialCollectionName, bson.M{"_id": created.ID})
+	suite.NoError(err)
+	suite.Equal(0, count)
+}
+
+func (suite *RegistryCredentialTestSuite) TestCreateRegistryCredentialFail() {
+	// the password is required
+	c := entity.RegistryCredential{
+		Name:     namesgenerator.GetRandomName(0),
+		URL:      "https://registry.example.com",
+		Username: "admin",
+	}
+	httpWriter := suite.request("POST", "/v1/registry/credentials", c)
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+
+	// the team doesn't exist
+	c.Password = "awesome"
+	c.Teams = []bson.ObjectId{bson.NewObjectId()}
+	httpWriter = suite.request("POST", "/v1/registry/credentials", c)
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+
+	// the secret of the user isn't overwritten
+	c.Teams = nil
+	secret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: kubeutils.RegistrySecretName(c.Name)},
+		Data:       map[string][]byte{"token": []byte("mine")},
+	}
+	_, err := suite.sp.KubeCtl.CreateSecret(&secret, "default")
+	suite.NoError(err)
+	defer suite.sp.KubeCtl.DeleteSecret(secret.Name, "default")
+	httpWriter = suite.request("POST", "/v1/registry/credentials", c)
+	assertResponseCode(suite.T(), http.StatusConflict, httpWriter)
+	current, err := suite.sp.KubeCtl.GetSecret(secret.Name, "default")
+	suite.NoError(err)
+	suite.Equal("mine", string(current.Data["token"]))
+}
+
+func (suite *RegistryCredentialTestSuite) TestRegistryCredentialWithInvalidID() {
+	httpWriter := suite.request("GET", "/v1/registry/credentials/"+bson.NewObjectId().Hex(), nil)
+	assertResponseCode(suite.T(), http.StatusNotFound, httpWriter)
+	httpWriter = suite.request("DELETE", "/v1/registry/credentials/"+bson.NewObjectId().Hex(), nil)
+	assertResponseCode(suite.T(), http.StatusNotFound, httpWriter)
+	httpWriter = suite.request("GET", "/v1/registry/credentials/awesome", nil)
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+}
@@ -0,0 +1,322 @@
+package server
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/linkernetworks/utils/timeutils"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/kubernetes"
+	"github.com/linkernetworks/vortex/src/kubeutils"
+	response "github.com/linkernetworks/vortex/src/net/http"
+	"github.com/linkernetworks/vortex/src/net/http/query"
+	"github.com/linkernetworks/vortex/src/secret"
+	"github.com/linkernetworks/vortex/src/server/backend"
+	"github.com/linkernetworks/vortex/src/web"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// ensureSecretIndex makes the secret names unique in the namespace
+func ensureSecretIndex(c *mgo.Collection) {
+	c.EnsureIndex(mgo.Index{
+		Key:    []string{"namespace", "name"},
+		Unique: true,
+	})
+}
+
+func createSecretHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+	userID, ok := req.Attribute("UserID").(string)
+	if !ok {
+		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: User ID is empty"))
+		return
+	}
+
+	n := entity.Secret{}
+	if err := req.ReadEntity(&n); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	if err := sp.Validator.Struct(n); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if kubeutils.IsRegistrySecretName(n.Name) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Secret name %s is reserved for the registry credentials", n.Name))
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+	ensureSecretIndex(session.C(entity.SecretCollectionName))
+
+	n.ID = bson.NewObjectId()
+	n.CreatedAt = timeutils.Now()
+	if n.Type == "" {
+		n.Type = string(v1.SecretTypeOpaque)
+	}
+	n.Keys = []string{}
+	for k := range n.Data {
+		n.Keys = append(n.Keys, k)
+	}
+	sort.Strings(n.Keys)
+
+	if err := secret.CreateSecret(sp, &n); err != nil {
+		if errors.IsAlreadyExists(err) {
+			response.Conflict(req.Request, resp.ResponseWriter, fmt.Errorf("Secret: %s already existed", n.Name))
+		} else if errors.IsInvalid(err) {
+			response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Create setting is invalid: %v", err))
+		} else {
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+	// the values are only kept by Kubernetes
+	n.Data = nil
+
+	n.OwnerID = bson.ObjectIdHex(userID)
+	if err := session.Insert(entity.SecretCollectionName, &n); err != nil {
+		if mgo.IsDup(err) {
+			response.Conflict(req.Request, resp.ResponseWriter, fmt.Errorf("Secret: %s already existed", n.Name))
+		} else {
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+	n.CreatedBy, _ = backend.FindUserByID(session, n.OwnerID)
+	resp.WriteHeaderAndEntity(http.StatusCreated, n)
+}
+
+func deleteSecretHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	id := req.PathParameter("id")
+	if !bson.IsObjectIdHex(id) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid secret ID: %s", id))
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	n := entity.Secret{}
+	if err := session.FindOne(entity.SecretCollectionName, bson.M{"_id": bson.ObjectIdHex(id)}, &n); err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+
+	if err := secret.DeleteSecret(sp, &n); err != nil {
+		if errors.IsForbidden(err) {
+			response.NotAcceptable(req.Request, resp.ResponseWriter, err)
+			return
+		} else if !errors.IsNotFound(err) {
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+			return
+		}
+	}
+
+	if err := session.Remove(entity.SecretCollectionName, "_id", bson.ObjectIdHex(id)); err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+			return
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+			return
+		}
+	}
+
+	resp.WriteEntity(response.ActionResponse{
+		Error:   false,
+		Message: "Delete success",
+	})
+}
+
+func listSecretHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	var pageSize = 1024
+	query := query.New(req.Request.URL.Query())
+
+	page, err := query.Int("page", 1)
+	if err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	pageSize, err = query.Int("page_size", pageSize)
+	if err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	secrets := []entity.Secret{}
+	selector := namespaceSelector(req, "namespace")
+	q := session.C(entity.SecretCollectionName).Find(selector).Sort("_id").Skip((page - 1) * pageSize).Limit(pageSize)
+	if err := q.All(&secrets); err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+			return
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+			return
+		}
+	}
+
+	// insert users entity
+	for i, s := range secrets {
+		// find owner in user entity
+		secrets[i].CreatedBy, _ = backend.FindUserByID(session, s.OwnerID)
+	}
+	count, err := session.Count(entity.SecretCollectionName, selector)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	totalPages := int(math.Ceil(float64(count) / float64(pageSize)))
+	resp.AddHeader("X-Total-Count", strconv.Itoa(count))
+	resp.AddHeader("X-Total-Pages", strconv.Itoa(totalPages))
+	resp.WriteEntity(secrets)
+}
+
+func getSecretHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	id := req.PathParameter("id")
+	if !bson.IsObjectIdHex(id) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid secret ID: %s", id))
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	var s entity.Secret
+	if err := session.FindOne(entity.SecretCollectionName, bson.M{"_id": bson.ObjectIdHex(id)}, &s); err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+			return
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+			return
+		}
+	}
+	// find owner in user entity
+	s.CreatedBy, _ = backend.FindUserByID(session, s.OwnerID)
+	resp.WriteEntity(s)
+}
+
+func uploadSecretYAMLHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+	userID, ok := req.Attribute("UserID").(string)
+	if !ok {
+		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: User ID is empty"))
+		return
+	}
+
+	if err := req.Request.ParseMultipartForm(_24K); nil != err {
+		response.InternalServerError(req.Request, resp.ResponseWriter, fmt.Errorf("Failed to read multipart form: %s", err.Error()))
+		return
+	}
+
+	infile, _, err := req.Request.FormFile("file")
+	if err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Error parsing uploaded file %v", err))
+		return
+	}
+
+	content, err := ioutil.ReadAll(infile)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, fmt.Errorf("Failed to read data: %s", err.Error()))
+		return
+	}
+
+	if len(content) == 0 {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Empty content"))
+		return
+	}
+
+	obj, err := kubernetes.ParseK8SYAML(content)
+	if err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	secretObj, ok := obj.(*v1.Secret)
+	if !ok {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("The YAML file is not for creating secret"))
+		return
+	}
+	if secretObj.Namespace == "" {
+		secretObj.Namespace = "default"
+	}
+	if secretObj.Type == "" {
+		secretObj.Type = v1.SecretTypeOpaque
+	}
+
+	d := entity.Secret{
+		ID:        bson.NewObjectId(),
+		OwnerID:   bson.ObjectIdHex(userID),
+		Name:      secretObj.ObjectMeta.Name,
+		Namespace: secretObj.ObjectMeta.Namespace,
+		Type:      string(secretObj.Type),
+		Keys:      secret.Keys(secretObj),
+	}
+	if err := sp.Validator.StructExcept(d, "Data"); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if kubeutils.IsRegistrySecretName(d.Name) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Secret name %s is reserved for the registry credentials", d.Name))
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+	ensureSecretIndex(session.C(entity.SecretCollectionName))
+
+	d.CreatedAt = timeutils.Now()
+	_, err = sp.KubeCtl.CreateSecret(secretObj, secretObj.Namespace)
+	if err != nil {
+		if errors.IsAlreadyExists(err) {
+			response.Conflict(req.Request, resp.ResponseWriter, fmt.Errorf("Secret Name: %s already existed", d.Name))
+		} else if errors.IsConflict(err) {
+			response.Conflict(req.Request, resp.ResponseWriter, fmt.Errorf("Create setting has conflict: %v", err))
+		} else if errors.IsInvalid(err) {
+			response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Create setting is invalid: %v", err))
+		} else {
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+
+	if err := session.Insert(entity.SecretCollectionName, &d); err != nil {
+		if mgo.IsDup(err) {
+			response.Conflict(req.Request, resp.ResponseWriter, fmt.Errorf("Secret Name: %s already existed", d.Name))
+		} else {
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+	// find owner in user entity
+	d.CreatedBy, _ = backend.FindUserByID(session, d.OwnerID)
+	resp.WriteHeaderAndEntity(http.StatusCreated, d)
+}
@@ -0,0 +1,211 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/secret"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type SecretTestSuite struct {
+	ServerTestSuite
+}
+
+func (suite *SecretTestSuite) SetupSuite() {
+	suite.setupServices(newSecretService)
+}
+
+func (suite *SecretTestSuite) TearDownSuite() {}
+
+func TestSecretSuite(t *testing.T) {
+	suite.Run(t, new(SecretTestSuite))
+}
+
+func (suite *SecretTestSuite) TestCreateSecret() {
+	s := entity.Secret{
+		Name:      namesgenerator.GetRandomName(0),
+		Namespace: "default",
+		Data: map[string]string{
+			"username": "admin",
+			"password": "awesome",
+		},
+	}
+
+	httpWriter := suite.request("POST", "/v1/secrets", s)
+	assertResponseCode(suite.T(), http.StatusCreated, httpWriter)
+	defer suite.session.Remove(entity.SecretCollectionName, "name", s.Name)
+	defer secret.DeleteSecret(suite.sp, &s)
+	suite.NotContains(httpWriter.Body.String(), "awesome")
+
+	created := entity.Secret{}
+	err := json.Unmarshal(httpWriter.Body.Bytes(), &created)
+	suite.NoError(err)
+	suite.Equal("Opaque", created.Type)
+	suite.Equal([]string{"password", "username"}, created.Keys)
+	suite.Nil(created.Data)
+
+	// the values are kept by Kubernetes only
+	document := bson.M{}
+	err = suite.session.FindOne(entity.SecretCollectionName, bson.M{"name": s.Name}, &document)
+	suite.NoError(err)
+	suite.NotContains(document, "data")
+	suite.NotContains(fmt.Sprint(document), "awesome")
+	k8sSecret, err := suite.sp.KubeCtl.GetSecret(s.Name, s.Namespace)
+	suite.NoError(err)
+	suite.Equal("awesome", string(k8sSecret.Data["password"]))
+
+	//Create again and it should fail since the name exist
+	assertResponseCode(suite.T(), http.StatusConflict, suite.request("POST", "/v1/secrets", s))
+}
+
+func (suite *SecretTestSuite) TestCreateSecretFail() {
+	// the secret has no data
+	s := entity.Secret{
+		Name:      namesgenerator.GetRandomName(0),
+		Namespace: "default",
+	}
+	assertResponseCode(suite.T(), http.StatusBadRequest, suite.request("POST", "/v1/secrets", s))
+
+	s.Data = map[string]string{"password": "awesome"}
+	s.Type = "kubernetes.io/unknown"
+	assertResponseCode(suite.T(), http.StatusBadRequest, suite.request("POST", "/v1/secrets", s))
+
+	// the names of the registry credential secrets are reserved
+	s.Type = ""
+	s.Name = "registry-" + namesgenerator.GetRandomName(0)
+	assertResponseCode(suite.T(), http.StatusBadRequest, suite.request("POST", "/v1/secrets", s))
+}
+
+func (suite *SecretTestSuite) TestDeleteSecret() {
+	s := entity.Secret{
+		ID:        bson.NewObjectId(),
+		Name:      namesgenerator.GetRandomName(0),
+		Namespace: "default",
+		Data:      map[string]string{"password": "awesome"},
+	}
+	err := secret.CreateSecret(suite.sp, &s)
+	suite.NoError(err)
+	err = suite.session.Insert(entity.SecretCollectionName, &s)
+	suite.NoError(err)
+
+	assertResponseCode(suite.T(), http.StatusOK, suite.request("DELETE", "/v1/secrets/"+s.ID.Hex(), nil))
+
+	n, err := suite.session.Count(entity.SecretCollectionName, bson.M{"_id": s.ID})
+	suite.NoError(err)
+	suite.Equal(0, n)
+	_, err = suite.sp.KubeCtl.GetSecret(s.Name, s.Namespace)
+	suite.Error(err)
+}
+
+func (suite *SecretTestSuite) TestDeleteSecretWithInvalidID() {
+	assertResponseCode(suite.T(), http.StatusNotFound, suite.request("DELETE", "/v1/secrets/"+bson.NewObjectId().Hex(), nil))
+	assertResponseCode(suite.T(), http.StatusBadRequest, suite.request("DELETE", "/v1/secrets/awesome", nil))
+}
+
+func (suite *SecretTestSuite) TestGetSecret() {
+	s := entity.Secret{
+		ID:        bson.NewObjectId(),
+		Name:      namesgenerator.GetRandomName(0),
+		Namespace: "default",
+		Keys:      []string{"password"},
+	}
+	err := suite.session.Insert(entity.SecretCollectionName, &s)
+	suite.NoError(err)
+	defer suite.session.Remove(entity.SecretCollectionName, "_id", s.ID)
+
+	httpWriter := suite.request("GET", "/v1/secrets/"+s.ID.Hex(), nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	ret := entity.Secret{}
+	err = json.Unmarshal(httpWriter.Body.Bytes(), &ret)
+	suite.NoError(err)
+	suite.Equal(s.Name, ret.Name)
+	suite.Equal([]string{"password"}, ret.Keys)
+	suite.NotContains(httpWriter.Body.String(), "\"data\"")
+
+	assertResponseCode(suite.T(), http.StatusNotFound, suite.request("GET", "/v1/secrets/"+bson.NewObjectId().Hex(), nil))
+}
+
+func (suite *SecretTestSuite) TestListSecret() {
+	secrets := []entity.Secret{}
+	count := 3
+	for i := 0; i < count; i++ {
+		s := entity.Secret{
+			ID:        bson.NewObjectId(),
+			Name:      namesgenerator.GetRandomName(0),
+			Namespace: fmt.Sprintf("namespace-%d", i),
+			Keys:      []string{"password"},
+		}
+		err := suite.session.Insert(entity.SecretCollectionName, &s)
+		suite.NoError(err)
+		defer suite.session.Remove(entity.SecretCollectionName, "_id", s.ID)
+		secrets = append(secrets, s)
+	}
+
+	httpWriter := suite.request("GET", "/v1/secrets?page_size=100", nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	retSecrets := []entity.Secret{}
+	err := json.Unmarshal(httpWriter.Body.Bytes(), &retSecrets)
+	suite.NoError(err)
+	suite.True(len(retSecrets) >= count)
+	suite.NotContains(httpWriter.Body.String(), "\"data\"")
+
+	assertResponseCode(suite.T(), http.StatusBadRequest, suite.request("GET", "/v1/secrets?page=asd", nil))
+}
+
+func (suite *SecretTestSuite) uploadYAML(filename string) *httptest.ResponseRecorder {
+	bodyBuf := bytes.NewBufferString("")
+	bodyWriter := multipart.NewWriter(bodyBuf)
+	_, err := bodyWriter.CreateFormFile("file", filename)
+	suite.NoError(err)
+
+	file, err := os.Open(filename)
+	suite.NoError(err)
+	defer file.Close()
+	boundary := bodyWriter.Boundary()
+	closeBuf := bytes.NewBufferString(fmt.Sprintf("\r\n--%s--\r\n", boundary))
+	fileStat, err := file.Stat()
+	suite.NoError(err)
+
+	httpRequest, err := http.NewRequest("POST", "http://localhost:7890/v1/secrets/upload/yaml", io.MultiReader(bodyBuf, file, closeBuf))
+	suite.NoError(err)
+	httpRequest.Header.Add("Content-Type", "multipart/form-data; boundary="+boundary)
+	httpRequest.Header.Add("Authorization", suite.JWTBearer)
+	httpRequest.ContentLength = fileStat.Size() + int64(bodyBuf.Len()) + int64(closeBuf.Len())
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	return httpWriter
+}
+
+func (suite *SecretTestSuite) TestUploadSecretYAML() {
+	httpWriter := suite.uploadYAML("../../testYAMLs/secret.yaml")
+	defer suite.session.Remove(entity.SecretCollectionName, "name", "upload-secret")
+	assertResponseCode(suite.T(), http.StatusCreated, httpWriter)
+	suite.False(strings.Contains(httpWriter.Body.String(), "awesome"))
+
+	//load data to check
+	retSecret := entity.Secret{}
+	err := suite.session.FindOne(entity.SecretCollectionName, bson.M{"name": "upload-secret"}, &retSecret)
+	suite.NoError(err)
+	suite.Equal("default", retSecret.Namespace)
+	suite.Equal([]string{"password", "username"}, retSecret.Keys)
+	defer secret.DeleteSecret(suite.sp, &retSecret)
+
+	//Create again and it should fail since the name exist
+	assertResponseCode(suite.T(), http.StatusConflict, suite.uploadYAML("../../testYAMLs/secret.yaml"))
+}
+
+func (suite *SecretTestSuite) TestUploadSecretYAMLFail() {
+	assertResponseCode(suite.T(), http.StatusBadRequest, suite.uploadYAML("../../testYAMLs/configmap.yaml"))
+}
@@ -143,7 +143,7 @@ func listServiceHandler(ctx *web.Context) {
 	var c = session.C(entity.ServiceCollectionName)
 	var q *mgo.Query
 
-	selector := bson.M{}
+	selector := namespaceSelector(req, "namespace")
 	q = c.Find(selector).Sort("_id").Skip((page - 1) * pageSize).Limit(pageSize)
 
 	if err := q.All(&services); err != nil {
@@ -163,7 +163,7 @@ func listServiceHandler(ctx *web.Context) {
 		services[i].CreatedBy, _ = backend.FindUserByID(session, service.OwnerID)
 	}
 
-	count, err := session.Count(entity.ServiceCollectionName, bson.M{})
+	count, err := session.Count(entity.ServiceCollectionName, selector)
 	if err != nil {
 		response.InternalServerError(req.Request, resp.ResponseWriter, err)
 		return
@@ -50,8 +50,8 @@ func (suite *ServiceTestSuite) SetupSuite() {
 	serviceService := newServiceService(suite.sp)
 	userService := newUserService(suite.sp)
 
-	suite.wc.Add(serviceService)
-	suite.wc.Add(userService)
+	suite.wc.Add(secureService(suite.sp, serviceService))
+	suite.wc.Add(secureService(suite.sp, userService))
 
 	token, _ := loginGetToken(suite.wc)
 	suite.NotEmpty(token)
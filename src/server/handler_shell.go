@@ -43,6 +43,8 @@ func handleExecShell(ctx *web.Context) {
 		bound:    make(chan error),
 		sizeChan: make(chan remotecommand.TerminalSize),
 	}
+	// the audit filter records the terminal session
+	req.SetAttribute("TerminalSessionID", sessionId)
 	go WaitForTerminal(sp.KubeCtl.Clientset, sp.ClusterConfig, req, sessionId)
 	resp.WriteHeaderAndEntity(http.StatusOK, TerminalResponse{Id: sessionId})
 }
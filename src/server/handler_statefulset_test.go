@@ -0,0 +1,167 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/suite"
+	corev1 "k8s.io/api/core/v1"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+type StatefulSetTestSuite struct {
+	ServerTestSuite
+	storage entity.Storage
+}
+
+func (suite *StatefulSetTestSuite) SetupSuite() {
+	suite.setupServices(newStatefulSetService)
+
+	suite.storage = entity.Storage{
+		ID:               bson.NewObjectId(),
+		Type:             entity.NFSStorageType,
+		Name:             namesgenerator.GetRandomName(0),
+		StorageClassName: namesgenerator.GetRandomName(0),
+	}
+	suite.session.Insert(entity.StorageCollectionName, suite.storage)
+}
+
+func (suite *StatefulSetTestSuite) TearDownSuite() {
+	suite.session.Remove(entity.StorageCollectionName, "_id", suite.storage.ID)
+}
+
+func TestStatefulSetSuite(t *testing.T) {
+	suite.Run(t, new(StatefulSetTestSuite))
+}
+
+func (suite *StatefulSetTestSuite) newStatefulSet() entity.StatefulSet {
+	return entity.StatefulSet{
+		Name:      namesgenerator.GetRandomName(0),
+		Namespace: "default",
+		Labels:    map[string]string{},
+		EnvVars:   map[string]string{},
+		Containers: []entity.Container{
+			{
+				Name:    namesgenerator.GetRandomName(0),
+				Image:   "busybox",
+				Command: []string{"sleep", "3600"},
+			},
+		},
+		Volumes:      []entity.DeploymentVolume{},
+		ConfigMaps:   []entity.DeploymentConfig{},
+		Networks:     []entity.DeploymentNetwork{},
+		NetworkType:  entity.DeploymentClusterNetwork,
+		NodeAffinity: []string{},
+		Replicas:     3,
+		VolumeClaims: []entity.StatefulSetVolumeClaim{
+			{
+				Name:        "data",
+				StorageName: suite.storage.Name,
+				AccessMode:  corev1.ReadWriteOnce,
+				Capacity:    "1Gi",
+				MountPath:   "/var/lib/data",
+			},
+		},
+	}
+}
+
+func (suite *StatefulSetTestSuite) TestCreateStatefulSet() {
+	s := suite.newStatefulSet()
+	httpWriter := suite.request("POST", "/v1/statefulsets", s)
+	assertResponseCode(suite.T(), http.StatusCreated, httpWriter)
+	defer suite.session.Remove(entity.StatefulSetCollectionName, "name", s.Name)
+
+	created := entity.StatefulSet{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &created))
+	suite.Equal(s.Name, created.Name)
+
+	current, err := suite.sp.KubeCtl.GetStatefulSet(s.Name, s.Namespace)
+	suite.NoError(err)
+	suite.Equal(s.Name, current.Spec.ServiceName)
+	suite.Len(current.Spec.VolumeClaimTemplates, 1)
+	service, err := suite.sp.KubeCtl.GetService(s.Name, s.Namespace)
+	suite.NoError(err)
+	suite.Equal(corev1.ClusterIPNone, service.Spec.ClusterIP)
+
+	// the name is used
+	httpWriter = suite.request("POST", "/v1/statefulsets", s)
+	assertResponseCode(suite.T(), http.StatusConflict, httpWriter)
+
+	httpWriter = suite.request("GET", "/v1/statefulsets/"+created.ID.Hex(), nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	httpWriter = suite.request("GET", "/v1/statefulsets/?namespace=default", nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	statefulSets := []entity.StatefulSet{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &statefulSets))
+	suite.NotEmpty(statefulSets)
+
+	httpWriter = suite.request("DELETE", "/v1/statefulsets/"+created.ID.Hex(), nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	_, err = suite.sp.KubeCtl.GetService(s.Name, s.Namespace)
+	suite.Error(err)
+
+	httpWriter = suite.request("DELETE", "/v1/statefulsets/"+created.ID.Hex(), nil)
+	assertResponseCode(suite.T(), http.StatusNotFound, httpWriter)
+}
+
+func (suite *StatefulSetTestSuite) TestCreateStatefulSetFail() {
+	s := suite.newStatefulSet()
+	s.Replicas = 0
+	httpWriter := suite.request("POST", "/v1/statefulsets", s)
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+
+	s = suite.newStatefulSet()
+	s.PodManagementPolicy = "Random"
+	httpWriter = suite.request("POST", "/v1/statefulsets", s)
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+
+	s = suite.newStatefulSet()
+	s.VolumeClaims[0].StorageName = namesgenerator.GetRandomName(0)
+	httpWriter = suite.request("POST", "/v1/statefulsets", s)
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+}
+
+func (suite *StatefulSetTestSuite) TestUpdateStatefulSet() {
+	s := suite.newStatefulSet()
+	httpWriter := suite.request("POST", "/v1/statefulsets", s)
+	assertResponseCode(suite.T(), http.StatusCreated, httpWriter)
+	defer suite.session.Remove(entity.StatefulSetCollectionName, "name", s.Name)
+	created := entity.StatefulSet{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &created))
+	path := fmt.Sprintf("/v1/statefulsets/%s", created.ID.Hex())
+
+	s.Replicas = 5
+	// the volume claims can't be changed
+	s.VolumeClaims = nil
+	httpWriter = suite.request("PUT", path, s)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	updated := entity.StatefulSet{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &updated))
+	suite.Equal(int32(5), updated.Replicas)
+	suite.Len(updated.VolumeClaims, 1)
+
+	current, err := suite.sp.KubeCtl.GetStatefulSet(s.Name, s.Namespace)
+	suite.NoError(err)
+	suite.Equal(int32(5), *current.Spec.Replicas)
+
+	s.Name = namesgenerator.GetRandomName(0)
+	httpWriter = suite.request("PUT", path, s)
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+
+	httpWriter = suite.request("PUT", "/v1/statefulsets/"+bson.NewObjectId().Hex(), s)
+	assertResponseCode(suite.T(), http.StatusNotFound, httpWriter)
+}
+
+func (suite *StatefulSetTestSuite) TestGetStatefulSetWithInvalidID() {
+	httpWriter := suite.request("GET", "/v1/statefulsets/"+bson.NewObjectId().Hex(), nil)
+	assertResponseCode(suite.T(), http.StatusNotFound, httpWriter)
+
+	httpWriter = suite.request("GET", "/v1/statefulsets/invalid", nil)
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+}
@@ -44,8 +44,8 @@ func (suite *StorageTestSuite) SetupSuite() {
 	storageService := newStorageService(sp)
 	userService := newUserService(suite.sp)
 
-	suite.wc.Add(storageService)
-	suite.wc.Add(userService)
+	suite.wc.Add(secureService(suite.sp, storageService))
+	suite.wc.Add(secureService(suite.sp, userService))
 
 	token, _ := loginGetToken(suite.wc)
 	suite.NotEmpty(token)
@@ -0,0 +1,287 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/entity"
+	response "github.com/linkernetworks/vortex/src/net/http"
+	"github.com/linkernetworks/vortex/src/registry"
+	"github.com/linkernetworks/vortex/src/server/backend"
+	"github.com/linkernetworks/vortex/src/web"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// createTeamHandler creates a team. The role must to have admin permission to access it.
+func createTeamHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+	userID, ok := req.Attribute("UserID").(string)
+	if !ok {
+		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: User ID is empty"))
+		return
+	}
+
+	team := entity.Team{}
+	if err := req.ReadEntity(&team); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if err := sp.Validator.Struct(team); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	for _, member := range team.Members {
+		if err := checkTeamMember(session, member); err != nil {
+			response.BadRequest(req.Request, resp.ResponseWriter, err)
+			return
+		}
+	}
+
+	team.OwnerID = bson.ObjectIdHex(userID)
+	if err := backend.CreateTeam(session, &team); err != nil {
+		if mgo.IsDup(err) {
+			response.Conflict(req.Request, resp.ResponseWriter, fmt.Errorf("Team: %s already existed", team.Name))
+		} else {
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+	team.Namespaces = []string{}
+	resp.WriteHeaderAndEntity(http.StatusCreated, team)
+}
+
+// listTeamHandler lists all teams for root and the teams of the member for the others
+func listTeamHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	memberID := bson.ObjectId("")
+	if role, _ := req.Attribute("Role").(string); role != entity.RootRole {
+		userID, _ := req.Attribute("UserID").(string)
+		if !bson.IsObjectIdHex(userID) {
+			response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: User ID is empty"))
+			return
+		}
+		memberID = bson.ObjectIdHex(userID)
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	teams, err := backend.ListTeams(session, memberID)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	resp.WriteEntity(teams)
+}
+
+// getTeamHandler gets the team, only root and the members can access it
+func getTeamHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	team, ok := findTeam(ctx, session)
+	if !ok {
+		return
+	}
+	if role, _ := req.Attribute("Role").(string); role != entity.RootRole {
+		userID, _ := req.Attribute("UserID").(string)
+		if !bson.IsObjectIdHex(userID) || team.Role(bson.ObjectIdHex(userID)) == "" {
+			// don't tell the others whether the team exists
+			response.NotFound(req.Request, resp.ResponseWriter, mgo.ErrNotFound)
+			return
+		}
+	}
+	resp.WriteEntity(team)
+}
+
+// deleteTeamHandler deletes the team, its namespaces are left without a team. The role must to have admin permission to access it.
+func deleteTeamHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	team, ok := findTeam(ctx, session)
+	if !ok {
+		return
+	}
+	if err := backend.DeleteTeam(session, team.ID); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	// the namespaces left without a team lose the registry credentials granted to the team
+	for _, namespace := range team.Namespaces {
+		if err := registry.ApplyNamespace(sp, namespace, ""); err != nil {
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+			return
+		}
+	}
+	resp.WriteEntity(response.ActionResponse{
+		Error:   false,
+		Message: "Team Deleted Success",
+	})
+}
+
+// setTeamMemberHandler adds a member to the team or changes the role of the member, only root and the team admins can access it
+func setTeamMemberHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	member := entity.TeamMember{}
+	if err := req.ReadEntity(&member); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if err := sp.Validator.Struct(member); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	team, ok := findTeam(ctx, session)
+	if !ok || !teamAdminOnly(ctx, team) {
+		return
+	}
+	if err := checkTeamMember(session, member); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	if err := backend.SetTeamMember(session, team.ID, member); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	team, err := backend.FindTeam(session, team.ID)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	resp.WriteEntity(team)
+}
+
+// removeTeamMemberHandler removes a member from the team, only root and the team admins can access it
+func removeTeamMemberHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	userID := req.PathParameter("userID")
+	if !bson.IsObjectIdHex(userID) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid user ID: %s", userID))
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	team, ok := findTeam(ctx, session)
+	if !ok || !teamAdminOnly(ctx, team) {
+		return
+	}
+	if err := backend.RemoveTeamMember(session, team.ID, bson.ObjectIdHex(userID)); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	resp.WriteEntity(response.ActionResponse{
+		Error:   false,
+		Message: "Team Member Removed Success",
+	})
+}
+
+// findTeam loads the team of the {id} path parameter, the error response is written if it fails
+func findTeam(ctx *web.Context, session *mongo.Session) (entity.Team, bool) {
+	req, resp := ctx.Request, ctx.Response
+
+	id := req.PathParameter("id")
+	if !bson.IsObjectIdHex(id) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid team ID: %s", id))
+		return entity.Team{}, false
+	}
+	team, err := backend.FindTeam(session, bson.ObjectIdHex(id))
+	if err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return entity.Team{}, false
+	}
+	return team, true
+}
+
+// teamAdminOnly rejects the callers who are neither root nor an admin of the team
+func teamAdminOnly(ctx *web.Context, team entity.Team) bool {
+	req, resp := ctx.Request, ctx.Response
+
+	if role, _ := req.Attribute("Role").(string); role == entity.RootRole {
+		return true
+	}
+	userID, _ := req.Attribute("UserID").(string)
+	if bson.IsObjectIdHex(userID) && team.Role(bson.ObjectIdHex(userID)) == entity.TeamAdminRole {
+		return true
+	}
+	permissionDenied(req, resp, "User "+userID+" is not an admin of team "+team.ID.Hex())
+	return false
+}
+
+// checkTeamMember makes sure the member is an existing user
+func checkTeamMember(session *mongo.Session, member entity.TeamMember) error {
+	if _, err := backend.FindUserByID(session, member.UserID); err != nil {
+		if err == mgo.ErrNotFound {
+			return fmt.Errorf("User %s doesn't exist", member.UserID.Hex())
+		}
+		return err
+	}
+	return nil
+}
+
+// namespaceTeam checks the team the new namespace is assigned to. The team is optional for root,
+// the others have to be an admin or a member of the team. The error response is written if it fails.
+func namespaceTeam(ctx *web.Context, session *mongo.Session, teamID string) (bson.ObjectId, bool) {
+	req, resp := ctx.Request, ctx.Response
+	role, _ := req.Attribute("Role").(string)
+
+	if teamID == "" {
+		if role == entity.RootRole {
+			return "", true
+		}
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("The namespace has to be assigned to a team"))
+		return "", false
+	}
+	if !bson.IsObjectIdHex(teamID) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid team ID: %s", teamID))
+		return "", false
+	}
+
+	team, err := backend.FindTeam(session, bson.ObjectIdHex(teamID))
+	if err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Team %s doesn't exist", teamID))
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return "", false
+	}
+	if role != entity.RootRole {
+		userID, _ := req.Attribute("UserID").(string)
+		if !bson.IsObjectIdHex(userID) {
+			response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: User ID is empty"))
+			return "", false
+		}
+		if teamRole := team.Role(bson.ObjectIdHex(userID)); teamRole != entity.TeamAdminRole && teamRole != entity.TeamMemberRole {
+			permissionDenied(req, resp, "User "+userID+" can't add namespaces to team "+teamID)
+			return "", false
+		}
+	}
+	return team.ID, true
+}
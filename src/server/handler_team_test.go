@@ -0,0 +1,175 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/server/backend"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type TeamTestSuite struct {
+	ServerTestSuite
+}
+
+func (suite *TeamTestSuite) SetupSuite() {
+	suite.setupServices(newTeamService, newNamespaceService, newDeploymentService)
+}
+
+func (suite *TeamTestSuite) TearDownSuite() {}
+
+func TestTeamSuite(t *testing.T) {
+	suite.Run(t, new(TeamTestSuite))
+}
+
+// createUser stores a user of the role and returns the user with a bearer token
+func (suite *TeamTestSuite) createUser(role string) (entity.User, string) {
+	user := entity.User{
+		ID:          bson.NewObjectId(),
+		DisplayName: role,
+		Role:        role,
+		LoginCredential: entity.LoginCredential{
+			Username: namesgenerator.GetRandomName(0) + "@linkernetworks.com",
+		},
+	}
+	suite.NoError(suite.session.Insert(entity.UserCollectionName, &user))
+
+	userSession := entity.Session{
+		ID:        bson.NewObjectId(),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	suite.NoError(backend.CreateSession(suite.session, userSession))
+
+	token, err := suite.sp.JWT.GenerateToken(user.ID.Hex(), user, userSession.ID.Hex())
+	suite.NoError(err)
+	return user, "Bearer " + token
+}
+
+func (suite *TeamTestSuite) createTeam(members ...entity.TeamMember) entity.Team {
+	team := entity.Team{Name: namesgenerator.GetRandomName(0), Members: members}
+	httpWriter := suite.requestAs("POST", "/v1/teams", suite.JWTBearer, team)
+	assertResponseCode(suite.T(), http.StatusCreated, httpWriter)
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &team))
+	return team
+}
+
+func (suite *TeamTestSuite) TestTeamMembers() {
+	admin, adminBearer := suite.createUser(entity.UserRole)
+	defer suite.session.Remove(entity.UserCollectionName, "_id", admin.ID)
+	member, memberBearer := suite.createUser(entity.UserRole)
+	defer suite.session.Remove(entity.UserCollectionName, "_id", member.ID)
+
+	team := suite.createTeam(entity.TeamMember{UserID: admin.ID, Role: entity.TeamAdminRole})
+	defer suite.session.Remove(entity.TeamCollectionName, "_id", team.ID)
+
+	// only the team admins can add members
+	newMember := entity.TeamMember{UserID: member.ID, Role: entity.TeamViewerRole}
+	assertResponseCode(suite.T(), http.StatusForbidden, suite.requestAs("PUT", "/v1/teams/"+team.ID.Hex()+"/members", memberBearer, newMember))
+	assertResponseCode(suite.T(), http.StatusNotFound, suite.requestAs("GET", "/v1/teams/"+team.ID.Hex(), memberBearer, nil))
+
+	assertResponseCode(suite.T(), http.StatusOK, suite.requestAs("PUT", "/v1/teams/"+team.ID.Hex()+"/members", adminBearer, newMember))
+	assertResponseCode(suite.T(), http.StatusOK, suite.requestAs("GET", "/v1/teams/"+team.ID.Hex(), memberBearer, nil))
+
+	// the members only list their teams
+	httpWriter := suite.requestAs("GET", "/v1/teams", memberBearer, nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	teams := []entity.Team{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &teams))
+	suite.Len(teams, 1)
+	suite.Equal(team.ID, teams[0].ID)
+
+	// unknown users can't be added
+	unknown := entity.TeamMember{UserID: bson.NewObjectId(), Role: entity.TeamMemberRole}
+	assertResponseCode(suite.T(), http.StatusBadRequest, suite.requestAs("PUT", "/v1/teams/"+team.ID.Hex()+"/members", adminBearer, unknown))
+
+	assertResponseCode(suite.T(), http.StatusOK, suite.requestAs("DELETE", "/v1/teams/"+team.ID.Hex()+"/members/"+member.ID.Hex(), adminBearer, nil))
+	assertResponseCode(suite.T(), http.StatusNotFound, suite.requestAs("GET", "/v1/teams/"+team.ID.Hex(), memberBearer, nil))
+}
+
+func (suite *TeamTestSuite) TestNamespaceOfTeam() {
+	member, memberBearer := suite.createUser(entity.UserRole)
+	defer suite.session.Remove(entity.UserCollectionName, "_id", member.ID)
+	viewer, viewerBearer := suite.createUser(entity.UserRole)
+	defer suite.session.Remove(entity.UserCollectionName, "_id", viewer.ID)
+	outsider, outsiderBearer := suite.createUser(entity.UserRole)
+	defer suite.session.Remove(entity.UserCollectionName, "_id", outsider.ID)
+
+	team := suite.createTeam(
+		entity.TeamMember{UserID: member.ID, Role: entity.TeamMemberRole},
+		entity.TeamMember{UserID: viewer.ID, Role: entity.TeamViewerRole},
+	)
+	defer suite.session.Remove(entity.TeamCollectionName, "_id", team.ID)
+
+	// the namespace has to be assigned to a team of the user
+	namespace := entity.Namespace{Name: namesgenerator.GetRandomName(0)}
+	assertResponseCode(suite.T(), http.StatusBadRequest, suite.requestAs("POST", "/v1/namespaces", memberBearer, namespace))
+	namespace.TeamID = team.ID
+	assertResponseCode(suite.T(), http.StatusForbidden, suite.requestAs("POST", "/v1/namespaces", viewerBearer, namespace))
+	assertResponseCode(suite.T(), http.StatusForbidden, suite.requestAs("POST", "/v1/namespaces", outsiderBearer, namespace))
+
+	httpWriter := suite.requestAs("POST", "/v1/namespaces", memberBearer, namespace)
+	assertResponseCode(suite.T(), http.StatusCreated, httpWriter)
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &namespace))
+	defer suite.session.Remove(entity.NamespaceCollectionName, "_id", namespace.ID)
+	suite.Equal(team.ID, namespace.TeamID)
+
+	// the outsiders can't see the namespace
+	assertResponseCode(suite.T(), http.StatusOK, suite.requestAs("GET", "/v1/namespaces/"+namespace.ID.Hex(), viewerBearer, nil))
+	assertResponseCode(suite.T(), http.StatusForbidden, suite.requestAs("GET", "/v1/namespaces/"+namespace.ID.Hex(), outsiderBearer, nil))
+
+	httpWriter = suite.requestAs("GET", "/v1/namespaces", outsiderBearer, nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	namespaces := []entity.Namespace{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &namespaces))
+	suite.Empty(namespaces)
+	suite.Equal("0", httpWriter.Header().Get("X-Total-Count"))
+
+	// the resources of the other namespaces are filtered out
+	deployment := entity.Deployment{ID: bson.NewObjectId(), OwnerID: member.ID, Name: namesgenerator.GetRandomName(0), Namespace: namespace.Name}
+	suite.NoError(suite.session.Insert(entity.DeploymentCollectionName, &deployment))
+	defer suite.session.Remove(entity.DeploymentCollectionName, "_id", deployment.ID)
+	otherDeployment := entity.Deployment{ID: bson.NewObjectId(), OwnerID: outsider.ID, Name: namesgenerator.GetRandomName(0), Namespace: "default"}
+	suite.NoError(suite.session.Insert(entity.DeploymentCollectionName, &otherDeployment))
+	defer suite.session.Remove(entity.DeploymentCollectionName, "_id", otherDeployment.ID)
+
+	httpWriter = suite.requestAs("GET", "/v1/deployments", viewerBearer, nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	deployments := []entity.Deployment{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &deployments))
+	suite.Len(deployments, 1)
+	suite.Equal(deployment.ID, deployments[0].ID)
+
+	assertResponseCode(suite.T(), http.StatusForbidden, suite.requestAs("GET", "/v1/deployments/"+otherDeployment.ID.Hex(), viewerBearer, nil))
+	// the viewers can only read
+	assertResponseCode(suite.T(), http.StatusForbidden, suite.requestAs("DELETE", "/v1/deployments/"+deployment.ID.Hex(), viewerBearer, nil))
+	// root can see everything
+	assertResponseCode(suite.T(), http.StatusOK, suite.requestAs("GET", "/v1/deployments/"+otherDeployment.ID.Hex(), suite.JWTBearer, nil))
+}
+
+func (suite *TeamTestSuite) TestAssignNamespaceTeam() {
+	team := suite.createTeam()
+	defer suite.session.Remove(entity.TeamCollectionName, "_id", team.ID)
+
+	namespace := entity.Namespace{ID: bson.NewObjectId(), Name: namesgenerator.GetRandomName(0)}
+	suite.NoError(suite.session.Insert(entity.NamespaceCollectionName, &namespace))
+	defer suite.session.Remove(entity.NamespaceCollectionName, "_id", namespace.ID)
+
+	httpWriter := suite.requestAs("PUT", "/v1/namespaces/"+namespace.ID.Hex()+"/team", suite.JWTBearer, entity.NamespaceTeamRequest{TeamID: team.ID.Hex()})
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	httpWriter = suite.requestAs("GET", "/v1/teams/"+team.ID.Hex(), suite.JWTBearer, nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &team))
+	suite.Equal([]string{namespace.Name}, team.Namespaces)
+
+	assertResponseCode(suite.T(), http.StatusOK, suite.requestAs("DELETE", "/v1/teams/"+team.ID.Hex(), suite.JWTBearer, nil))
+	retNamespace := entity.Namespace{}
+	suite.NoError(suite.session.FindOne(entity.NamespaceCollectionName, bson.M{"_id": namespace.ID}, &retNamespace))
+	suite.Equal(bson.ObjectId(""), retNamespace.TeamID)
+}
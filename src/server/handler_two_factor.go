@@ -0,0 +1,437 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/linkernetworks/logger"
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/entity"
+	response "github.com/linkernetworks/vortex/src/net/http"
+	"github.com/linkernetworks/vortex/src/server/backend"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/linkernetworks/vortex/src/totp"
+	"github.com/linkernetworks/vortex/src/web"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// twoFactorChallengeExpiry is how long the user can take to enter the code after the password
+const twoFactorChallengeExpiry = 5 * time.Minute
+
+// totpIssuer is the name shown by the authenticator apps
+const totpIssuer = "Vortex"
+
+// signInTokens issues the tokens of the authenticated user,
+// or a challenge token if the user has to pass the two-factor authentication first
+func signInTokens(sp *serviceprovider.Container, session *mongo.Session, req *http.Request, user entity.User) (entity.TokenResponse, error) {
+	enrollmentRequired := false
+	if !user.TOTPEnabled {
+		policy, err := backend.GetTwoFactorPolicy(session)
+		if err != nil {
+			return entity.TokenResponse{}, err
+		}
+		if !policy.RequireForRoot || user.Role != entity.RootRole {
+			return issueTokens(sp, session, req, user)
+		}
+		enrollmentRequired = true
+	}
+
+	challengeToken, err := backend.CreateTwoFactorChallenge(session, user.ID, twoFactorChallengeExpiry)
+	if err != nil {
+		return entity.TokenResponse{}, err
+	}
+	return entity.TokenResponse{
+		Error:              false,
+		Message:            "Two-factor authentication required",
+		TwoFactorRequired:  true,
+		EnrollmentRequired: enrollmentRequired,
+		ChallengeToken:     challengeToken,
+	}, nil
+}
+
+// verifySecondFactor checks the TOTP code or a recovery code of the user
+func verifySecondFactor(session *mongo.Session, user entity.User, code string) (bool, error) {
+	if len(code) == totp.Digits {
+		return backend.VerifyTOTP(session, user, code)
+	}
+	return backend.ConsumeRecoveryCode(session, user.ID, code)
+}
+
+// twoFactorSignInHandler completes the sign in with the challenge token and the code.
+// The pending enrollment of the challenge is confirmed by the code as well.
+func twoFactorSignInHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	request := entity.TwoFactorRequest{}
+	if err := req.ReadEntity(&request); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if err := sp.Validator.Struct(request); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	challenge, err := backend.AttemptTwoFactorChallenge(session, request.ChallengeToken)
+	if err != nil {
+		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: Invalid or expired challenge token"))
+		return
+	}
+	user, err := backend.FindUserByID(session, challenge.UserID)
+	if err != nil {
+		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: User ID not found"))
+		return
+	}
+	// the wrong codes lock the user out like the wrong passwords
+	locked, err := backend.IsLockedOut(lockoutConfig(sp), user)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if locked {
+		response.Forbidden(req.Request, resp.ResponseWriter, fmt.Errorf("The user is locked out after too many failed sign in"))
+		return
+	}
+
+	var passed bool
+	if user.TOTPEnabled {
+		passed, err = verifySecondFactor(session, user, request.Code)
+	} else {
+		// the enrollment required at sign in, only the TOTP code can confirm it
+		passed, err = backend.VerifyTOTP(session, user, request.Code)
+	}
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if !passed {
+		if err := backend.RecordFailedLogin(session, lockoutConfig(sp), user.LoginCredential.Username); err != nil {
+			logger.Warnf("Failed to record the failed sign in of %s: %v", user.LoginCredential.Username, err)
+		}
+		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: Incorrect two-factor authentication code"))
+		return
+	}
+
+	if err := backend.RemoveTwoFactorChallenge(session, challenge.ID); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	// the failed sign in are cleared only after both factors passed
+	if user.FailedLogins > 0 || user.LockedAt != nil {
+		if err := backend.UnlockUser(session, user.ID); err != nil {
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+			return
+		}
+	}
+
+	var recoveryCodes []string
+	if !user.TOTPEnabled {
+		if recoveryCodes, err = backend.EnableTOTP(session, user.ID); err != nil {
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+			return
+		}
+	}
+
+	tokens, err := issueTokens(sp, session, req.Request, user)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	tokens.RecoveryCodes = recoveryCodes
+	resp.WriteEntity(tokens)
+}
+
+// enrollTOTPChallengeHandler starts the TOTP enrollment required at sign in
+func enrollTOTPChallengeHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	request := entity.TwoFactorRequest{}
+	if err := req.ReadEntity(&request); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if err := sp.Validator.Struct(request); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	challenge, err := backend.GetTwoFactorChallenge(session, request.ChallengeToken)
+	if err != nil {
+		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: Invalid or expired challenge token"))
+		return
+	}
+	enrollTOTP(sp, session, ctx, challenge.UserID)
+}
+
+// enrollTOTPHandler starts the TOTP enrollment of the signed in user
+func enrollTOTPHandler(ctx *web.Context) {
+	sp := ctx.ServiceProvider
+
+	userID, ok := sessionUserID(ctx)
+	if !ok {
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	enrollTOTP(sp, session, ctx, userID)
+}
+
+// enrollTOTP generates a new secret of the pending enrollment
+func enrollTOTP(sp *serviceprovider.Container, session *mongo.Session, ctx *web.Context, userID bson.ObjectId) {
+	req, resp := ctx.Request, ctx.Response
+
+	user, err := backend.FindUserByID(session, userID)
+	if err != nil {
+		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: User ID not found"))
+		return
+	}
+	if user.TOTPEnabled {
+		response.Conflict(req.Request, resp.ResponseWriter, fmt.Errorf("TOTP is already enabled"))
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if err := backend.SetTOTPSecret(session, user.ID, secret); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	resp.WriteEntity(entity.TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(totpIssuer, user.LoginCredential.Username, secret),
+	})
+}
+
+// confirmTOTPHandler enables the pending enrollment with a TOTP code and returns the recovery codes
+func confirmTOTPHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	user, code, ok := readTOTPCodeRequest(ctx)
+	if !ok {
+		return
+	}
+	if user.TOTPEnabled {
+		response.Conflict(req.Request, resp.ResponseWriter, fmt.Errorf("TOTP is already enabled"))
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	passed, err := backend.VerifyTOTP(session, user, code)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if !passed {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Incorrect TOTP code"))
+		return
+	}
+
+	recoveryCodes, err := backend.EnableTOTP(session, user.ID)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	resp.WriteEntity(entity.RecoveryCodesResponse{
+		Error:         false,
+		Message:       "TOTP Enabled Success",
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+// disableTOTPHandler disables the TOTP of the signed in user with a TOTP code or a recovery code
+func disableTOTPHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	user, code, ok := readTOTPCodeRequest(ctx)
+	if !ok {
+		return
+	}
+	if !user.TOTPEnabled {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("TOTP is not enabled"))
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	passed, err := verifySecondFactor(session, user, code)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if !passed {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Incorrect two-factor authentication code"))
+		return
+	}
+
+	if err := backend.DisableTOTP(session, user.ID); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	resp.WriteEntity(response.ActionResponse{
+		Error:   false,
+		Message: "TOTP Disabled Success",
+	})
+}
+
+// regenerateRecoveryCodesHandler replaces the recovery codes of the signed in user
+func regenerateRecoveryCodesHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	user, code, ok := readTOTPCodeRequest(ctx)
+	if !ok {
+		return
+	}
+	if !user.TOTPEnabled {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("TOTP is not enabled"))
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	passed, err := backend.VerifyTOTP(session, user, code)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if !passed {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Incorrect TOTP code"))
+		return
+	}
+
+	recoveryCodes, err := backend.RegenerateRecoveryCodes(session, user.ID)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	resp.WriteEntity(entity.RecoveryCodesResponse{
+		Error:         false,
+		Message:       "Recovery Codes Regenerated Success",
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+// resetUserTwoFactorHandler disables the TOTP of a user who lost the device and the recovery codes
+func resetUserTwoFactorHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	id := req.PathParameter("id")
+	if !bson.IsObjectIdHex(id) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid user ID %s", id))
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	if err := backend.DisableTOTP(session, bson.ObjectIdHex(id)); err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+	resp.WriteEntity(response.ActionResponse{
+		Error:   false,
+		Message: "User Two-Factor Authentication Reset Success",
+	})
+}
+
+func getTwoFactorPolicyHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	policy, err := backend.GetTwoFactorPolicy(session)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	resp.WriteEntity(policy)
+}
+
+func updateTwoFactorPolicyHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	policy := entity.TwoFactorPolicy{}
+	if err := req.ReadEntity(&policy); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	policy, err := backend.UpdateTwoFactorPolicy(session, policy)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	resp.WriteEntity(policy)
+}
+
+// sessionUserID returns the user signed in with a session. The two-factor settings can't be changed by API tokens.
+func sessionUserID(ctx *web.Context) (bson.ObjectId, bool) {
+	req, resp := ctx.Request, ctx.Response
+
+	if _, ok := req.Attribute("APITokenID").(string); ok {
+		response.Forbidden(req.Request, resp.ResponseWriter, fmt.Errorf("API tokens can't change the two-factor authentication"))
+		return "", false
+	}
+	userID, ok := req.Attribute("UserID").(string)
+	if !ok {
+		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: User ID is empty"))
+		return "", false
+	}
+	return bson.ObjectIdHex(userID), true
+}
+
+// readTOTPCodeRequest returns the signed in user and the code of the request
+func readTOTPCodeRequest(ctx *web.Context) (entity.User, string, bool) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	userID, ok := sessionUserID(ctx)
+	if !ok {
+		return entity.User{}, "", false
+	}
+
+	request := entity.TOTPCodeRequest{}
+	if err := req.ReadEntity(&request); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return entity.User{}, "", false
+	}
+	if err := sp.Validator.Struct(request); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return entity.User{}, "", false
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	user, err := backend.FindUserByID(session, userID)
+	if err != nil {
+		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: User ID not found"))
+		return entity.User{}, "", false
+	}
+	return user, request.Code, true
+}
@@ -0,0 +1,220 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/server/backend"
+	"github.com/linkernetworks/vortex/src/totp"
+	"github.com/linkernetworks/vortex/src/utils"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type TwoFactorTestSuite struct {
+	ServerTestSuite
+}
+
+func (suite *TwoFactorTestSuite) SetupSuite() {
+	suite.setupServices()
+}
+
+func (suite *TwoFactorTestSuite) TearDownSuite() {}
+
+func TestTwoFactorSuite(t *testing.T) {
+	suite.Run(t, new(TwoFactorTestSuite))
+}
+
+func (suite *TwoFactorTestSuite) createUser(role string) entity.LoginCredential {
+	credential := entity.LoginCredential{
+		Username: namesgenerator.GetRandomName(0) + "@linkernetworks.com",
+		Password: "p@ssw0rd",
+	}
+	hashedPassword, err := utils.HashPassword(credential.Password)
+	suite.NoError(err)
+	user := entity.User{
+		ID: bson.NewObjectId(),
+		LoginCredential: entity.LoginCredential{
+			Username: credential.Username,
+			Password: hashedPassword,
+		},
+		DisplayName: "John Doe",
+		Role:        role,
+		FirstName:   "John",
+		LastName:    "Doe",
+		PhoneNumber: "0900000000",
+	}
+	suite.NoError(suite.session.Insert(entity.UserCollectionName, &user))
+	return credential
+}
+
+func (suite *TwoFactorTestSuite) TestEnrollAndSignIn() {
+	credential := suite.createUser(entity.UserRole)
+	defer suite.session.Remove(entity.UserCollectionName, "loginCredential.username", credential.Username)
+
+	tokens, err := signInGetTokens(suite.wc, credential)
+	suite.NoError(err)
+	suite.False(tokens.TwoFactorRequired)
+	JWTBearer := "Bearer " + tokens.AccessToken
+
+	httpWriter := suite.requestAs("POST", "/v1/users/2fa/totp", JWTBearer, nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	enrollment := entity.TOTPEnrollment{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &enrollment))
+	suite.NotEmpty(enrollment.Secret)
+	suite.Contains(enrollment.ProvisioningURI, "otpauth://totp/")
+
+	// the enrollment isn't enabled by a wrong code
+	httpWriter = suite.requestAs("POST", "/v1/users/2fa/totp/confirm", JWTBearer, entity.TOTPCodeRequest{Code: "000000"})
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+
+	now := time.Now()
+	code, err := totp.Code(enrollment.Secret, totp.Counter(now))
+	suite.NoError(err)
+	httpWriter = suite.requestAs("POST", "/v1/users/2fa/totp/confirm", JWTBearer, entity.TOTPCodeRequest{Code: code})
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	recovery := entity.RecoveryCodesResponse{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &recovery))
+	suite.NotEmpty(recovery.RecoveryCodes)
+
+	// the password alone gets a challenge instead of the tokens
+	tokens, err = signInGetTokens(suite.wc, credential)
+	suite.NoError(err)
+	suite.True(tokens.TwoFactorRequired)
+	suite.False(tokens.EnrollmentRequired)
+	suite.Empty(tokens.AccessToken)
+	suite.NotEmpty(tokens.ChallengeToken)
+
+	httpWriter = suite.requestAs("POST", "/v1/users/signin/2fa", "", entity.TwoFactorRequest{ChallengeToken: tokens.ChallengeToken, Code: "000000"})
+	assertResponseCode(suite.T(), http.StatusUnauthorized, httpWriter)
+
+	// the code used for the enrollment can't be replayed, so use the next one
+	nextCode, err := totp.Code(enrollment.Secret, totp.Counter(now)+1)
+	suite.NoError(err)
+	httpWriter = suite.requestAs("POST", "/v1/users/signin/2fa", "", entity.TwoFactorRequest{ChallengeToken: tokens.ChallengeToken, Code: nextCode})
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	signedIn := entity.TokenResponse{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &signedIn))
+	suite.NotEmpty(signedIn.AccessToken)
+
+	// the challenge is gone
+	httpWriter = suite.requestAs("POST", "/v1/users/signin/2fa", "", entity.TwoFactorRequest{ChallengeToken: tokens.ChallengeToken, Code: nextCode})
+	assertResponseCode(suite.T(), http.StatusUnauthorized, httpWriter)
+
+	// a recovery code works once
+	tokens, err = signInGetTokens(suite.wc, credential)
+	suite.NoError(err)
+	httpWriter = suite.requestAs("POST", "/v1/users/signin/2fa", "", entity.TwoFactorRequest{ChallengeToken: tokens.ChallengeToken, Code: recovery.RecoveryCodes[0]})
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	tokens, err = signInGetTokens(suite.wc, credential)
+	suite.NoError(err)
+	httpWriter = suite.requestAs("POST", "/v1/users/signin/2fa", "", entity.TwoFactorRequest{ChallengeToken: tokens.ChallengeToken, Code: recovery.RecoveryCodes[0]})
+	assertResponseCode(suite.T(), http.StatusUnauthorized, httpWriter)
+
+	// disable with a recovery code
+	httpWriter = suite.requestAs("POST", "/v1/users/2fa/totp/disable", "Bearer "+signedIn.AccessToken, entity.TOTPCodeRequest{Code: recovery.RecoveryCodes[1]})
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	tokens, err = signInGetTokens(suite.wc, credential)
+	suite.NoError(err)
+	suite.False(tokens.TwoFactorRequired)
+	suite.NotEmpty(tokens.AccessToken)
+}
+
+func (suite *TwoFactorTestSuite) TestRequireForRoot() {
+	// only root can change the policy
+	credential := suite.createUser(entity.UserRole)
+	defer suite.session.Remove(entity.UserCollectionName, "loginCredential.username", credential.Username)
+	tokens, err := signInGetTokens(suite.wc, credential)
+	suite.NoError(err)
+	httpWriter := suite.requestAs("PUT", "/v1/users/2fa/policy", "Bearer "+tokens.AccessToken, entity.TwoFactorPolicy{RequireForRoot: true})
+	assertResponseCode(suite.T(), http.StatusForbidden, httpWriter)
+
+	httpWriter = suite.requestAs("PUT", "/v1/users/2fa/policy", suite.JWTBearer, entity.TwoFactorPolicy{RequireForRoot: true})
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	defer suite.requestAs("PUT", "/v1/users/2fa/policy", suite.JWTBearer, entity.TwoFactorPolicy{RequireForRoot: false})
+
+	httpWriter = suite.requestAs("GET", "/v1/users/2fa/policy", suite.JWTBearer, nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	policy := entity.TwoFactorPolicy{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &policy))
+	suite.True(policy.RequireForRoot)
+
+	// the other roles are not affected
+	tokens, err = signInGetTokens(suite.wc, credential)
+	suite.NoError(err)
+	suite.False(tokens.TwoFactorRequired)
+
+	// a root without TOTP has to enroll at sign in
+	rootCredential := suite.createUser(entity.RootRole)
+	defer suite.session.Remove(entity.UserCollectionName, "loginCredential.username", rootCredential.Username)
+	tokens, err = signInGetTokens(suite.wc, rootCredential)
+	suite.NoError(err)
+	suite.True(tokens.TwoFactorRequired)
+	suite.True(tokens.EnrollmentRequired)
+	suite.Empty(tokens.AccessToken)
+
+	httpWriter = suite.requestAs("POST", "/v1/users/signin/2fa/enroll", "", entity.TwoFactorRequest{ChallengeToken: tokens.ChallengeToken})
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	enrollment := entity.TOTPEnrollment{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &enrollment))
+
+	code, err := totp.Code(enrollment.Secret, totp.Counter(time.Now()))
+	suite.NoError(err)
+	httpWriter = suite.requestAs("POST", "/v1/users/signin/2fa", "", entity.TwoFactorRequest{ChallengeToken: tokens.ChallengeToken, Code: code})
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	signedIn := entity.TokenResponse{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &signedIn))
+	suite.NotEmpty(signedIn.AccessToken)
+	suite.NotEmpty(signedIn.RecoveryCodes)
+}
+
+func (suite *TwoFactorTestSuite) TestResetUserTwoFactor() {
+	credential := suite.createUser(entity.UserRole)
+	defer suite.session.Remove(entity.UserCollectionName, "loginCredential.username", credential.Username)
+	user := entity.User{}
+	suite.NoError(suite.session.FindOne(entity.UserCollectionName, bson.M{"loginCredential.username": credential.Username}, &user))
+	suite.NoError(suite.session.C(entity.UserCollectionName).UpdateId(user.ID, bson.M{
+		"$set": bson.M{"totpEnabled": true, "totpSecret": "JBSWY3DPEHPK3PXP"},
+	}))
+
+	tokens, err := signInGetTokens(suite.wc, credential)
+	suite.NoError(err)
+	suite.True(tokens.TwoFactorRequired)
+
+	httpWriter := suite.requestAs("DELETE", "/v1/users/"+user.ID.Hex()+"/2fa", suite.JWTBearer, nil)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	tokens, err = signInGetTokens(suite.wc, credential)
+	suite.NoError(err)
+	suite.False(tokens.TwoFactorRequired)
+}
+
+func (suite *TwoFactorTestSuite) TestWrongCodesLockOut() {
+	credential := suite.createUser(entity.UserRole)
+	defer suite.session.Remove(entity.UserCollectionName, "loginCredential.username", credential.Username)
+	user := entity.User{}
+	suite.NoError(suite.session.FindOne(entity.UserCollectionName, bson.M{"loginCredential.username": credential.Username}, &user))
+	secret, err := totp.GenerateSecret()
+	suite.NoError(err)
+	suite.NoError(backend.SetTOTPSecret(suite.session, user.ID, secret))
+	_, err = backend.EnableTOTP(suite.session, user.ID)
+	suite.NoError(err)
+
+	// the new challenges of the correct password don't reset the count of the wrong codes
+	for i := 0; i < suite.sp.Config.Auth.Lockout.MaxAttempts; i++ {
+		tokens, err := signInGetTokens(suite.wc, credential)
+		suite.NoError(err)
+		suite.True(tokens.TwoFactorRequired)
+		httpWriter := suite.requestAs("POST", "/v1/users/signin/2fa", "", entity.TwoFactorRequest{ChallengeToken: tokens.ChallengeToken, Code: "000000"})
+		assertResponseCode(suite.T(), http.StatusUnauthorized, httpWriter)
+	}
+
+	httpWriter := suite.requestAs("POST", "/v1/users/signin", "", credential)
+	assertResponseCode(suite.T(), http.StatusForbidden, httpWriter)
+}
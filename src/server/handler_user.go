@@ -6,12 +6,18 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/linkernetworks/logger"
+	"github.com/linkernetworks/mongo"
 	"github.com/linkernetworks/utils/timeutils"
+	"github.com/linkernetworks/vortex/src/authprovider"
+	"github.com/linkernetworks/vortex/src/config"
 	"github.com/linkernetworks/vortex/src/entity"
 	response "github.com/linkernetworks/vortex/src/net/http"
 	"github.com/linkernetworks/vortex/src/net/http/query"
 	"github.com/linkernetworks/vortex/src/server/backend"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
 	"github.com/linkernetworks/vortex/src/utils"
 	"github.com/linkernetworks/vortex/src/web"
 
@@ -28,6 +34,11 @@ func signUpUserHandler(ctx *web.Context) {
 		return
 	}
 
+	if err := backend.CheckPasswordPolicy(passwordPolicy(sp), entity.User{}, user.LoginCredential.Password); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
 	hashedPassword, err := utils.HashPassword(user.LoginCredential.Password)
 	if err != nil {
 		response.BadRequest(req.Request, resp.ResponseWriter, err)
@@ -39,6 +50,12 @@ func signUpUserHandler(ctx *web.Context) {
 
 	// sign up user only can ba the role of user
 	user.Role = "user"
+	// the users of the external providers are provisioned on sign in
+	user.AuthProvider = ""
+	// TOTP is enrolled by the user
+	user.TOTPEnabled = false
+	user.MustChangePassword = false
+	user.LockedAt = nil
 
 	if err := sp.Validator.Struct(user); err != nil {
 		response.BadRequest(req.Request, resp.ResponseWriter, err)
@@ -107,30 +124,38 @@ func patchPasswordHandler(ctx *web.Context) {
 		return
 	}
 
+	if user.AuthProvider != "" {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("The password is managed by %s", user.AuthProvider))
+		return
+	}
+
 	if err := sp.Validator.Struct(newCred); err != nil {
 		response.BadRequest(req.Request, resp.ResponseWriter, err)
 		return
 	}
 
-	hashedPassword, err := utils.HashPassword(newCred.Password)
-	if err != nil {
+	if err := backend.CheckPasswordPolicy(passwordPolicy(sp), user, newCred.Password); err != nil {
 		response.BadRequest(req.Request, resp.ResponseWriter, err)
 		return
 	}
-	newCred.Password = hashedPassword
 
-	user.LoginCredential = newCred
-	modifier := bson.M{
-		"$set": user,
+	historySize := 0
+	if policy := passwordPolicy(sp); policy != nil {
+		historySize = policy.HistorySize
 	}
-	query := bson.M{
-		"_id": user.ID,
-	}
-	if err := session.Update(entity.UserCollectionName, query, modifier); err != nil {
-		response.InternalServerError(req.Request, resp, err)
+	if err := backend.UpdatePassword(session, user, newCred.Password, historySize); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
 		return
 	}
 
+	// the cached sessions and API tokens may still require the password change
+	if user.MustChangePassword {
+		if err := forgetUserCredentials(sp, session, user.ID); err != nil {
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+			return
+		}
+	}
+
 	resp.WriteEntity(response.ActionResponse{
 		Error:   false,
 		Message: "password successfully changed",
@@ -154,34 +179,303 @@ func signInUserHandler(ctx *web.Context) {
 		return
 	}
 
-	authenticatedUser, passed, err := backend.Authenticate(session, credential)
+	providers, err := authprovider.GetAuthProviders(sp.Config.Auth)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	// the locked out users can't sign in even with the correct password
+	existingUser := entity.User{}
+	if err := session.FindOne(entity.UserCollectionName, bson.M{"loginCredential.username": credential.Username}, &existingUser); err != nil && err != mgo.ErrNotFound {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	locked, err := backend.IsLockedOut(lockoutConfig(sp), existingUser)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if locked {
+		response.Forbidden(req.Request, resp.ResponseWriter, fmt.Errorf("The user is locked out after too many failed sign in"))
+		return
+	}
+
+	authenticatedUser, passed, err := authprovider.Authenticate(session, providers, credential)
+	if err == backend.ErrUserDisabled {
+		response.Forbidden(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if err != nil && err != authprovider.ErrUserNotManaged {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	// when authenticating not pass
+	if !passed {
+		if err := backend.RecordFailedLogin(session, lockoutConfig(sp), credential.Username); err != nil {
+			logger.Warnf("Failed to record the failed sign in of %s: %v", credential.Username, err)
+		}
+		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: Failed to login. Incorrect authentication credentials"))
+		return
+	}
+
+	// Passed
+	tokens, err := signInTokens(sp, session, req.Request, authenticatedUser)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	// the failed sign in are cleared by the second factor if it's required
+	if !tokens.TwoFactorRequired && (authenticatedUser.FailedLogins > 0 || authenticatedUser.LockedAt != nil) {
+		if err := backend.UnlockUser(session, authenticatedUser.ID); err != nil {
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+			return
+		}
+	}
+	resp.WriteEntity(tokens)
+}
+
+// refreshTokenHandler exchanges a refresh token for a new access token and a new refresh token
+func refreshTokenHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	refreshRequest := entity.RefreshTokenRequest{}
+	if err := req.ReadEntity(&refreshRequest); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	if err := sp.Validator.Struct(refreshRequest); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	refreshToken, err := backend.ConsumeRefreshToken(session, refreshRequest.RefreshToken)
 	if err != nil {
 		switch err {
 		case mgo.ErrNotFound:
-			response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: Failed to login. Incorrect authentication credentials"))
-			return
+			response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: Refresh token is invalid"))
+		default:
+			response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: %v", err))
+		}
+		return
+	}
+
+	user, err := backend.FindUserByID(session, refreshToken.UserID)
+	if err != nil {
+		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: User ID not found"))
+		return
+	}
+
+	// the refreshed session replaces the old one
+	if err := backend.RevokeSession(session, refreshToken.SessionID); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	sp.SessionCache.Delete(refreshToken.SessionID.Hex())
+
+	tokens, err := issueTokens(sp, session, req.Request, user)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	resp.WriteEntity(tokens)
+}
+
+// issueTokens starts a new session of the user and returns its access token and refresh token
+func issueTokens(sp *serviceprovider.Container, session *mongo.Session, req *http.Request, user entity.User) (entity.TokenResponse, error) {
+	userSession := entity.Session{
+		ID:         bson.NewObjectId(),
+		UserID:     user.ID,
+		RemoteAddr: req.RemoteAddr,
+		UserAgent:  req.UserAgent(),
+		ExpiresAt:  time.Now().Add(sp.JWT.TokenExpiry),
+		CreatedAt:  timeutils.Now(),
+
+		MustChangePassword: user.MustChangePassword,
+	}
+	if err := backend.CreateSession(session, userSession); err != nil {
+		return entity.TokenResponse{}, err
+	}
+
+	accessToken, err := sp.JWT.GenerateToken(user.ID.Hex(), user, userSession.ID.Hex())
+	if err != nil {
+		return entity.TokenResponse{}, err
+	}
+
+	refreshToken, err := backend.CreateRefreshToken(session, user.ID, userSession.ID, sp.JWT.RefreshTokenExpiry)
+	if err != nil {
+		return entity.TokenResponse{}, err
+	}
+
+	return entity.TokenResponse{
+		Error:        false,
+		Message:      accessToken,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    userSession.ExpiresAt.Unix(),
+	}, nil
+}
+
+// unlockUserHandler clears the lockout of the user
+func unlockUserHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	id := req.PathParameter("id")
+	if !bson.IsObjectIdHex(id) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid user ID %s", id))
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	if err := backend.UnlockUser(session, bson.ObjectIdHex(id)); err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
 		default:
 			response.InternalServerError(req.Request, resp.ResponseWriter, err)
-			return
 		}
+		return
 	}
+	resp.WriteEntity(response.ActionResponse{
+		Error:   false,
+		Message: "User Unlocked Success",
+	})
+}
 
-	// when authenticating not pass
-	if !passed {
-		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: Failed to login. Incorrect authentication credentials"))
+// passwordPolicy returns the password policy, or nil if every password is accepted
+func passwordPolicy(sp *serviceprovider.Container) *config.PasswordPolicyConfig {
+	if sp.Config.Auth == nil {
+		return nil
+	}
+	return sp.Config.Auth.PasswordPolicy
+}
+
+// lockoutConfig returns the lockout config, or nil if the users are never locked out
+func lockoutConfig(sp *serviceprovider.Container) *config.LockoutConfig {
+	if sp.Config.Auth == nil {
+		return nil
+	}
+	return sp.Config.Auth.Lockout
+}
+
+// forgetUserCredentials drops the sessions and API tokens of the user from the session cache,
+// so the changes of the user take effect on the next request
+func forgetUserCredentials(sp *serviceprovider.Container, session *mongo.Session, userID bson.ObjectId) error {
+	sessions, err := backend.ListActiveSessions(session, userID)
+	if err != nil {
+		return err
+	}
+	for _, s := range sessions {
+		sp.SessionCache.Delete(s.ID.Hex())
+	}
+	apiTokens, err := backend.ListAPITokens(session, userID)
+	if err != nil {
+		return err
+	}
+	for _, apiToken := range apiTokens {
+		sp.SessionCache.Delete(apiToken.TokenHash)
+	}
+	return nil
+}
+
+// revokeUserSessions revokes all sessions of the user and drops them from the session cache
+func revokeUserSessions(sp *serviceprovider.Container, session *mongo.Session, userID bson.ObjectId) error {
+	sessionIDs, err := backend.RevokeUserSessions(session, userID)
+	if err != nil {
+		return err
+	}
+	for _, sessionID := range sessionIDs {
+		sp.SessionCache.Delete(sessionID.Hex())
+	}
+	return nil
+}
+
+// revokeUserAPITokens removes all API tokens of the user and drops them from the session cache
+func revokeUserAPITokens(sp *serviceprovider.Container, session *mongo.Session, userID bson.ObjectId) error {
+	apiTokens, err := backend.RemoveUserAPITokens(session, userID)
+	if err != nil {
+		return err
+	}
+	for _, apiToken := range apiTokens {
+		sp.SessionCache.Delete(apiToken.TokenHash)
+	}
+	return nil
+}
+
+// signOutUserHandler revokes the session of the current token
+func signOutUserHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	sessionID, ok := req.Attribute("SessionID").(string)
+	if !ok || !bson.IsObjectIdHex(sessionID) {
+		response.Unauthorized(req.Request, resp.ResponseWriter, fmt.Errorf("Unauthorized: Session ID is empty"))
 		return
 	}
 
-	// Passed
-	tokenString, err := backend.GenerateToken(authenticatedUser.ID.Hex(), authenticatedUser)
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	if err := backend.RevokeSession(session, bson.ObjectIdHex(sessionID)); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	sp.SessionCache.Delete(sessionID)
+
+	resp.WriteEntity(response.ActionResponse{
+		Error:   false,
+		Message: "Sign Out Success",
+	})
+}
+
+// listUserSessionsHandler lists the active sessions of the user
+func listUserSessionsHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	id := req.PathParameter("id")
+	if !bson.IsObjectIdHex(id) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid user ID: %s", id))
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	sessions, err := backend.ListActiveSessions(session, bson.ObjectIdHex(id))
 	if err != nil {
 		response.InternalServerError(req.Request, resp.ResponseWriter, err)
 		return
 	}
+	resp.WriteEntity(sessions)
+}
+
+// revokeUserSessionsHandler revokes all sessions of the user. The role must to have admin permission to access it.
+func revokeUserSessionsHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	id := req.PathParameter("id")
+	if !bson.IsObjectIdHex(id) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid user ID: %s", id))
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	if err := revokeUserSessions(sp, session, bson.ObjectIdHex(id)); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
 
 	resp.WriteEntity(response.ActionResponse{
 		Error:   false,
-		Message: tokenString,
+		Message: "User Sessions Revoked Success",
 	})
 }
 
@@ -194,6 +488,11 @@ func createUserHandler(ctx *web.Context) {
 		return
 	}
 
+	if err := backend.CheckPasswordPolicy(passwordPolicy(sp), entity.User{}, user.LoginCredential.Password); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
 	hashedPassword, err := utils.HashPassword(user.LoginCredential.Password)
 	if err != nil {
 		response.BadRequest(req.Request, resp.ResponseWriter, err)
@@ -202,6 +501,9 @@ func createUserHandler(ctx *web.Context) {
 	user.LoginCredential.Password = hashedPassword
 
 	user.LoginCredential.Username = strings.ToLower(user.LoginCredential.Username)
+	user.AuthProvider = ""
+	user.TOTPEnabled = false
+	user.LockedAt = nil
 
 	if err := sp.Validator.Struct(user); err != nil {
 		response.BadRequest(req.Request, resp.ResponseWriter, err)
@@ -259,6 +561,17 @@ func deleteUserHandler(ctx *web.Context) {
 		}
 	}
 
+	// the deleted user can't use the signed in sessions anymore
+	if err := revokeUserSessions(sp, session, user.ID); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	if err := revokeUserAPITokens(sp, session, user.ID); err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
 	resp.WriteEntity(response.ActionResponse{
 		Error:   false,
 		Message: "User Deleted Success",
@@ -337,3 +650,65 @@ func getUserHandler(ctx *web.Context) {
 	}
 	resp.WriteEntity(user)
 }
+
+// updateUserHandler updates the profile, role and state of the user. The role must to have admin permission to access it.
+// The sessions of the user are revoked when the role changes or the user is disabled.
+func updateUserHandler(ctx *web.Context) {
+	sp, req, resp := ctx.ServiceProvider, ctx.Request, ctx.Response
+
+	id := req.PathParameter("id")
+	if !bson.IsObjectIdHex(id) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Invalid user ID: %s", id))
+		return
+	}
+
+	update := entity.UserUpdateRequest{}
+	if err := req.ReadEntity(&update); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+	if err := sp.Validator.Struct(update); err != nil {
+		response.BadRequest(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	// root can't lock themselves out
+	if userID, _ := req.Attribute("UserID").(string); userID == id && (update.Role != entity.RootRole || update.Disabled) {
+		response.BadRequest(req.Request, resp.ResponseWriter, fmt.Errorf("Can't demote or disable yourself"))
+		return
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	user, err := backend.FindUserByID(session, bson.ObjectIdHex(id))
+	if err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+
+	updatedUser, err := backend.UpdateUser(session, user.ID, update)
+	if err != nil {
+		response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		return
+	}
+
+	if user.Role != updatedUser.Role || user.Disabled != updatedUser.Disabled {
+		// the API tokens look up the role and state of the user again
+		if err := forgetUserCredentials(sp, session, user.ID); err != nil {
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+			return
+		}
+		// the session JWTs carry the old role
+		if err := revokeUserSessions(sp, session, user.ID); err != nil {
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+			return
+		}
+	}
+	resp.WriteEntity(updatedUser)
+}
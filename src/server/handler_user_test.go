@@ -45,7 +45,7 @@ func (suite *UserTestSuite) SetupSuite() {
 
 	userService := newUserService(suite.sp)
 
-	suite.wc.Add(userService)
+	suite.wc.Add(secureService(suite.sp, userService))
 
 	token, _ := loginGetToken(suite.wc)
 	suite.NotEmpty(token)
@@ -294,6 +294,190 @@ func (suite *UserTestSuite) TestSignInFailedUser() {
 	assertResponseCode(suite.T(), http.StatusUnauthorized, httpWriter)
 }
 
+func (suite *UserTestSuite) TestRefreshToken() {
+	var tokens entity.TokenResponse
+	userCred := entity.LoginCredential{
+		Username: "test@linkernetworks.com",
+		Password: "test",
+	}
+
+	bodyBytes, err := json.MarshalIndent(userCred, "", "  ")
+	suite.NoError(err)
+
+	bodyReader := strings.NewReader(string(bodyBytes))
+	httpRequest, err := http.NewRequest("POST", "http://localhost:7890/v1/users/signin", bodyReader)
+	suite.NoError(err)
+
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	err = json.Unmarshal(httpWriter.Body.Bytes(), &tokens)
+	suite.NoError(err)
+	suite.Equal(tokens.Message, tokens.AccessToken)
+	suite.NotEmpty(tokens.RefreshToken)
+
+	// exchange the refresh token
+	refreshBodyBytes, err := json.Marshal(entity.RefreshTokenRequest{RefreshToken: tokens.RefreshToken})
+	suite.NoError(err)
+
+	httpRequest, err = http.NewRequest("POST", "http://localhost:7890/v1/users/refresh", strings.NewReader(string(refreshBodyBytes)))
+	suite.NoError(err)
+
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpWriter = httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	refreshed := entity.TokenResponse{}
+	err = json.Unmarshal(httpWriter.Body.Bytes(), &refreshed)
+	suite.NoError(err)
+	suite.NotEmpty(refreshed.AccessToken)
+	suite.NotEqual(tokens.RefreshToken, refreshed.RefreshToken)
+
+	// a refresh token can only be used once
+	httpRequest, err = http.NewRequest("POST", "http://localhost:7890/v1/users/refresh", strings.NewReader(string(refreshBodyBytes)))
+	suite.NoError(err)
+
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpWriter = httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusUnauthorized, httpWriter)
+}
+
+func (suite *UserTestSuite) TestRefreshTokenFail() {
+	bodyBytes, err := json.Marshal(entity.RefreshTokenRequest{RefreshToken: "invalidRefreshToken"})
+	suite.NoError(err)
+
+	httpRequest, err := http.NewRequest("POST", "http://localhost:7890/v1/users/refresh", strings.NewReader(string(bodyBytes)))
+	suite.NoError(err)
+
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusUnauthorized, httpWriter)
+
+	httpRequest, err = http.NewRequest("POST", "http://localhost:7890/v1/users/refresh", strings.NewReader("{}"))
+	suite.NoError(err)
+
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpWriter = httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+}
+
+func (suite *UserTestSuite) TestSignOut() {
+	tokens, err := signInGetTokens(suite.wc, entity.LoginCredential{
+		Username: "test@linkernetworks.com",
+		Password: "test",
+	})
+	suite.NoError(err)
+	JWTBearer := "Bearer " + tokens.AccessToken
+
+	httpRequest, err := http.NewRequest("POST", "http://localhost:7890/v1/users/signout", nil)
+	suite.NoError(err)
+
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", JWTBearer)
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	// the revoked token is rejected
+	httpRequest, err = http.NewRequest("GET", "http://localhost:7890/v1/users/verify/auth", nil)
+	suite.NoError(err)
+
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", JWTBearer)
+	httpWriter = httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusUnauthorized, httpWriter)
+
+	// and so is the refresh token of the session
+	refreshBodyBytes, err := json.Marshal(entity.RefreshTokenRequest{RefreshToken: tokens.RefreshToken})
+	suite.NoError(err)
+
+	httpRequest, err = http.NewRequest("POST", "http://localhost:7890/v1/users/refresh", strings.NewReader(string(refreshBodyBytes)))
+	suite.NoError(err)
+
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpWriter = httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusUnauthorized, httpWriter)
+}
+
+func (suite *UserTestSuite) TestListAndRevokeUserSessions() {
+	password := "p@ssw0rd"
+	hashedPassword, err := utils.HashPassword(password)
+	suite.NoError(err)
+	user := entity.User{
+		ID: bson.NewObjectId(),
+		LoginCredential: entity.LoginCredential{
+			Username: namesgenerator.GetRandomName(0) + "@linkernetworks.com",
+			Password: hashedPassword,
+		},
+		DisplayName: "John Doe",
+		Role:        "user",
+		FirstName:   "John",
+		LastName:    "Doe",
+		PhoneNumber: "0900000000",
+	}
+	suite.session.Insert(entity.UserCollectionName, &user)
+	defer suite.session.Remove(entity.UserCollectionName, "_id", user.ID)
+
+	tokens, err := signInGetTokens(suite.wc, entity.LoginCredential{
+		Username: user.LoginCredential.Username,
+		Password: password,
+	})
+	suite.NoError(err)
+	JWTBearer := "Bearer " + tokens.AccessToken
+
+	// users can list their own sessions
+	httpRequest, err := http.NewRequest("GET", "http://localhost:7890/v1/users/"+user.ID.Hex()+"/sessions", nil)
+	suite.NoError(err)
+
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", JWTBearer)
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	sessions := []entity.Session{}
+	err = json.Unmarshal(httpWriter.Body.Bytes(), &sessions)
+	suite.NoError(err)
+	suite.Len(sessions, 1)
+
+	// users can't revoke sessions
+	httpRequest, err = http.NewRequest("DELETE", "http://localhost:7890/v1/users/"+user.ID.Hex()+"/sessions", nil)
+	suite.NoError(err)
+
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", JWTBearer)
+	httpWriter = httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusForbidden, httpWriter)
+
+	// root revokes all sessions of the user
+	httpRequest, err = http.NewRequest("DELETE", "http://localhost:7890/v1/users/"+user.ID.Hex()+"/sessions", nil)
+	suite.NoError(err)
+
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", suite.JWTBearer)
+	httpWriter = httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	httpRequest, err = http.NewRequest("GET", "http://localhost:7890/v1/users/"+user.ID.Hex()+"/sessions", nil)
+	suite.NoError(err)
+
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", JWTBearer)
+	httpWriter = httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusUnauthorized, httpWriter)
+}
+
 func (suite *UserTestSuite) TestCreateUser() {
 	user := entity.User{
 		ID: bson.NewObjectId(),
@@ -552,3 +736,326 @@ func (suite *UserTestSuite) TestListUserWithInvalidPage() {
 	suite.wc.Dispatch(httpWriter, httpRequest)
 	assertResponseCode(suite.T(), http.StatusInternalServerError, httpWriter)
 }
+
+func (suite *UserTestSuite) signIn(userCred entity.LoginCredential) *httptest.ResponseRecorder {
+	bodyBytes, err := json.MarshalIndent(userCred, "", "  ")
+	suite.NoError(err)
+
+	bodyReader := strings.NewReader(string(bodyBytes))
+	httpRequest, err := http.NewRequest("POST", "http://localhost:7890/v1/users/signin", bodyReader)
+	suite.NoError(err)
+
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	return httpWriter
+}
+
+func (suite *UserTestSuite) TestSignUpWeakPassword() {
+	user := entity.User{
+		ID: bson.NewObjectId(),
+		LoginCredential: entity.LoginCredential{
+			Username: namesgenerator.GetRandomName(0) + "@linkernetworks.com",
+			Password: "abc",
+		},
+		DisplayName: "John Doe",
+		FirstName:   "John",
+		LastName:    "Doe",
+		PhoneNumber: "0900000000",
+	}
+
+	bodyBytes, err := json.MarshalIndent(user, "", "  ")
+	suite.NoError(err)
+
+	bodyReader := strings.NewReader(string(bodyBytes))
+	httpRequest, err := http.NewRequest("POST", "http://localhost:7890/v1/users/signup", bodyReader)
+	suite.NoError(err)
+
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+	defer suite.session.Remove(entity.UserCollectionName, "loginCredential.username", user.LoginCredential.Username)
+}
+
+func (suite *UserTestSuite) TestSignInLockout() {
+	hashedPassword, err := utils.HashPassword("p@ssw0rd")
+	suite.NoError(err)
+	user := entity.User{
+		ID: bson.NewObjectId(),
+		LoginCredential: entity.LoginCredential{
+			Username: namesgenerator.GetRandomName(0) + "@linkernetworks.com",
+			Password: hashedPassword,
+		},
+		DisplayName: "John Doe",
+		Role:        entity.UserRole,
+	}
+	suite.NoError(suite.session.Insert(entity.UserCollectionName, &user))
+	defer suite.session.Remove(entity.UserCollectionName, "_id", user.ID)
+
+	wrongCred := entity.LoginCredential{Username: user.LoginCredential.Username, Password: "wrong"}
+	for i := 0; i < suite.sp.Config.Auth.Lockout.MaxAttempts; i++ {
+		assertResponseCode(suite.T(), http.StatusUnauthorized, suite.signIn(wrongCred))
+	}
+
+	// the correct password is rejected until root unlocks the user
+	userCred := entity.LoginCredential{Username: user.LoginCredential.Username, Password: "p@ssw0rd"}
+	assertResponseCode(suite.T(), http.StatusForbidden, suite.signIn(userCred))
+
+	httpRequest, err := http.NewRequest("POST", "http://localhost:7890/v1/users/"+user.ID.Hex()+"/unlock", nil)
+	suite.NoError(err)
+	httpRequest.Header.Add("Authorization", suite.JWTBearer)
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	assertResponseCode(suite.T(), http.StatusOK, suite.signIn(userCred))
+}
+
+func (suite *UserTestSuite) TestUnlockUserNotFound() {
+	httpRequest, err := http.NewRequest("POST", "http://localhost:7890/v1/users/"+bson.NewObjectId().Hex()+"/unlock", nil)
+	suite.NoError(err)
+	httpRequest.Header.Add("Authorization", suite.JWTBearer)
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusNotFound, httpWriter)
+}
+
+func (suite *UserTestSuite) TestMustChangePassword() {
+	hashedPassword, err := utils.HashPassword("password")
+	suite.NoError(err)
+	user := entity.User{
+		ID: bson.NewObjectId(),
+		LoginCredential: entity.LoginCredential{
+			Username: namesgenerator.GetRandomName(0) + "@linkernetworks.com",
+			Password: hashedPassword,
+		},
+		DisplayName:        "John Doe",
+		Role:               entity.UserRole,
+		MustChangePassword: true,
+	}
+	suite.NoError(suite.session.Insert(entity.UserCollectionName, &user))
+	defer suite.session.Remove(entity.UserCollectionName, "_id", user.ID)
+
+	tokens, err := signInGetTokens(suite.wc, entity.LoginCredential{Username: user.LoginCredential.Username, Password: "password"})
+	suite.NoError(err)
+	JWTBearer := "Bearer " + tokens.AccessToken
+
+	// other routes are rejected before the password is changed
+	httpRequest, err := http.NewRequest("GET", "http://localhost:7890/v1/users/"+user.ID.Hex(), nil)
+	suite.NoError(err)
+	httpRequest.Header.Add("Authorization", JWTBearer)
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusForbidden, httpWriter)
+
+	// the same password can't be kept
+	newCred := entity.LoginCredential{Username: user.LoginCredential.Username, Password: "password"}
+	bodyBytes, err := json.MarshalIndent(newCred, "", "  ")
+	suite.NoError(err)
+	httpRequest, err = http.NewRequest("PUT", "http://localhost:7890/v1/users/password", strings.NewReader(string(bodyBytes)))
+	suite.NoError(err)
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", JWTBearer)
+	httpWriter = httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+
+	newCred.Password = "p@ssw0rd"
+	bodyBytes, err = json.MarshalIndent(newCred, "", "  ")
+	suite.NoError(err)
+	httpRequest, err = http.NewRequest("PUT", "http://localhost:7890/v1/users/password", strings.NewReader(string(bodyBytes)))
+	suite.NoError(err)
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", JWTBearer)
+	httpWriter = httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	// the same session is allowed after the password is changed
+	httpRequest, err = http.NewRequest("GET", "http://localhost:7890/v1/users/"+user.ID.Hex(), nil)
+	suite.NoError(err)
+	httpRequest.Header.Add("Authorization", JWTBearer)
+	httpWriter = httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+}
+
+func (suite *UserTestSuite) createUser(password string) entity.User {
+	hashedPassword, err := utils.HashPassword(password)
+	suite.NoError(err)
+	user := entity.User{
+		ID: bson.NewObjectId(),
+		LoginCredential: entity.LoginCredential{
+			Username: namesgenerator.GetRandomName(0) + "@linkernetworks.com",
+			Password: hashedPassword,
+		},
+		DisplayName: "John Doe",
+		Role:        entity.UserRole,
+		FirstName:   "John",
+		LastName:    "Doe",
+		PhoneNumber: "0900000000",
+	}
+	suite.NoError(suite.session.Insert(entity.UserCollectionName, &user))
+	return user
+}
+
+func (suite *UserTestSuite) updateUser(id string, update entity.UserUpdateRequest) *httptest.ResponseRecorder {
+	bodyBytes, err := json.MarshalIndent(update, "", "  ")
+	suite.NoError(err)
+
+	httpRequest, err := http.NewRequest("PUT", "http://localhost:7890/v1/users/"+id, strings.NewReader(string(bodyBytes)))
+	suite.NoError(err)
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", suite.JWTBearer)
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	return httpWriter
+}
+
+func (suite *UserTestSuite) TestUpdateUser() {
+	user := suite.createUser("p@ssw0rd")
+	defer suite.session.Remove(entity.UserCollectionName, "_id", user.ID)
+
+	tokens, err := signInGetTokens(suite.wc, entity.LoginCredential{Username: user.LoginCredential.Username, Password: "p@ssw0rd"})
+	suite.NoError(err)
+
+	httpWriter := suite.updateUser(user.ID.Hex(), entity.UserUpdateRequest{
+		DisplayName: "Jane Doe",
+		Role:        entity.RootRole,
+		FirstName:   "Jane",
+		LastName:    "Doe",
+		PhoneNumber: "0911111111",
+	})
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	retUser := entity.User{}
+	suite.NoError(json.NewDecoder(httpWriter.Body).Decode(&retUser))
+	suite.Equal("Jane Doe", retUser.DisplayName)
+	suite.Equal(entity.RootRole, retUser.Role)
+
+	// the session with the old role is revoked
+	httpRequest, err := http.NewRequest("GET", "http://localhost:7890/v1/users/"+user.ID.Hex(), nil)
+	suite.NoError(err)
+	httpRequest.Header.Add("Authorization", "Bearer "+tokens.AccessToken)
+	httpWriter = httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusUnauthorized, httpWriter)
+}
+
+func (suite *UserTestSuite) TestUpdateUserFail() {
+	user := suite.createUser("p@ssw0rd")
+	defer suite.session.Remove(entity.UserCollectionName, "_id", user.ID)
+
+	// invalid role
+	httpWriter := suite.updateUser(user.ID.Hex(), entity.UserUpdateRequest{
+		DisplayName: "John Doe",
+		Role:        "admin",
+		FirstName:   "John",
+		LastName:    "Doe",
+		PhoneNumber: "0900000000",
+	})
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+
+	// unknown user
+	httpWriter = suite.updateUser(bson.NewObjectId().Hex(), entity.UserUpdateRequest{
+		DisplayName: "John Doe",
+		Role:        entity.UserRole,
+		FirstName:   "John",
+		LastName:    "Doe",
+		PhoneNumber: "0900000000",
+	})
+	assertResponseCode(suite.T(), http.StatusNotFound, httpWriter)
+
+	// root can't disable themselves
+	root := entity.User{}
+	suite.NoError(suite.session.FindOne(entity.UserCollectionName, bson.M{"loginCredential.username": "test@linkernetworks.com"}, &root))
+	httpWriter = suite.updateUser(root.ID.Hex(), entity.UserUpdateRequest{
+		DisplayName: root.DisplayName,
+		Role:        entity.RootRole,
+		FirstName:   root.FirstName,
+		LastName:    root.LastName,
+		PhoneNumber: root.PhoneNumber,
+		Disabled:    true,
+	})
+	assertResponseCode(suite.T(), http.StatusBadRequest, httpWriter)
+}
+
+func (suite *UserTestSuite) TestDisableUser() {
+	user := suite.createUser("p@ssw0rd")
+	defer suite.session.Remove(entity.UserCollectionName, "_id", user.ID)
+	userCred := entity.LoginCredential{Username: user.LoginCredential.Username, Password: "p@ssw0rd"}
+
+	tokens, err := signInGetTokens(suite.wc, userCred)
+	suite.NoError(err)
+
+	update := entity.UserUpdateRequest{
+		DisplayName: user.DisplayName,
+		Role:        user.Role,
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		PhoneNumber: user.PhoneNumber,
+		Disabled:    true,
+	}
+	assertResponseCode(suite.T(), http.StatusOK, suite.updateUser(user.ID.Hex(), update))
+
+	// the disabled user can't sign in or use the signed in session
+	assertResponseCode(suite.T(), http.StatusForbidden, suite.signIn(userCred))
+	httpRequest, err := http.NewRequest("GET", "http://localhost:7890/v1/users/"+user.ID.Hex(), nil)
+	suite.NoError(err)
+	httpRequest.Header.Add("Authorization", "Bearer "+tokens.AccessToken)
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusUnauthorized, httpWriter)
+
+	update.Disabled = false
+	assertResponseCode(suite.T(), http.StatusOK, suite.updateUser(user.ID.Hex(), update))
+	assertResponseCode(suite.T(), http.StatusOK, suite.signIn(userCred))
+
+	// the session of the user disabled without revoking the sessions is rejected too
+	tokens, err = signInGetTokens(suite.wc, userCred)
+	suite.NoError(err)
+	suite.NoError(suite.session.C(entity.UserCollectionName).UpdateId(user.ID, bson.M{"$set": bson.M{"disabled": true}}))
+	httpRequest, err = http.NewRequest("GET", "http://localhost:7890/v1/users/"+user.ID.Hex(), nil)
+	suite.NoError(err)
+	httpRequest.Header.Add("Authorization", "Bearer "+tokens.AccessToken)
+	httpWriter = httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusUnauthorized, httpWriter)
+}
+
+func (suite *UserTestSuite) TestAdminPasswordReset() {
+	user := suite.createUser("p@ssw0rd")
+	defer suite.session.Remove(entity.UserCollectionName, "_id", user.ID)
+
+	httpRequest, err := http.NewRequest("POST", "http://localhost:7890/v1/users/"+user.ID.Hex()+"/password/reset", nil)
+	suite.NoError(err)
+	httpRequest.Header.Add("Authorization", suite.JWTBearer)
+	httpWriter := httptest.NewRecorder()
+	suite.wc.Dispatch(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+
+	resetToken := entity.PasswordResetToken{}
+	suite.NoError(json.NewDecoder(httpWriter.Body).Decode(&resetToken))
+	suite.NotEmpty(resetToken.Token)
+
+	resetPassword := func(password string) *httptest.ResponseRecorder {
+		bodyBytes, err := json.MarshalIndent(entity.PasswordResetRequest{Token: resetToken.Token, Password: password}, "", "  ")
+		suite.NoError(err)
+		httpRequest, err := http.NewRequest("POST", "http://localhost:7890/v1/users/password/reset", strings.NewReader(string(bodyBytes)))
+		suite.NoError(err)
+		httpRequest.Header.Add("Content-Type", "application/json")
+		httpWriter := httptest.NewRecorder()
+		suite.wc.Dispatch(httpWriter, httpRequest)
+		return httpWriter
+	}
+
+	// the token isn't used up by a password rejected by the policy
+	assertResponseCode(suite.T(), http.StatusBadRequest, resetPassword("abc"))
+	assertResponseCode(suite.T(), http.StatusOK, resetPassword("n3wp@ss"))
+	// a reset token can only be used once
+	assertResponseCode(suite.T(), http.StatusUnauthorized, resetPassword("an0ther"))
+
+	assertResponseCode(suite.T(), http.StatusUnauthorized, suite.signIn(entity.LoginCredential{Username: user.LoginCredential.Username, Password: "p@ssw0rd"}))
+	assertResponseCode(suite.T(), http.StatusOK, suite.signIn(entity.LoginCredential{Username: user.LoginCredential.Username, Password: "n3wp@ss"}))
+}
@@ -136,7 +136,7 @@ func listVolumeHandler(ctx *web.Context) {
 	var c = session.C(entity.VolumeCollectionName)
 	var q *mgo.Query
 
-	selector := bson.M{}
+	selector := namespaceSelector(req, "namespace")
 	q = c.Find(selector).Sort("_id").Skip((page - 1) * pageSize).Limit(pageSize)
 
 	if err := q.All(&volumes); err != nil {
@@ -156,7 +156,7 @@ func listVolumeHandler(ctx *web.Context) {
 		volumes[i].CreatedBy, _ = backend.FindUserByID(session, volume.OwnerID)
 	}
 
-	count, err := session.Count(entity.VolumeCollectionName, bson.M{})
+	count, err := session.Count(entity.VolumeCollectionName, selector)
 	if err != nil {
 		response.InternalServerError(req.Request, resp.ResponseWriter, err)
 		return
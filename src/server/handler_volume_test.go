@@ -46,8 +46,8 @@ func (suite *VolumeTestSuite) SetupSuite() {
 	volumeService := newVolumeService(suite.sp)
 	userService := newUserService(suite.sp)
 
-	suite.wc.Add(userService)
-	suite.wc.Add(volumeService)
+	suite.wc.Add(secureService(suite.sp, userService))
+	suite.wc.Add(secureService(suite.sp, volumeService))
 
 	token, _ := loginGetToken(suite.wc)
 	suite.NotEmpty(token)
@@ -14,23 +14,38 @@ func (a *App) AppRoute() *mux.Router {
 	container := restful.NewContainer()
 
 	container.Filter(globalLogging)
+	container.Filter(auditFilter(a.ServiceProvider))
 
-	container.Add(newVersionService(a.ServiceProvider))
-	container.Add(newRegistryService(a.ServiceProvider))
-	container.Add(newUserService(a.ServiceProvider))
-	container.Add(newNetworkService(a.ServiceProvider))
-	container.Add(newStorageService(a.ServiceProvider))
-	container.Add(newVolumeService(a.ServiceProvider))
-	container.Add(newContainerService(a.ServiceProvider))
-	container.Add(newPodService(a.ServiceProvider))
-	container.Add(newDeploymentService(a.ServiceProvider))
-	container.Add(newServiceService(a.ServiceProvider))
-	container.Add(newNamespaceService(a.ServiceProvider))
-	container.Add(newConfigMapService(a.ServiceProvider))
-	container.Add(newMonitoringService(a.ServiceProvider))
-	container.Add(newAppService(a.ServiceProvider))
-	container.Add(newOVSService(a.ServiceProvider))
-	container.Add(newShellService(a.ServiceProvider))
+	// every route is guarded by the permission matrix in route_permission.go
+	services := []*restful.WebService{
+		newVersionService(a.ServiceProvider),
+		newRegistryService(a.ServiceProvider),
+		newUserService(a.ServiceProvider),
+		newNetworkService(a.ServiceProvider),
+		newIPAMService(a.ServiceProvider),
+		newStorageService(a.ServiceProvider),
+		newVolumeService(a.ServiceProvider),
+		newContainerService(a.ServiceProvider),
+		newPodService(a.ServiceProvider),
+		newDeploymentService(a.ServiceProvider),
+		newStatefulSetService(a.ServiceProvider),
+		newDaemonSetService(a.ServiceProvider),
+		newJobService(a.ServiceProvider),
+		newCronJobService(a.ServiceProvider),
+		newServiceService(a.ServiceProvider),
+		newNamespaceService(a.ServiceProvider),
+		newTeamService(a.ServiceProvider),
+		newConfigMapService(a.ServiceProvider),
+		newSecretService(a.ServiceProvider),
+		newMonitoringService(a.ServiceProvider),
+		newAppService(a.ServiceProvider),
+		newOVSService(a.ServiceProvider),
+		newShellService(a.ServiceProvider),
+		newAuditService(a.ServiceProvider),
+	}
+	for _, service := range services {
+		container.Add(secureService(a.ServiceProvider, service))
+	}
 
 	router.PathPrefix("/v1/sockjs").Handler(CreateAttachHandler("/v1/sockjs"))
 	router.PathPrefix("/v1/").Handler(container)
@@ -49,6 +64,11 @@ func newRegistryService(sp *serviceprovider.Container) *restful.WebService {
 	webService := new(restful.WebService)
 	webService.Path("/v1/registry").Consumes(restful.MIME_JSON, restful.MIME_JSON).Produces(restful.MIME_JSON, restful.MIME_JSON)
 	webService.Route(webService.POST("/auth").To(handler.RESTfulServiceHandler(sp, registryBasicAuthHandler)))
+	webService.Route(webService.POST("/credentials").To(handler.RESTfulServiceHandler(sp, createRegistryCredentialHandler)))
+	webService.Route(webService.GET("/credentials").To(handler.RESTfulServiceHandler(sp, listRegistryCredentialHandler)))
+	webService.Route(webService.GET("/credentials/{id}").To(handler.RESTfulServiceHandler(sp, getRegistryCredentialHandler)))
+	webService.Route(webService.PUT("/credentials/{id}").To(handler.RESTfulServiceHandler(sp, updateRegistryCredentialHandler)))
+	webService.Route(webService.DELETE("/credentials/{id}").To(handler.RESTfulServiceHandler(sp, deleteRegistryCredentialHandler)))
 	return webService
 }
 
@@ -58,23 +78,50 @@ func newUserService(sp *serviceprovider.Container) *restful.WebService {
 	// Authenticate handlers Sign Up / Sign In
 	webService.Route(webService.POST("/signup").To(handler.RESTfulServiceHandler(sp, signUpUserHandler)))
 	webService.Route(webService.POST("/signin").To(handler.RESTfulServiceHandler(sp, signInUserHandler)))
+	webService.Route(webService.POST("/refresh").To(handler.RESTfulServiceHandler(sp, refreshTokenHandler)))
+	webService.Route(webService.POST("/signin/2fa").To(handler.RESTfulServiceHandler(sp, twoFactorSignInHandler)))
+	webService.Route(webService.POST("/signin/2fa/enroll").To(handler.RESTfulServiceHandler(sp, enrollTOTPChallengeHandler)))
+	webService.Route(webService.GET("/oidc/login").To(handler.RESTfulServiceHandler(sp, oidcLoginHandler)))
+	webService.Route(webService.GET("/oidc/callback").To(handler.RESTfulServiceHandler(sp, oidcCallbackHandler)))
+	webService.Route(webService.POST("/oidc/token").To(handler.RESTfulServiceHandler(sp, oidcTokenHandler)))
+	webService.Route(webService.POST("/password/forgot").To(handler.RESTfulServiceHandler(sp, forgotPasswordHandler)))
+	webService.Route(webService.POST("/password/reset").To(handler.RESTfulServiceHandler(sp, resetPasswordHandler)))
 
-	// TODO only root role can access
+	// only root role can access
 	webService.Route(webService.GET("/").To(handler.RESTfulServiceHandler(sp, listUserHandler)))
 	webService.Route(webService.POST("/").To(handler.RESTfulServiceHandler(sp, createUserHandler)))
 	webService.Route(webService.DELETE("/{id}").To(handler.RESTfulServiceHandler(sp, deleteUserHandler)))
+	webService.Route(webService.DELETE("/{id}/sessions").To(handler.RESTfulServiceHandler(sp, revokeUserSessionsHandler)))
+	webService.Route(webService.DELETE("/{id}/2fa").To(handler.RESTfulServiceHandler(sp, resetUserTwoFactorHandler)))
+	webService.Route(webService.PUT("/{id}").To(handler.RESTfulServiceHandler(sp, updateUserHandler)))
+	webService.Route(webService.POST("/{id}/unlock").To(handler.RESTfulServiceHandler(sp, unlockUserHandler)))
+	webService.Route(webService.POST("/{id}/password/reset").To(handler.RESTfulServiceHandler(sp, resetUserPasswordHandler)))
+	webService.Route(webService.GET("/2fa/policy").To(handler.RESTfulServiceHandler(sp, getTwoFactorPolicyHandler)))
+	webService.Route(webService.PUT("/2fa/policy").To(handler.RESTfulServiceHandler(sp, updateTwoFactorPolicyHandler)))
 
-	// user role can access
-	webService.Route(webService.GET("/{id}").Filter(validateTokenMiddleware).To(handler.RESTfulServiceHandler(sp, getUserHandler)))
-	webService.Route(webService.GET("/verify/auth").Filter(validateTokenMiddleware).To(handler.RESTfulServiceHandler(sp, verifyTokenHandler)))
-	webService.Route(webService.PUT("/password").Filter(validateTokenMiddleware).To(handler.RESTfulServiceHandler(sp, patchPasswordHandler)))
+	// any signed in user can access
+	webService.Route(webService.GET("/{id}").To(handler.RESTfulServiceHandler(sp, getUserHandler)))
+	webService.Route(webService.GET("/{id}/sessions").To(handler.RESTfulServiceHandler(sp, listUserSessionsHandler)))
+	webService.Route(webService.POST("/signout").To(handler.RESTfulServiceHandler(sp, signOutUserHandler)))
+
+	// two-factor authentication
+	webService.Route(webService.POST("/2fa/totp").To(handler.RESTfulServiceHandler(sp, enrollTOTPHandler)))
+	webService.Route(webService.POST("/2fa/totp/confirm").To(handler.RESTfulServiceHandler(sp, confirmTOTPHandler)))
+	webService.Route(webService.POST("/2fa/totp/disable").To(handler.RESTfulServiceHandler(sp, disableTOTPHandler)))
+	webService.Route(webService.POST("/2fa/recovery-codes").To(handler.RESTfulServiceHandler(sp, regenerateRecoveryCodesHandler)))
+
+	// personal API tokens
+	webService.Route(webService.POST("/tokens").To(handler.RESTfulServiceHandler(sp, createAPITokenHandler)))
+	webService.Route(webService.GET("/tokens").To(handler.RESTfulServiceHandler(sp, listAPITokenHandler)))
+	webService.Route(webService.DELETE("/tokens/{id}").To(handler.RESTfulServiceHandler(sp, deleteAPITokenHandler)))
+	webService.Route(webService.GET("/verify/auth").To(handler.RESTfulServiceHandler(sp, verifyTokenHandler)))
+	webService.Route(webService.PUT("/password").To(handler.RESTfulServiceHandler(sp, patchPasswordHandler)))
 	return webService
 }
 
 func newNetworkService(sp *serviceprovider.Container) *restful.WebService {
 	webService := new(restful.WebService)
 	webService.Path("/v1/networks").Consumes(restful.MIME_JSON, restful.MIME_JSON).Produces(restful.MIME_JSON, restful.MIME_JSON)
-	webService.Filter(validateTokenMiddleware)
 	webService.Route(webService.GET("/").To(handler.RESTfulServiceHandler(sp, listNetworkHandler)))
 	webService.Route(webService.GET("/{id}").To(handler.RESTfulServiceHandler(sp, getNetworkHandler)))
 	webService.Route(webService.GET("/status/{id}").To(handler.RESTfulServiceHandler(sp, getNetworkStatusHandler)))
@@ -84,10 +131,17 @@ func newNetworkService(sp *serviceprovider.Container) *restful.WebService {
 	return webService
 }
 
+func newIPAMService(sp *serviceprovider.Container) *restful.WebService {
+	webService := new(restful.WebService)
+	webService.Path("/v1/ipam").Consumes(restful.MIME_JSON, restful.MIME_JSON).Produces(restful.MIME_JSON, restful.MIME_JSON)
+	webService.Route(webService.POST("/allocations").To(handler.RESTfulServiceHandler(sp, allocateIPHandler)))
+	webService.Route(webService.GET("/allocations").To(handler.RESTfulServiceHandler(sp, listIPAllocationHandler)))
+	return webService
+}
+
 func newStorageService(sp *serviceprovider.Container) *restful.WebService {
 	webService := new(restful.WebService)
 	webService.Path("/v1/storage").Consumes(restful.MIME_JSON, restful.MIME_JSON).Produces(restful.MIME_JSON, restful.MIME_JSON)
-	webService.Filter(validateTokenMiddleware)
 	webService.Route(webService.POST("/").To(handler.RESTfulServiceHandler(sp, createStorage)))
 	webService.Route(webService.GET("/").To(handler.RESTfulServiceHandler(sp, listStorage)))
 	webService.Route(webService.DELETE("/{id}").To(handler.RESTfulServiceHandler(sp, deleteStorage)))
@@ -97,7 +151,6 @@ func newStorageService(sp *serviceprovider.Container) *restful.WebService {
 func newVolumeService(sp *serviceprovider.Container) *restful.WebService {
 	webService := new(restful.WebService)
 	webService.Path("/v1/volume").Consumes(restful.MIME_JSON, restful.MIME_JSON).Produces(restful.MIME_JSON, restful.MIME_JSON)
-	webService.Filter(validateTokenMiddleware)
 	webService.Route(webService.POST("/").To(handler.RESTfulServiceHandler(sp, createVolumeHandler)))
 	webService.Route(webService.DELETE("/{id}").To(handler.RESTfulServiceHandler(sp, deleteVolumeHandler)))
 	webService.Route(webService.GET("/").To(handler.RESTfulServiceHandler(sp, listVolumeHandler)))
@@ -115,7 +168,6 @@ func newContainerService(sp *serviceprovider.Container) *restful.WebService {
 func newPodService(sp *serviceprovider.Container) *restful.WebService {
 	webService := new(restful.WebService)
 	webService.Path("/v1/pods").Consumes(restful.MIME_JSON, restful.MIME_JSON).Produces(restful.MIME_JSON, restful.MIME_JSON)
-	webService.Filter(validateTokenMiddleware)
 	webService.Route(webService.POST("/").To(handler.RESTfulServiceHandler(sp, createPodHandler)))
 	webService.Route(webService.DELETE("/{id}").To(handler.RESTfulServiceHandler(sp, deletePodHandler)))
 	webService.Route(webService.DELETE("/{namespace}/{pod}").To(handler.RESTfulServiceHandler(sp, deletePodFromClusterHandler)))
@@ -126,21 +178,69 @@ func newPodService(sp *serviceprovider.Container) *restful.WebService {
 
 func newDeploymentService(sp *serviceprovider.Container) *restful.WebService {
 	webService := new(restful.WebService)
-	webService.Filter(validateTokenMiddleware)
 	webService.Path("/v1/deployments").Consumes(restful.MIME_JSON, restful.MIME_JSON).Produces(restful.MIME_JSON, restful.MIME_JSON)
 	webService.Route(webService.POST("/").To(handler.RESTfulServiceHandler(sp, createDeploymentHandler)))
 	webService.Route(webService.DELETE("/{id}").To(handler.RESTfulServiceHandler(sp, deleteDeploymentHandler)))
 	webService.Route(webService.GET("/").To(handler.RESTfulServiceHandler(sp, listDeploymentHandler)))
 	webService.Route(webService.GET("/{id}").To(handler.RESTfulServiceHandler(sp, getDeploymentHandler)))
+	webService.Route(webService.GET("/{id}/status").To(handler.RESTfulServiceHandler(sp, getDeploymentStatusHandler)))
 	webService.Route(webService.POST("/upload/yaml").Consumes("multipart/form-data").To(handler.RESTfulServiceHandler(sp, uploadDeploymentYAMLHandler)))
 	webService.Route(webService.PUT("/autoscale").To(handler.RESTfulServiceHandler(sp, updateAutoscalerHandler)))
+	webService.Route(webService.PUT("/{id}").To(handler.RESTfulServiceHandler(sp, updateDeploymentHandler)))
+	webService.Route(webService.GET("/{id}/revisions").To(handler.RESTfulServiceHandler(sp, listDeploymentRevisionHandler)))
+	webService.Route(webService.POST("/{id}/rollback").To(handler.RESTfulServiceHandler(sp, rollbackDeploymentHandler)))
+	return webService
+}
+
+func newStatefulSetService(sp *serviceprovider.Container) *restful.WebService {
+	webService := new(restful.WebService)
+	webService.Path("/v1/statefulsets").Consumes(restful.MIME_JSON, restful.MIME_JSON).Produces(restful.MIME_JSON, restful.MIME_JSON)
+	webService.Route(webService.POST("/").To(handler.RESTfulServiceHandler(sp, createStatefulSetHandler)))
+	webService.Route(webService.DELETE("/{id}").To(handler.RESTfulServiceHandler(sp, deleteStatefulSetHandler)))
+	webService.Route(webService.GET("/").To(handler.RESTfulServiceHandler(sp, listStatefulSetHandler)))
+	webService.Route(webService.GET("/{id}").To(handler.RESTfulServiceHandler(sp, getStatefulSetHandler)))
+	webService.Route(webService.PUT("/{id}").To(handler.RESTfulServiceHandler(sp, updateStatefulSetHandler)))
+	return webService
+}
+
+func newDaemonSetService(sp *serviceprovider.Container) *restful.WebService {
+	webService := new(restful.WebService)
+	webService.Path("/v1/daemonsets").Consumes(restful.MIME_JSON, restful.MIME_JSON).Produces(restful.MIME_JSON, restful.MIME_JSON)
+	webService.Route(webService.POST("/").To(handler.RESTfulServiceHandler(sp, createDaemonSetHandler)))
+	webService.Route(webService.DELETE("/{id}").To(handler.RESTfulServiceHandler(sp, deleteDaemonSetHandler)))
+	webService.Route(webService.GET("/").To(handler.RESTfulServiceHandler(sp, listDaemonSetHandler)))
+	webService.Route(webService.GET("/{id}").To(handler.RESTfulServiceHandler(sp, getDaemonSetHandler)))
+	webService.Route(webService.PUT("/{id}").To(handler.RESTfulServiceHandler(sp, updateDaemonSetHandler)))
+	return webService
+}
+
+func newJobService(sp *serviceprovider.Container) *restful.WebService {
+	webService := new(restful.WebService)
+	webService.Path("/v1/jobs").Consumes(restful.MIME_JSON, restful.MIME_JSON).Produces(restful.MIME_JSON, restful.MIME_JSON)
+	webService.Route(webService.POST("/").To(handler.RESTfulServiceHandler(sp, createJobHandler)))
+	webService.Route(webService.DELETE("/{id}").To(handler.RESTfulServiceHandler(sp, deleteJobHandler)))
+	webService.Route(webService.GET("/").To(handler.RESTfulServiceHandler(sp, listJobHandler)))
+	webService.Route(webService.GET("/{id}").To(handler.RESTfulServiceHandler(sp, getJobHandler)))
+	webService.Route(webService.GET("/{id}/status").To(handler.RESTfulServiceHandler(sp, getJobStatusHandler)))
+	return webService
+}
+
+func newCronJobService(sp *serviceprovider.Container) *restful.WebService {
+	webService := new(restful.WebService)
+	webService.Path("/v1/cronjobs").Consumes(restful.MIME_JSON, restful.MIME_JSON).Produces(restful.MIME_JSON, restful.MIME_JSON)
+	webService.Route(webService.POST("/").To(handler.RESTfulServiceHandler(sp, createCronJobHandler)))
+	webService.Route(webService.DELETE("/{id}").To(handler.RESTfulServiceHandler(sp, deleteCronJobHandler)))
+	webService.Route(webService.GET("/").To(handler.RESTfulServiceHandler(sp, listCronJobHandler)))
+	webService.Route(webService.GET("/{id}").To(handler.RESTfulServiceHandler(sp, getCronJobHandler)))
+	webService.Route(webService.PUT("/{id}").To(handler.RESTfulServiceHandler(sp, updateCronJobHandler)))
+	webService.Route(webService.GET("/{id}/runs").To(handler.RESTfulServiceHandler(sp, listCronJobRunsHandler)))
+	webService.Route(webService.POST("/{id}/runs").To(handler.RESTfulServiceHandler(sp, triggerCronJobHandler)))
 	return webService
 }
 
 func newAppService(sp *serviceprovider.Container) *restful.WebService {
 	webService := new(restful.WebService)
 	webService.Path("/v1/apps").Consumes(restful.MIME_JSON, restful.MIME_JSON).Produces(restful.MIME_JSON, restful.MIME_JSON)
-	webService.Filter(validateTokenMiddleware)
 	webService.Route(webService.POST("/").To(handler.RESTfulServiceHandler(sp, createAppHandler)))
 	return webService
 }
@@ -148,7 +248,6 @@ func newAppService(sp *serviceprovider.Container) *restful.WebService {
 func newServiceService(sp *serviceprovider.Container) *restful.WebService {
 	webService := new(restful.WebService)
 	webService.Path("/v1/services").Consumes(restful.MIME_JSON, restful.MIME_JSON).Produces(restful.MIME_JSON, restful.MIME_JSON)
-	webService.Filter(validateTokenMiddleware)
 	webService.Route(webService.POST("/").To(handler.RESTfulServiceHandler(sp, createServiceHandler)))
 	webService.Route(webService.DELETE("/{id}").To(handler.RESTfulServiceHandler(sp, deleteServiceHandler)))
 	webService.Route(webService.GET("/").To(handler.RESTfulServiceHandler(sp, listServiceHandler)))
@@ -160,19 +259,32 @@ func newServiceService(sp *serviceprovider.Container) *restful.WebService {
 func newNamespaceService(sp *serviceprovider.Container) *restful.WebService {
 	webService := new(restful.WebService)
 	webService.Path("/v1/namespaces").Consumes(restful.MIME_JSON, restful.MIME_JSON).Produces(restful.MIME_JSON, restful.MIME_JSON)
-	webService.Filter(validateTokenMiddleware)
 	webService.Route(webService.POST("/").To(handler.RESTfulServiceHandler(sp, createNamespaceHandler)))
 	webService.Route(webService.DELETE("/{id}").To(handler.RESTfulServiceHandler(sp, deleteNamespaceHandler)))
 	webService.Route(webService.GET("/").To(handler.RESTfulServiceHandler(sp, listNamespaceHandler)))
 	webService.Route(webService.GET("/{id}").To(handler.RESTfulServiceHandler(sp, getNamespaceHandler)))
 	webService.Route(webService.POST("/upload/yaml").Consumes("multipart/form-data").To(handler.RESTfulServiceHandler(sp, uploadNamespaceYAMLHandler)))
+	webService.Route(webService.PUT("/{id}/team").To(handler.RESTfulServiceHandler(sp, assignNamespaceTeamHandler)))
+	webService.Route(webService.PUT("/{id}/quota").To(handler.RESTfulServiceHandler(sp, updateNamespaceQuotaHandler)))
+	webService.Route(webService.GET("/{id}/quota").To(handler.RESTfulServiceHandler(sp, getNamespaceQuotaHandler)))
+	return webService
+}
+
+func newTeamService(sp *serviceprovider.Container) *restful.WebService {
+	webService := new(restful.WebService)
+	webService.Path("/v1/teams").Consumes(restful.MIME_JSON, restful.MIME_JSON).Produces(restful.MIME_JSON, restful.MIME_JSON)
+	webService.Route(webService.POST("/").To(handler.RESTfulServiceHandler(sp, createTeamHandler)))
+	webService.Route(webService.DELETE("/{id}").To(handler.RESTfulServiceHandler(sp, deleteTeamHandler)))
+	webService.Route(webService.GET("/").To(handler.RESTfulServiceHandler(sp, listTeamHandler)))
+	webService.Route(webService.GET("/{id}").To(handler.RESTfulServiceHandler(sp, getTeamHandler)))
+	webService.Route(webService.PUT("/{id}/members").To(handler.RESTfulServiceHandler(sp, setTeamMemberHandler)))
+	webService.Route(webService.DELETE("/{id}/members/{userID}").To(handler.RESTfulServiceHandler(sp, removeTeamMemberHandler)))
 	return webService
 }
 
 func newConfigMapService(sp *serviceprovider.Container) *restful.WebService {
 	webService := new(restful.WebService)
 	webService.Path("/v1/configmaps").Consumes(restful.MIME_JSON, restful.MIME_JSON).Produces(restful.MIME_JSON, restful.MIME_JSON)
-	webService.Filter(validateTokenMiddleware)
 	webService.Route(webService.POST("/").To(handler.RESTfulServiceHandler(sp, createConfigMapHandler)))
 	webService.Route(webService.DELETE("/{id}").To(handler.RESTfulServiceHandler(sp, deleteConfigMapHandler)))
 	webService.Route(webService.GET("/").To(handler.RESTfulServiceHandler(sp, listConfigMapHandler)))
@@ -181,6 +293,17 @@ func newConfigMapService(sp *serviceprovider.Container) *restful.WebService {
 	return webService
 }
 
+func newSecretService(sp *serviceprovider.Container) *restful.WebService {
+	webService := new(restful.WebService)
+	webService.Path("/v1/secrets").Consumes(restful.MIME_JSON, restful.MIME_JSON).Produces(restful.MIME_JSON, restful.MIME_JSON)
+	webService.Route(webService.POST("/").To(handler.RESTfulServiceHandler(sp, createSecretHandler)))
+	webService.Route(webService.DELETE("/{id}").To(handler.RESTfulServiceHandler(sp, deleteSecretHandler)))
+	webService.Route(webService.GET("/").To(handler.RESTfulServiceHandler(sp, listSecretHandler)))
+	webService.Route(webService.GET("/{id}").To(handler.RESTfulServiceHandler(sp, getSecretHandler)))
+	webService.Route(webService.POST("/upload/yaml").Consumes("multipart/form-data").To(handler.RESTfulServiceHandler(sp, uploadSecretYAMLHandler)))
+	return webService
+}
+
 func newMonitoringService(sp *serviceprovider.Container) *restful.WebService {
 	webService := new(restful.WebService)
 	webService.Path("/v1/monitoring").Consumes(restful.MIME_JSON, restful.MIME_JSON).Produces(restful.MIME_JSON, restful.MIME_JSON)
@@ -199,6 +322,8 @@ func newMonitoringService(sp *serviceprovider.Container) *restful.WebService {
 	// controller
 	webService.Route(webService.GET("/controllers").To(handler.RESTfulServiceHandler(sp, listControllerMetricsHandler)))
 	webService.Route(webService.GET("/controllers/{controller}").To(handler.RESTfulServiceHandler(sp, getControllerMetricsHandler)))
+	// custom metric
+	webService.Route(webService.GET("/metrics/{metric}").To(handler.RESTfulServiceHandler(sp, getCustomMetricHandler)))
 	return webService
 }
 
@@ -215,3 +340,10 @@ func newShellService(sp *serviceprovider.Container) *restful.WebService {
 	webService.Route(webService.GET("/pod/{namespace}/{pod}/shell/{container}").To(handler.RESTfulServiceHandler(sp, handleExecShell)))
 	return webService
 }
+
+func newAuditService(sp *serviceprovider.Container) *restful.WebService {
+	webService := new(restful.WebService)
+	webService.Path("/v1/audit").Consumes(restful.MIME_JSON, restful.MIME_JSON).Produces(restful.MIME_JSON, restful.MIME_JSON)
+	webService.Route(webService.GET("/").To(handler.RESTfulServiceHandler(sp, listAuditHandler)))
+	return webService
+}
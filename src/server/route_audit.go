@@ -0,0 +1,185 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"github.com/linkernetworks/logger"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/server/backend"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+)
+
+// the request bodies larger than it are not recorded
+const maxAuditBodySize = 64 * 1024
+
+const redactedValue = "[REDACTED]"
+
+// auditedRoutes are the non-mutating routes which are audited as well
+var auditedRoutes = map[string]bool{
+	"/v1/exec/pod/{namespace}/{pod}/shell/{container}": true,
+}
+
+// auditFilter records every mutating API call and the audited routes into the audit collection
+func auditFilter(sp *serviceprovider.Container) restful.FilterFunction {
+	session := sp.Mongo.NewSession()
+	if err := backend.EnsureAuditIndex(session); err != nil {
+		logger.Warnf("Failed to ensure the index of the audit records: %v", err)
+	}
+	session.Close()
+
+	return func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		routePath := req.SelectedRoutePath()
+		// the unmatched requests have no route
+		if routePath == "" || !isAudited(req.Request.Method, routePath) {
+			chain.ProcessFilter(req, resp)
+			return
+		}
+
+		// the bodies of the secrets carry their values
+		body := ""
+		if auditResource(routePath) != entity.SecretCollectionName {
+			body = auditBody(req)
+		}
+		start := time.Now()
+		chain.ProcessFilter(req, resp)
+
+		userID, _ := req.Attribute("UserID").(string)
+		role, _ := req.Attribute("Role").(string)
+		apiTokenID, _ := req.Attribute("APITokenID").(string)
+		terminalSessionID, _ := req.Attribute("TerminalSessionID").(string)
+		status := resp.StatusCode()
+		outcome := entity.AuditSuccess
+		if status >= http.StatusBadRequest {
+			outcome = entity.AuditFailure
+		}
+		record := entity.AuditRecord{
+			UserID:            userID,
+			Role:              role,
+			APITokenID:        apiTokenID,
+			Method:            req.Request.Method,
+			Route:             routePath,
+			Path:              req.Request.URL.Path,
+			Resource:          auditResource(routePath),
+			Parameters:        req.PathParameters(),
+			Body:              body,
+			RemoteAddr:        req.Request.RemoteAddr,
+			Status:            status,
+			Outcome:           outcome,
+			Latency:           time.Since(start).Nanoseconds() / int64(time.Millisecond),
+			TerminalSessionID: terminalSessionID,
+			CreatedAt:         start,
+		}
+		if retention := auditRetention(sp); retention > 0 {
+			expiresAt := start.Add(retention)
+			record.ExpiresAt = &expiresAt
+		}
+
+		session := sp.Mongo.NewSession()
+		defer session.Close()
+		if err := backend.CreateAuditRecord(session, record); err != nil {
+			logger.Warnf("Failed to record the audit of %s %s: %v", record.Method, record.Path, err)
+		}
+	}
+}
+
+// isAudited reports whether the call to the route is recorded
+func isAudited(method, routePath string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return auditedRoutes[routePath]
+}
+
+// auditResource returns the resource type of the route, e.g. deployments of /v1/deployments/{id}
+func auditResource(routePath string) string {
+	return strings.SplitN(strings.TrimPrefix(routePath, "/v1/"), "/", 2)[0]
+}
+
+// auditRetention returns how long the audit records are kept, 0 keeps them forever
+func auditRetention(sp *serviceprovider.Container) time.Duration {
+	if sp.Config.Audit == nil || sp.Config.Audit.Retention == "" {
+		return 0
+	}
+	retention, err := time.ParseDuration(sp.Config.Audit.Retention)
+	if err != nil {
+		logger.Warnf("Invalid audit retention %s, the audit records are kept: %v", sp.Config.Audit.Retention, err)
+		return 0
+	}
+	return retention
+}
+
+// auditBody reads the JSON request body for the audit record and restores it for the handler
+func auditBody(req *restful.Request) string {
+	if req.Request.Body == nil || req.Request.ContentLength > maxAuditBodySize {
+		return ""
+	}
+	if mediaType, _, err := mime.ParseMediaType(req.HeaderParameter("Content-Type")); err != nil || mediaType != restful.MIME_JSON {
+		return ""
+	}
+	// the chunked bodies have no content length, read one byte more to tell they are too large
+	body, err := ioutil.ReadAll(io.LimitReader(req.Request.Body, maxAuditBodySize+1))
+	// the handler reads the rest of the large body
+	req.Request.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), req.Request.Body), req.Request.Body}
+	if err != nil || len(body) > maxAuditBodySize {
+		return ""
+	}
+	return sanitizeAuditBody(body)
+}
+
+// sanitizeAuditBody redacts the passwords, secrets, tokens, verification codes, environment variables
+// and data of the configmaps and secrets in the JSON body
+func sanitizeAuditBody(body []byte) string {
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return ""
+	}
+	sanitized, err := json.Marshal(redactSecrets(document))
+	if err != nil {
+		return ""
+	}
+	return string(sanitized)
+}
+
+func redactSecrets(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if isSecretKey(key) {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = redactSecrets(field)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactSecrets(item)
+		}
+	}
+	return value
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	switch key {
+	case "code", "envvars", "data":
+		return true
+	}
+	for _, secret := range []string{"password", "secret", "token"} {
+		if strings.Contains(key, secret) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestSanitizeAuditBody(t *testing.T) {
+	body := `{"loginCredential":{"username":"john@linkernetworks.com","password":"secret"},"code":"123456","members":[{"apiToken":"vtx_abc","role":"admin"}]}`
+	sanitized := sanitizeAuditBody([]byte(body))
+	assert.NotContains(t, sanitized, `"secret"`)
+	assert.NotContains(t, sanitized, "123456")
+	assert.NotContains(t, sanitized, "vtx_abc")
+	assert.Contains(t, sanitized, "john@linkernetworks.com")
+	assert.Contains(t, sanitized, `"role":"admin"`)
+	assert.Equal(t, "", sanitizeAuditBody([]byte("not json")))
+
+	// the environment variables and the data are redacted wholesale
+	body = `{"name":"web","envVars":{"DB_URL":"mysql://root:pw@db"},"data":{"id_rsa":"KEY"}}`
+	sanitized = sanitizeAuditBody([]byte(body))
+	assert.NotContains(t, sanitized, "DB_URL")
+	assert.NotContains(t, sanitized, "KEY")
+	assert.Contains(t, sanitized, `"name":"web"`)
+}
+
+func TestAuditBodyOfChunkedRequest(t *testing.T) {
+	large := `{"name":"` + strings.Repeat("a", maxAuditBodySize) + `"}`
+	httpRequest := httptest.NewRequest("POST", "/v1/deployments/", ioutil.NopCloser(strings.NewReader(large)))
+	httpRequest.Header.Set("Content-Type", restful.MIME_JSON)
+	// the chunked body has no content length
+	httpRequest.ContentLength = -1
+	req := restful.NewRequest(httpRequest)
+
+	assert.Equal(t, "", auditBody(req))
+	// the handler still reads the whole body
+	body, err := ioutil.ReadAll(req.Request.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, large, string(body))
+}
+
+func TestIsAudited(t *testing.T) {
+	assert.True(t, isAudited("POST", "/v1/deployments/"))
+	assert.True(t, isAudited("PUT", "/v1/users/{id}"))
+	assert.True(t, isAudited("DELETE", "/v1/deployments/{id}"))
+	assert.True(t, isAudited("GET", "/v1/exec/pod/{namespace}/{pod}/shell/{container}"))
+	assert.False(t, isAudited("GET", "/v1/deployments/{id}"))
+	assert.Equal(t, "deployments", auditResource("/v1/deployments/{id}"))
+	assert.Equal(t, "audit", auditResource("/v1/audit/"))
+}
+
+type AuditTestSuite struct {
+	ServerTestSuite
+}
+
+func (suite *AuditTestSuite) SetupSuite() {
+	suite.setupServices(newTeamService, newAuditService)
+	suite.wc.Filter(auditFilter(suite.sp))
+}
+
+func (suite *AuditTestSuite) TearDownSuite() {}
+
+func TestAuditSuite(t *testing.T) {
+	suite.Run(t, new(AuditTestSuite))
+}
+
+func (suite *AuditTestSuite) TestAuditRecord() {
+	id := bson.NewObjectId().Hex()
+	assertResponseCode(suite.T(), http.StatusNotFound, suite.request("DELETE", "/v1/teams/"+id, ""))
+
+	record := entity.AuditRecord{}
+	err := suite.session.FindOne(entity.AuditCollectionName, bson.M{"path": "/v1/teams/" + id}, &record)
+	suite.NoError(err)
+	defer suite.session.Remove(entity.AuditCollectionName, "_id", record.ID)
+	suite.NotEmpty(record.UserID)
+	suite.Equal(entity.RootRole, record.Role)
+	suite.Equal("DELETE", record.Method)
+	suite.Equal("/v1/teams/{id}", record.Route)
+	suite.Equal("teams", record.Resource)
+	suite.Equal(id, record.Parameters["id"])
+	suite.Equal(http.StatusNotFound, record.Status)
+	suite.Equal(entity.AuditFailure, record.Outcome)
+	// the retention of the testing config
+	suite.NotNil(record.ExpiresAt)
+
+	// the audit records are listed by the filters
+	httpWriter := suite.request("GET", "/v1/audit?resource=teams&outcome=failure&user="+record.UserID, "")
+	assertResponseCode(suite.T(), http.StatusOK, httpWriter)
+	records := []entity.AuditRecord{}
+	suite.NoError(json.Unmarshal(httpWriter.Body.Bytes(), &records))
+	found := false
+	for _, r := range records {
+		suite.Equal("teams", r.Resource)
+		suite.Equal(entity.AuditFailure, r.Outcome)
+		found = found || r.ID == record.ID
+	}
+	suite.True(found)
+
+	// listing is not audited
+	count, err := suite.session.Count(entity.AuditCollectionName, bson.M{"route": "/v1/audit/"})
+	suite.NoError(err)
+	suite.Equal(0, count)
+}
+
+func (suite *AuditTestSuite) TestAuditRedactPassword() {
+	username := namesgenerator.GetRandomName(0) + "@linkernetworks.com"
+	body := `{"username":"` + username + `","password":"wrong-password"}`
+	assertResponseCode(suite.T(), http.StatusUnauthorized, suite.request("POST", "/v1/users/signin", body))
+
+	record := entity.AuditRecord{}
+	err := suite.session.FindOne(entity.AuditCollectionName, bson.M{
+		"route": "/v1/users/signin",
+		"body":  bson.RegEx{Pattern: username},
+	}, &record)
+	suite.NoError(err)
+	defer suite.session.Remove(entity.AuditCollectionName, "_id", record.ID)
+	suite.NotContains(record.Body, "wrong-password")
+	suite.Contains(record.Body, redactedValue)
+}
+
+func (suite *AuditTestSuite) TestListAuditFail() {
+	assertResponseCode(suite.T(), http.StatusBadRequest, suite.request("GET", "/v1/audit?outcome=unknown", ""))
+	assertResponseCode(suite.T(), http.StatusBadRequest, suite.request("GET", "/v1/audit?since=yesterday", ""))
+}
@@ -1,16 +1,21 @@
 package server
 
 import (
-	"log"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/dgrijalva/jwt-go/request"
 	"github.com/emicklei/go-restful"
 	"github.com/linkernetworks/logger"
 	"github.com/linkernetworks/vortex/src/entity"
 	response "github.com/linkernetworks/vortex/src/net/http"
 	"github.com/linkernetworks/vortex/src/server/backend"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/linkernetworks/vortex/src/utils"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
 )
 
 func globalLogging(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
@@ -18,79 +23,255 @@ func globalLogging(req *restful.Request, resp *restful.Response, chain *restful.
 	chain.ProcessFilter(req, resp)
 }
 
-func validateTokenMiddleware(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
-	token, err := request.ParseFromRequest(req.Request, request.AuthorizationHeaderExtractor,
-		func(token *jwt.Token) (interface{}, error) {
-			return []byte(backend.SecretKey), nil
-		})
+// validateTokenMiddleware verifies the bearer token, which is either a session JWT signed with
+// the JWT keys of the service provider or a personal API token, and rejects revoked or expired tokens
+func validateTokenMiddleware(sp *serviceprovider.Container) restful.FilterFunction {
+	return func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		tokenString, err := request.AuthorizationHeaderExtractor.ExtractToken(req.Request)
+		if err != nil {
+			unauthorized(resp, "Unauthorized access to this resource", err)
+			return
+		}
 
-	if err == nil {
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			// save user ID to requests attributes
-			req.SetAttribute("UserID", claims["sub"])
-			// save role to requests attributes
-			req.SetAttribute("Role", claims["role"])
+		if strings.HasPrefix(tokenString, entity.APITokenPrefix) {
+			identity, ok := authenticateAPIToken(sp, tokenString)
+			if !ok {
+				unauthorized(resp, "API token is revoked or expired", fmt.Errorf("Invalid API token"))
+				return
+			}
+			// read-only tokens act as a guest
+			role := identity.role
+			if identity.scope == entity.APITokenReadOnly {
+				if req.Request.Method != http.MethodGet {
+					permissionDenied(req, resp, "API token "+identity.tokenID+" is read-only")
+					return
+				}
+				role = entity.GuestRole
+			}
+			if identity.mustChangePassword && !allowedBeforePasswordChange(req) {
+				passwordChangeRequired(req, resp)
+				return
+			}
+			req.SetAttribute("UserID", identity.userID)
+			req.SetAttribute("Role", role)
+			req.SetAttribute("APITokenID", identity.tokenID)
+			if len(identity.namespaces) > 0 {
+				req.SetAttribute("Namespaces", identity.namespaces)
+			}
 			chain.ProcessFilter(req, resp)
-		} else {
-			resp.WriteHeaderAndEntity(http.StatusUnauthorized,
-				response.ActionResponse{
-					Error:   true,
-					Message: "Token is invalid",
-				})
 			return
 		}
-	} else {
-		logger.Infof("Unauthorized access to this resource")
-		resp.WriteHeaderAndEntity(http.StatusUnauthorized,
-			response.ActionResponse{
-				Error:   true,
-				Message: "Unauthorized access to this resource",
-			})
-		return
+
+		claims, err := sp.JWT.ParseToken(tokenString)
+		if err != nil {
+			unauthorized(resp, "Unauthorized access to this resource", err)
+			return
+		}
+
+		sessionID, _ := claims["jti"].(string)
+		state := lookupSession(sp, sessionID)
+		if !state.active || state.userID != claims["sub"] {
+			unauthorized(resp, "Session is revoked or expired", fmt.Errorf("Inactive session %s", sessionID))
+			return
+		}
+		if state.mustChangePassword && !allowedBeforePasswordChange(req) {
+			passwordChangeRequired(req, resp)
+			return
+		}
+
+		// save user ID to requests attributes
+		req.SetAttribute("UserID", state.userID)
+		// save role to requests attributes, the role claim is only informative for the clients
+		req.SetAttribute("Role", state.role)
+		// save session ID to requests attributes
+		req.SetAttribute("SessionID", sessionID)
+		chain.ProcessFilter(req, resp)
 	}
 }
 
+// validateServiceAccountMiddleware verifies the bearer token is the token of a service account by the TokenReview
+// of Kubernetes, the handlers check the service account runs the pod the request names
+func validateServiceAccountMiddleware(sp *serviceprovider.Container) restful.FilterFunction {
+	return func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		tokenString, err := request.AuthorizationHeaderExtractor.ExtractToken(req.Request)
+		if err != nil {
+			unauthorized(resp, "Unauthorized access to this resource", err)
+			return
+		}
+
+		namespace, name, err := sp.KubeCtl.AuthenticateServiceAccount(tokenString)
+		if err != nil {
+			unauthorized(resp, "Service account token is invalid", err)
+			return
+		}
+		req.SetAttribute("ServiceAccountNamespace", namespace)
+		req.SetAttribute("ServiceAccount", name)
+		chain.ProcessFilter(req, resp)
+	}
+}
+
+func unauthorized(resp *restful.Response, message string, err error) {
+	logger.Infof("%s: %v", message, err)
+	resp.WriteHeaderAndEntity(http.StatusUnauthorized,
+		response.ActionResponse{
+			Error:   true,
+			Message: message,
+		})
+}
+
+// sessionState is what the session cache remembers of a session
+type sessionState struct {
+	active             bool
+	userID             string
+	role               string
+	mustChangePassword bool
+}
+
+// lookupSession looks up the session in the session cache first and only
+// asks mongo when the cached result is missing or expired
+func lookupSession(sp *serviceprovider.Container, sessionID string) sessionState {
+	if !bson.IsObjectIdHex(sessionID) {
+		return sessionState{}
+	}
+	if state, ok := sp.SessionCache.Get(sessionID); ok {
+		return state.(sessionState)
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	state := sessionState{}
+	activeSession, err := backend.FindActiveSession(session, bson.ObjectIdHex(sessionID))
+	user := entity.User{}
+	if err == nil {
+		// the role is read from the user, the token only proves the session
+		err = session.FindOne(entity.UserCollectionName, bson.M{"_id": activeSession.UserID}, &user)
+	}
+	switch err {
+	case nil:
+		// the sessions of the disabled user are rejected even before they are revoked
+		state = sessionState{
+			active:             !user.Disabled,
+			userID:             user.ID.Hex(),
+			role:               user.Role,
+			mustChangePassword: activeSession.MustChangePassword,
+		}
+	case mgo.ErrNotFound:
+	default:
+		// don't cache the failure of mongo
+		logger.Warnf("Failed to look up session %s: %v", sessionID, err)
+		return state
+	}
+	sp.SessionCache.Set(sessionID, state)
+	return state
+}
+
+// passwordChangeRoutes are the only routes allowed until the user changes the password
+var passwordChangeRoutes = map[string]bool{
+	"PUT /v1/users/password":    true,
+	"POST /v1/users/signout":    true,
+	"GET /v1/users/verify/auth": true,
+}
+
+func allowedBeforePasswordChange(req *restful.Request) bool {
+	return passwordChangeRoutes[req.Request.Method+" "+req.SelectedRoutePath()]
+}
+
+func passwordChangeRequired(req *restful.Request, resp *restful.Response) {
+	logger.Infof("%s %s: Forbidden: password change required", req.Request.Method, req.Request.URL)
+	resp.WriteHeaderAndEntity(http.StatusForbidden,
+		response.ActionResponse{
+			Error:   true,
+			Message: "Password change required",
+		})
+}
+
 func rootRole(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
-	role := req.Attribute("Role").(string)
+	role, _ := req.Attribute("Role").(string)
 	if role == entity.RootRole {
 		chain.ProcessFilter(req, resp)
 	} else {
-		log.Printf("User has no root role: Forbidden")
-		resp.WriteHeaderAndEntity(http.StatusForbidden,
-			response.ActionResponse{
-				Error:   true,
-				Message: "Permission denied",
-			})
-		return
+		permissionDenied(req, resp, "User has no root role")
 	}
 }
 
 func userRole(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
-	role := req.Attribute("Role").(string)
+	role, _ := req.Attribute("Role").(string)
 	if role == entity.RootRole || role == entity.UserRole {
 		chain.ProcessFilter(req, resp)
 	} else {
-		log.Printf("User has no user role: Forbidden")
-		resp.WriteHeaderAndEntity(http.StatusForbidden,
-			response.ActionResponse{
-				Error:   true,
-				Message: "Permission denied",
-			})
-		return
+		permissionDenied(req, resp, "User has no user role")
 	}
 }
 
 func guestRole(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
-	role := req.Attribute("Role").(string)
+	role, _ := req.Attribute("Role").(string)
 	if role == entity.RootRole || role == entity.UserRole || role == entity.GuestRole {
 		chain.ProcessFilter(req, resp)
 	} else {
-		log.Printf("User has no guest role: Forbidden")
-		resp.WriteHeaderAndEntity(http.StatusForbidden,
-			response.ActionResponse{
-				Error:   true,
-				Message: "Permission denied",
-			})
-		return
+		permissionDenied(req, resp, "User has no guest role")
+	}
+}
+
+// permissionDenied writes the uniform 403 payload returned by every access control filter
+func permissionDenied(req *restful.Request, resp *restful.Response, reason string) {
+	logger.Infof("%s %s: Forbidden: %s", req.Request.Method, req.Request.URL, reason)
+	resp.WriteHeaderAndEntity(http.StatusForbidden,
+		response.ActionResponse{
+			Error:   true,
+			Message: "Permission denied",
+		})
+}
+
+// apiTokenIdentity is what the session cache remembers of an API token
+type apiTokenIdentity struct {
+	valid      bool
+	tokenID    string
+	userID     string
+	role       string
+	scope      string
+	namespaces []string
+	expiresAt  *time.Time
+
+	mustChangePassword bool
+}
+
+// authenticateAPIToken looks up the API token in the session cache first and
+// only asks mongo when the cached result is missing or expired
+func authenticateAPIToken(sp *serviceprovider.Container, token string) (apiTokenIdentity, bool) {
+	key := utils.SHA256String(token)
+	if cached, ok := sp.SessionCache.Get(key); ok {
+		identity := cached.(apiTokenIdentity)
+		if identity.expiresAt != nil && time.Now().After(*identity.expiresAt) {
+			return apiTokenIdentity{}, false
+		}
+		return identity, identity.valid
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	identity := apiTokenIdentity{}
+	apiToken, user, err := backend.AuthenticateAPIToken(session, token)
+	switch err {
+	case nil:
+		identity = apiTokenIdentity{
+			valid:      true,
+			tokenID:    apiToken.ID.Hex(),
+			userID:     user.ID.Hex(),
+			role:       user.Role,
+			scope:      apiToken.Scope,
+			namespaces: apiToken.Namespaces,
+			expiresAt:  apiToken.ExpiresAt,
+
+			mustChangePassword: user.MustChangePassword,
+		}
+	case mgo.ErrNotFound, backend.ErrUserDisabled, backend.ErrAPITokenExpired:
+	default:
+		// don't cache the failure of mongo
+		logger.Warnf("Failed to look up API token: %v", err)
+		return identity, false
 	}
+	sp.SessionCache.Set(key, identity)
+	return identity, identity.valid
 }
@@ -0,0 +1,742 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/emicklei/go-restful"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/kubernetes"
+	response "github.com/linkernetworks/vortex/src/net/http"
+	"github.com/linkernetworks/vortex/src/server/backend"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/linkernetworks/vortex/src/utils"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// permission describes who is allowed to access a route
+type permission struct {
+	// role is the lowest role allowed to access the route, an empty role means the route is public
+	role string
+	// collection restricts non-root callers to the documents they own in this collection.
+	// The document is looked up by the {id} path parameter.
+	collection string
+	// ownerField is the document field compared with the caller's user ID, "ownerID" if empty
+	ownerField string
+	// owner restricts non-root callers to the objects owned by their documents,
+	// it finds the document by the name and the namespace the request names
+	owner ownerResolver
+	// serviceAccount authenticates the pods by their service account tokens instead of the tokens of the users
+	serviceAccount bool
+}
+
+// ownerResolver returns the collection and the selector of the document owning the object of the request
+type ownerResolver func(sp *serviceprovider.Container, req *restful.Request) (string, bson.M, error)
+
+var (
+	publicAccess = permission{}
+	guestAccess  = permission{role: entity.GuestRole}
+	userAccess   = permission{role: entity.UserRole}
+	rootAccess   = permission{role: entity.RootRole}
+	// selfAccess allows users to access their own user document
+	selfAccess = permission{role: entity.GuestRole, collection: entity.UserCollectionName, ownerField: "_id"}
+	// podAccess allows the pods to call the route with the tokens of their service accounts
+	podAccess = permission{serviceAccount: true}
+)
+
+// ownerAccess allows users to access the documents they created and root to access all of them
+func ownerAccess(collection string) permission {
+	return permission{role: entity.UserRole, collection: collection}
+}
+
+// routePermissions is the permission matrix of every route registered by AppRoute.
+// The key is the HTTP method and the full route path. A route missing from the
+// matrix is denied for everyone.
+// guest can read, user can write its own resources and root can access everything.
+var routePermissions = map[string]permission{
+	"GET /v1/version/": publicAccess,
+
+	"POST /v1/registry/auth":               guestAccess,
+	"POST /v1/registry/credentials":        rootAccess,
+	"GET /v1/registry/credentials":         userAccess,
+	"GET /v1/registry/credentials/{id}":    userAccess,
+	"PUT /v1/registry/credentials/{id}":    rootAccess,
+	"DELETE /v1/registry/credentials/{id}": rootAccess,
+
+	"POST /v1/users/signup":              publicAccess,
+	"POST /v1/users/signin":              publicAccess,
+	"POST /v1/users/refresh":             publicAccess,
+	"GET /v1/users/oidc/login":           publicAccess,
+	"GET /v1/users/oidc/callback":        publicAccess,
+	"POST /v1/users/oidc/token":          publicAccess,
+	"POST /v1/users/signin/2fa":          publicAccess,
+	"POST /v1/users/signin/2fa/enroll":   publicAccess,
+	"POST /v1/users/password/forgot":     publicAccess,
+	"POST /v1/users/password/reset":      publicAccess,
+	"GET /v1/users/":                     rootAccess,
+	"POST /v1/users/":                    rootAccess,
+	"DELETE /v1/users/{id}":              rootAccess,
+	"PUT /v1/users/{id}":                 rootAccess,
+	"DELETE /v1/users/{id}/sessions":     rootAccess,
+	"DELETE /v1/users/{id}/2fa":          rootAccess,
+	"POST /v1/users/{id}/unlock":         rootAccess,
+	"POST /v1/users/{id}/password/reset": rootAccess,
+	"GET /v1/users/2fa/policy":           rootAccess,
+	"PUT /v1/users/2fa/policy":           rootAccess,
+	"GET /v1/users/{id}":                 selfAccess,
+	"GET /v1/users/{id}/sessions":        selfAccess,
+	"POST /v1/users/signout":             guestAccess,
+	"POST /v1/users/2fa/totp":            guestAccess,
+	"POST /v1/users/2fa/totp/confirm":    guestAccess,
+	"POST /v1/users/2fa/totp/disable":    guestAccess,
+	"POST /v1/users/2fa/recovery-codes":  guestAccess,
+	"POST /v1/users/tokens":              guestAccess,
+	"GET /v1/users/tokens":               guestAccess,
+	"DELETE /v1/users/tokens/{id}":       permission{role: entity.GuestRole, collection: entity.APITokenCollectionName},
+	"GET /v1/users/verify/auth":          guestAccess,
+	"PUT /v1/users/password":             guestAccess,
+
+	"GET /v1/networks/":             guestAccess,
+	"GET /v1/networks/{id}":         guestAccess,
+	"GET /v1/networks/status/{id}":  guestAccess,
+	"POST /v1/networks/":            userAccess,
+	"DELETE /v1/networks/{id}":      ownerAccess(entity.NetworkCollectionName),
+	"GET /v1/networks/{node}/shell": rootAccess,
+
+	// the init containers of the pods have no user token, the allocation is checked against the running pod
+	"POST /v1/ipam/allocations": podAccess,
+	"GET /v1/ipam/allocations":  guestAccess,
+
+	"POST /v1/storage/":       userAccess,
+	"GET /v1/storage/":        guestAccess,
+	"DELETE /v1/storage/{id}": ownerAccess(entity.StorageCollectionName),
+
+	"POST /v1/volume/":       userAccess,
+	"DELETE /v1/volume/{id}": ownerAccess(entity.VolumeCollectionName),
+	"GET /v1/volume/":        guestAccess,
+
+	"GET /v1/containers/logs/{namespace}/{pod}/{container}":      guestAccess,
+	"GET /v1/containers/logs/file/{namespace}/{pod}/{container}": guestAccess,
+
+	"POST /v1/pods/":                    userAccess,
+	"DELETE /v1/pods/{id}":              ownerAccess(entity.PodCollectionName),
+	"DELETE /v1/pods/{namespace}/{pod}": {role: entity.UserRole, owner: podOwner},
+	"GET /v1/pods/":                     guestAccess,
+	"GET /v1/pods/{id}":                 guestAccess,
+
+	"POST /v1/deployments/":              userAccess,
+	"DELETE /v1/deployments/{id}":        ownerAccess(entity.DeploymentCollectionName),
+	"GET /v1/deployments/":               guestAccess,
+	"GET /v1/deployments/{id}":           guestAccess,
+	"GET /v1/deployments/{id}/status":    guestAccess,
+	"POST /v1/deployments/upload/yaml":   userAccess,
+	"PUT /v1/deployments/autoscale":      {role: entity.UserRole, owner: autoscalerOwner},
+	"PUT /v1/deployments/{id}":           ownerAccess(entity.DeploymentCollectionName),
+	"GET /v1/deployments/{id}/revisions": guestAccess,
+	"POST /v1/deployments/{id}/rollback": ownerAccess(entity.DeploymentCollectionName),
+
+	"POST /v1/statefulsets/":       userAccess,
+	"DELETE /v1/statefulsets/{id}": ownerAccess(entity.StatefulSetCollectionName),
+	"GET /v1/statefulsets/":        guestAccess,
+	"GET /v1/statefulsets/{id}":    guestAccess,
+	"PUT /v1/statefulsets/{id}":    ownerAccess(entity.StatefulSetCollectionName),
+
+	"POST /v1/daemonsets/":       userAccess,
+	"DELETE /v1/daemonsets/{id}": ownerAccess(entity.DaemonSetCollectionName),
+	"GET /v1/daemonsets/":        guestAccess,
+	"GET /v1/daemonsets/{id}":    guestAccess,
+	"PUT /v1/daemonsets/{id}":    ownerAccess(entity.DaemonSetCollectionName),
+
+	"POST /v1/jobs/":           userAccess,
+	"DELETE /v1/jobs/{id}":     ownerAccess(entity.JobCollectionName),
+	"GET /v1/jobs/":            guestAccess,
+	"GET /v1/jobs/{id}":        guestAccess,
+	"GET /v1/jobs/{id}/status": guestAccess,
+
+	"POST /v1/cronjobs/":          userAccess,
+	"DELETE /v1/cronjobs/{id}":    ownerAccess(entity.CronJobCollectionName),
+	"GET /v1/cronjobs/":           guestAccess,
+	"GET /v1/cronjobs/{id}":       guestAccess,
+	"PUT /v1/cronjobs/{id}":       ownerAccess(entity.CronJobCollectionName),
+	"GET /v1/cronjobs/{id}/runs":  guestAccess,
+	"POST /v1/cronjobs/{id}/runs": ownerAccess(entity.CronJobCollectionName),
+
+	"POST /v1/apps/": userAccess,
+
+	"POST /v1/services/":            userAccess,
+	"DELETE /v1/services/{id}":      ownerAccess(entity.ServiceCollectionName),
+	"GET /v1/services/":             guestAccess,
+	"GET /v1/services/{id}":         guestAccess,
+	"POST /v1/services/upload/yaml": userAccess,
+
+	"POST /v1/namespaces/":            userAccess,
+	"DELETE /v1/namespaces/{id}":      ownerAccess(entity.NamespaceCollectionName),
+	"GET /v1/namespaces/":             guestAccess,
+	"GET /v1/namespaces/{id}":         guestAccess,
+	"POST /v1/namespaces/upload/yaml": userAccess,
+	"PUT /v1/namespaces/{id}/team":    rootAccess,
+	"PUT /v1/namespaces/{id}/quota":   rootAccess,
+	"GET /v1/namespaces/{id}/quota":   guestAccess,
+
+	"POST /v1/teams/":                        rootAccess,
+	"DELETE /v1/teams/{id}":                  rootAccess,
+	"GET /v1/teams/":                         guestAccess,
+	"GET /v1/teams/{id}":                     guestAccess,
+	"PUT /v1/teams/{id}/members":             userAccess,
+	"DELETE /v1/teams/{id}/members/{userID}": userAccess,
+
+	"POST /v1/configmaps/":            userAccess,
+	"DELETE /v1/configmaps/{id}":      ownerAccess(entity.ConfigMapCollectionName),
+	"GET /v1/configmaps/":             guestAccess,
+	"GET /v1/configmaps/{id}":         guestAccess,
+	"POST /v1/configmaps/upload/yaml": userAccess,
+
+	"POST /v1/secrets/":            userAccess,
+	"DELETE /v1/secrets/{id}":      ownerAccess(entity.SecretCollectionName),
+	"GET /v1/secrets/":             guestAccess,
+	"GET /v1/secrets/{id}":         guestAccess,
+	"POST /v1/secrets/upload/yaml": userAccess,
+
+	"GET /v1/monitoring/nodes":                    guestAccess,
+	"GET /v1/monitoring/nodes/{node}":             guestAccess,
+	"GET /v1/monitoring/nodes/{node}/nics":        guestAccess,
+	"GET /v1/monitoring/pods":                     guestAccess,
+	"GET /v1/monitoring/pods/{pod}":               guestAccess,
+	"GET /v1/monitoring/pods/{pod}/{container}":   guestAccess,
+	"GET /v1/monitoring/services":                 guestAccess,
+	"GET /v1/monitoring/services/{service}":       guestAccess,
+	"GET /v1/monitoring/controllers":              guestAccess,
+	"GET /v1/monitoring/controllers/{controller}": guestAccess,
+	"GET /v1/monitoring/metrics/{metric}":         guestAccess,
+
+	"GET /v1/ovs/portinfos": guestAccess,
+
+	"GET /v1/exec/pod/{namespace}/{pod}/shell/{container}": userAccess,
+
+	"GET /v1/audit/": rootAccess,
+}
+
+// namespacedCollection is the collection of the namespaced documents of a web service
+type namespacedCollection struct {
+	name string
+	// field is the document field of the namespace name, "namespace" if empty
+	field string
+}
+
+// namespacedCollections maps the root path of a web service to the collection
+// of its namespaced documents, which are looked up by the {id} path parameter
+var namespacedCollections = map[string]namespacedCollection{
+	"/v1/volume":       {name: entity.VolumeCollectionName},
+	"/v1/pods":         {name: entity.PodCollectionName},
+	"/v1/deployments":  {name: entity.DeploymentCollectionName},
+	"/v1/statefulsets": {name: entity.StatefulSetCollectionName},
+	"/v1/daemonsets":   {name: entity.DaemonSetCollectionName},
+	"/v1/jobs":         {name: entity.JobCollectionName},
+	"/v1/cronjobs":     {name: entity.CronJobCollectionName},
+	"/v1/services":     {name: entity.ServiceCollectionName},
+	"/v1/configmaps":   {name: entity.ConfigMapCollectionName},
+	"/v1/secrets":      {name: entity.SecretCollectionName},
+	"/v1/namespaces":   {name: entity.NamespaceCollectionName, field: "name"},
+}
+
+// namespacedServices are the root paths of the web services touching the namespaced resources.
+// The non-root users can only access the namespaces of their teams through them.
+var namespacedServices = []string{
+	"/v1/volume",
+	"/v1/pods",
+	"/v1/deployments",
+	"/v1/statefulsets",
+	"/v1/daemonsets",
+	"/v1/jobs",
+	"/v1/cronjobs",
+	"/v1/services",
+	"/v1/configmaps",
+	"/v1/secrets",
+	"/v1/namespaces",
+	"/v1/apps",
+	"/v1/containers",
+	"/v1/exec",
+}
+
+// namespaceCreationRoutes create the namespaces, they are the only write routes of the
+// namespaced web services which don't name a namespace the caller can already access
+var namespaceCreationRoutes = []string{
+	"POST /v1/namespaces/",
+	"POST /v1/namespaces/upload/yaml",
+}
+
+// secureService attaches the permission matrix to every route of the web service
+func secureService(sp *serviceprovider.Container, webService *restful.WebService) *restful.WebService {
+	return webService.Filter(authorizeRoute(sp))
+}
+
+// authorizeRoute looks up the permission of the selected route and runs the
+// filters it requires before passing the request on
+func authorizeRoute(sp *serviceprovider.Container) restful.FilterFunction {
+	return func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		key := req.Request.Method + " " + req.SelectedRoutePath()
+		p, ok := routePermissions[key]
+		if !ok {
+			permissionDenied(req, resp, "No permission is defined for "+key)
+			return
+		}
+
+		filters := p.filters(sp)
+		if len(filters) == 0 {
+			chain.ProcessFilter(req, resp)
+			return
+		}
+		routeChain := restful.FilterChain{
+			Filters: filters,
+			Target: func(req *restful.Request, resp *restful.Response) {
+				chain.ProcessFilter(req, resp)
+			},
+		}
+		routeChain.ProcessFilter(req, resp)
+	}
+}
+
+// filters returns the filters a request has to pass to satisfy the permission
+func (p permission) filters(sp *serviceprovider.Container) []restful.FilterFunction {
+	filters := []restful.FilterFunction{}
+	if p.serviceAccount {
+		return append(filters, validateServiceAccountMiddleware(sp))
+	}
+	switch p.role {
+	case "":
+		return filters
+	case entity.RootRole:
+		filters = append(filters, validateTokenMiddleware(sp), rootRole)
+	case entity.UserRole:
+		filters = append(filters, validateTokenMiddleware(sp), userRole)
+	default:
+		filters = append(filters, validateTokenMiddleware(sp), guestRole)
+	}
+
+	if p.collection != "" {
+		ownerField := p.ownerField
+		if ownerField == "" {
+			ownerField = "ownerID"
+		}
+		filters = append(filters, ownerOnly(sp, p.collection, ownerField))
+	}
+	if p.owner != nil {
+		filters = append(filters, namedOwnerOnly(sp, p.owner))
+	}
+	return append(filters, restrictNamespaces(sp))
+}
+
+// ownerOnly rejects non-root callers who don't own the document of the {id} path parameter.
+// The admins of the team owning the namespace of the document are allowed as well.
+func ownerOnly(sp *serviceprovider.Container, collection string, ownerField string) restful.FilterFunction {
+	return func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		if role, _ := req.Attribute("Role").(string); role == entity.RootRole {
+			chain.ProcessFilter(req, resp)
+			return
+		}
+
+		id := req.PathParameter("id")
+		if !bson.IsObjectIdHex(id) {
+			response.NotFound(req.Request, resp.ResponseWriter, mgo.ErrNotFound)
+			return
+		}
+
+		allowOwner(sp, req, resp, chain, collection, ownerField, bson.M{"_id": bson.ObjectIdHex(id)})
+	}
+}
+
+// namedOwnerOnly rejects non-root callers who don't own the document found by the resolver.
+// The admins of the team owning the namespace of the document are allowed as well.
+func namedOwnerOnly(sp *serviceprovider.Container, resolve ownerResolver) restful.FilterFunction {
+	return func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		if role, _ := req.Attribute("Role").(string); role == entity.RootRole {
+			chain.ProcessFilter(req, resp)
+			return
+		}
+
+		collection, selector, err := resolve(sp, req)
+		if err != nil {
+			switch {
+			case err == mgo.ErrNotFound || errors.IsNotFound(err):
+				response.NotFound(req.Request, resp.ResponseWriter, err)
+			case err == errUnresolvableOwner:
+				permissionDenied(req, resp, err.Error())
+			default:
+				response.BadRequest(req.Request, resp.ResponseWriter, err)
+			}
+			return
+		}
+		allowOwner(sp, req, resp, chain, collection, "ownerID", selector)
+	}
+}
+
+// allowOwner passes the request on if the caller owns the document of the selector
+// or is an admin of the team owning the namespace of the document
+func allowOwner(sp *serviceprovider.Container, req *restful.Request, resp *restful.Response, chain *restful.FilterChain, collection string, ownerField string, selector bson.M) {
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	fields := bson.M{ownerField: 1}
+	namespaceField, namespaced := namespaceFieldOf(collection)
+	if namespaced {
+		fields[namespaceField] = 1
+	}
+	document := bson.M{}
+	if err := session.C(collection).Find(selector).Select(fields).One(&document); err != nil {
+		switch err {
+		case mgo.ErrNotFound:
+			response.NotFound(req.Request, resp.ResponseWriter, err)
+		default:
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+		}
+		return
+	}
+
+	userID, _ := req.Attribute("UserID").(string)
+	if ownerID, ok := document[ownerField].(bson.ObjectId); ok && ownerID.Hex() == userID {
+		chain.ProcessFilter(req, resp)
+		return
+	}
+	if namespace, ok := document[namespaceField].(string); namespaced && ok && bson.IsObjectIdHex(userID) {
+		roles, err := backend.TeamNamespaces(session, bson.ObjectIdHex(userID))
+		if err != nil {
+			response.InternalServerError(req.Request, resp.ResponseWriter, err)
+			return
+		}
+		if roles[namespace] == entity.TeamAdminRole {
+			chain.ProcessFilter(req, resp)
+			return
+		}
+	}
+	permissionDenied(req, resp, "User "+userID+" is not the owner of the "+collection+" document")
+}
+
+// errUnresolvableOwner is returned by the resolvers if the object isn't owned by any document
+var errUnresolvableOwner = fmt.Errorf("The object isn't created by any user")
+
+// controllerCollections maps the kinds of the controllers to the collections of their documents
+var controllerCollections = map[string]string{
+	"Deployment":  entity.DeploymentCollectionName,
+	"StatefulSet": entity.StatefulSetCollectionName,
+	"DaemonSet":   entity.DaemonSetCollectionName,
+	"Job":         entity.JobCollectionName,
+	"CronJob":     entity.CronJobCollectionName,
+}
+
+// podOwner resolves the document owning the pod of the {namespace} and {pod} path parameters,
+// which is the pod document or the document of the controller of the pod
+func podOwner(sp *serviceprovider.Container, req *restful.Request) (string, bson.M, error) {
+	namespace := req.PathParameter("namespace")
+	name := req.PathParameter("pod")
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+	selector := bson.M{"name": name, "namespace": namespace}
+	n, err := session.C(entity.PodCollectionName).Find(selector).Count()
+	if err != nil {
+		return "", nil, err
+	}
+	if n > 0 {
+		return entity.PodCollectionName, selector, nil
+	}
+
+	pod, err := sp.KubeCtl.GetPod(name, namespace)
+	if err != nil {
+		return "", nil, err
+	}
+	owner := metav1.GetControllerOf(pod)
+	// the pods of a deployment are controlled by its replica sets and the jobs can be controlled by a cron job
+	for owner != nil && (owner.Kind == "ReplicaSet" || owner.Kind == "Job") {
+		var parent *metav1.OwnerReference
+		switch owner.Kind {
+		case "ReplicaSet":
+			replicaSet, err := sp.KubeCtl.GetReplicaSet(owner.Name, namespace)
+			if err != nil {
+				return "", nil, err
+			}
+			parent = metav1.GetControllerOf(replicaSet)
+		case "Job":
+			job, err := sp.KubeCtl.GetJob(owner.Name, namespace)
+			if err != nil {
+				return "", nil, err
+			}
+			parent = metav1.GetControllerOf(job)
+		}
+		if parent == nil {
+			break
+		}
+		owner = parent
+	}
+	if owner == nil {
+		return "", nil, errUnresolvableOwner
+	}
+	collection, ok := controllerCollections[owner.Kind]
+	if !ok {
+		return "", nil, errUnresolvableOwner
+	}
+	return collection, bson.M{"name": owner.Name, "namespace": namespace}, nil
+}
+
+// autoscalerOwner resolves the deployment document of the autoscaler in the request body
+func autoscalerOwner(sp *serviceprovider.Container, req *restful.Request) (string, bson.M, error) {
+	body, err := ioutil.ReadAll(req.Request.Body)
+	if err != nil {
+		return "", nil, err
+	}
+	// put the body back for the handler
+	req.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	autoscalerInfo := entity.AutoscalerInfo{}
+	if err := json.Unmarshal(body, &autoscalerInfo); err != nil {
+		return "", nil, err
+	}
+	return entity.DeploymentCollectionName, bson.M{"name": autoscalerInfo.ScaleTargetRefName, "namespace": autoscalerInfo.Namespace}, nil
+}
+
+// namespaceFieldOf returns the document field of the namespace name if the collection is namespaced
+func namespaceFieldOf(collection string) (string, bool) {
+	for _, c := range namespacedCollections {
+		if c.name != collection {
+			continue
+		}
+		if c.field == "" {
+			return "namespace", true
+		}
+		return c.field, true
+	}
+	return "", false
+}
+
+// restrictNamespaces rejects the requests touching the namespaces the caller can't access.
+// The namespace restricted API tokens are limited to their namespaces, and the non-root users
+// are limited to the namespaces of their teams on the namespaced web services, where the
+// viewers of a team can only read. The namespaces of a request come from the {namespace}
+// path parameter, the document of the {id} path parameter, every "namespace" field of the
+// JSON body and the uploaded YAML. A write request of a restricted API token or a team scoped
+// user has to name at least one namespace. The namespaces the caller can read are saved to the "ReadableNamespaces"
+// request attribute for the list handlers.
+func restrictNamespaces(sp *serviceprovider.Container) restful.FilterFunction {
+	return func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		tokenNamespaces, _ := req.Attribute("Namespaces").([]string)
+		role, _ := req.Attribute("Role").(string)
+		teamScoped := role != entity.RootRole && isNamespacedService(req.SelectedRoutePath())
+		if len(tokenNamespaces) == 0 && !teamScoped {
+			chain.ProcessFilter(req, resp)
+			return
+		}
+
+		namespaces, ok := requestNamespaces(sp, req, resp)
+		if !ok {
+			return
+		}
+
+		if req.Request.Method != http.MethodGet && len(namespaces) == 0 &&
+			(len(tokenNamespaces) > 0 || !utils.Contains(namespaceCreationRoutes, req.Request.Method+" "+req.SelectedRoutePath())) {
+			permissionDenied(req, resp, "The request isn't bound to a namespace")
+			return
+		}
+
+		readable := tokenNamespaces
+		if len(tokenNamespaces) > 0 {
+			for _, namespace := range namespaces {
+				if !utils.Contains(tokenNamespaces, namespace) {
+					permissionDenied(req, resp, "The namespace "+namespace+" is not allowed")
+					return
+				}
+			}
+		}
+
+		if teamScoped {
+			userID, _ := req.Attribute("UserID").(string)
+			roles := map[string]string{}
+			if bson.IsObjectIdHex(userID) {
+				session := sp.Mongo.NewSession()
+				teamRoles, err := backend.TeamNamespaces(session, bson.ObjectIdHex(userID))
+				session.Close()
+				if err != nil {
+					response.InternalServerError(req.Request, resp.ResponseWriter, err)
+					return
+				}
+				roles = teamRoles
+			}
+			for _, namespace := range namespaces {
+				teamRole, ok := roles[namespace]
+				if !ok {
+					permissionDenied(req, resp, "User "+userID+" is not a member of the team of namespace "+namespace)
+					return
+				}
+				if teamRole == entity.TeamViewerRole && req.Request.Method != http.MethodGet {
+					permissionDenied(req, resp, "User "+userID+" can only read namespace "+namespace)
+					return
+				}
+			}
+
+			teamNamespaces := []string{}
+			for namespace := range roles {
+				if len(tokenNamespaces) == 0 || utils.Contains(tokenNamespaces, namespace) {
+					teamNamespaces = append(teamNamespaces, namespace)
+				}
+			}
+			readable = teamNamespaces
+		}
+
+		req.SetAttribute("ReadableNamespaces", readable)
+		chain.ProcessFilter(req, resp)
+	}
+}
+
+// isNamespacedService reports whether the route belongs to a namespaced web service
+func isNamespacedService(routePath string) bool {
+	for _, root := range namespacedServices {
+		if routePath == root || strings.HasPrefix(routePath, root+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// requestNamespaces collects the namespaces touched by the request,
+// the error response is written if they can't be verified
+func requestNamespaces(sp *serviceprovider.Container, req *restful.Request, resp *restful.Response) ([]string, bool) {
+	namespaces := []string{}
+	if namespace := req.PathParameter("namespace"); namespace != "" {
+		namespaces = append(namespaces, namespace)
+	}
+
+	if id := req.PathParameter("id"); bson.IsObjectIdHex(id) {
+		for root, collection := range namespacedCollections {
+			if !strings.HasPrefix(req.SelectedRoutePath(), root+"/") {
+				continue
+			}
+			field := collection.field
+			if field == "" {
+				field = "namespace"
+			}
+			session := sp.Mongo.NewSession()
+			document := bson.M{}
+			err := session.C(collection.name).FindId(bson.ObjectIdHex(id)).Select(bson.M{field: 1}).One(&document)
+			session.Close()
+			if err != nil && err != mgo.ErrNotFound {
+				response.InternalServerError(req.Request, resp.ResponseWriter, err)
+				return nil, false
+			}
+			if namespace, ok := document[field].(string); ok {
+				namespaces = append(namespaces, namespace)
+			}
+		}
+	}
+
+	if req.Request.Body != nil && req.Request.Method != http.MethodGet {
+		body, err := ioutil.ReadAll(req.Request.Body)
+		if err != nil {
+			response.BadRequest(req.Request, resp.ResponseWriter, err)
+			return nil, false
+		}
+		// put the body back for the handler
+		req.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		if len(body) == 0 {
+			return namespaces, true
+		}
+
+		if mediaType, params, err := mime.ParseMediaType(req.Request.Header.Get("Content-Type")); err == nil && mediaType == "multipart/form-data" {
+			namespace, err := namespaceOfUpload(body, params["boundary"])
+			if err != nil {
+				permissionDenied(req, resp, "The namespace of the uploaded file can't be verified")
+				return nil, false
+			}
+			if namespace != "" {
+				namespaces = append(namespaces, namespace)
+			}
+			return namespaces, true
+		}
+
+		var content interface{}
+		if err := json.Unmarshal(body, &content); err != nil {
+			permissionDenied(req, resp, "The namespace of the request body can't be verified")
+			return nil, false
+		}
+		namespaces = append(namespaces, namespacesOf(content)...)
+	}
+	return namespaces, true
+}
+
+// namespaceOfUpload returns the namespace of the Kubernetes object in the uploaded YAML file.
+// A new namespace object doesn't belong to any namespace.
+func namespaceOfUpload(body []byte, boundary string) (string, error) {
+	form, err := multipart.NewReader(bytes.NewReader(body), boundary).ReadForm(_24K)
+	if err != nil {
+		return "", err
+	}
+	defer form.RemoveAll()
+	files := form.File["file"]
+	if len(files) == 0 {
+		return "", fmt.Errorf("No uploaded file")
+	}
+	file, err := files[0].Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		return "", err
+	}
+
+	obj, err := kubernetes.ParseK8SYAML(content)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := obj.(*v1.Namespace); ok {
+		return "", nil
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return "", err
+	}
+	if accessor.GetNamespace() == "" {
+		return "default", nil
+	}
+	return accessor.GetNamespace(), nil
+}
+
+// namespacesOf collects the values of every "namespace" field in the JSON document,
+// the keys are matched case-insensitively like encoding/json decodes them
+func namespacesOf(content interface{}) []string {
+	namespaces := []string{}
+	switch v := content.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if namespace, ok := value.(string); ok && strings.EqualFold(key, "namespace") {
+				namespaces = append(namespaces, namespace)
+				continue
+			}
+			namespaces = append(namespaces, namespacesOf(value)...)
+		}
+	case []interface{}:
+		for _, value := range v {
+			namespaces = append(namespaces, namespacesOf(value)...)
+		}
+	}
+	return namespaces
+}
+
+// namespaceSelector returns the selector of the documents in the namespaces the caller can read,
+// the field is the document field of the namespace name
+func namespaceSelector(req *restful.Request, field string) bson.M {
+	namespaces, ok := req.Attribute("ReadableNamespaces").([]string)
+	if !ok {
+		return bson.M{}
+	}
+	return bson.M{field: bson.M{"$in": namespaces}}
+}
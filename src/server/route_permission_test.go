@@ -0,0 +1,266 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/server/backend"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type RoutePermissionTestSuite struct {
+	suite.Suite
+	sp  *serviceprovider.Container
+	app App
+}
+
+func (suite *RoutePermissionTestSuite) SetupSuite() {
+	cf := config.MustRead("../../config/testing.json")
+	suite.sp = serviceprovider.NewForTesting(cf)
+	suite.app = App{Config: cf, ServiceProvider: suite.sp}
+}
+
+func (suite *RoutePermissionTestSuite) TearDownSuite() {}
+
+func TestRoutePermissionSuite(t *testing.T) {
+	suite.Run(t, new(RoutePermissionTestSuite))
+}
+
+func (suite *RoutePermissionTestSuite) bearer(role string) string {
+	user := entity.User{
+		ID:          bson.NewObjectId(),
+		DisplayName: role,
+		Role:        role,
+		LoginCredential: entity.LoginCredential{
+			Username: role + "@linkernetworks.com",
+		},
+	}
+	userSession := entity.Session{
+		ID:        bson.NewObjectId(),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	session := suite.sp.Mongo.NewSession()
+	defer session.Close()
+	// the role is read from the user of the session
+	suite.NoError(session.Insert(entity.UserCollectionName, &user))
+	suite.NoError(backend.CreateSession(session, userSession))
+
+	token, err := suite.sp.JWT.GenerateToken(user.ID.Hex(), user, userSession.ID.Hex())
+	suite.NoError(err)
+	return "Bearer " + token
+}
+
+func (suite *RoutePermissionTestSuite) TestEveryRouteHasPermission() {
+	services := []*restful.WebService{
+		newVersionService(suite.sp),
+		newRegistryService(suite.sp),
+		newUserService(suite.sp),
+		newNetworkService(suite.sp),
+		newIPAMService(suite.sp),
+		newStorageService(suite.sp),
+		newVolumeService(suite.sp),
+		newContainerService(suite.sp),
+		newPodService(suite.sp),
+		newDeploymentService(suite.sp),
+		newStatefulSetService(suite.sp),
+		newDaemonSetService(suite.sp),
+		newJobService(suite.sp),
+		newCronJobService(suite.sp),
+		newServiceService(suite.sp),
+		newNamespaceService(suite.sp),
+		newTeamService(suite.sp),
+		newConfigMapService(suite.sp),
+		newSecretService(suite.sp),
+		newMonitoringService(suite.sp),
+		newAppService(suite.sp),
+		newOVSService(suite.sp),
+		newShellService(suite.sp),
+		newAuditService(suite.sp),
+	}
+	for _, service := range services {
+		for _, route := range service.Routes() {
+			_, ok := routePermissions[route.Method+" "+route.Path]
+			suite.True(ok, "missing permission of %s %s", route.Method, route.Path)
+		}
+	}
+}
+
+func (suite *RoutePermissionTestSuite) TestGuestCannotWrite() {
+	router := suite.app.AppRoute()
+
+	httpRequest, err := http.NewRequest("POST", "http://localhost:7890/v1/networks", strings.NewReader("{}"))
+	suite.NoError(err)
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", suite.bearer(entity.GuestRole))
+	httpWriter := httptest.NewRecorder()
+	router.ServeHTTP(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusForbidden, httpWriter)
+}
+
+func (suite *RoutePermissionTestSuite) TestUserCannotManageUsers() {
+	router := suite.app.AppRoute()
+
+	httpRequest, err := http.NewRequest("GET", "http://localhost:7890/v1/users", nil)
+	suite.NoError(err)
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", suite.bearer(entity.UserRole))
+	httpWriter := httptest.NewRecorder()
+	router.ServeHTTP(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusForbidden, httpWriter)
+}
+
+func (suite *RoutePermissionTestSuite) TestRoleClaimIsIgnored() {
+	router := suite.app.AppRoute()
+
+	// a token claiming the root role of a user session
+	user := entity.User{ID: bson.NewObjectId(), Role: entity.UserRole}
+	session := suite.sp.Mongo.NewSession()
+	defer session.Close()
+	suite.NoError(session.Insert(entity.UserCollectionName, &user))
+	userSession := entity.Session{
+		ID:        bson.NewObjectId(),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	suite.NoError(backend.CreateSession(session, userSession))
+	user.Role = entity.RootRole
+	token, err := suite.sp.JWT.GenerateToken(user.ID.Hex(), user, userSession.ID.Hex())
+	suite.NoError(err)
+
+	httpRequest, err := http.NewRequest("GET", "http://localhost:7890/v1/users", nil)
+	suite.NoError(err)
+	httpRequest.Header.Add("Authorization", "Bearer "+token)
+	httpWriter := httptest.NewRecorder()
+	router.ServeHTTP(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusForbidden, httpWriter)
+}
+
+func (suite *RoutePermissionTestSuite) TestMonitoringRequiresToken() {
+	router := suite.app.AppRoute()
+
+	httpRequest, err := http.NewRequest("GET", "http://localhost:7890/v1/monitoring/nodes", nil)
+	suite.NoError(err)
+	httpWriter := httptest.NewRecorder()
+	router.ServeHTTP(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusUnauthorized, httpWriter)
+}
+
+func (suite *RoutePermissionTestSuite) TestNamespacesOf() {
+	var content interface{}
+	err := json.Unmarshal([]byte(`{
+		"deployment": {"name": "web", "namespace": "ci"},
+		"service": {"name": "web", "namespace": "staging", "ports": [{"port": 80}]},
+		"volumes": [{"namespace": "ci"}],
+		"configMap": {"Namespace": "prod"}
+	}`), &content)
+	suite.NoError(err)
+	suite.ElementsMatch([]string{"ci", "staging", "ci", "prod"}, namespacesOf(content))
+}
+
+func (suite *RoutePermissionTestSuite) TestTeamUserWriteRequiresNamespace() {
+	router := suite.app.AppRoute()
+
+	httpRequest, err := http.NewRequest("POST", "http://localhost:7890/v1/configmaps/", strings.NewReader(`{"name": "web"}`))
+	suite.NoError(err)
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Authorization", suite.bearer(entity.UserRole))
+	httpWriter := httptest.NewRecorder()
+	router.ServeHTTP(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusForbidden, httpWriter)
+}
+
+func (suite *RoutePermissionTestSuite) TestIsNamespacedService() {
+	suite.True(isNamespacedService("/v1/deployments/{id}"))
+	suite.True(isNamespacedService("/v1/exec/pod/{namespace}/{pod}/shell/{container}"))
+	suite.False(isNamespacedService("/v1/networks/"))
+	suite.False(isNamespacedService("/v1/podsx/"))
+}
+
+func (suite *RoutePermissionTestSuite) TestNamespaceOfUpload() {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "configmap.yaml")
+	suite.NoError(err)
+	part.Write([]byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web\n  namespace: ci\n"))
+	suite.NoError(writer.Close())
+
+	namespace, err := namespaceOfUpload(body.Bytes(), writer.Boundary())
+	suite.NoError(err)
+	suite.Equal("ci", namespace)
+
+	_, err = namespaceOfUpload([]byte("not a form"), writer.Boundary())
+	suite.Error(err)
+}
+
+func (suite *RoutePermissionTestSuite) TestUserCannotDeleteOthersPodByName() {
+	router := suite.app.AppRoute()
+
+	pod := entity.Pod{
+		ID:        bson.NewObjectId(),
+		OwnerID:   bson.NewObjectId(),
+		Name:      namesgenerator.GetRandomName(0),
+		Namespace: "default",
+	}
+	session := suite.sp.Mongo.NewSession()
+	defer session.Close()
+	suite.NoError(session.Insert(entity.PodCollectionName, pod))
+	defer session.Remove(entity.PodCollectionName, "_id", pod.ID)
+
+	httpRequest, err := http.NewRequest("DELETE", "http://localhost:7890/v1/pods/default/"+pod.Name, nil)
+	suite.NoError(err)
+	httpRequest.Header.Add("Authorization", suite.bearer(entity.UserRole))
+	httpWriter := httptest.NewRecorder()
+	router.ServeHTTP(httpWriter, httpRequest)
+	assertResponseCode(suite.T(), http.StatusForbidden, httpWriter)
+}
+
+func (suite *RoutePermissionTestSuite) TestPodOwner() {
+	isController := true
+	replicaSet := appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: namesgenerator.GetRandomName(0),
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "Deployment", Name: "web", Controller: &isController},
+			},
+		},
+	}
+	_, err := suite.sp.KubeCtl.Clientset.AppsV1().ReplicaSets("default").Create(&replicaSet)
+	suite.NoError(err)
+	defer suite.sp.KubeCtl.Clientset.AppsV1().ReplicaSets("default").Delete(replicaSet.Name, &metav1.DeleteOptions{})
+
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: replicaSet.Name + "-abcde",
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "ReplicaSet", Name: replicaSet.Name, Controller: &isController},
+			},
+		},
+	}
+	_, err = suite.sp.KubeCtl.CreatePod(&pod, "default")
+	suite.NoError(err)
+	defer suite.sp.KubeCtl.DeletePod(pod.Name, "default")
+
+	req := restful.NewRequest(httptest.NewRequest("DELETE", "/v1/pods/default/"+pod.Name, nil))
+	req.PathParameters()["namespace"] = "default"
+	req.PathParameters()["pod"] = pod.Name
+	collection, selector, err := podOwner(suite.sp, req)
+	suite.NoError(err)
+	suite.Equal(entity.DeploymentCollectionName, collection)
+	suite.Equal(bson.M{"name": "web", "namespace": "default"}, selector)
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http/httptest"
+
+	restful "github.com/emicklei/go-restful"
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/stretchr/testify/suite"
+)
+
+// ServerTestSuite is the fixture of the suites requesting the secure services as the test user
+type ServerTestSuite struct {
+	suite.Suite
+	sp        *serviceprovider.Container
+	wc        *restful.Container
+	session   *mongo.Session
+	JWTBearer string
+}
+
+// setupServices adds the secure services and the user service to the container and signs in as the test user
+func (suite *ServerTestSuite) setupServices(newServices ...func(*serviceprovider.Container) *restful.WebService) {
+	cf := config.MustRead("../../config/testing.json")
+	sp := serviceprovider.NewForTesting(cf)
+
+	suite.sp = sp
+	// init session
+	suite.session = sp.Mongo.NewSession()
+	// init restful container
+	suite.wc = restful.NewContainer()
+	for _, newService := range append(newServices, newUserService) {
+		suite.wc.Add(secureService(suite.sp, newService(suite.sp)))
+	}
+
+	token, _ := loginGetToken(suite.wc)
+	suite.NotEmpty(token)
+	suite.JWTBearer = "Bearer " + token
+}
+
+// request requests as the test user
+func (suite *ServerTestSuite) request(method string, path string, body interface{}) *httptest.ResponseRecorder {
+	return suite.requestAs(method, path, suite.JWTBearer, body)
+}
+
+// requestAs requests with the bearer token, the request has no token if the bearer is empty
+func (suite *ServerTestSuite) requestAs(method string, path string, bearer string, body interface{}) *httptest.ResponseRecorder {
+	httpWriter, err := dispatchRequest(suite.wc, method, path, bearer, body)
+	suite.NoError(err)
+	return httpWriter
+}
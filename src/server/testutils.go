@@ -8,19 +8,25 @@ import (
 
 	restful "github.com/emicklei/go-restful"
 	"github.com/linkernetworks/vortex/src/entity"
-	response "github.com/linkernetworks/vortex/src/net/http"
 )
 
 func loginGetToken(wc *restful.Container) (string, error) {
-	var resp response.ActionResponse
-	userCred := entity.LoginCredential{
+	tokens, err := signInGetTokens(wc, entity.LoginCredential{
 		Username: "test@linkernetworks.com",
 		Password: "test",
+	})
+	if err != nil {
+		return "", err
 	}
+	return tokens.AccessToken, nil
+}
+
+func signInGetTokens(wc *restful.Container, userCred entity.LoginCredential) (entity.TokenResponse, error) {
+	var resp entity.TokenResponse
 
 	bodyBytes, err := json.MarshalIndent(userCred, "", "  ")
 	if err != nil {
-		return "", err
+		return resp, err
 	}
 
 	bodyReader := strings.NewReader(string(bodyBytes))
@@ -30,7 +36,7 @@ func loginGetToken(wc *restful.Container) (string, error) {
 		bodyReader,
 	)
 	if err != nil {
-		return "", err
+		return resp, err
 	}
 
 	httpRequest.Header.Add("Content-Type", "application/json")
@@ -38,8 +44,35 @@ func loginGetToken(wc *restful.Container) (string, error) {
 	wc.Dispatch(httpWriter, httpRequest)
 
 	decoder := json.NewDecoder(httpWriter.Body)
-	if err := decoder.Decode(&resp); err != nil {
-		return "", err
+	err = decoder.Decode(&resp)
+	return resp, err
+}
+
+// dispatchRequest dispatches the JSON request to the container, the string body is sent as it is
+// and the other body is encoded to JSON. The request has no token if the bearer is empty.
+func dispatchRequest(wc *restful.Container, method, path, bearer string, body interface{}) (*httptest.ResponseRecorder, error) {
+	bodyReader := strings.NewReader("")
+	switch b := body.(type) {
+	case nil:
+	case string:
+		bodyReader = strings.NewReader(b)
+	default:
+		bodyBytes, err := json.MarshalIndent(b, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		bodyReader = strings.NewReader(string(bodyBytes))
+	}
+
+	httpRequest, err := http.NewRequest(method, "http://localhost:7890"+path, bodyReader)
+	if err != nil {
+		return nil, err
 	}
-	return string(resp.Message), nil
+	httpRequest.Header.Add("Content-Type", "application/json")
+	if bearer != "" {
+		httpRequest.Header.Add("Authorization", bearer)
+	}
+	httpWriter := httptest.NewRecorder()
+	wc.Dispatch(httpWriter, httpRequest)
+	return httpWriter, nil
 }
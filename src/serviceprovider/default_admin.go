@@ -1,24 +1,58 @@
 package serviceprovider
 
 import (
+	"os"
+
 	"github.com/linkernetworks/mongo"
 	"github.com/linkernetworks/utils/timeutils"
+	"github.com/linkernetworks/vortex/src/config"
 	"github.com/linkernetworks/vortex/src/entity"
 	"github.com/linkernetworks/vortex/src/utils"
+	mgo "gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 )
 
-func createDefaultUser(mongoService *mongo.Service) error {
+const (
+	defaultAdminUsername = "admin@vortex.com"
+	defaultAdminPassword = "password"
+)
+
+// adminCredential resolves the bootstrap admin credential from the defaults,
+// the auth config and the VORTEX_ADMIN_USERNAME/VORTEX_ADMIN_PASSWORD environment variables
+func adminCredential(cf *config.AuthConfig) entity.LoginCredential {
+	credential := entity.LoginCredential{
+		Username: defaultAdminUsername,
+		Password: defaultAdminPassword,
+	}
+	if cf != nil && cf.Admin != nil {
+		if cf.Admin.Username != "" {
+			credential.Username = cf.Admin.Username
+		}
+		if cf.Admin.Password != "" {
+			credential.Password = cf.Admin.Password
+		}
+	}
+	if username := os.Getenv("VORTEX_ADMIN_USERNAME"); username != "" {
+		credential.Username = username
+	}
+	if password := os.Getenv("VORTEX_ADMIN_PASSWORD"); password != "" {
+		credential.Password = password
+	}
+	return credential
+}
+
+func createDefaultUser(mongoService *mongo.Service, cf *config.AuthConfig) error {
 	session := mongoService.NewSession()
 	defer session.Close()
-	hashedPassword, err := utils.HashPassword("password")
+	credential := adminCredential(cf)
+	hashedPassword, err := utils.HashPassword(credential.Password)
 	if err != nil {
 		return err
 	}
 	user := entity.User{
 		ID: bson.NewObjectId(),
 		LoginCredential: entity.LoginCredential{
-			Username: "admin@vortex.com",
+			Username: credential.Username,
 			Password: hashedPassword,
 		},
 		DisplayName: "administrator",
@@ -26,7 +60,9 @@ func createDefaultUser(mongoService *mongo.Service) error {
 		FirstName:   "administrator",
 		LastName:    "administrator",
 		PhoneNumber: "09521111111",
-		CreatedAt:   timeutils.Now(),
+		// the well-known default password has to be changed on the first login
+		MustChangePassword: credential.Password == defaultAdminPassword,
+		CreatedAt:          timeutils.Now(),
 	}
 	q := bson.M{"loginCredential.username": user.LoginCredential.Username}
 
@@ -35,7 +71,32 @@ func createDefaultUser(mongoService *mongo.Service) error {
 		return err
 	} else if count > 0 {
 		// admin user has already exists. Do not insert
-		return nil
+		return flagDefaultPassword(session, credential.Username, defaultAdminUsername)
+	}
+	if err := session.Insert(entity.UserCollectionName, &user); err != nil {
+		return err
+	}
+	// the admin of the earlier installs may still have the default password
+	return flagDefaultPassword(session, defaultAdminUsername)
+}
+
+// flagDefaultPassword requires the existing users who still use the default password to change it,
+// the admins created before the password change was required are flagged at the next start
+func flagDefaultPassword(session *mongo.Session, usernames ...string) error {
+	for _, username := range usernames {
+		user := entity.User{}
+		if err := session.FindOne(entity.UserCollectionName, bson.M{"loginCredential.username": username}, &user); err != nil {
+			if err == mgo.ErrNotFound {
+				continue
+			}
+			return err
+		}
+		if user.MustChangePassword || !utils.CheckPasswordHash(defaultAdminPassword, user.LoginCredential.Password) {
+			continue
+		}
+		if err := session.C(entity.UserCollectionName).UpdateId(user.ID, bson.M{"$set": bson.M{"mustChangePassword": true}}); err != nil {
+			return err
+		}
 	}
-	return session.Insert(entity.UserCollectionName, &user)
+	return nil
 }
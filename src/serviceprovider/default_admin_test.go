@@ -1,12 +1,15 @@
 package serviceprovider
 
 import (
+	"os"
 	"testing"
 
 	"github.com/linkernetworks/mongo"
 	"github.com/linkernetworks/vortex/src/config"
 	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/utils"
 	"github.com/stretchr/testify/suite"
+	"gopkg.in/mgo.v2/bson"
 )
 
 type CreateDefaultUserSuite struct {
@@ -33,6 +36,52 @@ func TestCreateDefaultUserSuite(t *testing.T) {
 }
 
 func (suite *CreateDefaultUserSuite) TestDefaultUserCreate() {
-	err := createDefaultUser(suite.service)
+	err := createDefaultUser(suite.service, nil)
 	suite.NoError(err)
+
+	user := entity.User{}
+	err = suite.session.FindOne(entity.UserCollectionName, bson.M{"loginCredential.username": "admin@vortex.com"}, &user)
+	suite.NoError(err)
+	suite.True(user.MustChangePassword)
+}
+
+func (suite *CreateDefaultUserSuite) TestFlagExistingDefaultPassword() {
+	// the admin created by an earlier install, which didn't require the password change
+	hashedPassword, err := utils.HashPassword("password")
+	suite.NoError(err)
+	admin := entity.User{
+		ID: bson.NewObjectId(),
+		LoginCredential: entity.LoginCredential{
+			Username: "admin@vortex.com",
+			Password: hashedPassword,
+		},
+		Role: "root",
+	}
+	suite.session.Remove(entity.UserCollectionName, "loginCredential.username", "admin@vortex.com")
+	suite.NoError(suite.session.Insert(entity.UserCollectionName, &admin))
+
+	err = createDefaultUser(suite.service, nil)
+	suite.NoError(err)
+
+	user := entity.User{}
+	err = suite.session.FindOne(entity.UserCollectionName, bson.M{"_id": admin.ID}, &user)
+	suite.NoError(err)
+	suite.True(user.MustChangePassword)
+}
+
+func (suite *CreateDefaultUserSuite) TestAdminCredential() {
+	credential := adminCredential(nil)
+	suite.Equal("admin@vortex.com", credential.Username)
+	suite.Equal("password", credential.Password)
+
+	cf := &config.AuthConfig{Admin: &config.AdminConfig{Username: "root@vortex.com", Password: "s3cret"}}
+	credential = adminCredential(cf)
+	suite.Equal("root@vortex.com", credential.Username)
+	suite.Equal("s3cret", credential.Password)
+
+	os.Setenv("VORTEX_ADMIN_PASSWORD", "fromenv")
+	defer os.Unsetenv("VORTEX_ADMIN_PASSWORD")
+	credential = adminCredential(cf)
+	suite.Equal("root@vortex.com", credential.Username)
+	suite.Equal("fromenv", credential.Password)
 }
@@ -6,7 +6,10 @@ import (
 	"path/filepath"
 
 	"github.com/linkernetworks/logger"
+	"github.com/linkernetworks/vortex/src/cache"
 	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/jwtprovider"
+	"github.com/linkernetworks/vortex/src/mailprovider"
 	"github.com/linkernetworks/vortex/src/prometheusprovider"
 
 	"github.com/linkernetworks/mongo"
@@ -25,8 +28,12 @@ type Container struct {
 	ClusterConfig *rest.Config
 	Mongo         *mongo.Service
 	Prometheus    *prometheusprovider.Service
+	JWT           *jwtprovider.Service
+	SessionCache  *cache.TTLCache
 	KubeCtl       *kubeCtl.KubeCtl
 	Validator     *validator.Validate
+	// Mailer is nil if SMTP isn't configured
+	Mailer mailprovider.Sender
 }
 
 // ServiceDiscoverResponse is the structure for Service Discover Response
@@ -48,6 +55,11 @@ func New(cf config.Config) *Container {
 	logger.Infof("Connecting to prometheus: %s", cf.Prometheus.URL)
 	prometheus := prometheusprovider.New(cf.Prometheus.URL)
 
+	jwt, err := jwtprovider.New(cf.JWT)
+	if err != nil {
+		panic(fmt.Errorf("Load the jwt config fail: %v", err))
+	}
+
 	kubeconfig := filepath.Join(os.Getenv("HOME"), ".kube", "config")
 	k8s, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
 	if err != nil {
@@ -59,20 +71,19 @@ func New(cf config.Config) *Container {
 
 	clientset := kubernetes.NewForConfigOrDie(k8s)
 
-	validate := validator.New()
-	// Register validation for kubernetes name
-	validate.RegisterValidation("k8sname", checkNameValidation)
-
 	sp := &Container{
 		Config:        cf,
 		ClusterConfig: k8s,
 		Mongo:         mongo,
 		Prometheus:    prometheus,
+		JWT:           jwt,
+		SessionCache:  cache.New(jwt.SessionCacheTTL),
 		KubeCtl:       kubeCtl.New(clientset),
-		Validator:     validate,
+		Validator:     newValidator(),
+		Mailer:        mailprovider.New(cf.SMTP),
 	}
 
-	if err := createDefaultUser(sp.Mongo); err != nil {
+	if err := createDefaultUser(sp.Mongo, cf.Auth); err != nil {
 		// ignore insert error
 		logger.Infof("Create Default admin user failed: %v", err)
 	}
@@ -90,18 +101,22 @@ func NewForTesting(cf config.Config) *Container {
 	logger.Infof("Connecting to prometheus: %s", cf.Prometheus.URL)
 	prometheus := prometheusprovider.New(cf.Prometheus.URL)
 
-	clientset := fakeclientset.NewSimpleClientset()
+	jwt, err := jwtprovider.New(cf.JWT)
+	if err != nil {
+		panic(fmt.Errorf("Load the jwt config fail: %v", err))
+	}
 
-	validate := validator.New()
-	// Register validation for kubernetes name
-	validate.RegisterValidation("k8sname", checkNameValidation)
+	clientset := fakeclientset.NewSimpleClientset()
 
 	sp := &Container{
-		Config:     cf,
-		Mongo:      mongo,
-		Prometheus: prometheus,
-		KubeCtl:    kubeCtl.New(clientset),
-		Validator:  validate,
+		Config:       cf,
+		Mongo:        mongo,
+		Prometheus:   prometheus,
+		JWT:          jwt,
+		SessionCache: cache.New(jwt.SessionCacheTTL),
+		KubeCtl:      kubeCtl.New(clientset),
+		Validator:    newValidator(),
+		Mailer:       mailprovider.New(cf.SMTP),
 	}
 
 	return sp
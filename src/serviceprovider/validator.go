@@ -1,11 +1,163 @@
 package serviceprovider
 
 import (
-	"gopkg.in/go-playground/validator.v9"
+	"net"
 	"regexp"
+
+	"github.com/linkernetworks/vortex/src/entity"
+	"gopkg.in/go-playground/validator.v9"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
+// newValidator returns the validator with the custom validations of the entities
+func newValidator() *validator.Validate {
+	validate := validator.New()
+	// Register validation for kubernetes name
+	validate.RegisterValidation("k8sname", checkNameValidation)
+	// Register validation for kubernetes resource quantity
+	validate.RegisterValidation("k8squantity", checkQuantityValidation)
+	// Register validation for the Prometheus metric name
+	validate.RegisterValidation("promname", checkPrometheusNameValidation)
+	// Register validation for the only handler of the container probe
+	validate.RegisterStructValidation(checkProbeValidation, entity.Probe{})
+	// Register validation for the resource limits of the container
+	validate.RegisterStructValidation(checkContainerValidation, entity.Container{})
+	// Register validation for the only source of the environment variable
+	validate.RegisterStructValidation(checkEnvVarSourceValidation, entity.EnvVarSource{})
+	// Register validation for the static address of the deployment network
+	validate.RegisterStructValidation(checkDeploymentNetworkValidation, entity.DeploymentNetwork{})
+	// Register validation for the addresses in the subnet
+	validate.RegisterStructValidation(checkSubnetValidation, entity.Subnet{})
+	// Register validation for the metrics of the autoscaler
+	validate.RegisterStructValidation(checkAutoscalerValidation, entity.AutoscalerInfo{})
+	validate.RegisterStructValidation(checkAutoscalerMetricValidation, entity.AutoscalerMetric{})
+	return validate
+}
+
 func checkNameValidation(fl validator.FieldLevel) bool {
 	re := regexp.MustCompile(`[a-z0-9]([-a-z0-9]*[a-z0-9])`)
 	return re.MatchString(fl.Field().String())
 }
+
+func checkQuantityValidation(fl validator.FieldLevel) bool {
+	_, err := resource.ParseQuantity(fl.Field().String())
+	return err == nil
+}
+
+var promNameRegexp = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+func checkPrometheusNameValidation(fl validator.FieldLevel) bool {
+	return promNameRegexp.MatchString(fl.Field().String())
+}
+
+func checkProbeValidation(sl validator.StructLevel) {
+	probe := sl.Current().Interface().(entity.Probe)
+	handlers := 0
+	if probe.HTTPGet != nil {
+		handlers++
+	}
+	if probe.TCPSocket != nil {
+		handlers++
+	}
+	if probe.Exec != nil {
+		handlers++
+	}
+	if handlers != 1 {
+		sl.ReportError(probe.HTTPGet, "HTTPGet", "httpGet", "probehandler", "")
+	}
+}
+
+func checkContainerValidation(sl validator.StructLevel) {
+	container := sl.Current().Interface().(entity.Container)
+	resources := []struct {
+		request int
+		limit   int
+		field   string
+	}{
+		{container.ResourceRequestCPU, container.ResourceLimitCPU, "ResourceLimitCPU"},
+		{container.ResourceRequestMemory, container.ResourceLimitMemory, "ResourceLimitMemory"},
+		{container.ResourceRequestEphemeralStorage, container.ResourceLimitEphemeralStorage, "ResourceLimitEphemeralStorage"},
+	}
+	for _, r := range resources {
+		// the limit can't be less than the request
+		if r.limit != 0 && r.limit < r.request {
+			sl.ReportError(r.limit, r.field, r.field, "gtefield", "")
+		}
+	}
+}
+
+func checkEnvVarSourceValidation(sl validator.StructLevel) {
+	source := sl.Current().Interface().(entity.EnvVarSource)
+	if (source.SecretKeyRef == nil) == (source.ConfigMapKeyRef == nil) {
+		sl.ReportError(source.SecretKeyRef, "SecretKeyRef", "secretKeyRef", "envvarsource", "")
+	}
+}
+
+func checkDeploymentNetworkValidation(sl validator.StructLevel) {
+	network := sl.Current().Interface().(entity.DeploymentNetwork)
+	// the static address needs its netmask
+	if (network.IPAddress == "") != (network.Netmask == "") {
+		sl.ReportError(network.Netmask, "Netmask", "netmask", "staticaddress", "")
+	}
+}
+
+func checkSubnetValidation(sl validator.StructLevel) {
+	subnet := sl.Current().Interface().(entity.Subnet)
+	_, ipnet, err := net.ParseCIDR(subnet.CIDR)
+	if err != nil {
+		// reported by the cidrv4 tag
+		return
+	}
+	addresses := []struct {
+		value string
+		field string
+	}{
+		{subnet.Gateway, "Gateway"},
+		{subnet.RangeStart, "RangeStart"},
+		{subnet.RangeEnd, "RangeEnd"},
+	}
+	for _, address := range subnet.Reserved {
+		addresses = append(addresses, struct {
+			value string
+			field string
+		}{address, "Reserved"})
+	}
+	for _, a := range addresses {
+		if ip := net.ParseIP(a.value); ip != nil && !ipnet.Contains(ip) {
+			sl.ReportError(a.value, a.field, a.field, "insubnet", "")
+		}
+	}
+
+	start, end := net.ParseIP(subnet.RangeStart).To4(), net.ParseIP(subnet.RangeEnd).To4()
+	if start != nil && end != nil && string(start) > string(end) {
+		sl.ReportError(subnet.RangeEnd, "RangeEnd", "rangeEnd", "gtefield", "")
+	}
+}
+
+func checkAutoscalerValidation(sl validator.StructLevel) {
+	autoscaler := sl.Current().Interface().(entity.AutoscalerInfo)
+	// the autoscaler needs at least one metric
+	if autoscaler.ResourceName == "" && len(autoscaler.Metrics) == 0 {
+		sl.ReportError(autoscaler.Metrics, "Metrics", "metrics", "autoscalermetric", "")
+	}
+	if autoscaler.ResourceName != "" && autoscaler.TargetAverageUtilization <= 0 {
+		sl.ReportError(autoscaler.TargetAverageUtilization, "TargetAverageUtilization", "targetAverageUtilization", "required_with", "ResourceName")
+	}
+}
+
+func checkAutoscalerMetricValidation(sl validator.StructLevel) {
+	metric := sl.Current().Interface().(entity.AutoscalerMetric)
+	switch metric.Type {
+	case entity.AutoscalerPodsMetric:
+		if metric.TargetAverageValue == "" {
+			sl.ReportError(metric.TargetAverageValue, "TargetAverageValue", "targetAverageValue", "required", "")
+		}
+	case entity.AutoscalerObjectMetric:
+		if metric.Target == nil {
+			sl.ReportError(metric.Target, "Target", "target", "required", "")
+		}
+		if metric.TargetValue == "" {
+			sl.ReportError(metric.TargetValue, "TargetValue", "targetValue", "required", "")
+		}
+	}
+}
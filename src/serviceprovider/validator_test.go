@@ -3,16 +3,11 @@ package serviceprovider
 import (
 	"testing"
 
+	"github.com/linkernetworks/vortex/src/entity"
 	"github.com/stretchr/testify/assert"
-	"gopkg.in/go-playground/validator.v9"
 )
 
-var validate *validator.Validate
-
-func init() {
-	validate = validator.New()
-	validate.RegisterValidation("k8sname", checkNameValidation)
-}
+var validate = newValidator()
 
 func TestCheckNameValidation(t *testing.T) {
 	name := "awesome"
@@ -29,3 +24,202 @@ func TestCheckNameValidationFail(t *testing.T) {
 	err := validate.Var(name, "required,k8sname")
 	assert.Error(t, err)
 }
+
+func TestCheckQuantityValidation(t *testing.T) {
+	for _, quantity := range []string{"4", "500m", "8Gi", "1.5"} {
+		assert.NoError(t, validate.Var(quantity, "k8squantity"))
+	}
+	assert.Error(t, validate.Var("eight gigabytes", "k8squantity"))
+}
+
+func TestCheckProbeValidation(t *testing.T) {
+	probes := []entity.Probe{
+		{HTTPGet: &entity.HTTPGetProbe{Path: "/healthz", Port: 8080}},
+		{TCPSocket: &entity.TCPSocketProbe{Port: 6379}, InitialDelaySeconds: 5, PeriodSeconds: 10},
+		{Exec: &entity.ExecProbe{Command: []string{"cat", "/tmp/healthy"}}},
+	}
+	for _, probe := range probes {
+		container := entity.Container{Name: "awesome", Image: "busybox", Command: []string{"sleep", "3600"}, LivenessProbe: &probe}
+		assert.NoError(t, validate.Struct(container))
+	}
+	// the probe is optional
+	assert.NoError(t, validate.Struct(entity.Container{Name: "awesome", Image: "busybox", Command: []string{"sleep", "3600"}}))
+}
+
+func TestCheckProbeValidationFail(t *testing.T) {
+	probes := []entity.Probe{
+		// no handler
+		{PeriodSeconds: 10},
+		// more than one handler
+		{HTTPGet: &entity.HTTPGetProbe{Path: "/", Port: 80}, TCPSocket: &entity.TCPSocketProbe{Port: 80}},
+		{HTTPGet: &entity.HTTPGetProbe{Path: "/", Port: 80, Scheme: "FTP"}},
+		{HTTPGet: &entity.HTTPGetProbe{Port: 80}},
+		{TCPSocket: &entity.TCPSocketProbe{Port: 70000}},
+		{Exec: &entity.ExecProbe{Command: []string{}}},
+		{Exec: &entity.ExecProbe{Command: []string{"true"}}, FailureThreshold: -1},
+	}
+	for _, probe := range probes {
+		container := entity.Container{Name: "awesome", Image: "busybox", Command: []string{"sleep", "3600"}, ReadinessProbe: &probe}
+		assert.Error(t, validate.Struct(container))
+	}
+}
+
+func TestCheckContainerValidation(t *testing.T) {
+	containers := []entity.Container{
+		{ResourceRequestCPU: 100, ResourceLimitCPU: 200},
+		{ResourceRequestMemory: 128, ResourceLimitMemory: 128},
+		// only the limit
+		{ResourceLimitEphemeralStorage: 1024},
+		// only the request
+		{ResourceRequestEphemeralStorage: 1024},
+	}
+	for _, container := range containers {
+		container.Name, container.Image, container.Command = "awesome", "busybox", []string{"sleep", "3600"}
+		assert.NoError(t, validate.Struct(container))
+	}
+
+	containers = []entity.Container{
+		{ResourceRequestCPU: 200, ResourceLimitCPU: 100},
+		{ResourceRequestMemory: 256, ResourceLimitMemory: 128},
+		{ResourceRequestEphemeralStorage: 2048, ResourceLimitEphemeralStorage: 1024},
+		{ResourceLimitMemory: -1},
+		{Ports: []entity.ContainerPort{{ContainerPort: 0}}},
+		{Ports: []entity.ContainerPort{{ContainerPort: 53, Protocol: "SCTP"}}},
+		{ImagePullPolicy: "Sometimes"},
+	}
+	for _, container := range containers {
+		container.Name, container.Image, container.Command = "awesome", "busybox", []string{"sleep", "3600"}
+		assert.Error(t, validate.Struct(container))
+	}
+}
+
+func TestCheckEnvVarSourceValidation(t *testing.T) {
+	container := entity.Container{Name: "awesome", Image: "busybox", Command: []string{"sleep", "3600"}}
+	container.EnvVarsFrom = []entity.EnvVarSource{
+		{Name: "PASSWORD", SecretKeyRef: &entity.KeySelector{Name: "database", Key: "password"}},
+		{Name: "MODE", ConfigMapKeyRef: &entity.KeySelector{Name: "settings", Key: "mode"}},
+	}
+	assert.NoError(t, validate.Struct(container))
+
+	for _, source := range []entity.EnvVarSource{
+		{Name: "PASSWORD"},
+		{Name: "PASSWORD", SecretKeyRef: &entity.KeySelector{Name: "database", Key: "password"}, ConfigMapKeyRef: &entity.KeySelector{Name: "settings", Key: "mode"}},
+		{Name: "PASSWORD", SecretKeyRef: &entity.KeySelector{Name: "database"}},
+		{SecretKeyRef: &entity.KeySelector{Name: "database", Key: "password"}},
+	} {
+		container.EnvVarsFrom = []entity.EnvVarSource{source}
+		assert.Error(t, validate.Struct(container))
+	}
+}
+
+func TestCheckDeploymentNetworkValidation(t *testing.T) {
+	network := entity.DeploymentNetwork{
+		Name:       "awesome",
+		IfName:     "eth1",
+		RoutesGw:   []entity.DeploymentRouteGw{},
+		RoutesIntf: []entity.DeploymentRouteIntf{},
+	}
+	// the address is allocated
+	assert.NoError(t, validate.Struct(network))
+
+	network.IPAddress = "10.1.0.2"
+	network.Netmask = "255.255.255.0"
+	assert.NoError(t, validate.Struct(network))
+
+	network.Netmask = ""
+	assert.Error(t, validate.Struct(network))
+}
+
+func TestCheckSubnetValidation(t *testing.T) {
+	subnet := entity.Subnet{
+		CIDR:       "10.1.0.0/24",
+		Gateway:    "10.1.0.1",
+		RangeStart: "10.1.0.10",
+		RangeEnd:   "10.1.0.200",
+		Reserved:   []string{"10.1.0.100"},
+	}
+	assert.NoError(t, validate.Struct(subnet))
+	assert.NoError(t, validate.Struct(entity.Subnet{CIDR: "10.1.0.0/24"}))
+
+	for _, s := range []entity.Subnet{
+		{CIDR: "10.1.0.0"},
+		{CIDR: "10.1.0.0/24", Gateway: "10.2.0.1"},
+		{CIDR: "10.1.0.0/24", RangeStart: "10.1.1.10"},
+		{CIDR: "10.1.0.0/24", RangeStart: "10.1.0.200", RangeEnd: "10.1.0.10"},
+		{CIDR: "10.1.0.0/24", Reserved: []string{"10.1.0.100", "10.2.0.100"}},
+	} {
+		assert.Error(t, validate.Struct(s))
+	}
+}
+
+func TestCheckAutoscalerValidation(t *testing.T) {
+	autoscaler := entity.AutoscalerInfo{
+		Name:                     "awesome",
+		Namespace:                "default",
+		ScaleTargetRefName:       "awesome",
+		ResourceName:             "cpu",
+		MinReplicas:              1,
+		MaxReplicas:              5,
+		TargetAverageUtilization: 50,
+	}
+	assert.NoError(t, validate.Struct(autoscaler))
+
+	autoscaler.ResourceName = ""
+	autoscaler.TargetAverageUtilization = 0
+	autoscaler.Metrics = []entity.AutoscalerMetric{
+		{Type: entity.AutoscalerPodsMetric, MetricName: "container_network_receive_packets", TargetAverageValue: "10k"},
+		{Type: entity.AutoscalerObjectMetric, MetricName: "requests", Target: &entity.AutoscalerObjectReference{Kind: "Service", Name: "awesome"}, TargetValue: "500"},
+	}
+	assert.NoError(t, validate.Struct(autoscaler))
+
+	for _, metrics := range [][]entity.AutoscalerMetric{
+		// no metric
+		{},
+		{{Type: "External", MetricName: "requests", TargetValue: "500"}},
+		{{Type: entity.AutoscalerPodsMetric, MetricName: "container_network_receive_packets"}},
+		{{Type: entity.AutoscalerPodsMetric, MetricName: `up"} or vector(1)`, TargetAverageValue: "10"}},
+		{{Type: entity.AutoscalerObjectMetric, MetricName: "requests", TargetValue: "500"}},
+		{{Type: entity.AutoscalerObjectMetric, MetricName: "requests", Target: &entity.AutoscalerObjectReference{Kind: "Service", Name: "awesome"}}},
+	} {
+		autoscaler.Metrics = metrics
+		assert.Error(t, validate.Struct(autoscaler))
+	}
+
+	// the resource metric needs its target
+	autoscaler.Metrics = nil
+	autoscaler.ResourceName = "memory"
+	assert.Error(t, validate.Struct(autoscaler))
+}
+
+func TestCheckAutoscalerBehaviorValidation(t *testing.T) {
+	window := int32(300)
+	autoscaler := entity.AutoscalerInfo{
+		Name:                     "awesome",
+		Namespace:                "default",
+		ScaleTargetRefName:       "awesome",
+		ResourceName:             "cpu",
+		MinReplicas:              1,
+		MaxReplicas:              5,
+		TargetAverageUtilization: 50,
+		Behavior: &entity.AutoscalerBehavior{
+			ScaleDown: &entity.AutoscalerScalingRules{
+				StabilizationWindowSeconds: &window,
+				Policies: []entity.AutoscalerScalingPolicy{
+					{Type: "Percent", Value: 10, PeriodSeconds: 60},
+				},
+			},
+		},
+	}
+	assert.NoError(t, validate.Struct(autoscaler))
+
+	autoscaler.Behavior.ScaleDown.Policies[0].PeriodSeconds = 3600
+	assert.Error(t, validate.Struct(autoscaler))
+
+	autoscaler.Behavior.ScaleDown.Policies[0].PeriodSeconds = 60
+	autoscaler.Behavior.ScaleDown.SelectPolicy = "Avg"
+	assert.Error(t, validate.Struct(autoscaler))
+
+	autoscaler.Behavior.ScaleDown.SelectPolicy = ""
+	autoscaler.MaxReplicas = 0
+	assert.Error(t, validate.Struct(autoscaler))
+}
@@ -0,0 +1,241 @@
+package statefulset
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+
+	"github.com/linkernetworks/mongo"
+	"github.com/linkernetworks/vortex/src/deployment"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/ipam"
+	"github.com/linkernetworks/vortex/src/kubeutils"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// VolumeClaimNamePrefix will set prefix of the volume claim templates,
+// the PVC of each replica is named <prefix>-<claim>-<statefulset>-<ordinal>
+const VolumeClaimNamePrefix = "claim"
+
+// CheckStatefulSetParameter will check the parameters of the StatefulSet
+func CheckStatefulSetParameter(sp *serviceprovider.Container, statefulSet *entity.StatefulSet) error {
+	template := statefulSet.PodTemplate()
+	if err := deployment.CheckPodParameter(sp, &template); err != nil {
+		return err
+	}
+
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	// the replicas get the following addresses of the static addresses, which must stay in the subnet
+	// and out of the addresses allocated by IPAM
+	if deployment.HasStaticAddress(&template) {
+		for _, v := range statefulSet.Networks {
+			if v.IPAddress == "" {
+				continue
+			}
+			last, err := kubeutils.OrdinalAddress(v.IPAddress, statefulSet.Replicas-1)
+			if err != nil {
+				return err
+			}
+			mask := net.IPMask(net.ParseIP(v.Netmask).To4())
+			if !net.ParseIP(v.IPAddress).Mask(mask).Equal(net.ParseIP(last).Mask(mask)) || ipToUint32(last) < ipToUint32(v.IPAddress) {
+				return fmt.Errorf("the address %s of the last replica on the network %s is out of the subnet", last, v.Name)
+			}
+
+			network := entity.Network{}
+			if err := session.FindOne(entity.NetworkCollectionName, bson.M{"name": v.Name}, &network); err != nil {
+				return fmt.Errorf("load the network %s error:%v", v.Name, err)
+			}
+			for i := range network.Subnets {
+				first, end := ipam.AddressRange(&network.Subnets[i])
+				if ipToUint32(v.IPAddress) <= end && ipToUint32(last) >= first {
+					return fmt.Errorf("the addresses %s - %s of the replicas on the network %s overlap the allocatable addresses of the subnet %s", v.IPAddress, last, v.Name, network.Subnets[i].CIDR)
+				}
+			}
+		}
+	}
+
+	names := map[string]bool{}
+	for _, v := range statefulSet.VolumeClaims {
+		if names[v.Name] {
+			return fmt.Errorf("the volume claim name %s is duplicated", v.Name)
+		}
+		names[v.Name] = true
+
+		if err := session.FindOne(entity.StorageCollectionName, bson.M{"name": v.StorageName}, &entity.Storage{}); err == mgo.ErrNotFound {
+			return fmt.Errorf("the storage named %s doesn't exist", v.StorageName)
+		} else if err != nil {
+			return fmt.Errorf("check the storage name error:%v", err)
+		}
+	}
+	return nil
+}
+
+func ipToUint32(ip string) uint32 {
+	return binary.BigEndian.Uint32(net.ParseIP(ip).To4())
+}
+
+// generateVolumeClaims generates the volume claim templates and their mounts
+func generateVolumeClaims(session *mongo.Session, statefulSet *entity.StatefulSet) ([]corev1.PersistentVolumeClaim, []corev1.VolumeMount, error) {
+	claims := []corev1.PersistentVolumeClaim{}
+	volumeMounts := []corev1.VolumeMount{}
+
+	for _, v := range statefulSet.VolumeClaims {
+		storage := entity.Storage{}
+		if err := session.FindOne(entity.StorageCollectionName, bson.M{"name": v.StorageName}, &storage); err != nil {
+			return nil, nil, fmt.Errorf("Get the storage object error:%v", err)
+		}
+		capacity, err := resource.ParseQuantity(v.Capacity)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Invalid capacity %s: %v", v.Capacity, err)
+		}
+
+		name := fmt.Sprintf("%s-%s", VolumeClaimNamePrefix, v.Name)
+		claims = append(claims, corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{v.AccessMode},
+				Resources: corev1.ResourceRequirements{
+					Requests: map[corev1.ResourceName]resource.Quantity{
+						corev1.ResourceStorage: capacity,
+					},
+				},
+				StorageClassName: &storage.StorageClassName,
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      name,
+			MountPath: v.MountPath,
+		})
+	}
+	return claims, volumeMounts, nil
+}
+
+// generateService generates the headless service giving the replicas their stable DNS names,
+// <statefulset>-<ordinal>.<statefulset>.<namespace>.svc
+func generateService(statefulSet *entity.StatefulSet) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   statefulSet.Name,
+			Labels: statefulSet.Labels,
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Selector: map[string]string{
+				deployment.DefaultLabel: statefulSet.Name,
+			},
+			PublishNotReadyAddresses: true,
+		},
+	}
+}
+
+// generateStatefulSet generates the kubernetes StatefulSet of the StatefulSet
+func generateStatefulSet(session *mongo.Session, statefulSet *entity.StatefulSet, ipamURL string) (*appsv1.StatefulSet, error) {
+	podTemplate := statefulSet.PodTemplate()
+	template, err := deployment.GeneratePodTemplate(session, &podTemplate, ipamURL, true)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, claimMounts, err := generateVolumeClaims(session, statefulSet)
+	if err != nil {
+		return nil, err
+	}
+	for i := range template.Spec.Containers {
+		template.Spec.Containers[i].VolumeMounts = append(template.Spec.Containers[i].VolumeMounts, claimMounts...)
+	}
+
+	policy := appsv1.OrderedReadyPodManagement
+	if statefulSet.PodManagementPolicy == entity.StatefulSetParallelPolicy {
+		policy = appsv1.ParallelPodManagement
+	}
+
+	p := appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   statefulSet.Name,
+			Labels: statefulSet.Labels,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					// vortex default label
+					deployment.DefaultLabel: statefulSet.Name,
+				},
+			},
+			Replicas:             &statefulSet.Replicas,
+			Template:             template,
+			VolumeClaimTemplates: claims,
+			ServiceName:          statefulSet.Name,
+			PodManagementPolicy:  policy,
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type: appsv1.RollingUpdateStatefulSetStrategyType,
+			},
+		},
+	}
+	return &p, nil
+}
+
+// CreateStatefulSet will create the headless service and the StatefulSet
+func CreateStatefulSet(sp *serviceprovider.Container, statefulSet *entity.StatefulSet) error {
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	p, err := generateStatefulSet(session, statefulSet, ipam.ServerURL(sp.Config.IPAM))
+	if err != nil {
+		return err
+	}
+	if _, err := sp.KubeCtl.CreateService(generateService(statefulSet), statefulSet.Namespace); err != nil {
+		return err
+	}
+	if _, err := sp.KubeCtl.CreateStatefulSet(p, statefulSet.Namespace); err != nil {
+		sp.KubeCtl.DeleteService(statefulSet.Name, statefulSet.Namespace)
+		return err
+	}
+	return nil
+}
+
+// UpdateStatefulSet will update the replicas and the pod template of the running StatefulSet,
+// the pods are replaced in the reverse order of their ordinals
+func UpdateStatefulSet(sp *serviceprovider.Container, statefulSet *entity.StatefulSet) error {
+	session := sp.Mongo.NewSession()
+	defer session.Close()
+
+	p, err := generateStatefulSet(session, statefulSet, ipam.ServerURL(sp.Config.IPAM))
+	if err != nil {
+		return err
+	}
+	current, err := sp.KubeCtl.GetStatefulSet(statefulSet.Name, statefulSet.Namespace)
+	if err != nil {
+		return err
+	}
+
+	// the selector, the volume claim templates and the pod management policy are immutable
+	current.Labels = p.Labels
+	current.Spec.Replicas = p.Spec.Replicas
+	current.Spec.Template = p.Spec.Template
+	_, err = sp.KubeCtl.UpdateStatefulSet(current, statefulSet.Namespace)
+	return err
+}
+
+// DeleteStatefulSet will delete the StatefulSet and its headless service, the PVCs of the replicas are kept
+func DeleteStatefulSet(sp *serviceprovider.Container, statefulSet *entity.StatefulSet) error {
+	if err := sp.KubeCtl.DeleteStatefulSet(statefulSet.Name, statefulSet.Namespace); err != nil {
+		return err
+	}
+	if err := sp.KubeCtl.DeleteService(statefulSet.Name, statefulSet.Namespace); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
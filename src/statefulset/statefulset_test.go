@@ -0,0 +1,195 @@
+package statefulset
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/linkernetworks/vortex/src/config"
+	"github.com/linkernetworks/vortex/src/deployment"
+	"github.com/linkernetworks/vortex/src/entity"
+	"github.com/linkernetworks/vortex/src/ipam"
+	"github.com/linkernetworks/vortex/src/serviceprovider"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"github.com/stretchr/testify/suite"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func init() {
+	rand.Seed(time.Now().UnixNano())
+}
+
+type StatefulSetTestSuite struct {
+	suite.Suite
+	sp      *serviceprovider.Container
+	storage entity.Storage
+}
+
+func (suite *StatefulSetTestSuite) SetupSuite() {
+	cf := config.MustRead("../../config/testing.json")
+	suite.sp = serviceprovider.NewForTesting(cf)
+
+	suite.storage = entity.Storage{
+		ID:               bson.NewObjectId(),
+		Type:             entity.NFSStorageType,
+		Name:             namesgenerator.GetRandomName(0),
+		StorageClassName: namesgenerator.GetRandomName(0),
+	}
+	session := suite.sp.Mongo.NewSession()
+	defer session.Close()
+	session.Insert(entity.StorageCollectionName, suite.storage)
+}
+
+func (suite *StatefulSetTestSuite) TearDownSuite() {
+	session := suite.sp.Mongo.NewSession()
+	defer session.Close()
+	session.Remove(entity.StorageCollectionName, "_id", suite.storage.ID)
+}
+
+func TestStatefulSetSuite(t *testing.T) {
+	suite.Run(t, new(StatefulSetTestSuite))
+}
+
+func (suite *StatefulSetTestSuite) newStatefulSet() *entity.StatefulSet {
+	return &entity.StatefulSet{
+		ID:        bson.NewObjectId(),
+		Name:      namesgenerator.GetRandomName(0),
+		Namespace: "default",
+		Containers: []entity.Container{
+			{
+				Name:    namesgenerator.GetRandomName(0),
+				Image:   "busybox",
+				Command: []string{"sleep", "3600"},
+			},
+		},
+		NetworkType: entity.DeploymentClusterNetwork,
+		Replicas:    3,
+		VolumeClaims: []entity.StatefulSetVolumeClaim{
+			{
+				Name:        "data",
+				StorageName: suite.storage.Name,
+				AccessMode:  corev1.ReadWriteOnce,
+				Capacity:    "1Gi",
+				MountPath:   "/var/lib/data",
+			},
+		},
+	}
+}
+
+func (suite *StatefulSetTestSuite) TestCheckStatefulSetParameter() {
+	statefulSet := suite.newStatefulSet()
+	suite.NoError(CheckStatefulSetParameter(suite.sp, statefulSet))
+
+	statefulSet.VolumeClaims = append(statefulSet.VolumeClaims, statefulSet.VolumeClaims[0])
+	suite.Error(CheckStatefulSetParameter(suite.sp, statefulSet))
+
+	statefulSet.VolumeClaims = []entity.StatefulSetVolumeClaim{{Name: "data", StorageName: namesgenerator.GetRandomName(0)}}
+	suite.Error(CheckStatefulSetParameter(suite.sp, statefulSet))
+}
+
+func (suite *StatefulSetTestSuite) TestCheckStatefulSetParameterOrdinalAddress() {
+	network := entity.Network{
+		ID:         bson.NewObjectId(),
+		Name:       namesgenerator.GetRandomName(0),
+		BridgeName: namesgenerator.GetRandomName(0),
+	}
+	session := suite.sp.Mongo.NewSession()
+	defer session.Close()
+	session.Insert(entity.NetworkCollectionName, network)
+	defer session.Remove(entity.NetworkCollectionName, "name", network.Name)
+
+	statefulSet := suite.newStatefulSet()
+	statefulSet.NetworkType = entity.DeploymentCustomNetwork
+	statefulSet.Networks = []entity.DeploymentNetwork{
+		{
+			Name:      network.Name,
+			IfName:    "eth1",
+			IPAddress: "10.1.0.252",
+			Netmask:   "255.255.255.0",
+		},
+	}
+	// 10.1.0.252 - 10.1.0.254
+	suite.NoError(CheckStatefulSetParameter(suite.sp, statefulSet))
+	// 10.1.0.252 - 10.1.1.0
+	statefulSet.Replicas = 5
+	suite.Error(CheckStatefulSetParameter(suite.sp, statefulSet))
+
+	// 10.1.0.252 - 10.1.0.254 overlap the addresses allocated by IPAM
+	statefulSet.Replicas = 3
+	session.Update(entity.NetworkCollectionName, bson.M{"name": network.Name}, bson.M{"$set": bson.M{
+		"subnets": []entity.Subnet{{CIDR: "10.1.0.0/24", RangeStart: "10.1.0.100", RangeEnd: "10.1.0.253"}},
+	}})
+	suite.Error(CheckStatefulSetParameter(suite.sp, statefulSet))
+	session.Update(entity.NetworkCollectionName, bson.M{"name": network.Name}, bson.M{"$set": bson.M{
+		"subnets": []entity.Subnet{{CIDR: "10.1.0.0/24", RangeStart: "10.1.0.100", RangeEnd: "10.1.0.200"}},
+	}})
+	suite.NoError(CheckStatefulSetParameter(suite.sp, statefulSet))
+}
+
+func (suite *StatefulSetTestSuite) TestGenerateStatefulSet() {
+	session := suite.sp.Mongo.NewSession()
+	defer session.Close()
+
+	statefulSet := suite.newStatefulSet()
+	statefulSet.PodManagementPolicy = entity.StatefulSetParallelPolicy
+	p, err := generateStatefulSet(session, statefulSet, ipam.DefaultServerURL)
+	suite.NoError(err)
+	suite.Equal(statefulSet.Name, p.Spec.ServiceName)
+	suite.Equal(int32(3), *p.Spec.Replicas)
+	suite.Equal(appsv1.ParallelPodManagement, p.Spec.PodManagementPolicy)
+	suite.Equal(statefulSet.Name, p.Spec.Template.Labels[deployment.DefaultLabel])
+
+	suite.Len(p.Spec.VolumeClaimTemplates, 1)
+	claim := p.Spec.VolumeClaimTemplates[0]
+	suite.Equal("claim-data", claim.Name)
+	suite.Equal(suite.storage.StorageClassName, *claim.Spec.StorageClassName)
+	suite.Contains(p.Spec.Template.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{Name: "claim-data", MountPath: "/var/lib/data"})
+
+	statefulSet.VolumeClaims[0].StorageName = namesgenerator.GetRandomName(0)
+	_, err = generateStatefulSet(session, statefulSet, ipam.DefaultServerURL)
+	suite.Error(err)
+}
+
+func (suite *StatefulSetTestSuite) TestGenerateService() {
+	statefulSet := suite.newStatefulSet()
+	service := generateService(statefulSet)
+	suite.Equal(statefulSet.Name, service.Name)
+	suite.Equal(corev1.ClusterIPNone, service.Spec.ClusterIP)
+	suite.Equal(statefulSet.Name, service.Spec.Selector[deployment.DefaultLabel])
+}
+
+func (suite *StatefulSetTestSuite) TestCreateStatefulSet() {
+	statefulSet := suite.newStatefulSet()
+	err := CreateStatefulSet(suite.sp, statefulSet)
+	suite.NoError(err)
+
+	service, err := suite.sp.KubeCtl.GetService(statefulSet.Name, statefulSet.Namespace)
+	suite.NoError(err)
+	suite.Equal(corev1.ClusterIPNone, service.Spec.ClusterIP)
+
+	statefulSet.Replicas = 5
+	err = UpdateStatefulSet(suite.sp, statefulSet)
+	suite.NoError(err)
+	current, err := suite.sp.KubeCtl.GetStatefulSet(statefulSet.Name, statefulSet.Namespace)
+	suite.NoError(err)
+	suite.Equal(int32(5), *current.Spec.Replicas)
+
+	err = DeleteStatefulSet(suite.sp, statefulSet)
+	suite.NoError(err)
+	_, err = suite.sp.KubeCtl.GetService(statefulSet.Name, statefulSet.Namespace)
+	suite.Error(err)
+}
+
+func (suite *StatefulSetTestSuite) TestCreateStatefulSetFail() {
+	statefulSet := suite.newStatefulSet()
+	statefulSet.NetworkType = "unknown"
+	err := CreateStatefulSet(suite.sp, statefulSet)
+	suite.Error(err)
+
+	_, err = suite.sp.KubeCtl.GetService(statefulSet.Name, statefulSet.Namespace)
+	suite.Error(err)
+}
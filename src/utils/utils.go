@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -13,3 +14,22 @@ func SHA256String(str string) string {
 	md := hash.Sum(nil)
 	return fmt.Sprintf("%s", hex.EncodeToString(md))
 }
+
+// RandomToken returns a hex encoded string of n cryptographically random bytes
+func RandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Contains reports whether the string is in the list
+func Contains(list []string, str string) bool {
+	for _, s := range list {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
@@ -10,3 +10,19 @@ func TestSHA256String(t *testing.T) {
 	o := SHA256String("12345678")
 	assert.Equal(t, "ef797c8118f02dfb649607dd5d3f8c7623048c9c063d532cc95c5ed7a898a64f", o)
 }
+
+func TestRandomToken(t *testing.T) {
+	token, err := RandomToken(32)
+	assert.NoError(t, err)
+	assert.Len(t, token, 64)
+
+	another, err := RandomToken(32)
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, another)
+}
+
+func TestContains(t *testing.T) {
+	assert.True(t, Contains([]string{"default", "vortex"}, "vortex"))
+	assert.False(t, Contains([]string{"default", "vortex"}, "kube-system"))
+	assert.False(t, Contains(nil, "default"))
+}